@@ -7,10 +7,12 @@ toolchain go1.23.11
 require (
 	github.com/gin-contrib/cors v1.7.6
 	github.com/gin-gonic/gin v1.10.1
+	github.com/lib/pq v1.10.9
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/viper v1.20.1
 	github.com/stretchr/testify v1.10.0
 	go.uber.org/fx v1.24.0
+	golang.org/x/sync v0.15.0
 )
 
 require (
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/app/listing"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	listingService listing.Service
+	now            func() time.Time
+}
+
+func NewAdminHandler(listingService listing.Service) *AdminHandler {
+	return &AdminHandler{
+		listingService: listingService,
+		now:            time.Now,
+	}
+}
+
+func (h *AdminHandler) GetDepositAnomalies(c *gin.Context) {
+	anomalies, err := h.listingService.GetDepositAnomalies(c.Request.Context())
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get deposit anomalies")
+		return
+	}
+	c.JSON(http.StatusOK, anomalies)
+}
+
+func (h *AdminHandler) Recompute(c *gin.Context) {
+	updated, err := h.listingService.RecomputeDerivedFields(c.Request.Context())
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		logError(c, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute listings", "updated": updated})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// PurgeDeleted permanently removes the listings deleted more than olderThan
+// days ago, responding with how many were purged
+func (h *AdminHandler) PurgeDeleted(c *gin.Context) {
+	if !checkQueryParams(c, purgeQueryParams) {
+		return
+	}
+	days, err := strconv.Atoi(c.Query("olderThan"))
+	now := h.now()
+	before := now.AddDate(0, 0, -days)
+	if err != nil || days < 0 || before.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid olderThan parameter, must be a non-negative number of days"})
+		return
+	}
+	purged, err := h.listingService.PurgeDeletedListings(c.Request.Context(), before)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		logError(c, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge deleted listings", "purged": purged})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
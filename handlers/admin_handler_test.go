@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/app/listing"
+	"github.com/getground/interview-backend-golang/internal/pkg/config"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAdminTestRouter(handler *AdminHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api/v1")
+	{
+		admin := api.Group("/admin")
+		{
+			admin.POST("/recompute", handler.Recompute)
+			admin.GET("/deposit-anomalies", handler.GetDepositAnomalies)
+			admin.POST("/purge-deleted", handler.PurgeDeleted)
+		}
+	}
+
+	return router
+}
+
+func TestAdminHandler_Recompute(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "successful recompute",
+			mockSetup: func(service *MockListingService) {
+				service.On("RecomputeDerivedFields", mock.Anything).Return(3, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"updated": 3},
+		},
+		{
+			name: "update fails part way",
+			mockSetup: func(service *MockListingService) {
+				service.On("RecomputeDerivedFields", mock.Anything).Return(1, errors.New("update failed"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: map[string]interface{}{
+				"error":   "Failed to recompute listings",
+				"updated": 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewAdminHandler(mockService)
+			router := setupAdminTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/recompute", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_GetDepositAnomalies(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "flagged listings",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetDepositAnomalies", mock.Anything).Return([]models.DepositAnomaly{
+					{ID: 80, PriceInCents: 23456700, EstimatedDepositInCents: 18798136, DepositRatio: 0.8, Reason: models.DepositAnomalyAboveMaximum},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []models.DepositAnomaly{
+				{ID: 80, PriceInCents: 23456700, EstimatedDepositInCents: 18798136, DepositRatio: 0.8, Reason: models.DepositAnomalyAboveMaximum},
+			},
+		},
+		{
+			name: "service error",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetDepositAnomalies", mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   map[string]interface{}{"error": "Failed to get deposit anomalies"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewAdminHandler(mockService)
+			router := setupAdminTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/deposit-anomalies", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_PurgeDeleted(t *testing.T) {
+	repo := models.NewListingRepository()
+	require.NoError(t, repo.Delete(context.Background(), 187))
+	handler := NewAdminHandler(listing.NewService(repo, &config.Config{}, nil, nil, nil))
+	// Run the handler 40 days on, so the deletion is 40 days old
+	handler.now = func() time.Time { return time.Now().AddDate(0, 0, 40) }
+	router := setupAdminTestRouter(handler)
+	purge := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/purge-deleted"+query, nil))
+		return w
+	}
+
+	w := purge("?olderThan=60")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"purged":0}`, w.Body.String())
+	deleted, err := repo.GetDeleted(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, deleted, 1)
+
+	w = purge("?olderThan=30")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"purged":1}`, w.Body.String())
+	deleted, err = repo.GetDeleted(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+	assert.ErrorIs(t, repo.Restore(context.Background(), 187), models.ErrNotFound)
+
+	for _, query := range []string{"", "?olderThan=abc", "?olderThan=-1", "?olderThan=30&force=true"} {
+		assert.Equal(t, http.StatusBadRequest, purge(query).Code, query)
+	}
+}
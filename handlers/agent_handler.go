@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getground/interview-backend-golang/internal/app/agent"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type AgentHandler struct {
+	service agent.Service
+}
+
+func NewAgentHandler(service agent.Service) *AgentHandler {
+	return &AgentHandler{
+		service: service,
+	}
+}
+
+type CreateAgentRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"required"`
+}
+
+func (h *AgentHandler) CreateAgent(c *gin.Context) {
+	var req CreateAgentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	agent, err := h.service.CreateAgent(c.Request.Context(), req.Name, req.Email)
+	if err != nil {
+		if writeDomainError(c, err, "agent") {
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to create agent")
+		return
+	}
+	c.JSON(http.StatusCreated, agent)
+}
+
+func (h *AgentHandler) GetAgentByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	agent, err := h.service.GetAgentByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get agent")
+		return
+	}
+	c.JSON(http.StatusOK, agent)
+}
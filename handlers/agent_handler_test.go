@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/internal/app/agent"
+	"github.com/getground/interview-backend-golang/internal/app/listing"
+	"github.com/getground/interview-backend-golang/internal/pkg/config"
+	"github.com/getground/interview-backend-golang/internal/pkg/middleware"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAgentTestRouter(handler *AgentHandler, listingHandler *ListingHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AgentIdentity())
+
+	api := router.Group("/api/v1")
+	{
+		api.POST("/listings/", listingHandler.CreateListing)
+
+		agents := api.Group("/agents")
+		{
+			agents.POST("/", handler.CreateAgent)
+			agents.GET("/:id", handler.GetAgentByID)
+			agents.GET("/:id/listings", listingHandler.GetAgentListings)
+		}
+	}
+
+	return router
+}
+
+func TestAgentHandler(t *testing.T) {
+	agents := models.NewAgentRepository()
+	router := setupAgentTestRouter(
+		NewAgentHandler(agent.NewService(agents)),
+		NewListingHandler(listing.NewService(models.NewListingRepository(), &config.Config{}, nil, agents, nil)),
+	)
+	send := func(method, path, body string, agentID int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if agentID != 0 {
+			req.Header.Set(middleware.AgentIDHeader, fmt.Sprint(agentID))
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/api/v1/agents/", `{"name":"Jane Smith","email":"jane@example.com"}`, 0)
+	require.Equal(t, http.StatusCreated, resp.Code)
+	var registered models.Agent
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &registered))
+	assert.NotZero(t, registered.ID)
+	agentPath := fmt.Sprintf("/api/v1/agents/%d", registered.ID)
+
+	resp = send(http.MethodGet, agentPath, "", 0)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"id":%d,"name":"Jane Smith","email":"jane@example.com"}`, registered.ID), resp.Body.String())
+
+	// The registered agent can own listings
+	resp = send(http.MethodPost, "/api/v1/listings/", `{"propertyType":"apartment","priceInCents":10000000,"addressDetails":{"city":"London","shortenedPostcode":"N1"}}`, registered.ID)
+	require.Equal(t, http.StatusCreated, resp.Code)
+	var created models.Listing
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+	assert.Equal(t, registered.ID, created.AgentID)
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, agentPath+"/listings", "", 0).Code)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "email already registered",
+			method:         http.MethodPost,
+			path:           "/api/v1/agents/",
+			body:           `{"name":"Jane Doe","email":"JANE@example.com"}`,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"Conflicting agent","issues":["email already exists"]}`,
+		},
+		{
+			name:           "blank name",
+			method:         http.MethodPost,
+			path:           "/api/v1/agents/",
+			body:           `{"name":" ","email":"john@example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Invalid agent","issues":["name is required"]}`,
+		},
+		{
+			name:           "missing email",
+			method:         http.MethodPost,
+			path:           "/api/v1/agents/",
+			body:           `{"name":"John Doe"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Invalid request body"}`,
+		},
+		{
+			name:           "unknown agent",
+			method:         http.MethodGet,
+			path:           "/api/v1/agents/99",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"Agent not found"}`,
+		},
+		{
+			name:           "invalid ID",
+			method:         http.MethodGet,
+			path:           "/api/v1/agents/abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Invalid ID parameter"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := send(tt.method, tt.path, tt.body, 0)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
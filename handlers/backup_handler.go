@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/porter"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// BackupHandler dumps the whole listing dataset to JSON and loads it back
+type BackupHandler struct {
+	porter *porter.Porter
+}
+
+func NewBackupHandler(porter *porter.Porter) *BackupHandler {
+	return &BackupHandler{
+		porter: porter,
+	}
+}
+
+// ExportListings responds with every listing as a versioned JSON document
+func (h *BackupHandler) ExportListings(c *gin.Context) {
+	data, err := h.porter.ExportJSON(c.Request.Context())
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to export listings")
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="listings.json"`)
+	c.Data(http.StatusOK, "application/json", data)
+}
+
+// ImportListings loads a document written by ExportListings, merging it by ID
+// or, with replace=true, swapping it in for every existing listing. Nothing is
+// stored if any listing in it is invalid.
+func (h *BackupHandler) ImportListings(c *gin.Context) {
+	if !checkQueryParams(c, importQueryParams) {
+		return
+	}
+	replace, err := strconv.ParseBool(c.DefaultQuery("replace", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid replace parameter"})
+		return
+	}
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if err := h.porter.ImportJSON(c.Request.Context(), data, replace); err != nil {
+		if writeDomainError(c, err, "listings") || writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to import listings")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// bindJSON decodes the request body into obj, writing a 413 and returning
+// false if the body is over the limit set by middleware.MaxBodyBytes, or a 400
+// if it can't be decoded
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	return false
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindJSON_OversizedBody(t *testing.T) {
+	const limit = 1024
+	exampleService := new(MockExampleService)
+	listingService := new(MockListingService)
+	exampleHandler := NewExampleHandler(exampleService)
+	listingHandler := NewListingHandler(listingService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.MaxBodyBytes(limit))
+	router.POST("/examples", exampleHandler.CreateExample)
+	router.PUT("/examples/:id", exampleHandler.UpdateExample)
+	router.POST("/listings", listingHandler.CreateListing)
+	router.PUT("/listings/:id", listingHandler.UpsertListing)
+
+	oversized := `{"name":"` + strings.Repeat("x", limit) + `","description":"` + strings.Repeat("x", limit) + `"}`
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/examples"},
+		{http.MethodPut, "/examples/1"},
+		{http.MethodPost, "/listings"},
+		{http.MethodPut, "/listings/1"},
+	}
+
+	for _, r := range requests {
+		for _, chunked := range []bool{false, true} {
+			req := httptest.NewRequest(r.method, r.path, strings.NewReader(oversized))
+			req.Header.Set("Content-Type", "application/json")
+			if chunked {
+				// Without a declared length the limit is only hit while decoding
+				req.ContentLength = -1
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code, "%s %s chunked=%t", r.method, r.path, chunked)
+			assert.JSONEq(t, `{"error":"Request body too large"}`, resp.Body.String())
+		}
+	}
+
+	// A body within the limit that can't be decoded is still a 400
+	req := httptest.NewRequest(http.MethodPost, "/listings", strings.NewReader(`{"price":`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	exampleService.AssertExpectations(t)
+	listingService.AssertExpectations(t)
+}
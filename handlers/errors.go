@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/requestid"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// statusClientClosedRequest is the non-standard status used when the client
+// went away before the response was written
+const statusClientClosedRequest = 499
+
+// writeContextError responds to an error caused by the request context being
+// cancelled or timing out, and reports false for any other error
+func writeContextError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled):
+		c.JSON(statusClientClosedRequest, gin.H{"error": "Request cancelled"})
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Request timed out"})
+	default:
+		return false
+	}
+	return true
+}
+
+// writeDomainError responds to an error matching models.ErrValidation with a
+// 400 or models.ErrConflict with a 409, naming the resource and giving the
+// reason, and reports false for any other error. Not-found errors are left to
+// the caller, as what is missing depends on the request.
+func writeDomainError(c *gin.Context, err error, resource string) bool {
+	switch {
+	case errors.Is(err, models.ErrValidation):
+		var validationErr *models.ValidationError
+		issues := []string{errors.Cause(err).Error()}
+		if errors.As(err, &validationErr) {
+			issues = validationErr.Issues
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + resource, "issues": issues})
+	case errors.Is(err, models.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "Conflicting " + resource, "issues": []string{errors.Cause(err).Error()}})
+	default:
+		return false
+	}
+	return true
+}
+
+// writeInternalError logs an unexpected error and responds with a 500 carrying
+// message
+func writeInternalError(c *gin.Context, err error, message string) {
+	logError(c, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
+
+// logError logs an unexpected error with the request ID, which the client
+// also gets in the X-Request-ID header, so a reported failure can be matched
+// to its log line
+func logError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	attrs := []any{"method", c.Request.Method, "route", c.FullPath(), "error", err.Error()}
+	if id := requestid.RequestIDFromContext(ctx); id != "" {
+		attrs = append(attrs, "requestID", id)
+	}
+	slog.ErrorContext(ctx, "request failed", attrs...)
+}
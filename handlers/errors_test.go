@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWriteInternalError_LogsRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	service := new(MockListingService)
+	service.On("GetFilterSchema", mock.Anything).Return(nil, errors.New("database error"))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.GET("/api/v1/listings/filter-schema", NewListingHandler(service).GetFilterSchema)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/filter-schema", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.JSONEq(t, `{"error":"Failed to get filter schema"}`, resp.Body.String())
+	output := logs.String()
+	assert.Contains(t, output, "level=ERROR")
+	assert.Contains(t, output, `msg="request failed"`)
+	assert.Contains(t, output, "route=/api/v1/listings/filter-schema")
+	assert.Contains(t, output, `error="database error"`)
+	assert.Contains(t, output, "requestID=req-42")
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/getground/interview-backend-golang/internal/app/example"
+	"github.com/getground/interview-backend-golang/models"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 )
@@ -31,13 +33,18 @@ type UpdateExampleRequest struct {
 
 func (h *ExampleHandler) CreateExample(c *gin.Context) {
 	var req CreateExampleRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	if !bindJSON(c, &req) {
 		return
 	}
 	example, err := h.service.CreateExample(c.Request.Context(), req.Name, req.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create example"})
+		if writeDomainError(c, err, "example") {
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to create example")
 		return
 	}
 	c.JSON(http.StatusCreated, example)
@@ -52,11 +59,41 @@ func (h *ExampleHandler) GetExampleByID(c *gin.Context) {
 	}
 	example, err := h.service.GetExampleByID(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, errors.New("not found")) {
+		if errors.Is(err, models.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Example not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get example"})
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get example")
+		return
+	}
+	c.JSON(http.StatusOK, example)
+}
+
+func (h *ExampleHandler) GetExampleByEmail(c *gin.Context) {
+	if !checkQueryParams(c, emailQueryParams) {
+		return
+	}
+	email := strings.TrimSpace(c.Query("email"))
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email parameter is required"})
+		return
+	}
+	example, err := h.service.GetExampleByEmail(c.Request.Context(), email)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Example not found"})
+			return
+		}
+		if writeDomainError(c, err, "example") {
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get example")
 		return
 	}
 	c.JSON(http.StatusOK, example)
@@ -65,7 +102,10 @@ func (h *ExampleHandler) GetExampleByID(c *gin.Context) {
 func (h *ExampleHandler) GetAllExamples(c *gin.Context) {
 	examples, err := h.service.GetAllExamples(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get examples"})
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get examples")
 		return
 	}
 	c.JSON(http.StatusOK, examples)
@@ -79,17 +119,22 @@ func (h *ExampleHandler) UpdateExample(c *gin.Context) {
 		return
 	}
 	var req UpdateExampleRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	if !bindJSON(c, &req) {
 		return
 	}
 	example, err := h.service.UpdateExample(c.Request.Context(), id, req.Name, req.Email)
 	if err != nil {
-		if errors.Is(err, errors.New("not found")) {
+		if errors.Is(err, models.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Example not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update example"})
+		if writeDomainError(c, err, "example") {
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to update example")
 		return
 	}
 	c.JSON(http.StatusOK, example)
@@ -104,11 +149,14 @@ func (h *ExampleHandler) DeleteExample(c *gin.Context) {
 	}
 	err = h.service.DeleteExample(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, errors.New("not found")) {
+		if errors.Is(err, models.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Example not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete example"})
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to delete example")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Example deleted successfully"})
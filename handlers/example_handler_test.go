@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -10,15 +11,17 @@ import (
 	"github.com/getground/interview-backend-golang/internal/app/example"
 	"github.com/getground/interview-backend-golang/models"
 	"github.com/gin-gonic/gin"
-	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockExampleService struct {
 	mock.Mock
 }
 
+var _ example.Service = (*MockExampleService)(nil)
+
 func (m *MockExampleService) CreateExample(ctx context.Context, name, email string) (*models.ExampleModel, error) {
 	args := m.Called(ctx, name, email)
 	if args.Get(0) == nil {
@@ -35,6 +38,14 @@ func (m *MockExampleService) GetExampleByID(ctx context.Context, id int64) (*mod
 	return args.Get(0).(*models.ExampleModel), args.Error(1)
 }
 
+func (m *MockExampleService) GetExampleByEmail(ctx context.Context, email string) (*models.ExampleModel, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExampleModel), args.Error(1)
+}
+
 func (m *MockExampleService) GetAllExamples(ctx context.Context) ([]*models.ExampleModel, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -66,6 +77,7 @@ func setupTestRouter(handler *ExampleHandler) *gin.Engine {
 		{
 			examples.POST("/", handler.CreateExample)
 			examples.GET("/", handler.GetAllExamples)
+			examples.GET("/by-email", handler.GetExampleByEmail)
 			examples.GET("/:id", handler.GetExampleByID)
 			examples.PUT("/:id", handler.UpdateExample)
 			examples.DELETE("/:id", handler.DeleteExample)
@@ -103,8 +115,10 @@ func TestExampleHandler_CreateExample(t *testing.T) {
 				"id": 1,
 				"name": "John Doe",
 				"email": "john@example.com",
-				"created_at": expected.CreatedAt,
-				"updated_at": expected.UpdatedAt,
+				"createdAt": "",
+				"updatedAt": "",
+				"created_at": "",
+				"updated_at": "",
 			},
 		},
 		{
@@ -114,8 +128,7 @@ func TestExampleHandler_CreateExample(t *testing.T) {
 				Email: "john@example.com",
 			},
 			mockSetup: func(service *MockExampleService) {
-				service.On("CreateExample", mock.Anything, "", "john@example.com").
-					Return(nil, errors.New("name is required"))
+				// Binding rejects the request before the service is called
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
@@ -129,8 +142,7 @@ func TestExampleHandler_CreateExample(t *testing.T) {
 				Email: "",
 			},
 			mockSetup: func(service *MockExampleService) {
-				service.On("CreateExample", mock.Anything, "John Doe", "").
-					Return(nil, errors.New("email is required"))
+				// Binding rejects the request before the service is called
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
@@ -155,7 +167,8 @@ func TestExampleHandler_CreateExample(t *testing.T) {
 			router.ServeHTTP(resp, req)
 			
 			assert.Equal(t, tt.expectedStatus, resp.Code)
-			assert.Equal(t, tt.expectedBody, resp.Body.Body)
+			expectedBody, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedBody), resp.Body.String())
 			
 			mockService.AssertExpectations(t)
 		})
@@ -187,8 +200,10 @@ func TestExampleHandler_GetExampleByID(t *testing.T) {
 				"id": 1,
 				"name": "John Doe",
 				"email": "john@example.com",
-				"created_at": expected.CreatedAt,
-				"updated_at": expected.UpdatedAt,
+				"createdAt": "",
+				"updatedAt": "",
+				"created_at": "",
+				"updated_at": "",
 			},
 		},
 		{
@@ -207,7 +222,7 @@ func TestExampleHandler_GetExampleByID(t *testing.T) {
 			id:   "999",
 			mockSetup: func(service *MockExampleService) {
 				service.On("GetExampleByID", mock.Anything, int64(999)).
-					Return(nil, errors.New("not found"))
+					Return(nil, models.ErrNotFound)
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody: map[string]interface{}{
@@ -229,13 +244,48 @@ func TestExampleHandler_GetExampleByID(t *testing.T) {
 			router.ServeHTTP(resp, req)
 			
 			assert.Equal(t, tt.expectedStatus, resp.Code)
-			assert.Equal(t, tt.expectedBody, resp.Body.Body)
+			expectedBody, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedBody), resp.Body.String())
 			
 			mockService.AssertExpectations(t)
 		})
 	}
 }
 
+func TestExampleHandler_GetExampleByEmail(t *testing.T) {
+	repo := models.NewExampleRepository()
+	require.NoError(t, repo.Create(context.Background(), &models.ExampleModel{Name: "John Doe", Email: "john@example.com"}))
+	router := setupTestRouter(NewExampleHandler(example.NewService(repo)))
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedName   string
+	}{
+		{name: "found", query: "?email=john@example.com", expectedStatus: http.StatusOK, expectedName: "John Doe"},
+		{name: "case-insensitive", query: "?email=JOHN@Example.COM", expectedStatus: http.StatusOK, expectedName: "John Doe"},
+		{name: "not found", query: "?email=jane@example.com", expectedStatus: http.StatusNotFound},
+		{name: "missing email", query: "", expectedStatus: http.StatusBadRequest},
+		{name: "unknown parameter", query: "?email=john@example.com&name=John", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/examples/by-email"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expectedStatus, resp.Code, resp.Body.String())
+			if tt.expectedName != "" {
+				var found models.ExampleModel
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &found))
+				assert.Equal(t, tt.expectedName, found.Name)
+			}
+		})
+	}
+}
+
 func TestExampleHandler_GetAllExamples(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -259,6 +309,8 @@ func TestExampleHandler_GetAllExamples(t *testing.T) {
 					"id": 1,
 					"name": "John Doe",
 					"email": "john@example.com",
+					"createdAt": "2023-10-27T10:00:00Z",
+					"updatedAt": "2023-10-27T10:00:00Z",
 					"created_at": "2023-10-27T10:00:00Z",
 					"updated_at": "2023-10-27T10:00:00Z",
 				},
@@ -266,6 +318,8 @@ func TestExampleHandler_GetAllExamples(t *testing.T) {
 					"id": 2,
 					"name": "Jane Doe",
 					"email": "jane@example.com",
+					"createdAt": "2023-10-27T11:00:00Z",
+					"updatedAt": "2023-10-27T11:00:00Z",
 					"created_at": "2023-10-27T11:00:00Z",
 					"updated_at": "2023-10-27T11:00:00Z",
 				},
@@ -295,7 +349,8 @@ func TestExampleHandler_GetAllExamples(t *testing.T) {
 			router.ServeHTTP(resp, req)
 			
 			assert.Equal(t, tt.expectedStatus, resp.Code)
-			assert.Equal(t, tt.expectedBody, resp.Body.Body)
+			expectedBody, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedBody), resp.Body.String())
 			
 			mockService.AssertExpectations(t)
 		})
@@ -319,23 +374,23 @@ func TestExampleHandler_UpdateExample(t *testing.T) {
 				Email: "john.updated@example.com",
 			},
 			mockSetup: func(service *MockExampleService) {
-				existing := &models.ExampleModel{
+				updated := &models.ExampleModel{
 					ID:    1,
-					Name:  "John Doe",
-					Email: "john@example.com",
+					Name:  "John Doe Updated",
+					Email: "john.updated@example.com",
 				}
-				service.On("GetExampleByID", mock.Anything, int64(1)).
-					Return(existing, nil)
 				service.On("UpdateExample", mock.Anything, int64(1), "John Doe Updated", "john.updated@example.com").
-					Return(existing, nil)
+					Return(updated, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: map[string]interface{}{
 				"id": 1,
 				"name": "John Doe Updated",
 				"email": "john.updated@example.com",
-				"created_at": existing.CreatedAt,
-				"updated_at": existing.UpdatedAt,
+				"createdAt": "",
+				"updatedAt": "",
+				"created_at": "",
+				"updated_at": "",
 			},
 		},
 		{
@@ -361,8 +416,8 @@ func TestExampleHandler_UpdateExample(t *testing.T) {
 				Email: "john.updated@example.com",
 			},
 			mockSetup: func(service *MockExampleService) {
-				service.On("GetExampleByID", mock.Anything, int64(999)).
-					Return(nil, errors.New("not found"))
+				service.On("UpdateExample", mock.Anything, int64(999), "John Doe Updated", "john.updated@example.com").
+					Return(nil, models.ErrNotFound)
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody: map[string]interface{}{
@@ -377,15 +432,7 @@ func TestExampleHandler_UpdateExample(t *testing.T) {
 				Email: "john.updated@example.com",
 			},
 			mockSetup: func(service *MockExampleService) {
-				existing := &models.ExampleModel{
-					ID:    1,
-					Name:  "John Doe",
-					Email: "john@example.com",
-				}
-				service.On("GetExampleByID", mock.Anything, int64(1)).
-					Return(existing, nil)
-				service.On("UpdateExample", mock.Anything, int64(1), "", "john.updated@example.com").
-					Return(nil, errors.New("name is required"))
+				// Binding rejects the request before the service is called
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
@@ -400,15 +447,7 @@ func TestExampleHandler_UpdateExample(t *testing.T) {
 				Email: "",
 			},
 			mockSetup: func(service *MockExampleService) {
-				existing := &models.ExampleModel{
-					ID:    1,
-					Name:  "John Doe",
-					Email: "john@example.com",
-				}
-				service.On("GetExampleByID", mock.Anything, int64(1)).
-					Return(existing, nil)
-				service.On("UpdateExample", mock.Anything, int64(1), "John Doe Updated", "").
-					Return(nil, errors.New("email is required"))
+				// Binding rejects the request before the service is called
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
@@ -433,7 +472,8 @@ func TestExampleHandler_UpdateExample(t *testing.T) {
 			router.ServeHTTP(resp, req)
 			
 			assert.Equal(t, tt.expectedStatus, resp.Code)
-			assert.Equal(t, tt.expectedBody, resp.Body.Body)
+			expectedBody, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedBody), resp.Body.String())
 			
 			mockService.AssertExpectations(t)
 		})
@@ -476,7 +516,7 @@ func TestExampleHandler_DeleteExample(t *testing.T) {
 			id:   "999",
 			mockSetup: func(service *MockExampleService) {
 				service.On("DeleteExample", mock.Anything, int64(999)).
-					Return(errors.New("not found"))
+					Return(models.ErrNotFound)
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody: map[string]interface{}{
@@ -498,12 +538,82 @@ func TestExampleHandler_DeleteExample(t *testing.T) {
 			router.ServeHTTP(resp, req)
 			
 			assert.Equal(t, tt.expectedStatus, resp.Code)
-			assert.Equal(t, tt.expectedBody, resp.Body.Body)
+			expectedBody, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedBody), resp.Body.String())
 			
 			mockService.AssertExpectations(t)
 		})
 	}
 }
-```
 
-```
+func TestExampleHandler_MissingExampleWithRepository(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+	}{
+		{name: "get", method: http.MethodGet},
+		{name: "update", method: http.MethodPut, body: `{"name":"John Doe","email":"john@example.com"}`},
+		{name: "delete", method: http.MethodDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewExampleHandler(example.NewService(models.NewExampleRepository()))
+			router := setupTestRouter(handler)
+
+			req, _ := http.NewRequest(tt.method, "/api/v1/examples/999", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusNotFound, resp.Code)
+			assert.JSONEq(t, `{"error":"Example not found"}`, resp.Body.String())
+		})
+	}
+}
+
+func TestExampleHandler_CreateExample_DomainErrors(t *testing.T) {
+	handler := NewExampleHandler(example.NewService(models.NewExampleRepository()))
+	router := setupTestRouter(handler)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/examples/", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"name":"John Doe","email":"john@example.com"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = post(`{"name":"Johnny Doe","email":"john@example.com"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"error":"Conflicting example","issues":["email already exists"]}`, w.Body.String())
+
+	w = post(`{"name":"Jane Doe","email":"jane.example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"Invalid example","issues":["invalid email format"]}`, w.Body.String())
+}
+
+func TestExampleHandler_UpdateExample_Conflict(t *testing.T) {
+	repo := models.NewExampleRepository()
+	handler := NewExampleHandler(example.NewService(repo))
+	router := setupTestRouter(handler)
+
+	ctx := context.Background()
+	john := &models.ExampleModel{Name: "John Doe", Email: "john@example.com"}
+	jane := &models.ExampleModel{Name: "Jane Doe", Email: "jane@example.com"}
+	assert.NoError(t, repo.Create(ctx, john))
+	assert.NoError(t, repo.Create(ctx, jane))
+
+	body := `{"name":"Jane Doe","email":"john@example.com"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/examples/2", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"error":"Conflicting example","issues":["email already exists"]}`, w.Body.String())
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getground/interview-backend-golang/internal/app/favorites"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// FavoritesHandler acts for the user that middleware.UserIdentity puts on the
+// request context, so its routes must be behind that middleware
+type FavoritesHandler struct {
+	service favorites.Service
+}
+
+func NewFavoritesHandler(service favorites.Service) *FavoritesHandler {
+	return &FavoritesHandler{
+		service: service,
+	}
+}
+
+func (h *FavoritesHandler) AddFavorite(c *gin.Context) {
+	userID, _ := models.UserIDFromContext(c.Request.Context())
+	listingID, err := strconv.ParseInt(c.Param("listingId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing ID parameter"})
+		return
+	}
+	added, err := h.service.AddFavorite(c.Request.Context(), userID, listingID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to add favorite")
+		return
+	}
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{"listingId": listingID})
+}
+
+func (h *FavoritesHandler) RemoveFavorite(c *gin.Context) {
+	userID, _ := models.UserIDFromContext(c.Request.Context())
+	listingID, err := strconv.ParseInt(c.Param("listingId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing ID parameter"})
+		return
+	}
+	if err := h.service.RemoveFavorite(c.Request.Context(), userID, listingID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Favorite not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to remove favorite")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Favorite removed successfully"})
+}
+
+func (h *FavoritesHandler) ListFavorites(c *gin.Context) {
+	userID, _ := models.UserIDFromContext(c.Request.Context())
+	listings, err := h.service.ListFavorites(c.Request.Context(), userID)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to list favorites")
+		return
+	}
+	c.JSON(http.StatusOK, listings)
+}
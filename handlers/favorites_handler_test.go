@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/middleware"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFavoritesService struct {
+	mock.Mock
+}
+
+func (m *MockFavoritesService) AddFavorite(ctx context.Context, userID, listingID int64) (bool, error) {
+	args := m.Called(ctx, userID, listingID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFavoritesService) RemoveFavorite(ctx context.Context, userID, listingID int64) error {
+	args := m.Called(ctx, userID, listingID)
+	return args.Error(0)
+}
+
+func (m *MockFavoritesService) ListFavorites(ctx context.Context, userID int64) ([]*models.Listing, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func setupFavoritesTestRouter(handler *FavoritesHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api/v1")
+	{
+		favorites := api.Group("/favorites", middleware.UserIdentity())
+		{
+			favorites.GET("/", handler.ListFavorites)
+			favorites.POST("/:listingId", handler.AddFavorite)
+			favorites.DELETE("/:listingId", handler.RemoveFavorite)
+		}
+	}
+
+	return router
+}
+
+func TestFavoritesHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		userID         string
+		mockSetup      func(*MockFavoritesService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:   "add favorite",
+			method: http.MethodPost,
+			path:   "/api/v1/favorites/187",
+			userID: "1",
+			mockSetup: func(service *MockFavoritesService) {
+				service.On("AddFavorite", mock.Anything, int64(1), int64(187)).Return(true, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   map[string]interface{}{"listingId": 187},
+		},
+		{
+			name:   "add existing favorite",
+			method: http.MethodPost,
+			path:   "/api/v1/favorites/187",
+			userID: "1",
+			mockSetup: func(service *MockFavoritesService) {
+				service.On("AddFavorite", mock.Anything, int64(1), int64(187)).Return(false, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"listingId": 187},
+		},
+		{
+			name:   "add unknown listing",
+			method: http.MethodPost,
+			path:   "/api/v1/favorites/999",
+			userID: "1",
+			mockSetup: func(service *MockFavoritesService) {
+				service.On("AddFavorite", mock.Anything, int64(1), int64(999)).
+					Return(false, errors.Wrap(models.ErrNotFound, "listing not found with id: 999"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"error": "Listing not found"},
+		},
+		{
+			name:   "list favorites",
+			method: http.MethodGet,
+			path:   "/api/v1/favorites/",
+			userID: "1",
+			mockSetup: func(service *MockFavoritesService) {
+				service.On("ListFavorites", mock.Anything, int64(1)).Return([]*models.Listing{{ID: 187}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{{ID: 187}},
+		},
+		{
+			name:   "remove favorite",
+			method: http.MethodDelete,
+			path:   "/api/v1/favorites/187",
+			userID: "1",
+			mockSetup: func(service *MockFavoritesService) {
+				service.On("RemoveFavorite", mock.Anything, int64(1), int64(187)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"message": "Favorite removed successfully"},
+		},
+		{
+			name:   "remove missing favorite",
+			method: http.MethodDelete,
+			path:   "/api/v1/favorites/187",
+			userID: "1",
+			mockSetup: func(service *MockFavoritesService) {
+				service.On("RemoveFavorite", mock.Anything, int64(1), int64(187)).
+					Return(errors.Wrap(models.ErrNotFound, "listing 187 is not a favorite of user 1"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"error": "Favorite not found"},
+		},
+		{
+			name:           "missing user",
+			method:         http.MethodGet,
+			path:           "/api/v1/favorites/",
+			mockSetup:      func(service *MockFavoritesService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   map[string]interface{}{"error": "A valid X-User-ID header is required"},
+		},
+		{
+			name:           "invalid listing id",
+			method:         http.MethodPost,
+			path:           "/api/v1/favorites/abc",
+			userID:         "1",
+			mockSetup:      func(service *MockFavoritesService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid listing ID parameter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockFavoritesService)
+			tt.mockSetup(mockService)
+
+			handler := NewFavoritesHandler(mockService)
+			router := setupFavoritesTestRouter(handler)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.userID != "" {
+				req.Header.Set(middleware.UserIDHeader, tt.userID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
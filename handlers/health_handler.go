@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/version"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	startedAt time.Time
+}
+
+func NewHealthHandler() *HealthHandler {
+	return &HealthHandler{
+		startedAt: time.Now(),
+	}
+}
+
+func (h *HealthHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "ok",
+		"version":   version.Version,
+		"commit":    version.Commit,
+		"buildTime": version.BuildTime,
+		"uptime":    time.Since(h.startedAt).Round(time.Second).String(),
+	})
+}
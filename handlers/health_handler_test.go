@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/version"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler_Health(t *testing.T) {
+	originalVersion, originalCommit, originalBuildTime := version.Version, version.Commit, version.BuildTime
+	defer func() {
+		version.Version, version.Commit, version.BuildTime = originalVersion, originalCommit, originalBuildTime
+	}()
+	version.Version = "v1.2.3"
+	version.Commit = "abc123"
+	version.BuildTime = "2024-01-01T00:00:00Z"
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := &HealthHandler{startedAt: time.Now().Add(-90 * time.Second)}
+	router.GET("/health", handler.Health)
+
+	req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, "ok", body["status"])
+	assert.Equal(t, "v1.2.3", body["version"])
+	assert.Equal(t, "abc123", body["commit"])
+	assert.Equal(t, "2024-01-01T00:00:00Z", body["buildTime"])
+	assert.Equal(t, "1m30s", body["uptime"])
+}
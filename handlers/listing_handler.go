@@ -0,0 +1,1448 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/app/listing"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type ListingHandler struct {
+	service listing.Service
+}
+
+func NewListingHandler(service listing.Service) *ListingHandler {
+	return &ListingHandler{
+		service: service,
+	}
+}
+
+func (h *ListingHandler) ImportListings(c *gin.Context) {
+	var listings []*models.Listing
+	if !bindJSON(c, &listings) {
+		return
+	}
+	results, err := h.service.ImportListings(c.Request.Context(), listings)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to import listings")
+		return
+	}
+	c.JSON(http.StatusOK, models.NewBulkResponse(results))
+}
+
+type bulkDeleteRequest struct {
+	IDs []int64 `json:"ids" binding:"required,min=1"`
+}
+
+func (h *ListingHandler) DeleteListings(c *gin.Context) {
+	var req bulkDeleteRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	results, err := h.service.DeleteListings(c.Request.Context(), req.IDs)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to delete listings")
+		return
+	}
+	c.JSON(http.StatusOK, models.NewBulkResponse(results))
+}
+
+// Views accepted by the collection endpoint's view query parameter
+const (
+	listingViewFull    = "full"
+	listingViewSummary = "summary"
+)
+
+func (h *ListingHandler) GetAllListings(c *gin.Context) {
+	if !checkQueryParams(c, listingQueryParams) {
+		return
+	}
+	filters, ok := parseListingFilters(c)
+	if !ok {
+		return
+	}
+	maxDescription, ok := maxDescriptionLengthQuery(c)
+	if !ok {
+		return
+	}
+	deposit, err := int64Query(c, "deposit", -1)
+	if err != nil || (c.Query("deposit") != "" && deposit < 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid deposit parameter"})
+		return
+	}
+	view := c.DefaultQuery("view", listingViewSummary)
+	if view != listingViewFull && view != listingViewSummary {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view parameter, must be full or summary"})
+		return
+	}
+	sortField, desc, ok := sortQuery(c)
+	if !ok {
+		return
+	}
+	var listings []*models.Listing
+	switch {
+	case c.Query("minDeposit") != "" || c.Query("maxDeposit") != "":
+		if listings, ok = h.getListingsByDepositRange(c); !ok {
+			return
+		}
+	case !filters.flags.IsZero():
+		listings, err = h.service.GetListingsByFlags(c.Request.Context(), filters.flags)
+		// The repository has already matched the flags
+		filters.flags = models.ListingFlags{}
+	case sortField != "":
+		listings, err = h.service.GetAllListingsSorted(c.Request.Context(), sortField, desc)
+		sortField = ""
+	default:
+		listings, err = h.service.GetAllListings(c.Request.Context())
+	}
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get listings")
+		return
+	}
+	if sortField != "" {
+		// sortQuery has already checked the field, so this can't fail
+		_ = models.SortListings(listings, sortField, desc)
+	}
+	if filters.priceReduced {
+		reduced, err := h.service.GetPriceReducedListings(c.Request.Context(), filters.priceReducedSince)
+		if err != nil {
+			if writeContextError(c, err) {
+				return
+			}
+			writeInternalError(c, err, "Failed to get listings")
+			return
+		}
+		for _, listing := range reduced {
+			filters.priceReducedIDs = append(filters.priceReducedIDs, listing.ID)
+		}
+	}
+	listings = truncateDescriptions(filters.apply(listings), maxDescription)
+	switch {
+	case view == listingViewSummary && deposit >= 0:
+		c.JSON(http.StatusOK, models.AffordableSummaries(listings, deposit))
+	case view == listingViewSummary:
+		c.JSON(http.StatusOK, models.Summaries(listings))
+	case deposit >= 0:
+		c.JSON(http.StatusOK, models.AffordableListings(listings, deposit))
+	default:
+		c.JSON(http.StatusOK, listings)
+	}
+}
+
+// sortQuery parses the optional sort and order query parameters, writing a 400
+// response and returning false if the field is unsupported, the order is not
+// asc or desc, or an order is given without a sort
+func sortQuery(c *gin.Context) (models.SortField, bool, bool) {
+	field := models.SortField(c.Query("sort"))
+	if field != "" && !field.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort parameter", "allowed": models.SortFields})
+		return "", false, false
+	}
+	order := c.Query("order")
+	if order != "" && (field == "" || (order != "asc" && order != "desc")) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order parameter, must be asc or desc and used with sort"})
+		return "", false, false
+	}
+	return field, order == "desc", true
+}
+
+// maxDescriptionLengthQuery parses the optional maxDescriptionLength query
+// parameter used by collection endpoints, returning -1 when it is not set
+func maxDescriptionLengthQuery(c *gin.Context) (int, bool) {
+	value := c.Query("maxDescriptionLength")
+	if value == "" {
+		return -1, true
+	}
+	maxLength, err := strconv.Atoi(value)
+	if err != nil || maxLength < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maxDescriptionLength parameter, must be a non-negative integer"})
+		return 0, false
+	}
+	return maxLength, true
+}
+
+// truncateDescriptions returns copies of the listings with descriptions cut to
+// maxLength characters, or left out entirely when maxLength is 0. A negative
+// maxLength returns the listings unchanged.
+func truncateDescriptions(listings []*models.Listing, maxLength int) []*models.Listing {
+	if maxLength < 0 {
+		return listings
+	}
+	truncated := make([]*models.Listing, 0, len(listings))
+	for _, listing := range listings {
+		summary := *listing
+		if description := []rune(summary.Description); len(description) > maxLength {
+			summary.Description = string(description[:maxLength])
+		}
+		truncated = append(truncated, &summary)
+	}
+	return truncated
+}
+
+// getListingsByDepositRange writes an error response and returns false if the
+// deposit range is invalid or the lookup fails
+func (h *ListingHandler) getListingsByDepositRange(c *gin.Context) ([]*models.Listing, bool) {
+	minDeposit, err := int64Query(c, "minDeposit", 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid minDeposit parameter"})
+		return nil, false
+	}
+	maxDeposit, err := int64Query(c, "maxDeposit", math.MaxInt64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maxDeposit parameter"})
+		return nil, false
+	}
+	if minDeposit < 0 || maxDeposit < 0 || minDeposit > maxDeposit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid deposit range"})
+		return nil, false
+	}
+	listings, err := h.service.GetListingsByDepositRange(c.Request.Context(), minDeposit, maxDeposit)
+	if err != nil {
+		if writeContextError(c, err) {
+			return nil, false
+		}
+		writeInternalError(c, err, "Failed to get listings")
+		return nil, false
+	}
+	return listings, true
+}
+
+// listingFilters are the optional filters applied to fetched listings
+type listingFilters struct {
+	mortgageable  bool
+	regions       []models.Region
+	propertyTypes []models.PropertyType
+	ids           []int64
+	tags          []string
+	maxAgeYears   *int
+	minPhotos     int
+	now           time.Time
+	// priceReduced is set when only listings repriced downwards since
+	// priceReducedSince (or ever, when zero) should match. The matching IDs
+	// are fetched separately into priceReducedIDs before apply is called.
+	priceReduced      bool
+	priceReducedSince time.Time
+	priceReducedIDs   []int64
+	flags             models.ListingFlags
+}
+
+// parseListingFilters writes an error response and returns false if any filter is invalid
+func parseListingFilters(c *gin.Context) (listingFilters, bool) {
+	filters := listingFilters{now: time.Now()}
+	mortgageable, err := strconv.ParseBool(c.DefaultQuery("mortgageable", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mortgageable parameter"})
+		return filters, false
+	}
+	filters.mortgageable = mortgageable
+	for _, value := range multiValueQuery(c, "region") {
+		region := models.Region(value)
+		if !region.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region parameter", "allowed": models.Regions})
+			return filters, false
+		}
+		filters.regions = append(filters.regions, region)
+	}
+	for _, value := range multiValueQuery(c, "propertyType") {
+		propertyType := models.PropertyType(value)
+		if !propertyType.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid propertyType parameter", "allowed": models.PropertyTypes})
+			return filters, false
+		}
+		filters.propertyTypes = append(filters.propertyTypes, propertyType)
+	}
+	for _, value := range multiValueQuery(c, "tag") {
+		if tag := models.NormalizeTag(value); tag != "" {
+			filters.tags = append(filters.tags, tag)
+		}
+	}
+	for _, value := range multiValueQuery(c, "ids") {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ids parameter, must be positive integers"})
+			return filters, false
+		}
+		filters.ids = append(filters.ids, id)
+	}
+	if value := c.Query("maxAgeYears"); value != "" {
+		maxAgeYears, err := strconv.Atoi(value)
+		if err != nil || maxAgeYears < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maxAgeYears parameter, must be a non-negative integer"})
+			return filters, false
+		}
+		filters.maxAgeYears = &maxAgeYears
+	}
+	if value := c.Query("minPhotos"); value != "" {
+		minPhotos, err := strconv.Atoi(value)
+		if err != nil || minPhotos < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid minPhotos parameter, must be a non-negative integer"})
+			return filters, false
+		}
+		filters.minPhotos = minPhotos
+	}
+	if !parseListingFlags(c, &filters.flags) {
+		return filters, false
+	}
+	priceReduced, err := strconv.ParseBool(c.DefaultQuery("priceReduced", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid priceReduced parameter"})
+		return filters, false
+	}
+	filters.priceReduced = priceReduced
+	if value := c.Query("priceReducedWithinDays"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 || !priceReduced {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid priceReducedWithinDays parameter, must be a positive integer used with priceReduced=true"})
+			return filters, false
+		}
+		filters.priceReducedSince = filters.now.AddDate(0, 0, -days)
+	}
+	return filters, true
+}
+
+// apply returns the listings matching every filter that is set. A listing
+// matches a multi-value filter if it matches any of its values. Mortgageable
+// drops the cash-only listings a mortgage buyer can't act on.
+func (f listingFilters) apply(listings []*models.Listing) []*models.Listing {
+	filtered := make([]*models.Listing, 0, len(listings))
+	for _, listing := range listings {
+		if f.mortgageable && !listing.IsMortgageable() {
+			continue
+		}
+		if len(f.regions) > 0 && !slices.Contains(f.regions, listing.AddressDetails.Region) {
+			continue
+		}
+		if len(f.propertyTypes) > 0 && !slices.Contains(f.propertyTypes, listing.PropertyType) {
+			continue
+		}
+		if len(f.ids) > 0 && !slices.Contains(f.ids, listing.ID) {
+			continue
+		}
+		if len(f.tags) > 0 && !slices.ContainsFunc(f.tags, listing.HasTag) {
+			continue
+		}
+		if f.maxAgeYears != nil {
+			// Listings with an unknown build year can't be shown to be young enough
+			if age, ok := listing.AgeYears(f.now); !ok || age > *f.maxAgeYears {
+				continue
+			}
+		}
+		if len(listing.Photos) < f.minPhotos {
+			continue
+		}
+		if f.priceReduced && !slices.Contains(f.priceReducedIDs, listing.ID) {
+			continue
+		}
+		if !f.flags.Matches(listing) {
+			continue
+		}
+		filtered = append(filtered, listing)
+	}
+	return filtered
+}
+
+// multiValueQuery returns every value of a query parameter that may be repeated
+// (?region=London&region=Wales) or comma-separated (?region=London,Wales), or
+// both. The two forms are treated identically: values are trimmed, and empty
+// and duplicate values are dropped.
+func multiValueQuery(c *gin.Context, key string) []string {
+	values := make([]string, 0)
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			value = strings.TrimSpace(value)
+			if value != "" && !slices.Contains(values, value) {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// regionQuery parses the optional region query parameter, writing a 400
+// response listing the allowed regions and returning false if it is unknown
+func regionQuery(c *gin.Context) (models.Region, bool) {
+	region := models.Region(c.Query("region"))
+	if region != "" && !region.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region parameter", "allowed": models.Regions})
+		return "", false
+	}
+	return region, true
+}
+
+func (h *ListingHandler) SearchListings(c *gin.Context) {
+	if !checkQueryParams(c, searchQueryParams) {
+		return
+	}
+	filter, ok := parseSearchFilter(c)
+	if !ok {
+		return
+	}
+	listings, err := h.service.SearchListings(c.Request.Context(), filter)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search", "issues": validationErr.Issues})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to search listings")
+		return
+	}
+	c.JSON(http.StatusOK, listings)
+}
+
+// csvFlushRows is how many CSV rows are written between flushes to the client
+const csvFlushRows = 100
+
+// ExportListingsCSV streams the listings matching the search filters as CSV.
+// Rows are flushed as they are written rather than buffered, so once the
+// first row is out an encoding error can only end the response early.
+func (h *ListingHandler) ExportListingsCSV(c *gin.Context) {
+	if !checkQueryParams(c, searchQueryParams) {
+		return
+	}
+	filter, ok := parseSearchFilter(c)
+	if !ok {
+		return
+	}
+	listings, err := h.service.SearchListings(c.Request.Context(), filter)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search", "issues": validationErr.Issues})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to export listings")
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="listings.csv"`)
+	c.Status(http.StatusOK)
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.Write(models.ListingCSVHeader); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	for i, listing := range listings {
+		if err := writer.Write(listing.CSVRecord()); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if (i+1)%csvFlushRows == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func (h *ListingHandler) GetFilterSchema(c *gin.Context) {
+	schema, err := h.service.GetFilterSchema(c.Request.Context())
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get filter schema")
+		return
+	}
+	c.JSON(http.StatusOK, schema)
+}
+
+// parseSearchFilter maps the search query parameters onto a ListingFilter,
+// leaving unset parameters nil. It writes a 400 response and returns false if
+// a value can't be parsed.
+func parseSearchFilter(c *gin.Context) (models.ListingFilter, bool) {
+	var filter models.ListingFilter
+	parseInt64 := func(value string) (int64, error) { return strconv.ParseInt(value, 10, 64) }
+	ok := optionalQuery(c, "region", parseString, &filter.Region) &&
+		optionalQuery(c, "propertyType", parseString, &filter.PropertyType) &&
+		optionalQuery(c, "city", parseString, &filter.City) &&
+		optionalQuery(c, "minPrice", parseInt64, &filter.MinPrice) &&
+		optionalQuery(c, "maxPrice", parseInt64, &filter.MaxPrice) &&
+		optionalQuery(c, "minBedrooms", strconv.Atoi, &filter.MinBedrooms) &&
+		optionalQuery(c, "maxBedrooms", strconv.Atoi, &filter.MaxBedrooms) &&
+		optionalQuery(c, "minBathrooms", strconv.Atoi, &filter.MinBathrooms) &&
+		optionalQuery(c, "maxBathrooms", strconv.Atoi, &filter.MaxBathrooms) &&
+		optionalQuery(c, "minSize", strconv.Atoi, &filter.MinSize) &&
+		optionalQuery(c, "maxSize", strconv.Atoi, &filter.MaxSize) &&
+		parseListingFlags(c, &filter.ListingFlags)
+	return filter, ok
+}
+
+// parseListingFlags reads the optional boolean flag query parameters into
+// flags. It writes a 400 response and returns false if a value isn't a bool.
+func parseListingFlags(c *gin.Context, flags *models.ListingFlags) bool {
+	return optionalQuery(c, "isTenanted", strconv.ParseBool, &flags.IsTenanted) &&
+		optionalQuery(c, "isCashOnly", strconv.ParseBool, &flags.IsCashOnly) &&
+		optionalQuery(c, "isNewBuild", strconv.ParseBool, &flags.IsNewBuild) &&
+		optionalQuery(c, "isShareSale", strconv.ParseBool, &flags.IsShareSale) &&
+		optionalQuery(c, "isCompany", strconv.ParseBool, &flags.IsCompany)
+}
+
+// optionalQuery parses the query parameter into *dest when it is set, leaving
+// dest nil otherwise. It writes a 400 response and returns false if the value
+// can't be parsed.
+func optionalQuery[T any](c *gin.Context, key string, parse func(string) (T, error), dest **T) bool {
+	raw := c.Query(key)
+	if raw == "" {
+		return true
+	}
+	value, err := parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + key + " parameter"})
+		return false
+	}
+	*dest = &value
+	return true
+}
+
+// parseString returns value as a string-based type such as models.Region
+func parseString[T ~string](value string) (T, error) {
+	return T(value), nil
+}
+
+func (h *ListingHandler) GetListingsInBoundingBox(c *gin.Context) {
+	if !checkQueryParams(c, boundingBoxQueryParams) {
+		return
+	}
+	var box models.BoundingBox
+	for _, param := range []struct {
+		key   string
+		value *float64
+	}{
+		{"north", &box.North},
+		{"south", &box.South},
+		{"east", &box.East},
+		{"west", &box.West},
+	} {
+		value, err := strconv.ParseFloat(c.Query(param.key), 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param.key + " parameter"})
+			return
+		}
+		*param.value = value
+	}
+	if err := box.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	maxDescription, ok := maxDescriptionLengthQuery(c)
+	if !ok {
+		return
+	}
+	listings, err := h.service.GetListingsInBoundingBox(c.Request.Context(), box)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get listings")
+		return
+	}
+	c.JSON(http.StatusOK, truncateDescriptions(listings, maxDescription))
+}
+
+func (h *ListingHandler) SearchByCity(c *gin.Context) {
+	if !checkQueryParams(c, citySearchQueryParams) {
+		return
+	}
+	city := strings.TrimSpace(c.Query("city"))
+	if city == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "city parameter is required"})
+		return
+	}
+	maxDescription, ok := maxDescriptionLengthQuery(c)
+	if !ok {
+		return
+	}
+	result, err := h.service.SearchByCity(c.Request.Context(), city)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to search listings")
+		return
+	}
+	result.Listings = truncateDescriptions(result.Listings, maxDescription)
+	c.JSON(http.StatusOK, result)
+}
+
+// defaultNearCityRadiusMiles is the search radius when radiusMiles is not given
+const defaultNearCityRadiusMiles = 10
+
+func (h *ListingHandler) GetListingsNearCity(c *gin.Context) {
+	if !checkQueryParams(c, nearCityQueryParams) {
+		return
+	}
+	city := strings.TrimSpace(c.Query("city"))
+	if city == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "city parameter is required"})
+		return
+	}
+	radiusMiles := float64(defaultNearCityRadiusMiles)
+	if value := c.Query("radiusMiles"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radiusMiles parameter"})
+			return
+		}
+		radiusMiles = parsed
+	}
+	result, err := h.service.GetListingsNearCity(c.Request.Context(), city, radiusMiles)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid near-city search", "issues": validationErr.Issues})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get listings near city")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *ListingHandler) CountByPropertyType(c *gin.Context) {
+	if !checkQueryParams(c, countByTypeQueryParams) {
+		return
+	}
+	includeEmpty, err := strconv.ParseBool(c.DefaultQuery("includeEmpty", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid includeEmpty parameter"})
+		return
+	}
+	counts, err := h.service.CountByPropertyType(c.Request.Context(), includeEmpty)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to count listings by property type")
+		return
+	}
+	c.JSON(http.StatusOK, counts)
+}
+
+func (h *ListingHandler) GetVelocityByRegion(c *gin.Context) {
+	velocity, err := h.service.GetVelocityByRegion(c.Request.Context())
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get listing velocity")
+		return
+	}
+	c.JSON(http.StatusOK, velocity)
+}
+
+func (h *ListingHandler) GetCreatedOverTime(c *gin.Context) {
+	if !checkQueryParams(c, createdOverTimeQueryParams) {
+		return
+	}
+	bucket := models.TimeBucket(c.DefaultQuery("bucket", string(models.TimeBucketDay)))
+	if !bucket.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bucket parameter, must be one of: day, week, month"})
+		return
+	}
+	counts, err := h.service.GetCreatedOverTime(c.Request.Context(), bucket)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get listing creation counts")
+		return
+	}
+	c.JSON(http.StatusOK, counts)
+}
+
+func (h *ListingHandler) GetCheapestByRegion(c *gin.Context) {
+	if !checkQueryParams(c, cheapestQueryParams) {
+		return
+	}
+	n, err := strconv.Atoi(c.DefaultQuery("n", "3"))
+	if err != nil || n <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid n parameter, must be a positive integer"})
+		return
+	}
+	cheapest, err := h.service.GetCheapestByRegion(c.Request.Context(), n)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get cheapest listings")
+		return
+	}
+	c.JSON(http.StatusOK, cheapest)
+}
+
+func (h *ListingHandler) GetDepositDemand(c *gin.Context) {
+	demand, err := h.service.GetDepositDemand(c.Request.Context())
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get deposit demand")
+		return
+	}
+	c.JSON(http.StatusOK, demand)
+}
+
+func (h *ListingHandler) GetCityGroups(c *gin.Context) {
+	if !checkQueryParams(c, pageQueryParams) {
+		return
+	}
+	page, ok := pageQuery(c)
+	if !ok {
+		return
+	}
+	groups, err := h.service.GetCityGroups(c.Request.Context())
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get city groups")
+		return
+	}
+	c.JSON(http.StatusOK, models.Paginate(groups, page))
+}
+
+func (h *ListingHandler) GetPriceBands(c *gin.Context) {
+	bands, err := h.service.GetPriceBands(c.Request.Context())
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get price bands")
+		return
+	}
+	c.JSON(http.StatusOK, bands)
+}
+
+// maxYieldHistogramBuckets caps how finely the yield histogram can be split
+const maxYieldHistogramBuckets = 100
+
+func (h *ListingHandler) GetYieldHistogram(c *gin.Context) {
+	if !checkQueryParams(c, histogramQueryParams) {
+		return
+	}
+	buckets, err := strconv.Atoi(c.DefaultQuery("buckets", "10"))
+	if err != nil || buckets <= 0 || buckets > maxYieldHistogramBuckets {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid buckets parameter, must be an integer between 1 and 100"})
+		return
+	}
+	histogram, err := h.service.GetYieldHistogram(c.Request.Context(), buckets)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get yield histogram")
+		return
+	}
+	c.JSON(http.StatusOK, histogram)
+}
+
+func (h *ListingHandler) GetPricePerSqFtByRegion(c *gin.Context) {
+	averages, err := h.service.GetPricePerSqFtByRegion(c.Request.Context())
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get price per square foot by region")
+		return
+	}
+	c.JSON(http.StatusOK, averages)
+}
+
+func (h *ListingHandler) GetMedianPrice(c *gin.Context) {
+	if !checkQueryParams(c, medianPriceQueryParams) {
+		return
+	}
+	region, ok := regionQuery(c)
+	if !ok {
+		return
+	}
+	switch groupBy := c.Query("groupBy"); groupBy {
+	case "":
+		median, err := h.service.GetMedianPrice(c.Request.Context(), region)
+		if err != nil {
+			if writeContextError(c, err) {
+				return
+			}
+			writeInternalError(c, err, "Failed to get median price")
+			return
+		}
+		c.JSON(http.StatusOK, median)
+	case "region":
+		if region != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "region cannot be combined with groupBy=region"})
+			return
+		}
+		medians, err := h.service.GetMedianPriceByRegion(c.Request.Context())
+		if err != nil {
+			if writeContextError(c, err) {
+				return
+			}
+			writeInternalError(c, err, "Failed to get median price")
+			return
+		}
+		c.JSON(http.StatusOK, medians)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid groupBy parameter, must be: region"})
+	}
+}
+
+func (h *ListingHandler) GetMultiStats(c *gin.Context) {
+	var req models.MultiStatsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stats, err := h.service.GetMultiStats(c.Request.Context(), req)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get stats")
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *ListingHandler) GetPivot(c *gin.Context) {
+	if !checkQueryParams(c, pivotQueryParams) {
+		return
+	}
+	rows := models.PivotDimension(c.Query("rows"))
+	cols := models.PivotDimension(c.Query("cols"))
+	if !rows.IsValid() || !cols.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rows or cols parameter", "allowed": models.PivotDimensions})
+		return
+	}
+	if rows == cols {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rows and cols must be different dimensions"})
+		return
+	}
+	table, err := h.service.GetPivot(c.Request.Context(), rows, cols)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get pivot")
+		return
+	}
+	c.JSON(http.StatusOK, table)
+}
+
+func (h *ListingHandler) GetChanges(c *gin.Context) {
+	if !checkQueryParams(c, changesQueryParams) {
+		return
+	}
+	since, err := int64Query(c, "since", 0)
+	if err != nil || since < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since parameter"})
+		return
+	}
+	changes, err := h.service.WaitForChanges(c.Request.Context(), since)
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get listing changes")
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+func (h *ListingHandler) GetNeighbours(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	neighbours, err := h.service.GetNeighbours(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get neighbouring listings")
+		return
+	}
+	c.JSON(http.StatusOK, neighbours)
+}
+
+func (h *ListingHandler) GetNearby(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	nearby, err := h.service.GetNearby(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get nearby listings")
+		return
+	}
+	c.JSON(http.StatusOK, nearby)
+}
+
+func (h *ListingHandler) CreateListing(c *gin.Context) {
+	var listing models.Listing
+	if !bindJSON(c, &listing) {
+		return
+	}
+	created, err := h.service.CreateListing(c.Request.Context(), &listing)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing", "issues": validationErr.Issues})
+			return
+		}
+		var publishErr *models.PublishError
+		if errors.As(err, &publishErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Listing cannot be published", "issues": publishErr.Issues})
+			return
+		}
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Agent not found"})
+			return
+		}
+		if writeDomainError(c, err, "listing") {
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to create listing")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (h *ListingHandler) GetListingByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	listing, err := h.service.GetListingByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get listing")
+		return
+	}
+	c.JSON(http.StatusOK, models.NewDetailResponse(listing, nil))
+}
+
+func (h *ListingHandler) LookupPostcode(c *gin.Context) {
+	lookup, err := h.service.LookupPostcode(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid postcode", "issues": validationErr.Issues})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to look up postcode")
+		return
+	}
+	c.JSON(http.StatusOK, lookup)
+}
+
+// GetAgentListings lists the agent's listings, including drafts only when the
+// agent itself is asking
+func (h *ListingHandler) GetAgentListings(c *gin.Context) {
+	agentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	listings, err := h.service.GetAgentListings(c.Request.Context(), agentID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get agent listings")
+		return
+	}
+	c.JSON(http.StatusOK, listings)
+}
+
+func (h *ListingHandler) GetAgentListingIssues(c *gin.Context) {
+	agentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	reports, err := h.service.GetAgentListingIssues(c.Request.Context(), agentID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get listing issues")
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+func (h *ListingHandler) ExportListing(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	export, err := h.service.ExportListing(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to export listing")
+		return
+	}
+	c.JSON(http.StatusOK, export)
+}
+
+func (h *ListingHandler) GetListingPhotos(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	photos, err := h.service.GetListingPhotos(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get listing photos")
+		return
+	}
+	c.JSON(http.StatusOK, photos)
+}
+
+func (h *ListingHandler) GetPriceHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	history, err := h.service.GetPriceHistory(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get price history")
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *ListingHandler) DeleteListingPhoto(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	photoID, err := strconv.ParseInt(c.Param("photoId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID parameter"})
+		return
+	}
+	photos, err := h.service.DeleteListingPhoto(c.Request.Context(), id, photoID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing or photo not found"})
+			return
+		}
+		var publishErr *models.PublishError
+		if errors.As(err, &publishErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Listing would no longer meet its publish profile", "issues": publishErr.Issues})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to delete listing photo")
+		return
+	}
+	c.JSON(http.StatusOK, photos)
+}
+
+// AddListingPhoto adds the photo in the body as the listing's last one,
+// responding 201 with all of its photos
+func (h *ListingHandler) AddListingPhoto(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	var photo models.Photo
+	if !bindJSON(c, &photo) {
+		return
+	}
+	photos, err := h.service.AddListingPhoto(c.Request.Context(), id, photo)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		var publishErr *models.PublishError
+		if errors.As(err, &publishErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Listing would no longer meet its publish profile", "issues": publishErr.Issues})
+			return
+		}
+		if writeDomainError(c, err, "photo") {
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to add listing photo")
+		return
+	}
+	c.JSON(http.StatusCreated, photos)
+}
+
+// RemoveListingPhoto removes the listing's photo with the originalURL query
+// parameter, responding with the remaining photos
+func (h *ListingHandler) RemoveListingPhoto(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	if !checkQueryParams(c, removePhotoQueryParams) {
+		return
+	}
+	originalURL := strings.TrimSpace(c.Query("originalURL"))
+	if originalURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "originalURL parameter is required"})
+		return
+	}
+	photos, err := h.service.RemoveListingPhoto(c.Request.Context(), id, originalURL)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing or photo not found"})
+			return
+		}
+		var publishErr *models.PublishError
+		if errors.As(err, &publishErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Listing would no longer meet its publish profile", "issues": publishErr.Issues})
+			return
+		}
+		if writeDomainError(c, err, "photo") {
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to remove listing photo")
+		return
+	}
+	c.JSON(http.StatusOK, photos)
+}
+
+// DeleteListing soft-deletes the listing so that RestoreListing can bring it back
+func (h *ListingHandler) DeleteListing(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	if err := h.service.DeleteListing(c.Request.Context(), id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to delete listing")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Listing deleted successfully"})
+}
+
+// RestoreListing brings back a deleted listing, responding with it, or with
+// 409 if another listing now has its address
+func (h *ListingHandler) RestoreListing(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	listing, err := h.service.RestoreListing(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deleted listing not found"})
+			return
+		}
+		if writeDomainError(c, err, "listing") || writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to restore listing")
+		return
+	}
+	c.JSON(http.StatusOK, listing)
+}
+
+// GetDeletedListings lists the deleted listings, which no other endpoint returns
+func (h *ListingHandler) GetDeletedListings(c *gin.Context) {
+	listings, err := h.service.GetDeletedListings(c.Request.Context())
+	if err != nil {
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to get deleted listings")
+		return
+	}
+	c.JSON(http.StatusOK, listings)
+}
+
+type addTagsRequest struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+func (h *ListingHandler) AddTags(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	var req addTagsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	tags, err := h.service.AddTags(c.Request.Context(), id, req.Tags)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tags", "issues": validationErr.Issues})
+			return
+		}
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to tag listing")
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *ListingHandler) RemoveTag(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	tags, err := h.service.RemoveTag(c.Request.Context(), id, c.Param("tag"))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing or tag not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to untag listing")
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// UpsertListing creates the listing under the ID in the path, or replaces the
+// listing that already has it, responding 201 or 200 accordingly
+// UpdateListing replaces an existing listing, answering 404 if there is none
+// rather than creating it as UpsertListing does
+func (h *ListingHandler) UpdateListing(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	var listing models.Listing
+	if !bindJSON(c, &listing) {
+		return
+	}
+	if listing.ID != 0 && listing.ID != id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Body id does not match the ID parameter"})
+		return
+	}
+	updated, err := h.service.UpdateListing(c.Request.Context(), id, &listing)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing", "issues": validationErr.Issues})
+			return
+		}
+		var publishErr *models.PublishError
+		if errors.As(err, &publishErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Listing cannot be published", "issues": publishErr.Issues})
+			return
+		}
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeDomainError(c, err, "listing") {
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to update listing")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *ListingHandler) UpsertListing(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	var listing models.Listing
+	if !bindJSON(c, &listing) {
+		return
+	}
+	if listing.ID != 0 && listing.ID != id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Body id does not match the ID parameter"})
+		return
+	}
+	stored, created, err := h.service.UpsertListing(c.Request.Context(), id, &listing)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing", "issues": validationErr.Issues})
+			return
+		}
+		var publishErr *models.PublishError
+		if errors.As(err, &publishErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Listing cannot be published", "issues": publishErr.Issues})
+			return
+		}
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Agent not found"})
+			return
+		}
+		if writeDomainError(c, err, "listing") {
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to save listing")
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, stored)
+}
+
+func (h *ListingHandler) PublishListing(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID parameter"})
+		return
+	}
+	listing, err := h.service.PublishListing(c.Request.Context(), id)
+	if err != nil {
+		var publishErr *models.PublishError
+		if errors.As(err, &publishErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Listing cannot be published", "issues": publishErr.Issues})
+			return
+		}
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to publish listing")
+		return
+	}
+	c.JSON(http.StatusOK, listing)
+}
+
+// int64Query parses an optional integer query parameter, returning def when it is absent
+func int64Query(c *gin.Context, key string, def int64) (int64, error) {
+	value := c.Query(key)
+	if value == "" {
+		return def, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
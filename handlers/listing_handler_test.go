@@ -0,0 +1,3606 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/app/listing"
+	"github.com/getground/interview-backend-golang/internal/pkg/config"
+	"github.com/getground/interview-backend-golang/internal/pkg/middleware"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockListingService struct {
+	mock.Mock
+}
+
+var _ listing.Service = (*MockListingService)(nil)
+
+func (m *MockListingService) CreateListing(ctx context.Context, listing *models.Listing) (*models.Listing, error) {
+	args := m.Called(ctx, listing)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) DeleteListings(ctx context.Context, ids []int64) ([]models.DeleteResult, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DeleteResult), args.Error(1)
+}
+
+func (m *MockListingService) ImportListings(ctx context.Context, listings []*models.Listing) ([]models.ImportResult, error) {
+	args := m.Called(ctx, listings)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ImportResult), args.Error(1)
+}
+
+func (m *MockListingService) GetAllListings(ctx context.Context) ([]*models.Listing, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetListingsByFlags(ctx context.Context, flags models.ListingFlags) ([]*models.Listing, error) {
+	args := m.Called(ctx, flags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetListingsByDepositRange(ctx context.Context, minDeposit, maxDeposit int64) ([]*models.Listing, error) {
+	args := m.Called(ctx, minDeposit, maxDeposit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetVelocityByRegion(ctx context.Context) ([]models.RegionVelocity, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RegionVelocity), args.Error(1)
+}
+
+func (m *MockListingService) GetCreatedOverTime(ctx context.Context, bucket models.TimeBucket) ([]models.TimeBucketCount, error) {
+	args := m.Called(ctx, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TimeBucketCount), args.Error(1)
+}
+
+func (m *MockListingService) GetCheapestByRegion(ctx context.Context, n int) ([]models.RegionListings, error) {
+	args := m.Called(ctx, n)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RegionListings), args.Error(1)
+}
+
+func (m *MockListingService) GetCityGroups(ctx context.Context) ([]models.CityGroup, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CityGroup), args.Error(1)
+}
+
+func (m *MockListingService) GetDepositDemand(ctx context.Context) ([]models.RegionDepositDemand, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RegionDepositDemand), args.Error(1)
+}
+
+func (m *MockListingService) GetPriceBands(ctx context.Context) ([]models.PriceBand, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PriceBand), args.Error(1)
+}
+
+func (m *MockListingService) RecomputeDerivedFields(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockListingService) WaitForChanges(ctx context.Context, since int64) (models.ListingChanges, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).(models.ListingChanges), args.Error(1)
+}
+
+func (m *MockListingService) DeleteListingPhoto(ctx context.Context, id, photoID int64) ([]models.Photo, error) {
+	args := m.Called(ctx, id, photoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Photo), args.Error(1)
+}
+
+func (m *MockListingService) AddListingPhoto(ctx context.Context, id int64, photo models.Photo) ([]models.Photo, error) {
+	args := m.Called(ctx, id, photo)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Photo), args.Error(1)
+}
+
+func (m *MockListingService) RemoveListingPhoto(ctx context.Context, id int64, originalURL string) ([]models.Photo, error) {
+	args := m.Called(ctx, id, originalURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Photo), args.Error(1)
+}
+
+func (m *MockListingService) GetBlendedYield(ctx context.Context, req models.BlendedYieldRequest) (models.BlendedYield, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(models.BlendedYield), args.Error(1)
+}
+
+func (m *MockListingService) PublishListing(ctx context.Context, id int64) (*models.Listing, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetMedianPrice(ctx context.Context, region models.Region) (models.PriceMedian, error) {
+	args := m.Called(ctx, region)
+	return args.Get(0).(models.PriceMedian), args.Error(1)
+}
+
+func (m *MockListingService) GetMedianPriceByRegion(ctx context.Context) ([]models.PriceMedian, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PriceMedian), args.Error(1)
+}
+
+func (m *MockListingService) GetPricePerSqFtByRegion(ctx context.Context) ([]models.RegionPricePerSqFt, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RegionPricePerSqFt), args.Error(1)
+}
+
+func (m *MockListingService) GetListingsInBoundingBox(ctx context.Context, box models.BoundingBox) ([]*models.Listing, error) {
+	args := m.Called(ctx, box)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) SearchByCity(ctx context.Context, city string) (models.CitySearchResult, error) {
+	args := m.Called(ctx, city)
+	return args.Get(0).(models.CitySearchResult), args.Error(1)
+}
+
+func (m *MockListingService) GetListingsNearCity(ctx context.Context, city string, radiusMiles float64) (models.NearCityResult, error) {
+	args := m.Called(ctx, city, radiusMiles)
+	return args.Get(0).(models.NearCityResult), args.Error(1)
+}
+
+func (m *MockListingService) GetDepositAnomalies(ctx context.Context) ([]models.DepositAnomaly, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DepositAnomaly), args.Error(1)
+}
+
+func (m *MockListingService) CountByPropertyType(ctx context.Context, includeEmpty bool) (map[models.PropertyType]int, error) {
+	args := m.Called(ctx, includeEmpty)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[models.PropertyType]int), args.Error(1)
+}
+
+func (m *MockListingService) GetNeighbours(ctx context.Context, id int64) ([]*models.Listing, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetNearby(ctx context.Context, id int64) ([]*models.Listing, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetMultiStats(ctx context.Context, req models.MultiStatsRequest) ([]models.ListingStats, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListingStats), args.Error(1)
+}
+
+func (m *MockListingService) GetPivot(ctx context.Context, rows, cols models.PivotDimension) (models.PivotTable, error) {
+	args := m.Called(ctx, rows, cols)
+	return args.Get(0).(models.PivotTable), args.Error(1)
+}
+
+func (m *MockListingService) UpsertListing(ctx context.Context, id int64, listing *models.Listing) (*models.Listing, bool, error) {
+	args := m.Called(ctx, id, listing)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*models.Listing), args.Bool(1), args.Error(2)
+}
+
+func (m *MockListingService) GetListingByID(ctx context.Context, id int64) (*models.Listing, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetAgentListings(ctx context.Context, agentID int64) ([]*models.Listing, error) {
+	args := m.Called(ctx, agentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetAgentListingIssues(ctx context.Context, agentID int64) ([]models.ListingQualityReport, error) {
+	args := m.Called(ctx, agentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListingQualityReport), args.Error(1)
+}
+
+func (m *MockListingService) UpdateListing(ctx context.Context, id int64, listing *models.Listing) (*models.Listing, error) {
+	args := m.Called(ctx, id, listing)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetPriceHistory(ctx context.Context, id int64) ([]models.PriceChange, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PriceChange), args.Error(1)
+}
+
+func (m *MockListingService) GetAllListingsSorted(ctx context.Context, field models.SortField, desc bool) ([]*models.Listing, error) {
+	args := m.Called(ctx, field, desc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetPriceReducedListings(ctx context.Context, since time.Time) ([]*models.Listing, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) GetFilterSchema(ctx context.Context) ([]models.FilterField, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.FilterField), args.Error(1)
+}
+
+func (m *MockListingService) LookupPostcode(ctx context.Context, code string) (models.PostcodeLookup, error) {
+	args := m.Called(ctx, code)
+	return args.Get(0).(models.PostcodeLookup), args.Error(1)
+}
+
+func (m *MockListingService) SearchListings(ctx context.Context, filter models.ListingFilter) ([]*models.Listing, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) AddTags(ctx context.Context, id int64, tags []string) ([]string, error) {
+	args := m.Called(ctx, id, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockListingService) RemoveTag(ctx context.Context, id int64, tag string) ([]string, error) {
+	args := m.Called(ctx, id, tag)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockListingService) DeleteListing(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockListingService) RestoreListing(ctx context.Context, id int64) (*models.Listing, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) PurgeDeletedListings(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockListingService) GetDeletedListings(ctx context.Context) ([]*models.Listing, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingService) ExportListing(ctx context.Context, id int64) (models.ListingExport, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.ListingExport), args.Error(1)
+}
+
+func (m *MockListingService) GetYieldHistogram(ctx context.Context, buckets int) (models.YieldHistogram, error) {
+	args := m.Called(ctx, buckets)
+	return args.Get(0).(models.YieldHistogram), args.Error(1)
+}
+
+func (m *MockListingService) GetListingPhotos(ctx context.Context, id int64) ([]models.Photo, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Photo), args.Error(1)
+}
+
+func setupListingTestRouter(handler *ListingHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api/v1")
+	{
+		listings := api.Group("/listings")
+		{
+			listings.GET("/", handler.GetAllListings)
+			listings.POST("/", handler.CreateListing)
+			listings.POST("/import", handler.ImportListings)
+			listings.POST("/bulk-delete", handler.DeleteListings)
+			listings.GET("/bbox", handler.GetListingsInBoundingBox)
+			listings.GET("/near-city", handler.GetListingsNearCity)
+			listings.GET("/by-city", handler.SearchByCity)
+			listings.GET("/search", handler.SearchListings)
+			listings.GET("/export.csv", handler.ExportListingsCSV)
+			listings.GET("/filter-schema", handler.GetFilterSchema)
+			listings.GET("/velocity", handler.GetVelocityByRegion)
+			listings.GET("/created-over-time", handler.GetCreatedOverTime)
+			listings.GET("/cheapest-by-region", handler.GetCheapestByRegion)
+			listings.GET("/deposit-demand", handler.GetDepositDemand)
+			listings.GET("/city-groups", handler.GetCityGroups)
+			listings.GET("/count-by-type", handler.CountByPropertyType)
+			listings.GET("/price-bands", handler.GetPriceBands)
+			listings.GET("/yield-histogram", handler.GetYieldHistogram)
+			listings.GET("/median-price", handler.GetMedianPrice)
+			listings.GET("/ppsf-by-region", handler.GetPricePerSqFtByRegion)
+			listings.GET("/pivot", handler.GetPivot)
+			listings.POST("/multi-stats", handler.GetMultiStats)
+			listings.GET("/changes", handler.GetChanges)
+			listings.GET("/deleted", handler.GetDeletedListings)
+			listings.GET("/:id", handler.GetListingByID)
+			listings.PUT("/:id", handler.UpsertListing)
+			listings.PATCH("/:id", handler.UpdateListing)
+			listings.DELETE("/:id", handler.DeleteListing)
+			listings.POST("/:id/publish", handler.PublishListing)
+			listings.POST("/:id/restore", handler.RestoreListing)
+			listings.GET("/:id/neighbours", handler.GetNeighbours)
+			listings.GET("/:id/nearby", handler.GetNearby)
+			listings.GET("/:id/export.json", handler.ExportListing)
+			listings.GET("/:id/photos", handler.GetListingPhotos)
+			listings.POST("/:id/photos", handler.AddListingPhoto)
+			listings.DELETE("/:id/photos", handler.RemoveListingPhoto)
+			listings.GET("/:id/price-history", handler.GetPriceHistory)
+			listings.DELETE("/:id/photos/:photoId", handler.DeleteListingPhoto)
+			listings.POST("/:id/tags", handler.AddTags)
+			listings.DELETE("/:id/tags/:tag", handler.RemoveTag)
+		}
+
+		api.GET("/postcode/:code", handler.LookupPostcode)
+
+		agents := api.Group("/agents")
+		{
+			agents.GET("/:id/listings", handler.GetAgentListings)
+			agents.GET("/:id/listings/issues", handler.GetAgentListingIssues)
+		}
+	}
+
+	return router
+}
+
+func TestListingHandler_GetListingPhotos(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "listing with photos",
+			id:   "1",
+			mockSetup: func(service *MockListingService) {
+				photos := []models.Photo{
+					{ID: 1, Position: 0, OriginalURL: "https://example.com/a.jpg", MimeType: "image/jpeg"},
+					{ID: 2, Position: 1, OriginalURL: "https://example.com/b.jpg", MimeType: "image/jpeg"},
+				}
+				service.On("GetListingPhotos", mock.Anything, int64(1)).
+					Return(photos, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []models.Photo{
+				{ID: 1, Position: 0, OriginalURL: "https://example.com/a.jpg", MimeType: "image/jpeg"},
+				{ID: 2, Position: 1, OriginalURL: "https://example.com/b.jpg", MimeType: "image/jpeg"},
+			},
+		},
+		{
+			name: "listing without photos",
+			id:   "2",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingPhotos", mock.Anything, int64(2)).
+					Return([]models.Photo{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []models.Photo{},
+		},
+		{
+			name: "invalid ID",
+			id:   "invalid",
+			mockSetup: func(service *MockListingService) {
+				// No mock setup needed for invalid ID as it will return 400 directly
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid ID parameter",
+			},
+		},
+		{
+			name: "not found",
+			id:   "999",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingPhotos", mock.Anything, int64(999)).
+					Return(nil, errors.Wrap(models.ErrNotFound, "listing not found with id: 999"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody: map[string]interface{}{
+				"error": "Listing not found",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.id+"/photos", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			expectedBody, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedBody), resp.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetListingByID(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "success",
+			id:   "1",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingByID", mock.Anything, int64(1)).
+					Return((&models.Listing{ID: 1, PriceInCents: 25000000}).WithVsRegionMedian(20000000), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   models.NewListingResponse((&models.Listing{ID: 1, PriceInCents: 25000000}).WithVsRegionMedian(20000000)),
+		},
+		{
+			name: "not found",
+			id:   "99",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingByID", mock.Anything, int64(99)).
+					Return(nil, errors.Wrap(models.ErrNotFound, "failed to get listing with id: 99"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"error": "Listing not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.id, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			expectedBody, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedBody), resp.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, resp.Body.String(), `"vsRegionMedian":25`)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetListing_Envelope(t *testing.T) {
+	service := listing.NewService(models.NewListingRepository(), &config.Config{}, nil, nil, nil)
+	router := setupListingTestRouter(NewListingHandler(service))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/187", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	var body map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Len(t, body, 3)
+	assert.JSONEq(t, `"listing"`, string(body["type"]))
+	assert.JSONEq(t, `null`, string(body["development"]))
+	var detail models.Listing
+	require.NoError(t, json.Unmarshal(body["listing"], &detail))
+	assert.Equal(t, int64(187), detail.ID)
+	assert.Equal(t, "5 Camden High Street", detail.AddressDetails.AddressLine1)
+}
+
+func TestListingHandler_ExportListingsCSV(t *testing.T) {
+	visibleAt := "2024-03-01T12:00:00Z"
+	known := &models.Listing{
+		ID:     187,
+		Status: models.ListingStatusPublished,
+		AddressDetails: models.AddressDetails{
+			AddressLine1:      "5 Camden High Street",
+			City:              "London",
+			Postcode:          "NW1 7JE",
+			ShortenedPostcode: "NW1",
+			Country:           "UK",
+			Region:            models.RegionLondon,
+		},
+		PropertyType:               models.PropertyTypeApartment,
+		Bedrooms:                   2,
+		Bathrooms:                  1,
+		SizeSqFt:                   500,
+		PriceInCents:               12500000,
+		EstimatedDepositInCents:    3125000,
+		MinimumDepositInCents:      1000000,
+		MonthlyRentalIncomeInCents: 75000,
+		GrossYield:                 0.072,
+		IsTenanted:                 true,
+		MadeVisibleAt:              &visibleAt,
+		Tags:                       []string{"garden"},
+		Photos:                     []models.Photo{{ID: 1}},
+		Description:                "Flat, near the station",
+	}
+	region := models.RegionLondon
+
+	mockService := new(MockListingService)
+	mockService.On("SearchListings", mock.Anything, models.ListingFilter{Region: &region}).
+		Return([]*models.Listing{known}, nil)
+
+	router := setupListingTestRouter(NewListingHandler(mockService))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/export.csv?region=London", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="listings.csv"`, resp.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,status,addressLine1,addressLine2,city,postcode,shortenedPostcode,country,region,"+
+		"latitude,longitude,propertyType,bedrooms,bathrooms,sizeSqFt,buildYear,"+
+		"priceInCents,estimatedDepositInCents,minimumDepositInCents,monthlyRentalIncomeInCents,"+
+		"grossYield,pricePerSqFtInCents,isCashOnly,isCompany,isFeatured,isNewBuild,isShareSale,isTenanted,"+
+		"madeVisibleAt,agentId,tags,photoCount,description\n"+
+		"187,published,5 Camden High Street,,London,NW1 7JE,NW1,UK,London,"+
+		",,apartment,2,1,500,0,"+
+		"12500000,3125000,1000000,75000,"+
+		"0.072,25000,false,false,false,false,false,true,"+
+		"2024-03-01T12:00:00Z,,garden,1,\"Flat, near the station\"\n", resp.Body.String())
+	mockService.AssertExpectations(t)
+
+	t.Run("invalid filter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/export.csv?minPrice=cheap", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestListingHandler_ExportListingsCSV_Gzip(t *testing.T) {
+	listings := make([]*models.Listing, 150)
+	for i := range listings {
+		listings[i] = &models.Listing{ID: int64(i + 1), AddressDetails: models.AddressDetails{City: "London"}}
+	}
+	mockService := new(MockListingService)
+	mockService.On("SearchListings", mock.Anything, models.ListingFilter{}).Return(listings, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Gzip(1024))
+	router.GET("/api/v1/listings/export.csv", NewListingHandler(mockService).ExportListingsCSV)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/export.csv", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, resp.Flushed)
+	// The headers as sent, not as they were left once the handler finished
+	assert.Equal(t, "gzip", resp.Result().Header.Get("Content-Encoding"))
+	reader, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	records, err := csv.NewReader(reader).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 151)
+	assert.Equal(t, "150", records[150][0])
+}
+
+func TestListingHandler_GetNearby(t *testing.T) {
+	service := listing.NewService(models.NewListingRepository(), &config.Config{}, nil, nil, nil)
+	router := setupListingTestRouter(NewListingHandler(service))
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedIDs    []int64
+	}{
+		// 66, 71 and 72 share PR1 in the sample data
+		{name: "others in the area", path: "/api/v1/listings/66/nearby", expectedStatus: http.StatusOK, expectedIDs: []int64{71, 72}},
+		{name: "same set from another listing", path: "/api/v1/listings/71/nearby", expectedStatus: http.StatusOK, expectedIDs: []int64{66, 72}},
+		{name: "not found", path: "/api/v1/listings/999/nearby", expectedStatus: http.StatusNotFound},
+		{name: "invalid id", path: "/api/v1/listings/abc/nearby", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedIDs == nil {
+				return
+			}
+			var nearby []models.Listing
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &nearby))
+			ids := make([]int64, 0, len(nearby))
+			for _, listing := range nearby {
+				ids = append(ids, listing.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestListingHandler_ExportListing(t *testing.T) {
+	pricePerSqFt := int64(31250)
+	export := models.ListingExport{
+		Listing:             &models.Listing{ID: 1, PriceInCents: 25000000, SizeSqFt: 800},
+		FormattedPrice:      "£250,000",
+		StampDutyInCents:    1500000,
+		PricePerSqFtInCents: &pricePerSqFt,
+		ExportedAt:          "2024-03-11T12:00:00Z",
+	}
+
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "success",
+			id:   "1",
+			mockSetup: func(service *MockListingService) {
+				service.On("ExportListing", mock.Anything, int64(1)).Return(export, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   export,
+		},
+		{
+			name: "not found",
+			id:   "99",
+			mockSetup: func(service *MockListingService) {
+				service.On("ExportListing", mock.Anything, int64(99)).
+					Return(models.ListingExport{}, errors.Wrap(models.ErrNotFound, "failed to export listing with id: 99"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"error": "Listing not found"},
+		},
+		{
+			name:           "invalid id",
+			id:             "abc",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid ID parameter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.id+"/export.json", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			expectedBody, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedBody), resp.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetAllListings_DepositRange(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:  "no filter",
+			query: "?view=full",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListings", mock.Anything).
+					Return([]*models.Listing{{ID: 1}, {ID: 2}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{{ID: 1}, {ID: 2}},
+		},
+		{
+			name:  "min and max",
+			query: "?view=full&minDeposit=1000000&maxDeposit=3000000",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsByDepositRange", mock.Anything, int64(1000000), int64(3000000)).
+					Return([]*models.Listing{{ID: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{{ID: 1}},
+		},
+		{
+			name:  "min only",
+			query: "?view=full&minDeposit=1000000",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsByDepositRange", mock.Anything, int64(1000000), int64(math.MaxInt64)).
+					Return([]*models.Listing{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{},
+		},
+		{
+			name:  "mortgageable excludes cash-only",
+			query: "?view=full&mortgageable=true",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListings", mock.Anything).
+					Return([]*models.Listing{{ID: 1, IsCashOnly: true}, {ID: 2}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{{ID: 2}},
+		},
+		{
+			name:  "mortgageable with deposit range",
+			query: "?view=full&minDeposit=1000000&mortgageable=true",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsByDepositRange", mock.Anything, int64(1000000), int64(math.MaxInt64)).
+					Return([]*models.Listing{{ID: 1}, {ID: 3, IsCashOnly: true}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{{ID: 1}},
+		},
+		{
+			name:  "mortgageable false keeps cash-only",
+			query: "?view=full&mortgageable=false",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListings", mock.Anything).
+					Return([]*models.Listing{{ID: 1, IsCashOnly: true}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{{ID: 1, IsCashOnly: true}},
+		},
+		{
+			name:  "region and property type",
+			query: "?view=full&region=London&propertyType=apartment",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListings", mock.Anything).
+					Return([]*models.Listing{
+						{ID: 1, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, PropertyType: models.PropertyTypeApartment},
+						{ID: 2, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, PropertyType: models.PropertyTypeDetached},
+						{ID: 3, AddressDetails: models.AddressDetails{Region: models.RegionWales}, PropertyType: models.PropertyTypeApartment},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []*models.Listing{
+				{ID: 1, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, PropertyType: models.PropertyTypeApartment},
+			},
+		},
+		{
+			name:           "invalid region",
+			query:          "?view=full&region=Atlantis",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error":   "Invalid region parameter",
+				"allowed": models.Regions,
+			},
+		},
+		{
+			name:           "invalid property type",
+			query:          "?view=full&propertyType=castle",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error":   "Invalid propertyType parameter",
+				"allowed": models.PropertyTypes,
+			},
+		},
+		{
+			name:  "max age years",
+			query: "?view=full&maxAgeYears=5",
+			mockSetup: func(service *MockListingService) {
+				year := time.Now().Year()
+				service.On("GetAllListings", mock.Anything).
+					Return([]*models.Listing{
+						{ID: 1, BuildYear: year - 2},
+						{ID: 2, BuildYear: year - 5},
+						{ID: 3, BuildYear: year - 6},
+						{ID: 4},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{{ID: 1, BuildYear: time.Now().Year() - 2}, {ID: 2, BuildYear: time.Now().Year() - 5}},
+		},
+		{
+			name:  "min photos",
+			query: "?view=full&minPhotos=2",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListings", mock.Anything).
+					Return([]*models.Listing{
+						{ID: 1, Photos: []models.Photo{{ID: 1}, {ID: 2}, {ID: 3}}},
+						{ID: 2, Photos: []models.Photo{{ID: 1}, {ID: 2}}},
+						{ID: 3, Photos: []models.Photo{{ID: 1}}},
+						{ID: 4},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []*models.Listing{
+				{ID: 1, Photos: []models.Photo{{ID: 1}, {ID: 2}, {ID: 3}}},
+				{ID: 2, Photos: []models.Photo{{ID: 1}, {ID: 2}}},
+			},
+		},
+		{
+			name:  "min photos of zero keeps listings without photos",
+			query: "?view=full&minPhotos=0",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListings", mock.Anything).
+					Return([]*models.Listing{{ID: 3, Photos: []models.Photo{{ID: 1}}}, {ID: 4}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{{ID: 3, Photos: []models.Photo{{ID: 1}}}, {ID: 4}},
+		},
+		{
+			name:  "min photos combined with region",
+			query: "?view=full&minPhotos=1&region=London",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListings", mock.Anything).
+					Return([]*models.Listing{
+						{ID: 1, Photos: []models.Photo{{ID: 1}}, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+						{ID: 2, Photos: []models.Photo{{ID: 1}}, AddressDetails: models.AddressDetails{Region: models.RegionWales}},
+						{ID: 3, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []*models.Listing{
+				{ID: 1, Photos: []models.Photo{{ID: 1}}, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+			},
+		},
+		{
+			name:           "invalid min photos",
+			query:          "?view=full&minPhotos=many",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid minPhotos parameter, must be a non-negative integer",
+			},
+		},
+		{
+			name:           "unknown filter",
+			query:          "?view=full&region=London&colour=blue",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error":   "Unknown query parameter: colour",
+				"allowed": listingQueryParams,
+			},
+		},
+		{
+			name:           "filter names are case sensitive",
+			query:          "?view=full&Region=London",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error":   "Unknown query parameter: Region",
+				"allowed": listingQueryParams,
+			},
+		},
+		{
+			name:           "invalid max age years",
+			query:          "?view=full&maxAgeYears=-1",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid maxAgeYears parameter, must be a non-negative integer",
+			},
+		},
+		{
+			name:           "invalid mortgageable",
+			query:          "?view=full&mortgageable=maybe",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid mortgageable parameter",
+			},
+		},
+		{
+			name:           "non-numeric deposit",
+			query:          "?view=full&minDeposit=abc",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid minDeposit parameter",
+			},
+		},
+		{
+			name:           "negative deposit",
+			query:          "?view=full&minDeposit=-1&maxDeposit=3000000",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid deposit range",
+			},
+		},
+		{
+			name:           "min above max",
+			query:          "?view=full&minDeposit=3000000&maxDeposit=1000000",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid deposit range",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			expectedBody, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedBody), resp.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetAllListings_MaxDescriptionLength(t *testing.T) {
+	tests := []struct {
+		name                string
+		query               string
+		expectedStatus      int
+		expectedDescription string
+	}{
+		{
+			name:                "full description by default",
+			query:               "?view=full",
+			expectedStatus:      http.StatusOK,
+			expectedDescription: "Modern flat near the station",
+		},
+		{
+			name:                "truncated",
+			query:               "?view=full&maxDescriptionLength=11",
+			expectedStatus:      http.StatusOK,
+			expectedDescription: "Modern flat",
+		},
+		{
+			name:                "omitted",
+			query:               "?view=full&maxDescriptionLength=0",
+			expectedStatus:      http.StatusOK,
+			expectedDescription: "",
+		},
+		{
+			name:           "invalid",
+			query:          "?view=full&maxDescriptionLength=-1",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := &models.Listing{ID: 1, Description: "Modern flat near the station"}
+			mockService := new(MockListingService)
+			mockService.On("GetAllListings", mock.Anything).Return([]*models.Listing{stored}, nil).Maybe()
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var listings []map[string]interface{}
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &listings))
+				require.Len(t, listings, 1)
+				assert.Equal(t, tt.expectedDescription, listings[0]["description"])
+			}
+			// The stored listing keeps its full description for other views
+			assert.Equal(t, "Modern flat near the station", stored.Description)
+		})
+	}
+}
+
+func TestListingHandler_GetAllListings_Affordable(t *testing.T) {
+	tests := []struct {
+		name               string
+		query              string
+		expectedStatus     int
+		expectedAffordable map[float64]interface{}
+	}{
+		{
+			name:               "no deposit leaves the field out",
+			query:              "",
+			expectedStatus:     http.StatusOK,
+			expectedAffordable: map[float64]interface{}{1: nil, 2: nil, 3: nil},
+		},
+		{
+			name:               "flags listings the deposit covers",
+			query:              "?deposit=2500000",
+			expectedStatus:     http.StatusOK,
+			expectedAffordable: map[float64]interface{}{1: true, 2: true, 3: false},
+		},
+		{
+			name:               "full listings without a deposit",
+			query:              "?view=full",
+			expectedStatus:     http.StatusOK,
+			expectedAffordable: map[float64]interface{}{1: nil, 2: nil, 3: nil},
+		},
+		{
+			name:               "flags full listings the deposit covers",
+			query:              "?view=full&deposit=2500000",
+			expectedStatus:     http.StatusOK,
+			expectedAffordable: map[float64]interface{}{1: true, 2: true, 3: false},
+		},
+		{
+			name:           "negative deposit",
+			query:          "?deposit=-5",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "non-numeric deposit",
+			query:          "?deposit=lots",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			mockService.On("GetAllListings", mock.Anything).Return([]*models.Listing{
+				// Only the minimum deposit has to be covered, not the estimate
+				{ID: 1, MinimumDepositInCents: 1000000, EstimatedDepositInCents: 5000000},
+				{ID: 2, MinimumDepositInCents: 2500000, EstimatedDepositInCents: 5000000},
+				{ID: 3, MinimumDepositInCents: 2500001, EstimatedDepositInCents: 2500001},
+			}, nil).Maybe()
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var listings []map[string]interface{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &listings))
+			require.Len(t, listings, len(tt.expectedAffordable))
+			for _, listing := range listings {
+				affordable, present := listing["affordable"]
+				expected := tt.expectedAffordable[listing["id"].(float64)]
+				assert.Equal(t, expected != nil, present)
+				assert.Equal(t, expected, affordable)
+			}
+		})
+	}
+}
+
+func TestListingHandler_GetAllListings_SummaryView(t *testing.T) {
+	listings := []*models.Listing{
+		{
+			ID:             1,
+			AddressDetails: models.AddressDetails{City: "Manchester", ShortenedPostcode: "M1"},
+			PriceInCents:   25000000,
+			Bedrooms:       2,
+			Description:    "Bright two bed flat",
+			Photos:         []models.Photo{{ID: 1, ThumbnailURL: "https://example.com/1-thumb.jpg"}},
+		},
+		{ID: 2, AddressDetails: models.AddressDetails{City: "Leeds", Region: models.RegionNorthEast}, PriceInCents: 15000000},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:           "summaries by default",
+			query:          "",
+			expectedStatus: http.StatusOK,
+			expectedBody: []models.ListingSummary{
+				{ID: 1, Address: "Manchester, M1", PriceInCents: 25000000, Bedrooms: 2, ThumbnailURL: "https://example.com/1-thumb.jpg"},
+				{ID: 2, Address: "Leeds", PriceInCents: 15000000},
+			},
+		},
+		{
+			name:           "summaries",
+			query:          "?view=summary",
+			expectedStatus: http.StatusOK,
+			expectedBody: []models.ListingSummary{
+				{ID: 1, Address: "Manchester, M1", PriceInCents: 25000000, Bedrooms: 2, ThumbnailURL: "https://example.com/1-thumb.jpg"},
+				{ID: 2, Address: "Leeds", PriceInCents: 15000000},
+			},
+		},
+		{
+			name:           "summaries after filtering",
+			query:          "?view=summary&region=North%20East",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []models.ListingSummary{{ID: 2, Address: "Leeds", PriceInCents: 15000000}},
+		},
+		{
+			name:           "full view",
+			query:          "?view=full",
+			expectedStatus: http.StatusOK,
+			expectedBody:   listings,
+		},
+		{
+			name:           "unknown view",
+			query:          "?view=compact",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid view parameter, must be full or summary"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			mockService.On("GetAllListings", mock.Anything).Return(listings, nil).Maybe()
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), resp.Body.String())
+		})
+	}
+}
+
+func TestListingHandler_GetCreatedOverTime(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:  "default bucket",
+			query: "",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetCreatedOverTime", mock.Anything, models.TimeBucketDay).
+					Return([]models.TimeBucketCount{{Bucket: "2023-02-01", Count: 2}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []models.TimeBucketCount{{Bucket: "2023-02-01", Count: 2}},
+		},
+		{
+			name:  "month bucket",
+			query: "?bucket=month",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetCreatedOverTime", mock.Anything, models.TimeBucketMonth).
+					Return([]models.TimeBucketCount{{Bucket: "2023-02", Count: 5}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []models.TimeBucketCount{{Bucket: "2023-02", Count: 5}},
+		},
+		{
+			name:           "invalid bucket",
+			query:          "?bucket=year",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid bucket parameter, must be one of: day, week, month",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/listings/created-over-time"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			expectedBody, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedBody), resp.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_UnknownQueryParams(t *testing.T) {
+	tests := []struct {
+		path    string
+		allowed []string
+	}{
+		{path: "/api/v1/listings/created-over-time?bucket=week&", allowed: createdOverTimeQueryParams},
+		{path: "/api/v1/listings/cheapest-by-region?n=2&", allowed: cheapestQueryParams},
+		{path: "/api/v1/listings/yield-histogram?buckets=5&", allowed: histogramQueryParams},
+		{path: "/api/v1/listings/median-price?region=London&", allowed: medianPriceQueryParams},
+		{path: "/api/v1/listings/pivot?rows=region&cols=propertyType&", allowed: pivotQueryParams},
+		{path: "/api/v1/listings/changes?since=1&", allowed: changesQueryParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// No service call is expected, so the mock fails the test if one is made
+			mockService := new(MockListingService)
+			router := setupListingTestRouter(NewListingHandler(mockService))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path+"colour=blue", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+			expected, _ := json.Marshal(gin.H{"error": "Unknown query parameter: colour", "allowed": tt.allowed})
+			assert.JSONEq(t, string(expected), resp.Body.String())
+		})
+	}
+}
+
+func TestListingHandler_GetChanges(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:  "changes since version",
+			query: "?since=3",
+			mockSetup: func(service *MockListingService) {
+				service.On("WaitForChanges", mock.Anything, int64(3)).
+					Return(models.ListingChanges{
+						Version: 4,
+						Changes: []models.ListingChange{{Version: 4, Type: models.ChangeTypeCreated, ListingID: 9}},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: models.ListingChanges{
+				Version: 4,
+				Changes: []models.ListingChange{{Version: 4, Type: models.ChangeTypeCreated, ListingID: 9}},
+			},
+		},
+		{
+			name:  "since defaults to zero",
+			query: "",
+			mockSetup: func(service *MockListingService) {
+				service.On("WaitForChanges", mock.Anything, int64(0)).
+					Return(models.ListingChanges{Changes: []models.ListingChange{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   models.ListingChanges{Changes: []models.ListingChange{}},
+		},
+		{
+			name:           "invalid since",
+			query:          "?since=-1",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid since parameter",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/changes"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_DeleteListingPhoto(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "successful deletion",
+			path: "/api/v1/listings/1/photos/11",
+			mockSetup: func(service *MockListingService) {
+				service.On("DeleteListingPhoto", mock.Anything, int64(1), int64(11)).
+					Return([]models.Photo{{ID: 10, Position: 0}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []models.Photo{{ID: 10, Position: 0}},
+		},
+		{
+			name: "not found",
+			path: "/api/v1/listings/1/photos/99",
+			mockSetup: func(service *MockListingService) {
+				service.On("DeleteListingPhoto", mock.Anything, int64(1), int64(99)).
+					Return(nil, errors.Wrap(models.ErrNotFound, "photo not found with id: 99"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody: map[string]interface{}{
+				"error": "Listing or photo not found",
+			},
+		},
+		{
+			name:           "invalid photo ID",
+			path:           "/api/v1/listings/1/photos/abc",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid photo ID parameter",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_ContextErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:           "client disconnected",
+			err:            errors.Wrap(context.Canceled, "failed to get all listings"),
+			expectedStatus: 499,
+			expectedBody:   map[string]interface{}{"error": "Request cancelled"},
+		},
+		{
+			name:           "deadline exceeded",
+			err:            context.DeadlineExceeded,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   map[string]interface{}{"error": "Request timed out"},
+		},
+		{
+			name:           "other error",
+			err:            errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   map[string]interface{}{"error": "Failed to get listings"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			mockService.On("GetAllListings", mock.Anything).Return(nil, tt.err)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_PublishListing(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "successful publish",
+			id:   "1",
+			mockSetup: func(service *MockListingService) {
+				service.On("PublishListing", mock.Anything, int64(1)).
+					Return(&models.Listing{ID: 1, Status: models.ListingStatusPublished}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   &models.Listing{ID: 1, Status: models.ListingStatusPublished},
+		},
+		{
+			name: "incomplete listing",
+			id:   "1",
+			mockSetup: func(service *MockListingService) {
+				service.On("PublishListing", mock.Anything, int64(1)).
+					Return(nil, &models.PublishError{Issues: []string{"description is required"}})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error":  "Listing cannot be published",
+				"issues": []string{"description is required"},
+			},
+		},
+		{
+			name: "not found",
+			id:   "999",
+			mockSetup: func(service *MockListingService) {
+				service.On("PublishListing", mock.Anything, int64(999)).
+					Return(nil, errors.Wrap(models.ErrNotFound, "listing not found with id: 999"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody: map[string]interface{}{
+				"error": "Listing not found",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/listings/"+tt.id+"/publish", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetListingsInBoundingBox(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:  "valid box",
+			query: "?north=51.7&south=51.3&east=0.3&west=-0.5",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsInBoundingBox", mock.Anything, models.BoundingBox{North: 51.7, South: 51.3, East: 0.3, West: -0.5}).
+					Return([]*models.Listing{{ID: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{{ID: 1}},
+		},
+		{
+			name:           "missing edge",
+			query:          "?north=51.7&south=51.3&east=0.3",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid west parameter",
+			},
+		},
+		{
+			name:           "unknown parameter",
+			query:          "?north=51.7&south=51.3&east=0.3&west=-0.5&zoom=12",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error":   "Unknown query parameter: zoom",
+				"allowed": boundingBoxQueryParams,
+			},
+		},
+		{
+			name:           "east not greater than west",
+			query:          "?north=51.7&south=51.3&east=-0.5&west=0.3",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "east must be greater than west",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/bbox"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetListingsNearCity(t *testing.T) {
+	distance := 1.5
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:  "default radius",
+			query: "?city=London",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsNearCity", mock.Anything, "London", 10.0).Return(models.NearCityResult{
+					City:        "London",
+					RadiusMiles: 10,
+					Listings:    []models.NearbyListing{{Listing: &models.Listing{ID: 1}, DistanceMiles: &distance}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: models.NearCityResult{
+				City:        "London",
+				RadiusMiles: 10,
+				Listings:    []models.NearbyListing{{Listing: &models.Listing{ID: 1}, DistanceMiles: &distance}},
+			},
+		},
+		{
+			name:  "explicit radius",
+			query: "?city=Leeds&radiusMiles=2.5",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsNearCity", mock.Anything, "Leeds", 2.5).
+					Return(models.NearCityResult{City: "Leeds", RadiusMiles: 2.5, Listings: []models.NearbyListing{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   models.NearCityResult{City: "Leeds", RadiusMiles: 2.5, Listings: []models.NearbyListing{}},
+		},
+		{
+			name:           "missing city",
+			query:          "?radiusMiles=5",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "city parameter is required"},
+		},
+		{
+			name:           "invalid radius",
+			query:          "?city=London&radiusMiles=far",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid radiusMiles parameter"},
+		},
+		{
+			name:  "unknown city",
+			query: "?city=Atlantis",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsNearCity", mock.Anything, "Atlantis", 10.0).
+					Return(models.NearCityResult{}, &models.ValidationError{Issues: []string{`unknown city "Atlantis"`}})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error":  "Invalid near-city search",
+				"issues": []string{`unknown city "Atlantis"`},
+			},
+		},
+		{
+			name:           "unknown parameter",
+			query:          "?city=London&sort=price",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error":   "Unknown query parameter: sort",
+				"allowed": nearCityQueryParams,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/near-city"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_CountByPropertyType(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:  "includes empty types by default",
+			query: "",
+			mockSetup: func(service *MockListingService) {
+				service.On("CountByPropertyType", mock.Anything, true).
+					Return(map[models.PropertyType]int{models.PropertyTypeApartment: 2, models.PropertyTypeDetached: 0}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]int{"apartment": 2, "detached": 0},
+		},
+		{
+			name:  "omits empty types",
+			query: "?includeEmpty=false",
+			mockSetup: func(service *MockListingService) {
+				service.On("CountByPropertyType", mock.Anything, false).
+					Return(map[models.PropertyType]int{models.PropertyTypeApartment: 2}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]int{"apartment": 2},
+		},
+		{
+			name:           "invalid includeEmpty",
+			query:          "?includeEmpty=maybe",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid includeEmpty parameter"},
+		},
+		{
+			name:  "service error",
+			query: "",
+			mockSetup: func(service *MockListingService) {
+				service.On("CountByPropertyType", mock.Anything, true).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   map[string]interface{}{"error": "Failed to count listings by property type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/count-by-type"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetPivot(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:  "valid dimensions",
+			query: "?rows=region&cols=propertyType",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetPivot", mock.Anything, models.PivotDimensionRegion, models.PivotDimensionPropertyType).
+					Return(models.PivotTable{Rows: "region", Cols: "propertyType", RowKeys: []string{}, ColKeys: []string{}, Counts: [][]int{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   models.PivotTable{Rows: "region", Cols: "propertyType", RowKeys: []string{}, ColKeys: []string{}, Counts: [][]int{}},
+		},
+		{
+			name:           "unknown dimension",
+			query:          "?rows=region&cols=colour",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error":   "Invalid rows or cols parameter",
+				"allowed": models.PivotDimensions,
+			},
+		},
+		{
+			name:           "same dimension twice",
+			query:          "?rows=region&cols=region",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "rows and cols must be different dimensions",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/pivot"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetYieldHistogram(t *testing.T) {
+	histogram := models.YieldHistogram{Total: 1, Buckets: []models.YieldBucket{{MinYield: 0.05, MaxYield: 0.05, Count: 1}}}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:  "default buckets",
+			query: "",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetYieldHistogram", mock.Anything, 10).Return(histogram, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   histogram,
+		},
+		{
+			name:           "too many buckets",
+			query:          "?buckets=101",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid buckets parameter, must be an integer between 1 and 100",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/yield-histogram"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetMultiStats(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "named filters",
+			body: `{"filters":[{"name":"London apartments","region":"London","propertyType":"apartment"}]}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("GetMultiStats", mock.Anything, models.MultiStatsRequest{Filters: []models.NamedStatsFilter{
+					{Name: "London apartments", Region: models.RegionLondon, PropertyType: models.PropertyTypeApartment},
+				}}).Return([]models.ListingStats{{Name: "London apartments"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []models.ListingStats{{Name: "London apartments"}},
+		},
+		{
+			name:           "duplicate names",
+			body:           `{"filters":[{"name":"a"},{"name":"a"}]}`,
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": `filter name "a" is used more than once`},
+		},
+		{
+			name:           "unknown region",
+			body:           `{"filters":[{"name":"a","region":"Atlantis"}]}`,
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": `filter "a" has an unknown region "Atlantis"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/listings/multi-stats", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetAllListings_MultiValueFilters(t *testing.T) {
+	listings := []*models.Listing{
+		{ID: 1, PropertyType: models.PropertyTypeApartment, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 2, PropertyType: models.PropertyTypeDetached, AddressDetails: models.AddressDetails{Region: models.RegionWales}},
+		{ID: 3, PropertyType: models.PropertyTypeApartment, AddressDetails: models.AddressDetails{Region: models.RegionNorthWest}},
+		{ID: 4, PropertyType: models.PropertyTypeTerraced, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+	}
+
+	tests := []struct {
+		name        string
+		queries     []string
+		expectedIDs []int64
+	}{
+		{
+			name:        "regions",
+			queries:     []string{"?region=London&region=Wales", "?region=London,Wales", "?region=London, Wales&region=London"},
+			expectedIDs: []int64{1, 2, 4},
+		},
+		{
+			name:        "property types",
+			queries:     []string{"?propertyType=apartment&propertyType=terraced", "?propertyType=apartment,terraced"},
+			expectedIDs: []int64{1, 3, 4},
+		},
+		{
+			name:        "ids",
+			queries:     []string{"?ids=2&ids=3", "?ids=2,3", "?ids=2,,3"},
+			expectedIDs: []int64{2, 3},
+		},
+		{
+			name:        "combined with other filters",
+			queries:     []string{"?region=London&region=North West&propertyType=apartment", "?region=London,North West&propertyType=apartment"},
+			expectedIDs: []int64{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, query := range tt.queries {
+			t.Run(tt.name+" "+query, func(t *testing.T) {
+				mockService := new(MockListingService)
+				mockService.On("GetAllListings", mock.Anything).Return(listings, nil)
+
+				handler := NewListingHandler(mockService)
+				router := setupListingTestRouter(handler)
+
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+strings.ReplaceAll(query, " ", "%20"), nil)
+				resp := httptest.NewRecorder()
+				router.ServeHTTP(resp, req)
+
+				require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+				var result []*models.Listing
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+				ids := make([]int64, 0, len(result))
+				for _, listing := range result {
+					ids = append(ids, listing.ID)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+			})
+		}
+	}
+
+	t.Run("an invalid value in either form is rejected", func(t *testing.T) {
+		for _, query := range []string{"?region=London&region=Atlantis", "?region=London,Atlantis"} {
+			handler := NewListingHandler(new(MockListingService))
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+		}
+	})
+}
+
+func TestListingHandler_UpdateListing(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		body           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "updates an existing listing",
+			id:   "187",
+			body: `{"propertyType":"apartment","priceInCents":12000000}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("UpdateListing", mock.Anything, int64(187), mock.AnythingOfType("*models.Listing")).
+					Return(&models.Listing{ID: 187, PriceInCents: 12000000}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   &models.Listing{ID: 187, PriceInCents: 12000000},
+		},
+		{
+			name: "missing listing",
+			id:   "999",
+			body: `{"propertyType":"apartment","priceInCents":12000000}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("UpdateListing", mock.Anything, int64(999), mock.AnythingOfType("*models.Listing")).
+					Return(nil, errors.Wrap(models.ErrNotFound, "listing not found with id: 999"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"error": "Listing not found"},
+		},
+		{
+			name: "another agent's listing",
+			id:   "187",
+			body: `{"propertyType":"apartment","priceInCents":12000000}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("UpdateListing", mock.Anything, int64(187), mock.AnythingOfType("*models.Listing")).
+					Return(nil, models.ConflictErrorf("listing 187 belongs to another agent"))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   map[string]interface{}{"error": "Conflicting listing", "issues": []string{"listing 187 belongs to another agent"}},
+		},
+		{
+			name: "invalid listing",
+			id:   "187",
+			body: `{}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("UpdateListing", mock.Anything, int64(187), mock.AnythingOfType("*models.Listing")).
+					Return(nil, &models.ValidationError{Issues: []string{"city is required"}})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid listing", "issues": []string{"city is required"}},
+		},
+		{
+			name:           "body id differs from path",
+			id:             "187",
+			body:           `{"id":5}`,
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Body id does not match the ID parameter"},
+		},
+		{
+			name:           "invalid ID",
+			id:             "abc",
+			body:           `{}`,
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid ID parameter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/listings/"+tt.id, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_UpsertListing(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		body           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "creates a new listing",
+			id:   "500",
+			body: `{"propertyType":"apartment","priceInCents":10000000}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("UpsertListing", mock.Anything, int64(500), mock.AnythingOfType("*models.Listing")).
+					Return(&models.Listing{ID: 500, PriceInCents: 10000000}, true, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   &models.Listing{ID: 500, PriceInCents: 10000000},
+		},
+		{
+			name: "updates an existing listing",
+			id:   "187",
+			body: `{"id":187,"propertyType":"apartment","priceInCents":12000000}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("UpsertListing", mock.Anything, int64(187), mock.AnythingOfType("*models.Listing")).
+					Return(&models.Listing{ID: 187, PriceInCents: 12000000}, false, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   &models.Listing{ID: 187, PriceInCents: 12000000},
+		},
+		{
+			name:           "body id differs from path",
+			id:             "187",
+			body:           `{"id":5}`,
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Body id does not match the ID parameter"},
+		},
+		{
+			name: "invalid listing",
+			id:   "500",
+			body: `{}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("UpsertListing", mock.Anything, int64(500), mock.AnythingOfType("*models.Listing")).
+					Return(nil, false, &models.ValidationError{Issues: []string{"city is required"}})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid listing", "issues": []string{"city is required"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/listings/"+tt.id, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetAgentListings(t *testing.T) {
+	ctx := context.Background()
+	agents := models.NewAgentRepository()
+	owner := &models.Agent{Name: "Jane Smith", Email: "jane@example.com"}
+	other := &models.Agent{Name: "John Doe", Email: "john@example.com"}
+	require.NoError(t, agents.Create(ctx, owner))
+	require.NoError(t, agents.Create(ctx, other))
+	repo := models.NewListingRepository()
+	newListing := func(agentID int64, status models.ListingStatus) int64 {
+		listing := &models.Listing{
+			AgentID:      agentID,
+			Status:       status,
+			PropertyType: models.PropertyTypeApartment,
+			PriceInCents: 20000000,
+			AddressDetails: models.AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "N1",
+				Region:            models.RegionLondon,
+			},
+		}
+		require.NoError(t, repo.Create(ctx, listing))
+		return listing.ID
+	}
+	published := newListing(owner.ID, models.ListingStatusPublished)
+	draft := newListing(owner.ID, models.ListingStatusDraft)
+	newListing(other.ID, models.ListingStatusPublished)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AgentIdentity())
+	router.GET("/api/v1/agents/:id/listings", NewListingHandler(listing.NewService(repo, &config.Config{}, nil, agents, nil)).GetAgentListings)
+	get := func(path string, agentID int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if agentID != 0 {
+			req.Header.Set(middleware.AgentIDHeader, fmt.Sprint(agentID))
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	listingIDs := func(resp *httptest.ResponseRecorder) []int64 {
+		var listings []*models.Listing
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &listings))
+		ids := make([]int64, 0, len(listings))
+		for _, listing := range listings {
+			ids = append(ids, listing.ID)
+		}
+		return ids
+	}
+	path := fmt.Sprintf("/api/v1/agents/%d/listings", owner.ID)
+
+	resp := get(path, owner.ID)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []int64{published, draft}, listingIDs(resp))
+
+	for _, caller := range []int64{0, other.ID} {
+		resp = get(path, caller)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, []int64{published}, listingIDs(resp))
+	}
+
+	resp = get("/api/v1/agents/99/listings", 0)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.JSONEq(t, `{"error":"Agent not found"}`, resp.Body.String())
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/agents/abc/listings", 0).Code)
+}
+
+func TestListingHandler_UpsertListing_Owner(t *testing.T) {
+	ctx := context.Background()
+	agents := models.NewAgentRepository()
+	owner := &models.Agent{Name: "Jane Smith", Email: "jane@example.com"}
+	other := &models.Agent{Name: "John Doe", Email: "john@example.com"}
+	require.NoError(t, agents.Create(ctx, owner))
+	require.NoError(t, agents.Create(ctx, other))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AgentIdentity())
+	router.PUT("/api/v1/listings/:id", NewListingHandler(listing.NewService(models.NewListingRepository(), &config.Config{}, nil, agents, nil)).UpsertListing)
+	put := func(agentID int64) *httptest.ResponseRecorder {
+		body := `{"propertyType":"apartment","priceInCents":10000000,"addressDetails":{"city":"London","shortenedPostcode":"N1"}}`
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/listings/500", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.AgentIDHeader, fmt.Sprint(agentID))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	require.Equal(t, http.StatusCreated, put(owner.ID).Code)
+	require.Equal(t, http.StatusOK, put(owner.ID).Code)
+
+	resp := put(other.ID)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"error":"Conflicting listing","issues":["listing 500 belongs to another agent"]}`, resp.Body.String())
+}
+
+func TestListingHandler_GetAgentListingIssues(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "agent with listing issues",
+			id:   "1",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAgentListingIssues", mock.Anything, int64(1)).
+					Return([]models.ListingQualityReport{{ListingID: 2, Issues: []string{"at least one photo is required"}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []models.ListingQualityReport{{ListingID: 2, Issues: []string{"at least one photo is required"}}},
+		},
+		{
+			name: "unknown agent",
+			id:   "99",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAgentListingIssues", mock.Anything, int64(99)).Return(nil, models.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"error": "Agent not found"},
+		},
+		{
+			name:           "invalid id",
+			id:             "abc",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid ID parameter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/agents/"+tt.id+"/listings/issues", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_CreateListing(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "creates a listing",
+			body: `{"propertyType":"apartment","priceInCents":10000000}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("CreateListing", mock.Anything, mock.AnythingOfType("*models.Listing")).
+					Return(&models.Listing{ID: 501, PropertyType: models.PropertyTypeApartment, PriceInCents: 10000000}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   &models.Listing{ID: 501, PropertyType: models.PropertyTypeApartment, PriceInCents: 10000000},
+		},
+		{
+			name:           "invalid body",
+			body:           `{`,
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid request body"},
+		},
+		{
+			name: "invalid listing",
+			body: `{}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("CreateListing", mock.Anything, mock.AnythingOfType("*models.Listing")).
+					Return(nil, &models.ValidationError{Issues: []string{"city is required"}})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid listing", "issues": []string{"city is required"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/listings/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_DeleteListing(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "deletes a listing",
+			id:   "187",
+			mockSetup: func(service *MockListingService) {
+				service.On("DeleteListing", mock.Anything, int64(187)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"message": "Listing deleted successfully"},
+		},
+		{
+			name: "listing not found",
+			id:   "999",
+			mockSetup: func(service *MockListingService) {
+				service.On("DeleteListing", mock.Anything, int64(999)).Return(models.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"error": "Listing not found"},
+		},
+		{
+			name:           "invalid id",
+			id:             "abc",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid ID parameter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/listings/"+tt.id, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetPriceHistory(t *testing.T) {
+	changedAt := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "repriced listing",
+			id:   "187",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetPriceHistory", mock.Anything, int64(187)).Return([]models.PriceChange{
+					{ChangedAt: changedAt, OldPriceInCents: 25000000, NewPriceInCents: 24000000},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []map[string]interface{}{
+				{"changedAt": "2024-03-01T09:00:00Z", "oldPriceInCents": 25000000, "newPriceInCents": 24000000},
+			},
+		},
+		{
+			name: "never repriced",
+			id:   "185",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetPriceHistory", mock.Anything, int64(185)).Return([]models.PriceChange{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []interface{}{},
+		},
+		{
+			name: "listing not found",
+			id:   "999",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetPriceHistory", mock.Anything, int64(999)).Return(nil, models.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"error": "Listing not found"},
+		},
+		{
+			name:           "invalid id",
+			id:             "abc",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid ID parameter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.id+"/price-history", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_LookupPostcode(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "valid postcode",
+			code: "m11ae",
+			mockSetup: func(service *MockListingService) {
+				service.On("LookupPostcode", mock.Anything, "m11ae").Return(models.PostcodeLookup{
+					Postcode:          "M1 1AE",
+					ShortenedPostcode: "M1",
+					City:              "Manchester",
+					Region:            models.RegionNorthWest,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"postcode":          "M1 1AE",
+				"shortenedPostcode": "M1",
+				"city":              "Manchester",
+				"region":            "North West",
+			},
+		},
+		{
+			name: "malformed postcode",
+			code: "NOTAPOSTCODE",
+			mockSetup: func(service *MockListingService) {
+				service.On("LookupPostcode", mock.Anything, "NOTAPOSTCODE").
+					Return(models.PostcodeLookup{}, &models.ValidationError{Issues: []string{`invalid UK postcode "NOTAPOSTCODE"`}})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error":  "Invalid postcode",
+				"issues": []string{`invalid UK postcode "NOTAPOSTCODE"`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/postcode/"+tt.code, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_SearchListings(t *testing.T) {
+	region := models.RegionLondon
+	propertyType := models.PropertyTypeApartment
+	city := "lon"
+	maxPrice := int64(15000000)
+	bedrooms := 2
+	tenanted := false
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:  "query parameters map onto the filter",
+			query: "?region=London&propertyType=apartment&city=lon&maxPrice=15000000&minBedrooms=2&maxBedrooms=2&isTenanted=false",
+			mockSetup: func(service *MockListingService) {
+				service.On("SearchListings", mock.Anything, models.ListingFilter{
+					Region:       &region,
+					PropertyType: &propertyType,
+					City:         &city,
+					MaxPrice:     &maxPrice,
+					MinBedrooms:  &bedrooms,
+					MaxBedrooms:  &bedrooms,
+					ListingFlags: models.ListingFlags{IsTenanted: &tenanted},
+				}).Return([]*models.Listing{{ID: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{{ID: 1}},
+		},
+		{
+			name: "no parameters",
+			mockSetup: func(service *MockListingService) {
+				service.On("SearchListings", mock.Anything, models.ListingFilter{}).Return([]*models.Listing{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []*models.Listing{},
+		},
+		{
+			name:           "malformed number",
+			query:          "?minPrice=cheap",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid minPrice parameter"},
+		},
+		{
+			name:           "malformed flag",
+			query:          "?isTenanted=maybe",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid isTenanted parameter"},
+		},
+		{
+			name:  "invalid filter",
+			query: "?region=Atlantis",
+			mockSetup: func(service *MockListingService) {
+				service.On("SearchListings", mock.Anything, mock.AnythingOfType("models.ListingFilter")).
+					Return(nil, &models.ValidationError{Issues: []string{`unknown region "Atlantis"`}})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid search", "issues": []string{`unknown region "Atlantis"`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/search"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_SearchListings_SizeRange(t *testing.T) {
+	service := listing.NewService(models.NewListingRepository(), &config.Config{}, nil, nil, nil)
+	router := setupListingTestRouter(NewListingHandler(service))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/search?minSize=300&maxSize=301", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	var listings []*models.Listing
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &listings))
+	ids := make([]int64, 0, len(listings))
+	for _, listing := range listings {
+		ids = append(ids, listing.ID)
+	}
+	assert.Equal(t, []int64{68, 79, 82, 103}, ids)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/listings/search?minSize=2342&maxSize=32", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.JSONEq(t, `{"error":"Invalid search","issues":["minSize cannot be greater than maxSize"]}`, resp.Body.String())
+}
+
+func TestListingHandler_GetAllListings_TagFilter(t *testing.T) {
+	listings := []*models.Listing{
+		{ID: 1, Tags: []string{"investor favourite"}},
+		{ID: 2, Tags: []string{"price reduced", "garden"}},
+		{ID: 3},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		expectedIDs []int64
+	}{
+		{name: "single tag", query: "?tag=garden", expectedIDs: []int64{2}},
+		{name: "case and spacing ignored", query: "?tag=Investor%20%20Favourite", expectedIDs: []int64{1}},
+		{name: "any of several tags", query: "?tag=garden,investor%20favourite", expectedIDs: []int64{1, 2}},
+		{name: "unknown tag", query: "?tag=penthouse", expectedIDs: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			mockService.On("GetAllListings", mock.Anything).Return(listings, nil)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+			var result []*models.Listing
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+			ids := make([]int64, 0, len(result))
+			for _, listing := range result {
+				ids = append(ids, listing.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestListingHandler_Tags(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:   "add tags",
+			method: http.MethodPost,
+			path:   "/api/v1/listings/187/tags",
+			body:   `{"tags":["Garden"]}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("AddTags", mock.Anything, int64(187), []string{"Garden"}).Return([]string{"garden"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"garden"},
+		},
+		{
+			name:           "add without tags",
+			method:         http.MethodPost,
+			path:           "/api/v1/listings/187/tags",
+			body:           `{}`,
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid request body"},
+		},
+		{
+			name:   "add invalid tags",
+			method: http.MethodPost,
+			path:   "/api/v1/listings/187/tags",
+			body:   `{"tags":[" "]}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("AddTags", mock.Anything, int64(187), []string{" "}).
+					Return(nil, &models.ValidationError{Issues: []string{"at least one tag is required"}})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Invalid tags", "issues": []string{"at least one tag is required"}},
+		},
+		{
+			name:   "remove tag",
+			method: http.MethodDelete,
+			path:   "/api/v1/listings/187/tags/price%20reduced",
+			mockSetup: func(service *MockListingService) {
+				service.On("RemoveTag", mock.Anything, int64(187), "price reduced").Return([]string{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{},
+		},
+		{
+			name:   "remove missing tag",
+			method: http.MethodDelete,
+			path:   "/api/v1/listings/187/tags/garden",
+			mockSetup: func(service *MockListingService) {
+				service.On("RemoveTag", mock.Anything, int64(187), "garden").Return(nil, models.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"error": "Listing or tag not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetAllListings_PriceReduced(t *testing.T) {
+	listings := []*models.Listing{{ID: 1}, {ID: 2}}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedIDs    []int64
+	}{
+		{
+			name:  "only reduced listings",
+			query: "?priceReduced=true",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListings", mock.Anything).Return(listings, nil)
+				service.On("GetPriceReducedListings", mock.Anything, time.Time{}).Return([]*models.Listing{{ID: 2}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{2},
+		},
+		{
+			name:  "within a window",
+			query: "?priceReduced=true&priceReducedWithinDays=30",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListings", mock.Anything).Return(listings, nil)
+				service.On("GetPriceReducedListings", mock.Anything, mock.MatchedBy(func(since time.Time) bool {
+					return since.Before(time.Now().AddDate(0, 0, -29)) && since.After(time.Now().AddDate(0, 0, -31))
+				})).Return([]*models.Listing{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{},
+		},
+		{
+			name:  "filter off",
+			query: "?priceReduced=false",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListings", mock.Anything).Return(listings, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{1, 2},
+		},
+		{
+			name:           "window without priceReduced",
+			query:          "?priceReducedWithinDays=30",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid priceReduced",
+			query:          "?priceReduced=maybe",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expectedStatus, resp.Code, resp.Body.String())
+			if tt.expectedIDs != nil {
+				var result []*models.Listing
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+				ids := make([]int64, 0, len(result))
+				for _, listing := range result {
+					ids = append(ids, listing.ID)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetFilterSchema(t *testing.T) {
+	mockService := new(MockListingService)
+	mockService.On("GetFilterSchema", mock.Anything).Return(models.BuildFilterSchema([]*models.Listing{{PriceInCents: 100}}), nil)
+
+	handler := NewListingHandler(mockService)
+	router := setupListingTestRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/filter-schema", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	var schema []models.FilterField
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &schema))
+
+	// Every parameter the search endpoint accepts must be described
+	var params []string
+	for _, field := range schema {
+		params = append(params, field.Params...)
+	}
+	assert.ElementsMatch(t, searchQueryParams, params)
+	mockService.AssertExpectations(t)
+}
+
+func TestListingHandler_GetFilterSchema_Error(t *testing.T) {
+	mockService := new(MockListingService)
+	mockService.On("GetFilterSchema", mock.Anything).Return(nil, errors.New("boom"))
+
+	handler := NewListingHandler(mockService)
+	router := setupListingTestRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/filter-schema", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.JSONEq(t, `{"error":"Failed to get filter schema"}`, resp.Body.String())
+}
+
+func TestListingHandler_GetAllListings_Sort(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedIDs    []int64
+	}{
+		{
+			name:  "sort descending",
+			query: "?sort=price&order=desc",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListingsSorted", mock.Anything, models.SortByPrice, true).
+					Return([]*models.Listing{{ID: 3}, {ID: 1}, {ID: 2}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{3, 1, 2},
+		},
+		{
+			name:  "sort ascending by default",
+			query: "?sort=yield",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListingsSorted", mock.Anything, models.SortByYield, false).
+					Return([]*models.Listing{{ID: 2}, {ID: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{2, 1},
+		},
+		{
+			name:  "sort by price per square foot",
+			query: "?sort=pricePerSqFt",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetAllListingsSorted", mock.Anything, models.SortByPricePerSqFt, false).
+					Return([]*models.Listing{{ID: 4}, {ID: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{4, 1},
+		},
+		{
+			name:  "sort a deposit range",
+			query: "?minDeposit=0&maxDeposit=100000000&sort=bedrooms&order=desc",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsByDepositRange", mock.Anything, int64(0), int64(100000000)).
+					Return([]*models.Listing{{ID: 1, Bedrooms: 1}, {ID: 2, Bedrooms: 3}, {ID: 3, Bedrooms: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{2, 1, 3},
+		},
+		{
+			name:           "unknown sort field",
+			query:          "?sort=colour",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid order",
+			query:          "?sort=price&order=up",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "order without sort",
+			query:          "?order=desc",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expectedStatus, resp.Code, resp.Body.String())
+			if tt.expectedIDs != nil {
+				var result []*models.Listing
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+				ids := make([]int64, 0, len(result))
+				for _, listing := range result {
+					ids = append(ids, listing.ID)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetDepositDemand(t *testing.T) {
+	mockService := new(MockListingService)
+	mockService.On("GetDepositDemand", mock.Anything).Return([]models.RegionDepositDemand{
+		{Region: models.RegionLondon, ListingCount: 2, TotalMinimumDepositInCents: 12500000},
+	}, nil)
+
+	handler := NewListingHandler(mockService)
+	router := setupListingTestRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/deposit-demand", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `[{"region":"London","listingCount":2,"totalMinimumDepositInCents":12500000}]`, resp.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestListingHandler_GetPricePerSqFtByRegion(t *testing.T) {
+	average := 55000.0
+	mockService := new(MockListingService)
+	mockService.On("GetPricePerSqFtByRegion", mock.Anything).Return([]models.RegionPricePerSqFt{
+		{Region: models.RegionLondon, Count: 2, ExcludedCount: 1, AveragePricePerSqFtInCents: &average},
+		{Region: models.RegionWales, ExcludedCount: 1},
+	}, nil)
+
+	handler := NewListingHandler(mockService)
+	router := setupListingTestRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/ppsf-by-region", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `[
+		{"region":"London","count":2,"excludedCount":1,"averagePricePerSqFtInCents":55000},
+		{"region":"Wales","count":0,"excludedCount":1,"averagePricePerSqFtInCents":null}
+	]`, resp.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestListingHandler_GetAllListings_Flags(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedIDs    []int64
+	}{
+		{
+			name:  "single flag",
+			query: "?isShareSale=true",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsByFlags", mock.Anything, models.ListingFlags{IsShareSale: &yes}).
+					Return([]*models.Listing{{ID: 1}, {ID: 2}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{1, 2},
+		},
+		{
+			name:  "several flags",
+			query: "?isShareSale=true&isCashOnly=true",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsByFlags", mock.Anything, models.ListingFlags{IsShareSale: &yes, IsCashOnly: &yes}).
+					Return([]*models.Listing{{ID: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{1},
+		},
+		{
+			name:  "false flag",
+			query: "?isCompany=false",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsByFlags", mock.Anything, models.ListingFlags{IsCompany: &no}).
+					Return([]*models.Listing{{ID: 1}, {ID: 2}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{1, 2},
+		},
+		{
+			name:  "sorted",
+			query: "?isShareSale=true&sort=price&order=desc",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsByFlags", mock.Anything, models.ListingFlags{IsShareSale: &yes}).
+					Return([]*models.Listing{{ID: 1, PriceInCents: 100}, {ID: 2, PriceInCents: 200}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{2, 1},
+		},
+		{
+			name:  "with a deposit range",
+			query: "?isShareSale=true&minDeposit=1000000",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsByDepositRange", mock.Anything, int64(1000000), int64(math.MaxInt64)).
+					Return([]*models.Listing{{ID: 1, IsShareSale: true}, {ID: 2}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int64{1},
+		},
+		{
+			name:  "lookup failure",
+			query: "?isShareSale=true",
+			mockSetup: func(service *MockListingService) {
+				service.On("GetListingsByFlags", mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid flag",
+			query:          "?isCompany=sometimes",
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expectedStatus, resp.Code, resp.Body.String())
+			if tt.expectedIDs != nil {
+				var result []*models.Listing
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+				assert.Equal(t, tt.expectedIDs, listingIDsOf(result))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_BulkEndpoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "import with mixed results",
+			path: "/api/v1/listings/import",
+			body: `[{"priceInCents": 100}, {}]`,
+			mockSetup: func(service *MockListingService) {
+				service.On("ImportListings", mock.Anything, mock.Anything).Return([]models.ImportResult{
+					{Index: 0, ID: 7, Status: http.StatusCreated, Created: true, Errors: []string{}, Warnings: []string{}},
+					{Index: 1, Status: http.StatusBadRequest, Errors: []string{"city is required"}, Warnings: []string{}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"succeeded": 1, "failed": 1, "results": [
+				{"index": 0, "id": 7, "status": 201, "created": true, "errors": [], "warnings": []},
+				{"index": 1, "status": 400, "created": false, "errors": ["city is required"], "warnings": []}
+			]}`,
+		},
+		{
+			name: "delete with mixed results",
+			path: "/api/v1/listings/bulk-delete",
+			body: `{"ids": [1, 2]}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("DeleteListings", mock.Anything, []int64{1, 2}).Return([]models.DeleteResult{
+					{ID: 1, Status: http.StatusOK},
+					{ID: 2, Status: http.StatusNotFound, Error: "listing not found"},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"succeeded": 1, "failed": 1, "results": [
+				{"id": 1, "status": 200},
+				{"id": 2, "status": 404, "error": "listing not found"}
+			]}`,
+		},
+		{
+			name:           "delete without ids",
+			path:           "/api/v1/listings/bulk-delete",
+			body:           `{"ids": []}`,
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error": "Invalid request body"}`,
+		},
+		{
+			name: "delete batch interrupted",
+			path: "/api/v1/listings/bulk-delete",
+			body: `{"ids": [1]}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("DeleteListings", mock.Anything, []int64{1}).Return(nil, errors.New("boom"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error": "Failed to delete listings"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListingHandler_GetCityGroups(t *testing.T) {
+	groups := []models.CityGroup{
+		{City: "Leeds", ListingCount: 3},
+		{City: "London", ListingCount: 2},
+		{City: "Bristol", ListingCount: 1},
+		{City: "Cardiff", ListingCount: 1},
+		{City: "York", ListingCount: 1},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCities []string
+		expectedMeta   models.PageMeta
+	}{
+		{
+			name:           "default page",
+			expectedStatus: http.StatusOK,
+			expectedCities: []string{"Leeds", "London", "Bristol", "Cardiff", "York"},
+			expectedMeta:   models.PageMeta{Page: 1, PageSize: 20, TotalItems: 5, TotalPages: 1},
+		},
+		{
+			name:           "first page",
+			query:          "?pageSize=2",
+			expectedStatus: http.StatusOK,
+			expectedCities: []string{"Leeds", "London"},
+			expectedMeta:   models.PageMeta{Page: 1, PageSize: 2, TotalItems: 5, TotalPages: 3},
+		},
+		{
+			name:           "second page",
+			query:          "?page=2&pageSize=2",
+			expectedStatus: http.StatusOK,
+			expectedCities: []string{"Bristol", "Cardiff"},
+			expectedMeta:   models.PageMeta{Page: 2, PageSize: 2, TotalItems: 5, TotalPages: 3},
+		},
+		{
+			name:           "last page",
+			query:          "?page=3&pageSize=2",
+			expectedStatus: http.StatusOK,
+			expectedCities: []string{"York"},
+			expectedMeta:   models.PageMeta{Page: 3, PageSize: 2, TotalItems: 5, TotalPages: 3},
+		},
+		{
+			name:           "past the end",
+			query:          "?page=4&pageSize=2",
+			expectedStatus: http.StatusOK,
+			expectedCities: []string{},
+			expectedMeta:   models.PageMeta{Page: 4, PageSize: 2, TotalItems: 5, TotalPages: 3},
+		},
+		{
+			name:           "huge page",
+			query:          "?page=9223372036854775807&pageSize=2",
+			expectedStatus: http.StatusOK,
+			expectedCities: []string{},
+			expectedMeta:   models.PageMeta{Page: math.MaxInt64, PageSize: 2, TotalItems: 5, TotalPages: 3},
+		},
+		{name: "page zero", query: "?page=0", expectedStatus: http.StatusBadRequest},
+		{name: "page size too large", query: "?pageSize=101", expectedStatus: http.StatusBadRequest},
+		{name: "unknown parameter", query: "?limit=2", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			mockService.On("GetCityGroups", mock.Anything).Return(groups, nil).Maybe()
+
+			handler := NewListingHandler(mockService)
+			router := setupListingTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listings/city-groups"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expectedStatus, resp.Code, resp.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var paged models.Paged[models.CityGroup]
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &paged))
+			cities := make([]string, 0, len(paged.Items))
+			for _, group := range paged.Items {
+				cities = append(cities, group.City)
+			}
+			assert.Equal(t, tt.expectedCities, cities)
+			assert.Equal(t, tt.expectedMeta, paged.Pagination)
+		})
+	}
+}
+
+func TestListingHandler_AddAndRemoveListingPhoto(t *testing.T) {
+	repo := models.NewListingRepository()
+	created := &models.Listing{
+		PropertyType:   models.PropertyTypeApartment,
+		PriceInCents:   20000000,
+		AddressDetails: models.AddressDetails{City: "London", ShortenedPostcode: "N1", Region: models.RegionLondon},
+	}
+	require.NoError(t, repo.Create(context.Background(), created))
+	service := listing.NewService(repo, &config.Config{}, nil, nil, nil)
+	router := setupListingTestRouter(NewListingHandler(service))
+	photosPath := fmt.Sprintf("/api/v1/listings/%d/photos", created.ID)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	photoBody := func(name, mimeType string) string {
+		return fmt.Sprintf(`{"originalURL":"https://example.com/%[1]s","standardURL":"https://example.com/%[1]s_standard","thumbnailURL":"https://example.com/%[1]s_thumbnail","mimeType":%[2]q}`, name, mimeType)
+	}
+
+	resp := serve(http.MethodPost, photosPath, photoBody("a.jpg", "image/jpeg"))
+	require.Equal(t, http.StatusCreated, resp.Code)
+	resp = serve(http.MethodPost, photosPath, photoBody("b.png", "image/png"))
+	require.Equal(t, http.StatusCreated, resp.Code)
+	var photos []models.Photo
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &photos))
+	require.Len(t, photos, 2)
+	assert.Equal(t, "https://example.com/b.png", photos[1].OriginalURL)
+	assert.Equal(t, 1, photos[1].Position)
+
+	resp = serve(http.MethodGet, photosPath, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &photos))
+	assert.Len(t, photos, 2)
+
+	resp = serve(http.MethodPost, photosPath, photoBody("a.jpg", "image/jpeg"))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"error":"Conflicting photo","issues":["photo \"https://example.com/a.jpg\" is already on the listing"]}`, resp.Body.String())
+
+	resp = serve(http.MethodPost, photosPath, photoBody("c.gif", "image/gif"))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.JSONEq(t, `{"error":"Invalid photo","issues":["mimeType \"image/gif\" is not one of image/jpeg, image/png, image/webp"]}`, resp.Body.String())
+
+	resp = serve(http.MethodPost, "/api/v1/listings/999/photos", photoBody("c.jpg", "image/jpeg"))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = serve(http.MethodDelete, photosPath+"?originalURL=https://example.com/a.jpg", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &photos))
+	require.Len(t, photos, 1)
+	assert.Equal(t, "https://example.com/b.png", photos[0].OriginalURL)
+	assert.Equal(t, 0, photos[0].Position)
+
+	resp = serve(http.MethodDelete, photosPath+"?originalURL=https://example.com/a.jpg", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = serve(http.MethodDelete, photosPath, "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestListingHandler_DeleteAndRestoreListing(t *testing.T) {
+	service := listing.NewService(models.NewListingRepository(), &config.Config{}, nil, nil, nil)
+	router := setupListingTestRouter(NewListingHandler(service))
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	listingIDs := func(resp *httptest.ResponseRecorder) []int64 {
+		var listings []*models.Listing
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &listings))
+		ids := make([]int64, 0, len(listings))
+		for _, listing := range listings {
+			ids = append(ids, listing.ID)
+		}
+		return ids
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodDelete, "/api/v1/listings/187").Code)
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/v1/listings/187").Code)
+	resp := serve(http.MethodGet, "/api/v1/listings/")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, listingIDs(resp), int64(187))
+	resp = serve(http.MethodGet, "/api/v1/listings/search?minPrice=1")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, listingIDs(resp), int64(187))
+
+	resp = serve(http.MethodGet, "/api/v1/listings/deleted")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []int64{187}, listingIDs(resp))
+
+	resp = serve(http.MethodPost, "/api/v1/listings/187/restore")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var restored models.Listing
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &restored))
+	assert.Equal(t, int64(187), restored.ID)
+	assert.Nil(t, restored.DeletedAt)
+	assert.NotContains(t, resp.Body.String(), "deletedAt")
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/listings/187").Code)
+	resp = serve(http.MethodGet, "/api/v1/listings/deleted")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, listingIDs(resp))
+
+	resp = serve(http.MethodPost, "/api/v1/listings/187/restore")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.JSONEq(t, `{"error":"Deleted listing not found"}`, resp.Body.String())
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/api/v1/listings/abc/restore").Code)
+}
+
+func TestListingHandler_RestoreListing_Conflict(t *testing.T) {
+	service := new(MockListingService)
+	service.On("RestoreListing", mock.Anything, int64(187)).
+		Return(nil, models.ConflictErrorf("listing 187 cannot be restored as listing 201 has the same address"))
+	router := setupListingTestRouter(NewListingHandler(service))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/api/v1/listings/187/restore", nil))
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"error":"Conflicting listing","issues":["listing 187 cannot be restored as listing 201 has the same address"]}`, resp.Body.String())
+	service.AssertExpectations(t)
+}
+
+func TestListingHandler_RemoveListingPhoto_PublishProfile(t *testing.T) {
+	repo := models.NewListingRepository()
+	newListing := func(status models.ListingStatus) *models.Listing {
+		listing := &models.Listing{
+			Status:       status,
+			PropertyType: models.PropertyTypeApartment,
+			PriceInCents: 20000000,
+			Description:  "Bright flat",
+			AddressDetails: models.AddressDetails{
+				City:              "London",
+				Postcode:          "N1 1AA",
+				ShortenedPostcode: "N1",
+				Region:            models.RegionLondon,
+			},
+			Photos: []models.Photo{{
+				OriginalURL:  "https://example.com/a.jpg",
+				StandardURL:  "https://example.com/a_standard.jpg",
+				ThumbnailURL: "https://example.com/a_thumbnail.jpg",
+				MimeType:     "image/jpeg",
+			}},
+		}
+		require.NoError(t, repo.Create(context.Background(), listing))
+		return listing
+	}
+	published := newListing(models.ListingStatusPublished)
+	draft := newListing(models.ListingStatusDraft)
+	service := listing.NewService(repo, &config.Config{}, nil, nil, nil)
+	router := setupListingTestRouter(NewListingHandler(service))
+	removePath := func(id int64) string {
+		return fmt.Sprintf("/api/v1/listings/%d/photos?originalURL=https://example.com/a.jpg", id)
+	}
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, removePath(published.ID), nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{"error":"Listing would no longer meet its publish profile","issues":["at least one photo is required"]}`, resp.Body.String())
+	photos, err := repo.ListPhotos(context.Background(), published.ID)
+	require.NoError(t, err)
+	assert.Len(t, photos, 1)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, removePath(draft.ID), nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `[]`, resp.Body.String())
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageQuery parses the optional page and pageSize query parameters used by
+// paginated endpoints, defaulting to the first page of defaultPageSize items.
+// It writes a 400 response and returns false if either is out of range.
+func pageQuery(c *gin.Context) (models.Page, bool) {
+	page := models.Page{Number: 1, Size: defaultPageSize}
+	if value := c.Query("page"); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page parameter, must be a positive integer"})
+			return page, false
+		}
+		page.Number = number
+	}
+	if value := c.Query("pageSize"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pageSize parameter, must be between 1 and 100"})
+			return page, false
+		}
+		page.Size = size
+	}
+	return page, true
+}
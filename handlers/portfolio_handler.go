@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/getground/interview-backend-golang/internal/app/listing"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type PortfolioHandler struct {
+	listingService listing.Service
+}
+
+func NewPortfolioHandler(listingService listing.Service) *PortfolioHandler {
+	return &PortfolioHandler{
+		listingService: listingService,
+	}
+}
+
+func (h *PortfolioHandler) GetBlendedYield(c *gin.Context) {
+	var req models.BlendedYieldRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.listingService.GetBlendedYield(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		if writeContextError(c, err) {
+			return
+		}
+		writeInternalError(c, err, "Failed to calculate blended yield")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupPortfolioTestRouter(handler *PortfolioHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api/v1")
+	{
+		portfolio := api.Group("/portfolio")
+		{
+			portfolio.POST("/blended-yield", handler.GetBlendedYield)
+		}
+	}
+
+	return router
+}
+
+func TestPortfolioHandler_GetBlendedYield(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockListingService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "successful calculation",
+			body: `{"listings":[{"id":1},{"id":2}]}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("GetBlendedYield", mock.Anything, mock.AnythingOfType("models.BlendedYieldRequest")).
+					Return(models.BlendedYield{
+						BlendedGrossYield: 0.06,
+						Holdings: []models.HoldingYield{
+							{ListingID: 1, GrossYield: 0.04, Weight: 0.5},
+							{ListingID: 2, GrossYield: 0.08, Weight: 0.5},
+						},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: models.BlendedYield{
+				BlendedGrossYield: 0.06,
+				Holdings: []models.HoldingYield{
+					{ListingID: 1, GrossYield: 0.04, Weight: 0.5},
+					{ListingID: 2, GrossYield: 0.08, Weight: 0.5},
+				},
+			},
+		},
+		{
+			name:           "negative weight",
+			body:           `{"listings":[{"id":1,"weight":-1},{"id":2,"weight":1}]}`,
+			mockSetup:      func(service *MockListingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "weight for listing 1 must not be negative",
+			},
+		},
+		{
+			name: "listing not found",
+			body: `{"listings":[{"id":999}]}`,
+			mockSetup: func(service *MockListingService) {
+				service.On("GetBlendedYield", mock.Anything, mock.AnythingOfType("models.BlendedYieldRequest")).
+					Return(models.BlendedYield{}, errors.Wrap(models.ErrNotFound, "listing not found with id: 999"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody: map[string]interface{}{
+				"error": "Listing not found",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockListingService)
+			tt.mockSetup(mockService)
+
+			handler := NewPortfolioHandler(mockService)
+			router := setupPortfolioTestRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/portfolio/blended-yield", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
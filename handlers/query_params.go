@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Allowlists of the query parameters each endpoint understands. A new filter
+// or sort key must be added here before a handler can read it. Deployments can
+// narrow them further with the server.disabled_query_params and
+// server.disabled_sort_fields settings.
+var (
+	listingQueryParams = []string{
+		"minDeposit", "maxDeposit", "deposit", "mortgageable", "region",
+		"propertyType", "ids", "tag", "maxAgeYears", "minPhotos", "maxDescriptionLength", "view",
+		"priceReduced", "priceReducedWithinDays", "sort", "order",
+		"isTenanted", "isCashOnly", "isNewBuild", "isShareSale", "isCompany",
+	}
+	pageQueryParams            = []string{"page", "pageSize"}
+	boundingBoxQueryParams     = []string{"north", "south", "east", "west", "maxDescriptionLength"}
+	citySearchQueryParams      = []string{"city", "maxDescriptionLength"}
+	nearCityQueryParams        = []string{"city", "radiusMiles"}
+	countByTypeQueryParams     = []string{"includeEmpty"}
+	emailQueryParams           = []string{"email"}
+	removePhotoQueryParams     = []string{"originalURL"}
+	purgeQueryParams           = []string{"olderThan"}
+	importQueryParams          = []string{"replace"}
+	createdOverTimeQueryParams = []string{"bucket"}
+	cheapestQueryParams        = []string{"n"}
+	histogramQueryParams       = []string{"buckets"}
+	medianPriceQueryParams     = []string{"region", "groupBy"}
+	pivotQueryParams           = []string{"rows", "cols"}
+	changesQueryParams         = []string{"since"}
+	searchQueryParams          = []string{
+		"region", "propertyType", "city", "minPrice", "maxPrice", "minBedrooms",
+		"maxBedrooms", "minBathrooms", "maxBathrooms", "minSize", "maxSize", "isTenanted",
+		"isCashOnly", "isNewBuild", "isShareSale", "isCompany",
+	}
+)
+
+// checkQueryParams writes a 400 response naming the first unknown query
+// parameter and returns false if the request uses any key not in allowed
+func checkQueryParams(c *gin.Context, allowed []string) bool {
+	query := c.Request.URL.Query()
+	unknown := make([]string, 0)
+	for key := range query {
+		if !slices.Contains(allowed, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return true
+	}
+	slices.Sort(unknown)
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown query parameter: " + unknown[0], "allowed": allowed})
+	return false
+}
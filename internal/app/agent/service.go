@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+type Service interface {
+	CreateAgent(ctx context.Context, name, email string) (*models.Agent, error)
+	GetAgentByID(ctx context.Context, id int64) (*models.Agent, error)
+}
+
+type service struct {
+	repo models.AgentRepository
+}
+
+func NewService(repo models.AgentRepository) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// CreateAgent registers an agent, whose ID can then be sent as X-Agent-ID to
+// own listings. A missing name or email is a validation error and an email
+// another agent uses is a conflict.
+func (s *service) CreateAgent(ctx context.Context, name, email string) (*models.Agent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	agent := &models.Agent{
+		Name:  strings.TrimSpace(name),
+		Email: strings.TrimSpace(email),
+	}
+	if err := s.repo.Create(ctx, agent); err != nil {
+		return nil, errors.Wrap(err, "failed to create agent")
+	}
+	return agent, nil
+}
+
+func (s *service) GetAgentByID(ctx context.Context, id int64) (*models.Agent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	agent, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get agent with id: %d", id)
+	}
+	return agent, nil
+}
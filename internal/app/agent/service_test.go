@@ -0,0 +1,31 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CreateAgent(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(models.NewAgentRepository())
+
+	agent, err := service.CreateAgent(ctx, " Jane Smith ", "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Smith", agent.Name)
+	assert.NotZero(t, agent.ID)
+
+	found, err := service.GetAgentByID(ctx, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, agent, found)
+
+	_, err = service.CreateAgent(ctx, "Jane Doe", "JANE@example.com")
+	assert.ErrorIs(t, err, models.ErrConflict)
+	_, err = service.CreateAgent(ctx, "  ", "john@example.com")
+	assert.ErrorIs(t, err, models.ErrValidation)
+	_, err = service.GetAgentByID(ctx, 99)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
@@ -2,6 +2,7 @@ package example
 
 import (
 	"context"
+	"strings"
 
 	"github.com/getground/interview-backend-golang/models"
 	"github.com/pkg/errors"
@@ -10,6 +11,7 @@ import (
 type Service interface {
 	CreateExample(ctx context.Context, name, email string) (*models.ExampleModel, error)
 	GetExampleByID(ctx context.Context, id int64) (*models.ExampleModel, error)
+	GetExampleByEmail(ctx context.Context, email string) (*models.ExampleModel, error)
 	GetAllExamples(ctx context.Context) ([]*models.ExampleModel, error)
 	UpdateExample(ctx context.Context, id int64, name, email string) (*models.ExampleModel, error)
 	DeleteExample(ctx context.Context, id int64) error
@@ -26,11 +28,14 @@ func NewService(repo models.ExampleRepository) Service {
 }
 
 func (s *service) CreateExample(ctx context.Context, name, email string) (*models.ExampleModel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if name == "" {
-		return nil, errors.New("name is required")
+		return nil, models.ValidationErrorf("name is required")
 	}
 	if email == "" {
-		return nil, errors.New("email is required")
+		return nil, models.ValidationErrorf("email is required")
 	}
 	example := &models.ExampleModel{
 		Name:  name,
@@ -44,6 +49,9 @@ func (s *service) CreateExample(ctx context.Context, name, email string) (*model
 }
 
 func (s *service) GetExampleByID(ctx context.Context, id int64) (*models.ExampleModel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	example, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get example with id: %d", id)
@@ -51,7 +59,27 @@ func (s *service) GetExampleByID(ctx context.Context, id int64) (*models.Example
 	return example, nil
 }
 
+// GetExampleByEmail looks the example up by email, ignoring case and
+// surrounding whitespace
+func (s *service) GetExampleByEmail(ctx context.Context, email string) (*models.ExampleModel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, models.ValidationErrorf("email is required")
+	}
+	example, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get example with email: %s", email)
+	}
+	return example, nil
+}
+
 func (s *service) GetAllExamples(ctx context.Context) ([]*models.ExampleModel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	examples, err := s.repo.GetAll(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get all examples")
@@ -60,11 +88,14 @@ func (s *service) GetAllExamples(ctx context.Context) ([]*models.ExampleModel, e
 }
 
 func (s *service) UpdateExample(ctx context.Context, id int64, name, email string) (*models.ExampleModel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if name == "" {
-		return nil, errors.New("name is required")
+		return nil, models.ValidationErrorf("name is required")
 	}
 	if email == "" {
-		return nil, errors.New("email is required")
+		return nil, models.ValidationErrorf("email is required")
 	}
 	example := &models.ExampleModel{
 		ID:    id,
@@ -79,6 +110,9 @@ func (s *service) UpdateExample(ctx context.Context, id int64, name, email strin
 }
 
 func (s *service) DeleteExample(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	err := s.repo.Delete(ctx, id)
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete example with id: %d", id)
@@ -5,7 +5,6 @@ import (
 	"testing"
 
 	"github.com/getground/interview-backend-golang/models"
-	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -27,6 +26,14 @@ func (m *MockExampleRepository) GetByID(ctx context.Context, id int64) (*models.
 	return args.Get(0).(*models.ExampleModel), args.Error(1)
 }
 
+func (m *MockExampleRepository) GetByEmail(ctx context.Context, email string) (*models.ExampleModel, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExampleModel), args.Error(1)
+}
+
 func (m *MockExampleRepository) GetAll(ctx context.Context) ([]*models.ExampleModel, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -103,6 +110,26 @@ func TestService_CreateExample(t *testing.T) {
 	}
 }
 
+func TestService_GetExampleByEmail(t *testing.T) {
+	expected := &models.ExampleModel{ID: 1, Name: "John Doe", Email: "john@example.com"}
+	mockRepo := new(MockExampleRepository)
+	mockRepo.On("GetByEmail", mock.Anything, "John@Example.com").Return(expected, nil)
+	mockRepo.On("GetByEmail", mock.Anything, "nobody@example.com").Return(nil, models.ErrNotFound)
+	service := NewService(mockRepo)
+
+	result, err := service.GetExampleByEmail(context.Background(), " John@Example.com ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	_, err = service.GetExampleByEmail(context.Background(), "nobody@example.com")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = service.GetExampleByEmail(context.Background(), "  ")
+	assert.ErrorIs(t, err, models.ErrValidation)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestService_GetExampleByID(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -129,7 +156,7 @@ func TestService_GetExampleByID(t *testing.T) {
 			inputID: 999,
 			mockSetup: func(repo *MockExampleRepository) {
 				repo.On("GetByID", mock.Anything, int64(999)).
-					Return(nil, errors.New("not found"))
+					Return(nil, models.ErrNotFound)
 			},
 			expectedError: true,
 		},
@@ -157,4 +184,3 @@ func TestService_GetExampleByID(t *testing.T) {
 		})
 	}
 }
-```
@@ -0,0 +1,76 @@
+package favorites
+
+import (
+	"context"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+type Service interface {
+	AddFavorite(ctx context.Context, userID, listingID int64) (bool, error)
+	RemoveFavorite(ctx context.Context, userID, listingID int64) error
+	ListFavorites(ctx context.Context, userID int64) ([]*models.Listing, error)
+}
+
+type service struct {
+	store    models.FavoritesStore
+	listings models.ListingRepository
+}
+
+func NewService(store models.FavoritesStore, listings models.ListingRepository) Service {
+	return &service{
+		store:    store,
+		listings: listings,
+	}
+}
+
+// AddFavorite saves a listing for the user once it has checked the listing
+// exists, returning false if the user had already saved it
+func (s *service) AddFavorite(ctx context.Context, userID, listingID int64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if _, err := s.listings.GetByID(ctx, listingID); err != nil {
+		return false, errors.Wrapf(err, "failed to add favorite listing with id: %d", listingID)
+	}
+	added, err := s.store.Add(ctx, userID, listingID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to add favorite listing with id: %d", listingID)
+	}
+	return added, nil
+}
+
+func (s *service) RemoveFavorite(ctx context.Context, userID, listingID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.store.Remove(ctx, userID, listingID); err != nil {
+		return errors.Wrapf(err, "failed to remove favorite listing with id: %d", listingID)
+	}
+	return nil
+}
+
+// ListFavorites returns the user's saved listings in the order they were
+// saved, leaving out any that no longer exist
+func (s *service) ListFavorites(ctx context.Context, userID int64) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ids, err := s.store.List(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list favorites")
+	}
+	listings := make([]*models.Listing, 0, len(ids))
+	for _, id := range ids {
+		listing, err := s.listings.GetByID(ctx, id)
+		if errors.Is(err, models.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get favorite listing with id: %d", id)
+		}
+		listings = append(listings, listing)
+	}
+	return listings, nil
+}
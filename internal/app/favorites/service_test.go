@@ -0,0 +1,65 @@
+package favorites
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listingIDs returns the IDs of the listings in order
+func listingIDs(listings []*models.Listing) []int64 {
+	ids := make([]int64, 0, len(listings))
+	for _, listing := range listings {
+		ids = append(ids, listing.ID)
+	}
+	return ids
+}
+
+func TestService_Favorites(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(models.NewFavoritesStore(), models.NewListingRepository())
+	const userID, otherUserID = 1, 2
+
+	added, err := service.AddFavorite(ctx, userID, 187)
+	require.NoError(t, err)
+	assert.True(t, added)
+	added, err = service.AddFavorite(ctx, userID, 79)
+	require.NoError(t, err)
+	assert.True(t, added)
+
+	// Adding the same listing twice keeps a single entry
+	added, err = service.AddFavorite(ctx, userID, 187)
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	favorites, err := service.ListFavorites(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{187, 79}, listingIDs(favorites))
+
+	otherFavorites, err := service.ListFavorites(ctx, otherUserID)
+	require.NoError(t, err)
+	assert.Empty(t, otherFavorites)
+
+	require.NoError(t, service.RemoveFavorite(ctx, userID, 187))
+	favorites, err = service.ListFavorites(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{79}, listingIDs(favorites))
+
+	assert.ErrorIs(t, service.RemoveFavorite(ctx, userID, 187), models.ErrNotFound)
+}
+
+func TestService_AddFavorite_UnknownListing(t *testing.T) {
+	store := models.NewFavoritesStore()
+	service := NewService(store, models.NewListingRepository())
+
+	added, err := service.AddFavorite(context.Background(), 1, 999999)
+
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.False(t, added)
+	ids, err := store.List(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
@@ -0,0 +1,44 @@
+package listing
+
+import (
+	"context"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// GetAgentListings returns the agent's listings ordered by ID. The agent
+// itself sees all of them; anyone else sees only the published ones.
+func (s *service) GetAgentListings(ctx context.Context, agentID int64) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.checkAgent(ctx, agentID); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetByAgent(ctx, agentID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get listings for agent with id: %d", agentID)
+	}
+	if callerID, _ := models.AgentIDFromContext(ctx); callerID == agentID {
+		return listings, nil
+	}
+	published := make([]*models.Listing, 0, len(listings))
+	for _, listing := range listings {
+		if listing.Status == models.ListingStatusPublished {
+			published = append(published, listing)
+		}
+	}
+	return published, nil
+}
+
+// checkAgent returns a wrapped models.ErrNotFound if the agent doesn't exist
+func (s *service) checkAgent(ctx context.Context, agentID int64) error {
+	if s.agents == nil {
+		return errors.Wrapf(models.ErrNotFound, "agent not found with id: %d", agentID)
+	}
+	if _, err := s.agents.GetByID(ctx, agentID); err != nil {
+		return errors.Wrapf(err, "failed to get agent with id: %d", agentID)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package listing
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+func (s *service) GetCheapestByRegion(ctx context.Context, n int) ([]models.RegionListings, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, errors.New("n must be greater than 0")
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for cheapest by region")
+	}
+	byRegion := make(map[models.Region][]*models.Listing)
+	for _, listing := range listings {
+		byRegion[listing.AddressDetails.Region] = append(byRegion[listing.AddressDetails.Region], listing)
+	}
+	result := make([]models.RegionListings, 0, len(byRegion))
+	for region, regionListings := range byRegion {
+		result = append(result, models.RegionListings{
+			Region:   region,
+			Listings: cheapestN(regionListings, n),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Region < result[j].Region
+	})
+	return result, nil
+}
+
+// priceMaxHeap keeps the most expensive listing at the root, so the n cheapest
+// listings can be tracked by evicting the root whenever the heap exceeds n
+type priceMaxHeap []*models.Listing
+
+func (h priceMaxHeap) Len() int { return len(h) }
+
+func (h priceMaxHeap) Less(i, j int) bool {
+	if h[i].PriceInCents != h[j].PriceInCents {
+		return h[i].PriceInCents > h[j].PriceInCents
+	}
+	return h[i].ID > h[j].ID
+}
+
+func (h priceMaxHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *priceMaxHeap) Push(x interface{}) { *h = append(*h, x.(*models.Listing)) }
+
+func (h *priceMaxHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// cheapestN returns the n cheapest listings ordered by ascending price (then ID)
+// in O(len(listings) log n) without sorting the full list
+func cheapestN(listings []*models.Listing, n int) []*models.Listing {
+	h := make(priceMaxHeap, 0, n+1)
+	for _, listing := range listings {
+		heap.Push(&h, listing)
+		if h.Len() > n {
+			heap.Pop(&h)
+		}
+	}
+	cheapest := make([]*models.Listing, h.Len())
+	for i := len(cheapest) - 1; i >= 0; i-- {
+		cheapest[i] = heap.Pop(&h).(*models.Listing)
+	}
+	return cheapest
+}
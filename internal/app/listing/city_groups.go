@@ -0,0 +1,54 @@
+package listing
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// GetCityGroups groups listings by city, ignoring case and surrounding spaces,
+// busiest city first and then by name. Each group takes the spelling of its
+// first listing by ID.
+func (s *service) GetCityGroups(ctx context.Context) ([]models.CityGroup, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for city groups")
+	}
+	type cityTotals struct {
+		group models.CityGroup
+		total int64
+	}
+	byCity := make(map[string]*cityTotals)
+	for _, listing := range listings {
+		city := strings.TrimSpace(listing.AddressDetails.City)
+		if city == "" {
+			continue
+		}
+		key := strings.ToLower(city)
+		totals, ok := byCity[key]
+		if !ok {
+			totals = &cityTotals{group: models.CityGroup{City: city}}
+			byCity[key] = totals
+		}
+		totals.group.ListingCount++
+		totals.total += listing.PriceInCents
+	}
+	groups := make([]models.CityGroup, 0, len(byCity))
+	for _, totals := range byCity {
+		totals.group.AveragePriceInCents = float64(totals.total) / float64(totals.group.ListingCount)
+		groups = append(groups, totals.group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].ListingCount != groups[j].ListingCount {
+			return groups[i].ListingCount > groups[j].ListingCount
+		}
+		return groups[i].City < groups[j].City
+	})
+	return groups, nil
+}
@@ -0,0 +1,62 @@
+package listing
+
+import (
+	"context"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// defaultDepositRatioBand is used when no valid band is configured
+var defaultDepositRatioBand = depositRatioBand{min: 0.05, max: 0.40}
+
+// depositRatioBand is the inclusive range of plausible deposit to price ratios
+type depositRatioBand struct {
+	min, max float64
+}
+
+// newDepositRatioBand returns the configured band, falling back to the
+// default when it is unset or its maximum isn't above its minimum
+func newDepositRatioBand(min, max float64) depositRatioBand {
+	if min < 0 || max <= min {
+		return defaultDepositRatioBand
+	}
+	return depositRatioBand{min: min, max: max}
+}
+
+// GetDepositAnomalies returns the listings whose estimated deposit as a
+// fraction of the price falls outside the configured band, by ID. Listings
+// without a price have no ratio and are skipped.
+func (s *service) GetDepositAnomalies(ctx context.Context) ([]models.DepositAnomaly, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for deposit anomalies")
+	}
+	anomalies := make([]models.DepositAnomaly, 0)
+	for _, listing := range listings {
+		if listing.PriceInCents <= 0 {
+			continue
+		}
+		ratio := float64(listing.EstimatedDepositInCents) / float64(listing.PriceInCents)
+		var reason string
+		switch {
+		case ratio < s.depositRatioBand.min:
+			reason = models.DepositAnomalyBelowMinimum
+		case ratio > s.depositRatioBand.max:
+			reason = models.DepositAnomalyAboveMaximum
+		default:
+			continue
+		}
+		anomalies = append(anomalies, models.DepositAnomaly{
+			ID:                      listing.ID,
+			PriceInCents:            listing.PriceInCents,
+			EstimatedDepositInCents: listing.EstimatedDepositInCents,
+			DepositRatio:            ratio,
+			Reason:                  reason,
+		})
+	}
+	return anomalies, nil
+}
@@ -0,0 +1,42 @@
+package listing
+
+import (
+	"context"
+	"sort"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// GetDepositDemand sums the minimum deposits of the published listings in each
+// region, ordered by region. Drafts are hidden from buyers, so they are left out.
+func (s *service) GetDepositDemand(ctx context.Context) ([]models.RegionDepositDemand, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for deposit demand")
+	}
+	byRegion := make(map[models.Region]*models.RegionDepositDemand)
+	for _, listing := range listings {
+		if listing.Status != models.ListingStatusPublished {
+			continue
+		}
+		demand, ok := byRegion[listing.AddressDetails.Region]
+		if !ok {
+			demand = &models.RegionDepositDemand{Region: listing.AddressDetails.Region}
+			byRegion[listing.AddressDetails.Region] = demand
+		}
+		demand.ListingCount++
+		demand.TotalMinimumDepositInCents += listing.MinimumDepositInCents
+	}
+	result := make([]models.RegionDepositDemand, 0, len(byRegion))
+	for _, demand := range byRegion {
+		result = append(result, *demand)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Region < result[j].Region
+	})
+	return result, nil
+}
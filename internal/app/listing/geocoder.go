@@ -0,0 +1,124 @@
+package listing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// ErrCityNotFound is returned by a Geocoder that doesn't know the city
+var ErrCityNotFound = errors.New("city not found")
+
+// Geocoder finds the centre of a city. Any error other than ErrCityNotFound
+// means geocoding is unavailable.
+type Geocoder interface {
+	Geocode(ctx context.Context, city string) (models.Coordinates, error)
+}
+
+// defaultCityCentres are the centres of the cities in defaultCityRegions
+var defaultCityCentres = map[string]models.Coordinates{
+	"london":     {Latitude: 51.5074, Longitude: -0.1278},
+	"manchester": {Latitude: 53.4808, Longitude: -2.2426},
+	"liverpool":  {Latitude: 53.4084, Longitude: -2.9916},
+	"preston":    {Latitude: 53.7632, Longitude: -2.7031},
+	"newcastle":  {Latitude: 54.9783, Longitude: -1.6178},
+	"leeds":      {Latitude: 53.8008, Longitude: -1.5491},
+	"sheffield":  {Latitude: 53.3811, Longitude: -1.4701},
+	"bristol":    {Latitude: 51.4545, Longitude: -2.5879},
+	"exeter":     {Latitude: 50.7184, Longitude: -3.5339},
+	"plymouth":   {Latitude: 50.3755, Longitude: -4.1427},
+	"brighton":   {Latitude: 50.8225, Longitude: -0.1372},
+	"canterbury": {Latitude: 51.2802, Longitude: 1.0789},
+	"maidstone":  {Latitude: 51.2704, Longitude: 0.5227},
+	"dover":      {Latitude: 51.1279, Longitude: 1.3134},
+	"ashford":    {Latitude: 51.1465, Longitude: 0.8750},
+	"birmingham": {Latitude: 52.4862, Longitude: -1.8904},
+	"nottingham": {Latitude: 52.9548, Longitude: -1.1581},
+	"leicester":  {Latitude: 52.6369, Longitude: -1.1398},
+	"edinburgh":  {Latitude: 55.9533, Longitude: -3.1883},
+	"glasgow":    {Latitude: 55.8642, Longitude: -4.2518},
+	"cardiff":    {Latitude: 51.4816, Longitude: -3.1791},
+	"swansea":    {Latitude: 51.6214, Longitude: -3.9436},
+}
+
+// StaticGeocoder geocodes cities from a fixed table of centres
+type StaticGeocoder struct {
+	centres map[string]models.Coordinates
+}
+
+// NewStaticGeocoder creates a geocoder knowing the given city centres, keyed
+// by city name in any case
+func NewStaticGeocoder(centres map[string]models.Coordinates) *StaticGeocoder {
+	normalized := make(map[string]models.Coordinates, len(centres))
+	for city, centre := range centres {
+		normalized[normalizeCity(city)] = centre
+	}
+	return &StaticGeocoder{centres: normalized}
+}
+
+// NewDefaultGeocoder creates a geocoder for the cities in the built-in region lookup
+func NewDefaultGeocoder() Geocoder {
+	return NewStaticGeocoder(defaultCityCentres)
+}
+
+// Geocode returns the centre of the city, ignoring case and surrounding spaces
+func (g *StaticGeocoder) Geocode(ctx context.Context, city string) (models.Coordinates, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Coordinates{}, err
+	}
+	centre, ok := g.centres[normalizeCity(city)]
+	if !ok {
+		return models.Coordinates{}, errors.Wrapf(ErrCityNotFound, "no centre for %q", city)
+	}
+	return centre, nil
+}
+
+// HTTPGeocoder geocodes cities with a lookup service, calling
+// GET <baseURL>?city=<city> and expecting a 200 with the centre's latitude and
+// longitude as JSON, or a 404 for a city it doesn't know
+type HTTPGeocoder struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewHTTPGeocoder creates a geocoder calling the service at baseURL through
+// client, which should be the shared outbound client from httpclient.New
+func NewHTTPGeocoder(client *http.Client, baseURL string) *HTTPGeocoder {
+	return &HTTPGeocoder{client: client, baseURL: baseURL}
+}
+
+// Geocode asks the lookup service for the centre of the city
+func (g *HTTPGeocoder) Geocode(ctx context.Context, city string) (models.Coordinates, error) {
+	endpoint, err := url.Parse(g.baseURL)
+	if err != nil {
+		return models.Coordinates{}, errors.Wrap(err, "invalid geocoder url")
+	}
+	query := endpoint.Query()
+	query.Set("city", city)
+	endpoint.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return models.Coordinates{}, errors.Wrap(err, "failed to build geocoder request")
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return models.Coordinates{}, errors.Wrapf(err, "failed to geocode %q", city)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return models.Coordinates{}, errors.Wrapf(ErrCityNotFound, "no centre for %q", city)
+	default:
+		return models.Coordinates{}, errors.Errorf("geocoder responded %d for %q", resp.StatusCode, city)
+	}
+	var centre models.Coordinates
+	if err := json.NewDecoder(resp.Body).Decode(&centre); err != nil {
+		return models.Coordinates{}, errors.Wrapf(err, "failed to decode centre of %q", city)
+	}
+	return centre, nil
+}
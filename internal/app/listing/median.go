@@ -0,0 +1,79 @@
+package listing
+
+import (
+	"context"
+	"sort"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// GetMedianPrice returns the median price across all listings, or only those
+// in region when it is set
+func (s *service) GetMedianPrice(ctx context.Context, region models.Region) (models.PriceMedian, error) {
+	if err := ctx.Err(); err != nil {
+		return models.PriceMedian{}, err
+	}
+	var listings []*models.Listing
+	var err error
+	if region == "" {
+		listings, err = s.repo.GetAll(ctx)
+	} else {
+		listings, err = s.repo.GetByRegion(ctx, string(region))
+	}
+	if err != nil {
+		return models.PriceMedian{}, errors.Wrap(err, "failed to get listings for median price")
+	}
+	prices := make([]int64, 0, len(listings))
+	for _, listing := range listings {
+		prices = append(prices, listing.PriceInCents)
+	}
+	return models.PriceMedian{
+		Region:             region,
+		Count:              len(prices),
+		MedianPriceInCents: median(prices),
+	}, nil
+}
+
+// GetMedianPriceByRegion returns the median price for each region with listings
+func (s *service) GetMedianPriceByRegion(ctx context.Context) ([]models.PriceMedian, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for median price")
+	}
+	pricesByRegion := make(map[models.Region][]int64)
+	for _, listing := range listings {
+		region := listing.AddressDetails.Region
+		pricesByRegion[region] = append(pricesByRegion[region], listing.PriceInCents)
+	}
+	medians := make([]models.PriceMedian, 0, len(pricesByRegion))
+	for region, prices := range pricesByRegion {
+		medians = append(medians, models.PriceMedian{
+			Region:             region,
+			Count:              len(prices),
+			MedianPriceInCents: median(prices),
+		})
+	}
+	sort.Slice(medians, func(i, j int) bool {
+		return medians[i].Region < medians[j].Region
+	})
+	return medians, nil
+}
+
+// median returns the middle value of prices, averaging the two middle values
+// for an even count, or nil if there are none. prices is sorted in place.
+func median(prices []int64) *float64 {
+	if len(prices) == 0 {
+		return nil
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+	mid := len(prices) / 2
+	m := float64(prices[mid])
+	if len(prices)%2 == 0 {
+		m = (float64(prices[mid-1]) + float64(prices[mid])) / 2
+	}
+	return &m
+}
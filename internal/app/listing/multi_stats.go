@@ -0,0 +1,53 @@
+package listing
+
+import (
+	"context"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// GetMultiStats computes stats for each named filter in the order given. The
+// listings are fetched once and each is checked against every filter in a
+// single pass.
+func (s *service) GetMultiStats(ctx context.Context, req models.MultiStatsRequest) ([]models.ListingStats, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for multi stats")
+	}
+
+	prices := make([][]int64, len(req.Filters))
+	yieldTotals := make([]float64, len(req.Filters))
+	for _, listing := range listings {
+		for i, filter := range req.Filters {
+			if filter.Matches(listing) {
+				prices[i] = append(prices[i], listing.PriceInCents)
+				yieldTotals[i] += listing.GrossYield
+			}
+		}
+	}
+
+	stats := make([]models.ListingStats, 0, len(req.Filters))
+	for i, filter := range req.Filters {
+		result := models.ListingStats{Name: filter.Name, Count: len(prices[i])}
+		if result.Count > 0 {
+			var total int64
+			for _, price := range prices[i] {
+				total += price
+			}
+			averagePrice := float64(total) / float64(result.Count)
+			averageYield := yieldTotals[i] / float64(result.Count)
+			result.AveragePriceInCents = &averagePrice
+			result.AverageGrossYield = &averageYield
+			result.MedianPriceInCents = median(prices[i])
+		}
+		stats = append(stats, result)
+	}
+	return stats, nil
+}
@@ -0,0 +1,92 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// GetListingsNearCity returns the listings within radiusMiles of the city
+// centre, nearest first with ties by ID. Listings without coordinates can't be
+// placed and are left out. If the city can't be geocoded the listings in its
+// region are returned instead, flagged as a region fallback. An empty city, a
+// radius that isn't positive or a city that is neither geocoded nor mapped to
+// a region returns a *models.ValidationError.
+func (s *service) GetListingsNearCity(ctx context.Context, city string, radiusMiles float64) (models.NearCityResult, error) {
+	if err := ctx.Err(); err != nil {
+		return models.NearCityResult{}, err
+	}
+	city = strings.TrimSpace(city)
+	if city == "" {
+		return models.NearCityResult{}, &models.ValidationError{Issues: []string{"city is required"}}
+	}
+	if !(radiusMiles > 0) {
+		return models.NearCityResult{}, &models.ValidationError{Issues: []string{"radiusMiles must be greater than 0"}}
+	}
+	result := models.NearCityResult{City: city, RadiusMiles: radiusMiles}
+
+	centre, geocodeErr := s.geocode(ctx, city)
+	if geocodeErr != nil {
+		if err := ctx.Err(); err != nil {
+			return models.NearCityResult{}, err
+		}
+		return s.nearCityByRegion(ctx, result, geocodeErr)
+	}
+	result.Centre = &centre
+
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return models.NearCityResult{}, errors.Wrapf(err, "failed to get listings near city: %s", city)
+	}
+	result.Listings = make([]models.NearbyListing, 0)
+	for _, listing := range listings {
+		coordinates := listing.AddressDetails.Coordinates
+		if coordinates == nil {
+			continue
+		}
+		distance := models.DistanceMiles(centre, *coordinates)
+		if distance > radiusMiles {
+			continue
+		}
+		result.Listings = append(result.Listings, models.NearbyListing{Listing: listing, DistanceMiles: &distance})
+	}
+	sort.SliceStable(result.Listings, func(i, j int) bool {
+		return *result.Listings[i].DistanceMiles < *result.Listings[j].DistanceMiles
+	})
+	return result, nil
+}
+
+// geocode finds the city centre, treating a missing geocoder as unavailable
+func (s *service) geocode(ctx context.Context, city string) (models.Coordinates, error) {
+	if s.geocoder == nil {
+		return models.Coordinates{}, errors.New("geocoding is not configured")
+	}
+	return s.geocoder.Geocode(ctx, city)
+}
+
+// nearCityByRegion fills result with the listings in the city's region after
+// geocoding failed with geocodeErr
+func (s *service) nearCityByRegion(ctx context.Context, result models.NearCityResult, geocodeErr error) (models.NearCityResult, error) {
+	region, ok := s.regions.RegionForCity(result.City)
+	if !ok {
+		if errors.Is(geocodeErr, ErrCityNotFound) {
+			return models.NearCityResult{}, &models.ValidationError{Issues: []string{fmt.Sprintf("unknown city %q", result.City)}}
+		}
+		return models.NearCityResult{}, errors.Wrapf(geocodeErr, "failed to geocode city: %s", result.City)
+	}
+	listings, err := s.repo.GetByRegion(ctx, string(region))
+	if err != nil {
+		return models.NearCityResult{}, errors.Wrapf(err, "failed to get listings in region: %s", region)
+	}
+	result.RegionFallback = true
+	result.Region = region
+	result.Listings = make([]models.NearbyListing, 0, len(listings))
+	for _, listing := range listings {
+		result.Listings = append(result.Listings, models.NearbyListing{Listing: listing})
+	}
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package listing
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+func (s *service) GetPivot(ctx context.Context, rows, cols models.PivotDimension) (models.PivotTable, error) {
+	if err := ctx.Err(); err != nil {
+		return models.PivotTable{}, err
+	}
+	if !rows.IsValid() || !cols.IsValid() {
+		return models.PivotTable{}, errors.Errorf("invalid pivot dimensions: %s, %s", rows, cols)
+	}
+	if rows == cols {
+		return models.PivotTable{}, errors.New("pivot rows and cols must be different dimensions")
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return models.PivotTable{}, errors.Wrap(err, "failed to get listings for pivot")
+	}
+
+	rowIndex := make(map[string]int)
+	colIndex := make(map[string]int)
+	for _, listing := range listings {
+		rowIndex[rows.ValueOf(listing)] = 0
+		colIndex[cols.ValueOf(listing)] = 0
+	}
+	table := models.PivotTable{
+		Rows:    rows,
+		Cols:    cols,
+		RowKeys: sortedPivotKeys(rowIndex),
+		ColKeys: sortedPivotKeys(colIndex),
+		Total:   len(listings),
+	}
+	table.Counts = make([][]int, len(table.RowKeys))
+	for i := range table.Counts {
+		table.Counts[i] = make([]int, len(table.ColKeys))
+	}
+	for _, listing := range listings {
+		table.Counts[rowIndex[rows.ValueOf(listing)]][colIndex[cols.ValueOf(listing)]]++
+	}
+	return table, nil
+}
+
+// sortedPivotKeys returns the keys of index in order, numerically when both
+// keys are numbers, and records each key's position in index
+func sortedPivotKeys(index map[string]int) []string {
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	for i, key := range keys {
+		index[key] = i
+	}
+	return keys
+}
@@ -0,0 +1,44 @@
+package listing
+
+import (
+	"context"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+func (s *service) GetBlendedYield(ctx context.Context, req models.BlendedYieldRequest) (models.BlendedYield, error) {
+	if err := ctx.Err(); err != nil {
+		return models.BlendedYield{}, err
+	}
+	if err := req.Validate(); err != nil {
+		return models.BlendedYield{}, err
+	}
+	var total float64
+	for _, holding := range req.Holdings {
+		total += holdingWeight(holding)
+	}
+	result := models.BlendedYield{Holdings: make([]models.HoldingYield, 0, len(req.Holdings))}
+	for _, holding := range req.Holdings {
+		listing, err := s.repo.GetByID(ctx, holding.ListingID)
+		if err != nil {
+			return models.BlendedYield{}, errors.Wrapf(err, "failed to get listing with id: %d", holding.ListingID)
+		}
+		weight := holdingWeight(holding) / total
+		result.BlendedGrossYield += listing.GrossYield * weight
+		result.Holdings = append(result.Holdings, models.HoldingYield{
+			ListingID:  listing.ID,
+			GrossYield: listing.GrossYield,
+			Weight:     weight,
+		})
+	}
+	return result, nil
+}
+
+// holdingWeight returns the holding's weight, counting unweighted holdings equally
+func holdingWeight(holding models.PortfolioHolding) float64 {
+	if holding.Weight == nil {
+		return 1
+	}
+	return *holding.Weight
+}
@@ -0,0 +1,94 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// defaultPriceBandEdges splits listings into under £100k, £100k-£250k and £250k+
+var defaultPriceBandEdges = []int64{10000000, 25000000}
+
+func (s *service) GetPriceBands(ctx context.Context) ([]models.PriceBand, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for price bands")
+	}
+	bands := newPriceBands(s.priceBandEdges)
+	for _, listing := range listings {
+		// The first edge above the price identifies the band, so a price equal
+		// to an edge falls into the band that starts at it
+		i := sort.Search(len(s.priceBandEdges), func(i int) bool {
+			return s.priceBandEdges[i] > listing.PriceInCents
+		})
+		bands[i].Count++
+		bands[i].Listings = append(bands[i].Listings, listing)
+	}
+	return bands, nil
+}
+
+// newPriceBands creates len(edges)+1 empty bands bounded by the sorted edges
+func newPriceBands(edges []int64) []models.PriceBand {
+	bands := make([]models.PriceBand, len(edges)+1)
+	for i := range bands {
+		bands[i].Listings = []*models.Listing{}
+		if i > 0 {
+			bands[i].MinPriceInCents = &edges[i-1]
+		}
+		if i < len(edges) {
+			bands[i].MaxPriceInCents = &edges[i]
+		}
+		switch {
+		case bands[i].MinPriceInCents == nil && bands[i].MaxPriceInCents == nil:
+			bands[i].Label = "Any price"
+		case bands[i].MinPriceInCents == nil:
+			bands[i].Label = "Under " + formatPounds(*bands[i].MaxPriceInCents)
+		case bands[i].MaxPriceInCents == nil:
+			bands[i].Label = formatPounds(*bands[i].MinPriceInCents) + " and over"
+		default:
+			bands[i].Label = formatPounds(*bands[i].MinPriceInCents) + " to " + formatPounds(*bands[i].MaxPriceInCents)
+		}
+	}
+	return bands
+}
+
+// normalizePriceBandEdges returns the positive edges sorted and de-duplicated,
+// falling back to the default edges when none are configured
+func normalizePriceBandEdges(edges []int64) []int64 {
+	if len(edges) == 0 {
+		edges = defaultPriceBandEdges
+	}
+	normalized := make([]int64, 0, len(edges))
+	for _, edge := range edges {
+		if edge > 0 {
+			normalized = append(normalized, edge)
+		}
+	}
+	sort.Slice(normalized, func(i, j int) bool { return normalized[i] < normalized[j] })
+	unique := normalized[:0]
+	for i, edge := range normalized {
+		if i == 0 || edge != normalized[i-1] {
+			unique = append(unique, edge)
+		}
+	}
+	return unique
+}
+
+// formatPounds renders cents as a short pound amount, e.g. £100k or £1.5m
+func formatPounds(cents int64) string {
+	pounds := float64(cents) / 100
+	switch {
+	case pounds >= 1000000:
+		return fmt.Sprintf("£%gm", pounds/1000000)
+	case pounds >= 1000:
+		return fmt.Sprintf("£%gk", pounds/1000)
+	default:
+		return fmt.Sprintf("£%g", pounds)
+	}
+}
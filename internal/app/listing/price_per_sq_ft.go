@@ -0,0 +1,54 @@
+package listing
+
+import (
+	"context"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// GetPricePerSqFtByRegion returns the average price per square foot in every
+// region, in the order of models.Regions. Listings without a size are left out
+// of the average, and a region with no sized listings has a nil average.
+func (s *service) GetPricePerSqFtByRegion(ctx context.Context) ([]models.RegionPricePerSqFt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for price per square foot")
+	}
+
+	type regionTotals struct {
+		count, excluded int
+		sum             float64
+	}
+	totals := make(map[models.Region]*regionTotals, len(models.Regions))
+	for _, region := range models.Regions {
+		totals[region] = &regionTotals{}
+	}
+	for _, listing := range listings {
+		t, ok := totals[listing.AddressDetails.Region]
+		if !ok {
+			continue
+		}
+		if listing.SizeSqFt <= 0 {
+			t.excluded++
+			continue
+		}
+		t.count++
+		t.sum += float64(listing.PriceInCents) / float64(listing.SizeSqFt)
+	}
+
+	result := make([]models.RegionPricePerSqFt, 0, len(models.Regions))
+	for _, region := range models.Regions {
+		t := totals[region]
+		entry := models.RegionPricePerSqFt{Region: region, Count: t.count, ExcludedCount: t.excluded}
+		if t.count > 0 {
+			average := t.sum / float64(t.count)
+			entry.AveragePricePerSqFtInCents = &average
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
@@ -0,0 +1,29 @@
+package listing
+
+import (
+	"context"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// CountByPropertyType returns how many listings there are of each property
+// type. With includeEmpty every known type is present, with 0 when it has no
+// listings; otherwise only types with listings are.
+func (s *service) CountByPropertyType(ctx context.Context, includeEmpty bool) (map[models.PropertyType]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountByPropertyType(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to count listings by property type")
+	}
+	if includeEmpty {
+		for _, propertyType := range models.PropertyTypes {
+			if _, ok := counts[propertyType]; !ok {
+				counts[propertyType] = 0
+			}
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,32 @@
+package listing
+
+import (
+	"context"
+	"sort"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// GetAgentListingIssues returns a quality report for each of the agent's
+// listings that has problems, ordered by listing ID
+func (s *service) GetAgentListingIssues(ctx context.Context, agentID int64) ([]models.ListingQualityReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.checkAgent(ctx, agentID); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetByAgent(ctx, agentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for listing issues")
+	}
+	reports := make([]models.ListingQualityReport, 0)
+	for _, listing := range listings {
+		if issues := models.CheckListingQuality(listing); len(issues) > 0 {
+			reports = append(reports, models.ListingQualityReport{ListingID: listing.ID, Issues: issues})
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].ListingID < reports[j].ListingID })
+	return reports, nil
+}
@@ -0,0 +1,30 @@
+package listing
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+func (s *service) RecomputeDerivedFields(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get listings to recompute")
+	}
+	updated := 0
+	for _, listing := range listings {
+		// Work on a copy so a failed update doesn't leave the stored listing modified
+		recomputed := *listing
+		if !recomputed.RecomputeDerivedFields(s.depositRate, s.rounding) {
+			continue
+		}
+		if err := s.repo.Update(ctx, &recomputed); err != nil {
+			return updated, errors.Wrapf(err, "failed to update listing with id: %d", listing.ID)
+		}
+		updated++
+	}
+	return updated, nil
+}
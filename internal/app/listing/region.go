@@ -0,0 +1,128 @@
+package listing
+
+import (
+	"strings"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/config"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// defaultCityRegions maps lower-cased city names to their region
+var defaultCityRegions = map[string]models.Region{
+	"london":     models.RegionLondon,
+	"manchester": models.RegionNorthWest,
+	"liverpool":  models.RegionNorthWest,
+	"preston":    models.RegionNorthWest,
+	"newcastle":  models.RegionNorthEast,
+	"leeds":      models.RegionNorthEast,
+	"sheffield":  models.RegionNorthEast,
+	"bristol":    models.RegionSouthWest,
+	"exeter":     models.RegionSouthWest,
+	"plymouth":   models.RegionSouthWest,
+	"brighton":   models.RegionSouthEast,
+	"canterbury": models.RegionSouthEast,
+	"maidstone":  models.RegionSouthEast,
+	"dover":      models.RegionSouthEast,
+	"ashford":    models.RegionSouthEast,
+	"birmingham": models.RegionMidlands,
+	"nottingham": models.RegionMidlands,
+	"leicester":  models.RegionMidlands,
+	"edinburgh":  models.RegionScotland,
+	"glasgow":    models.RegionScotland,
+	"cardiff":    models.RegionWales,
+	"swansea":    models.RegionWales,
+}
+
+// postcodeAreaCities maps the postcode areas of the cities in
+// defaultCityRegions to the city they cover
+var postcodeAreaCities = map[string]string{
+	"E": "London", "EC": "London", "N": "London", "NW": "London",
+	"SE": "London", "SW": "London", "W": "London", "WC": "London",
+	"M":  "Manchester",
+	"L":  "Liverpool",
+	"PR": "Preston",
+	"NE": "Newcastle",
+	"LS": "Leeds",
+	"S":  "Sheffield",
+	"BS": "Bristol",
+	"EX": "Exeter",
+	"PL": "Plymouth",
+	"BN": "Brighton",
+	"CT": "Canterbury",
+	"ME": "Maidstone",
+	"B":  "Birmingham",
+	"NG": "Nottingham",
+	"LE": "Leicester",
+	"EH": "Edinburgh",
+	"G":  "Glasgow",
+	"CF": "Cardiff",
+	"SA": "Swansea",
+}
+
+// RegionResolver fills in a missing listing region from its city
+type RegionResolver struct {
+	cityRegions   map[string]models.Region
+	defaultRegion models.Region
+	strict        bool
+}
+
+// NewRegionResolver creates a resolver from the built-in lookup and listing config
+func NewRegionResolver(cfg config.ListingConfig) *RegionResolver {
+	cityRegions := make(map[string]models.Region, len(defaultCityRegions)+len(cfg.CityRegions))
+	for city, region := range defaultCityRegions {
+		cityRegions[city] = region
+	}
+	for city, region := range cfg.CityRegions {
+		cityRegions[normalizeCity(city)] = models.Region(region)
+	}
+	return &RegionResolver{
+		cityRegions:   cityRegions,
+		defaultRegion: models.Region(cfg.DefaultRegion),
+		strict:        cfg.StrictRegion,
+	}
+}
+
+// RegionForCity returns the region a city maps to, if known
+func (r *RegionResolver) RegionForCity(city string) (models.Region, bool) {
+	region, ok := r.cityRegions[normalizeCity(city)]
+	return region, ok
+}
+
+// LookupPostcode describes a parsed postcode, guessing its city from the
+// postcode area and its region from the city lookup
+func (r *RegionResolver) LookupPostcode(postcode models.Postcode) models.PostcodeLookup {
+	lookup := models.PostcodeLookup{
+		Postcode:          postcode.Full,
+		ShortenedPostcode: postcode.Outward,
+	}
+	if city, ok := postcodeAreaCities[postcode.Area]; ok {
+		lookup.City = city
+		lookup.Region, _ = r.RegionForCity(city)
+	}
+	return lookup
+}
+
+// Resolve sets the listing's region when it is missing, using the city lookup
+// and then the default region. In strict mode a missing region is an error.
+func (r *RegionResolver) Resolve(listing *models.Listing) error {
+	if listing.AddressDetails.Region != "" {
+		return nil
+	}
+	if r.strict {
+		return errors.New("region is required")
+	}
+	if region, ok := r.RegionForCity(listing.AddressDetails.City); ok {
+		listing.AddressDetails.Region = region
+		return nil
+	}
+	if r.defaultRegion != "" {
+		listing.AddressDetails.Region = r.defaultRegion
+		return nil
+	}
+	return errors.Errorf("region is required and could not be inferred from city %q", listing.AddressDetails.City)
+}
+
+func normalizeCity(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
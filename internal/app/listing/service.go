@@ -0,0 +1,904 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/config"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+type Service interface {
+	CreateListing(ctx context.Context, listing *models.Listing) (*models.Listing, error)
+	ImportListings(ctx context.Context, listings []*models.Listing) ([]models.ImportResult, error)
+	GetAllListings(ctx context.Context) ([]*models.Listing, error)
+	GetListingByID(ctx context.Context, id int64) (*models.Listing, error)
+	GetAgentListingIssues(ctx context.Context, agentID int64) ([]models.ListingQualityReport, error)
+	GetAgentListings(ctx context.Context, agentID int64) ([]*models.Listing, error)
+	ExportListing(ctx context.Context, id int64) (models.ListingExport, error)
+	GetListingPhotos(ctx context.Context, id int64) ([]models.Photo, error)
+	GetPriceHistory(ctx context.Context, id int64) ([]models.PriceChange, error)
+	GetPriceReducedListings(ctx context.Context, since time.Time) ([]*models.Listing, error)
+	GetAllListingsSorted(ctx context.Context, field models.SortField, desc bool) ([]*models.Listing, error)
+	DeleteListingPhoto(ctx context.Context, id, photoID int64) ([]models.Photo, error)
+	AddListingPhoto(ctx context.Context, id int64, photo models.Photo) ([]models.Photo, error)
+	RemoveListingPhoto(ctx context.Context, id int64, originalURL string) ([]models.Photo, error)
+	AddTags(ctx context.Context, id int64, tags []string) ([]string, error)
+	RemoveTag(ctx context.Context, id int64, tag string) ([]string, error)
+	UpdateListing(ctx context.Context, id int64, listing *models.Listing) (*models.Listing, error)
+	UpsertListing(ctx context.Context, id int64, listing *models.Listing) (*models.Listing, bool, error)
+	DeleteListing(ctx context.Context, id int64) error
+	RestoreListing(ctx context.Context, id int64) (*models.Listing, error)
+	GetDeletedListings(ctx context.Context) ([]*models.Listing, error)
+	PurgeDeletedListings(ctx context.Context, before time.Time) (int, error)
+	DeleteListings(ctx context.Context, ids []int64) ([]models.DeleteResult, error)
+	PublishListing(ctx context.Context, id int64) (*models.Listing, error)
+	GetListingsByDepositRange(ctx context.Context, minDeposit, maxDeposit int64) ([]*models.Listing, error)
+	GetListingsByFlags(ctx context.Context, flags models.ListingFlags) ([]*models.Listing, error)
+	GetListingsInBoundingBox(ctx context.Context, box models.BoundingBox) ([]*models.Listing, error)
+	SearchByCity(ctx context.Context, city string) (models.CitySearchResult, error)
+	GetListingsNearCity(ctx context.Context, city string, radiusMiles float64) (models.NearCityResult, error)
+	SearchListings(ctx context.Context, filter models.ListingFilter) ([]*models.Listing, error)
+	GetFilterSchema(ctx context.Context) ([]models.FilterField, error)
+	LookupPostcode(ctx context.Context, code string) (models.PostcodeLookup, error)
+	GetNeighbours(ctx context.Context, id int64) ([]*models.Listing, error)
+	GetNearby(ctx context.Context, id int64) ([]*models.Listing, error)
+	GetVelocityByRegion(ctx context.Context) ([]models.RegionVelocity, error)
+	GetCreatedOverTime(ctx context.Context, bucket models.TimeBucket) ([]models.TimeBucketCount, error)
+	GetCheapestByRegion(ctx context.Context, n int) ([]models.RegionListings, error)
+	GetDepositDemand(ctx context.Context) ([]models.RegionDepositDemand, error)
+	GetDepositAnomalies(ctx context.Context) ([]models.DepositAnomaly, error)
+	GetCityGroups(ctx context.Context) ([]models.CityGroup, error)
+	CountByPropertyType(ctx context.Context, includeEmpty bool) (map[models.PropertyType]int, error)
+	GetPriceBands(ctx context.Context) ([]models.PriceBand, error)
+	GetYieldHistogram(ctx context.Context, buckets int) (models.YieldHistogram, error)
+	GetMedianPrice(ctx context.Context, region models.Region) (models.PriceMedian, error)
+	GetMedianPriceByRegion(ctx context.Context) ([]models.PriceMedian, error)
+	GetPricePerSqFtByRegion(ctx context.Context) ([]models.RegionPricePerSqFt, error)
+	GetMultiStats(ctx context.Context, req models.MultiStatsRequest) ([]models.ListingStats, error)
+	GetPivot(ctx context.Context, rows, cols models.PivotDimension) (models.PivotTable, error)
+	RecomputeDerivedFields(ctx context.Context) (int, error)
+	WaitForChanges(ctx context.Context, since int64) (models.ListingChanges, error)
+	GetBlendedYield(ctx context.Context, req models.BlendedYieldRequest) (models.BlendedYield, error)
+}
+
+type service struct {
+	repo               models.ListingRepository
+	agents             models.AgentRepository
+	geocoder           Geocoder
+	changes            *models.ListingChangeLog
+	regions            *RegionResolver
+	priceBandEdges     []int64
+	depositRate        float64
+	rounding           models.RoundingMode
+	changesPollTimeout time.Duration
+	publishProfile     models.ValidationProfile
+	newBuildMaxAge     int
+	depositRatioBand   depositRatioBand
+	maxMinDepositRatio float64
+	photoMimeTypes     []string
+	now                func() time.Time
+}
+
+// NewService creates the listing service. A nil geocoder leaves near-city
+// searches to fall back to matching the city's region.
+func NewService(repo models.ListingRepository, cfg *config.Config, changes *models.ListingChangeLog, agents models.AgentRepository, geocoder Geocoder) Service {
+	return &service{
+		repo:               repo,
+		agents:             agents,
+		geocoder:           geocoder,
+		changes:            changes,
+		regions:            NewRegionResolver(cfg.Listing),
+		priceBandEdges:     normalizePriceBandEdges(cfg.Listing.PriceBandEdges),
+		depositRate:        cfg.Listing.DepositRate,
+		rounding:           roundingMode(cfg.Listing.RoundingMode),
+		changesPollTimeout: cfg.Listing.ChangesPollTimeout,
+		publishProfile:     publishProfile(cfg.Listing.PublishRequiredFields),
+		newBuildMaxAge:     cfg.Listing.NewBuildMaxAgeYears,
+		depositRatioBand:   newDepositRatioBand(cfg.Listing.DepositRatioMin, cfg.Listing.DepositRatioMax),
+		maxMinDepositRatio: cfg.Listing.MaxMinimumDepositRatio,
+		photoMimeTypes:     photoMimeTypes(cfg.Listing.PhotoMimeTypes),
+		now:                time.Now,
+	}
+}
+
+// roundingMode returns the configured rounding mode, falling back to
+// models.StandardRounding when it is unset or unknown
+func roundingMode(mode string) models.RoundingMode {
+	if rounding := models.RoundingMode(mode); rounding.IsValid() {
+		return rounding
+	}
+	return models.StandardRounding
+}
+
+func (s *service) CreateListing(ctx context.Context, listing *models.Listing) (*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listing.AddressDetails.NormalizePostcodes()
+	if err := s.regions.Resolve(listing); err != nil {
+		return nil, &models.ValidationError{Issues: []string{err.Error()}}
+	}
+	if err := s.validate(listing); err != nil {
+		return nil, err
+	}
+	listing.DeriveNewBuild(s.now(), s.newBuildMaxAge)
+	if err := s.validateForStatus(listing); err != nil {
+		return nil, err
+	}
+	if err := s.assignOwner(ctx, listing); err != nil {
+		return nil, err
+	}
+	err := s.repo.Create(ctx, listing)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create listing")
+	}
+	return listing, nil
+}
+
+// assignOwner sets a new listing's agent to the authenticated agent and checks
+// that it exists. Any agentId in the request body is ignored, so without an
+// authenticated agent the listing has no owner.
+func (s *service) assignOwner(ctx context.Context, listing *models.Listing) error {
+	listing.AgentID, _ = models.AgentIDFromContext(ctx)
+	if listing.AgentID == 0 {
+		return nil
+	}
+	if s.agents == nil {
+		return errors.Errorf("agent not found with id: %d", listing.AgentID)
+	}
+	if _, err := s.agents.GetByID(ctx, listing.AgentID); err != nil {
+		return errors.Wrap(err, "failed to check listing owner")
+	}
+	return nil
+}
+
+// keepOwner gives listing the owner of the stored listing with its ID, which
+// only that owner may replace; anyone may replace a listing with no owner. It
+// reports whether a listing is stored under the ID.
+func (s *service) keepOwner(ctx context.Context, listing *models.Listing) (bool, error) {
+	listing.AgentID = 0
+	existing, err := s.repo.GetByID(ctx, listing.ID)
+	if errors.Is(err, models.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check owner of listing with id: %d", listing.ID)
+	}
+	if agentID, _ := models.AgentIDFromContext(ctx); existing.AgentID != 0 && existing.AgentID != agentID {
+		return true, models.ConflictErrorf("listing %d belongs to another agent", listing.ID)
+	}
+	listing.AgentID = existing.AgentID
+	return true, nil
+}
+
+func (s *service) ImportListings(ctx context.Context, listings []*models.Listing) ([]models.ImportResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	results := make([]models.ImportResult, 0, len(listings))
+	for i, listing := range listings {
+		if listing == nil {
+			results = append(results, models.ImportResult{
+				Index:    i,
+				Status:   http.StatusBadRequest,
+				Errors:   []string{"listing is empty"},
+				Warnings: []string{},
+			})
+			continue
+		}
+		// Each item goes through the same checks as create
+		listing.AddressDetails.NormalizePostcodes()
+		result := models.ImportResult{Index: i, Status: http.StatusBadRequest, Errors: []string{}}
+		if err := s.regions.Resolve(listing); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		listing.DeriveNewBuild(s.now(), s.newBuildMaxAge)
+		result.Warnings = models.ValidateListing(listing).Warnings
+		var validationErr *models.ValidationError
+		if len(result.Errors) == 0 && errors.As(s.validate(listing), &validationErr) {
+			result.Errors = append(result.Errors, validationErr.Issues...)
+		}
+		var publishErr *models.PublishError
+		if len(result.Errors) == 0 && errors.As(s.validateForStatus(listing), &publishErr) {
+			result.Errors = append(result.Errors, publishErr.Issues...)
+		}
+		if len(result.Errors) == 0 {
+			if err := s.assignOwner(ctx, listing); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			}
+		}
+		if len(result.Errors) == 0 {
+			if err := s.repo.Create(ctx, listing); err != nil {
+				result.Status = http.StatusInternalServerError
+				result.Errors = append(result.Errors, err.Error())
+			} else {
+				result.Status = http.StatusCreated
+				result.ID = listing.ID
+				result.Created = true
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *service) GetAllListings(ctx context.Context) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get all listings")
+	}
+	return listings, nil
+}
+
+// GetAllListingsSorted returns every listing ordered by field, with ties broken
+// by ascending ID. An unsupported field returns a *models.ValidationError.
+func (s *service) GetAllListingsSorted(ctx context.Context, field models.SortField, desc bool) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !field.IsValid() {
+		return nil, &models.ValidationError{Issues: []string{fmt.Sprintf("unknown sort field %q", field)}}
+	}
+	listings, err := s.repo.GetAllSorted(ctx, field, desc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sorted listings")
+	}
+	return listings, nil
+}
+
+// GetListingByID returns a listing for its detail view, including how its price
+// compares to the median in its region. The comparison is left out when the
+// listing is the only one in its region, as there is no market to compare with.
+func (s *service) GetListingByID(ctx context.Context, id int64) (*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get listing with id: %d", id)
+	}
+	regionMedian, err := s.GetMedianPrice(ctx, listing.AddressDetails.Region)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compare listing with id: %d to its region", id)
+	}
+	if regionMedian.Count < 2 || regionMedian.MedianPriceInCents == nil {
+		return listing, nil
+	}
+	return listing.WithVsRegionMedian(*regionMedian.MedianPriceInCents), nil
+}
+
+// ExportListing returns the listing together with every field derived from it
+func (s *service) ExportListing(ctx context.Context, id int64) (models.ListingExport, error) {
+	if err := ctx.Err(); err != nil {
+		return models.ListingExport{}, err
+	}
+	listing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.ListingExport{}, errors.Wrapf(err, "failed to export listing with id: %d", id)
+	}
+	return models.NewListingExport(listing, s.now()), nil
+}
+
+func (s *service) GetListingPhotos(ctx context.Context, id int64) ([]models.Photo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get photos for listing with id: %d", id)
+	}
+	if listing.Photos == nil {
+		return []models.Photo{}, nil
+	}
+	return listing.Photos, nil
+}
+
+// GetPriceHistory returns the listing's price changes, oldest first
+func (s *service) GetPriceHistory(ctx context.Context, id int64) ([]models.PriceChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	history, err := s.repo.GetPriceHistory(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get price history for listing with id: %d", id)
+	}
+	return history, nil
+}
+
+// GetPriceReducedListings returns the listings repriced below an earlier price
+// since the given time, or over their whole history when since is zero
+func (s *service) GetPriceReducedListings(ctx context.Context, since time.Time) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetPriceReduced(ctx, since)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get price-reduced listings")
+	}
+	return listings, nil
+}
+
+// DeleteListingPhoto removes one photo from a listing and returns the remaining
+// photos renumbered from position 0, so the next photo becomes primary if the
+// primary was removed
+func (s *service) DeleteListingPhoto(ctx context.Context, id, photoID int64) ([]models.Photo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get listing with id: %d", id)
+	}
+	photos := make([]models.Photo, 0, len(listing.Photos))
+	found := false
+	for _, photo := range listing.Photos {
+		if photo.ID == photoID {
+			found = true
+			continue
+		}
+		photo.Position = len(photos)
+		photos = append(photos, photo)
+	}
+	if !found {
+		return nil, errors.Wrapf(models.ErrNotFound, "photo not found with id: %d", photoID)
+	}
+	updated := *listing
+	updated.Photos = photos
+	if err := s.validateForStatus(&updated); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, &updated); err != nil {
+		return nil, errors.Wrapf(err, "failed to delete photo %d from listing with id: %d", photoID, id)
+	}
+	return updated.Photos, nil
+}
+
+// AddListingPhoto adds the photo as the listing's last one and returns all of
+// its photos. An invalid photo returns a *models.ValidationError and one
+// already on the listing an error matching models.ErrConflict.
+func (s *service) AddListingPhoto(ctx context.Context, id int64, photo models.Photo) ([]models.Photo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := photo.Validate(s.photoMimeTypes); err != nil {
+		return nil, err
+	}
+	err := s.checkPhotoChange(ctx, id, func(photos []models.Photo) ([]models.Photo, error) {
+		return append(photos, photo), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddPhoto(ctx, id, photo); err != nil {
+		return nil, errors.Wrapf(err, "failed to add photo to listing with id: %d", id)
+	}
+	return s.listPhotos(ctx, id)
+}
+
+// RemoveListingPhoto removes the listing's photo with the original URL and
+// returns the remaining photos renumbered from position 0. Removing the last
+// photo of a published listing returns a *models.PublishError.
+func (s *service) RemoveListingPhoto(ctx context.Context, id int64, originalURL string) ([]models.Photo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(originalURL) == "" {
+		return nil, &models.ValidationError{Issues: []string{"originalURL is required"}}
+	}
+	err := s.checkPhotoChange(ctx, id, func(photos []models.Photo) ([]models.Photo, error) {
+		remaining := slices.DeleteFunc(slices.Clone(photos), func(photo models.Photo) bool {
+			return photo.OriginalURL == originalURL
+		})
+		if len(remaining) == len(photos) {
+			return nil, errors.Wrapf(models.ErrNotFound, "photo not found with original URL: %s", originalURL)
+		}
+		return remaining, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.RemovePhoto(ctx, id, originalURL); err != nil {
+		return nil, errors.Wrapf(err, "failed to remove photo from listing with id: %d", id)
+	}
+	return s.listPhotos(ctx, id)
+}
+
+// checkPhotoChange loads the listing and checks it would still pass
+// validateForStatus with its photos changed by change, so a published listing
+// can't lose the photos its publish profile requires
+func (s *service) checkPhotoChange(ctx context.Context, id int64, change func([]models.Photo) ([]models.Photo, error)) error {
+	listing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get listing with id: %d", id)
+	}
+	photos, err := change(listing.Photos)
+	if err != nil {
+		return errors.Wrapf(err, "failed to change photos of listing with id: %d", id)
+	}
+	listing.Photos = photos
+	return s.validateForStatus(listing)
+}
+
+// listPhotos returns the listing's photos after they were changed
+func (s *service) listPhotos(ctx context.Context, id int64) ([]models.Photo, error) {
+	photos, err := s.repo.ListPhotos(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get photos for listing with id: %d", id)
+	}
+	return photos, nil
+}
+
+// UpdateListing replaces the existing listing with the given ID, returning
+// models.ErrNotFound if there is none. The listing keeps its owner, and
+// replacing another agent's listing is a conflict. Invalid listings return a
+// *models.ValidationError.
+func (s *service) UpdateListing(ctx context.Context, id int64, listing *models.Listing) (*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listing.ID = id
+	listing.AddressDetails.NormalizePostcodes()
+	if err := s.regions.Resolve(listing); err != nil {
+		return nil, &models.ValidationError{Issues: []string{err.Error()}}
+	}
+	if err := s.validate(listing); err != nil {
+		return nil, err
+	}
+	listing.DeriveNewBuild(s.now(), s.newBuildMaxAge)
+	if err := s.validateForStatus(listing); err != nil {
+		return nil, err
+	}
+	if _, err := s.keepOwner(ctx, listing); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, listing); err != nil {
+		return nil, errors.Wrapf(err, "failed to update listing with id: %d", id)
+	}
+	return listing, nil
+}
+
+// UpsertListing stores the listing under the given ID, creating it if the ID
+// is unused and replacing the existing listing otherwise. It reports whether
+// the listing was created. A new listing is owned by the authenticated agent,
+// while an existing one keeps its owner and replacing another agent's listing
+// is a conflict. Invalid listings return a *models.ValidationError.
+func (s *service) UpsertListing(ctx context.Context, id int64, listing *models.Listing) (*models.Listing, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	listing.ID = id
+	listing.AddressDetails.NormalizePostcodes()
+	if err := s.regions.Resolve(listing); err != nil {
+		return nil, false, &models.ValidationError{Issues: []string{err.Error()}}
+	}
+	if err := s.validate(listing); err != nil {
+		return nil, false, err
+	}
+	listing.DeriveNewBuild(s.now(), s.newBuildMaxAge)
+	if err := s.validateForStatus(listing); err != nil {
+		return nil, false, err
+	}
+	exists, err := s.keepOwner(ctx, listing)
+	if err != nil {
+		return nil, false, err
+	}
+	if !exists {
+		if err := s.assignOwner(ctx, listing); err != nil {
+			return nil, false, err
+		}
+	}
+	created, err := s.repo.Upsert(ctx, listing)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to upsert listing with id: %d", id)
+	}
+	return listing, created, nil
+}
+
+// DeleteListing soft-deletes the listing with the given ID
+func (s *service) DeleteListing(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return errors.Wrapf(err, "failed to delete listing with id: %d", id)
+	}
+	return nil
+}
+
+// RestoreListing brings back a soft-deleted listing and returns it, or
+// models.ErrNotFound if there is no deleted listing with the ID
+func (s *service) RestoreListing(ctx context.Context, id int64) (*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, errors.Wrapf(err, "failed to restore listing with id: %d", id)
+	}
+	listing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get listing with id: %d", id)
+	}
+	return listing, nil
+}
+
+// GetDeletedListings returns the soft-deleted listings ordered by ID
+func (s *service) GetDeletedListings(ctx context.Context) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetDeleted(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get deleted listings")
+	}
+	return listings, nil
+}
+
+// PurgeDeletedListings permanently removes the listings deleted before the
+// given time, returning how many were removed
+func (s *service) PurgeDeletedListings(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	purged, err := s.repo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return purged, errors.Wrap(err, "failed to purge deleted listings")
+	}
+	return purged, nil
+}
+
+// DeleteListings deletes each listing in turn, reporting a 200 for each one
+// deleted, a 404 for IDs that don't exist and a 500 for other failures. It
+// only returns an error if the context ends before the batch is processed.
+func (s *service) DeleteListings(ctx context.Context, ids []int64) ([]models.DeleteResult, error) {
+	results := make([]models.DeleteResult, 0, len(ids))
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result := models.DeleteResult{ID: id, Status: http.StatusOK}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			result.Status = http.StatusInternalServerError
+			result.Error = "failed to delete listing"
+			if errors.Is(err, models.ErrNotFound) {
+				result.Status = http.StatusNotFound
+				result.Error = "listing not found"
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// PublishListing moves a listing to published once it passes the publish profile
+func (s *service) PublishListing(ctx context.Context, id int64) (*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get listing with id: %d", id)
+	}
+	if listing.Status == models.ListingStatusPublished {
+		return listing, nil
+	}
+	published := *listing
+	published.Status = models.ListingStatusPublished
+	if err := s.validateForStatus(&published); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, &published); err != nil {
+		return nil, errors.Wrapf(err, "failed to publish listing with id: %d", id)
+	}
+	return &published, nil
+}
+
+// validate applies the structural checks from models.ValidateListing along
+// with the business rules on the derived yield, deposit and photos, returning
+// a *models.ValidationError listing every problem found
+func (s *service) validate(listing *models.Listing) error {
+	issues := models.ValidateListing(listing).Errors
+	if grossYield := listing.ComputeGrossYield(); grossYield < 0 || grossYield > 1 {
+		issues = append(issues, "gross yield must be between 0 and 1")
+	}
+	issues = append(issues, s.minimumDepositIssues(listing)...)
+	issues = append(issues, models.PhotoIssues(listing.Photos, s.photoMimeTypes)...)
+	if len(issues) > 0 {
+		return &models.ValidationError{Issues: issues}
+	}
+	return nil
+}
+
+// minimumDepositIssues reports a minimum deposit above the configured
+// fraction of the price. A deposit above the whole price is left to
+// models.ValidateListing.
+func (s *service) minimumDepositIssues(listing *models.Listing) []string {
+	if s.maxMinDepositRatio <= 0 || listing.PriceInCents <= 0 || listing.MinimumDepositInCents > listing.PriceInCents {
+		return nil
+	}
+	if float64(listing.MinimumDepositInCents) > s.maxMinDepositRatio*float64(listing.PriceInCents) {
+		return []string{fmt.Sprintf("minimum deposit cannot be more than %g%% of the price", s.maxMinDepositRatio*100)}
+	}
+	return nil
+}
+
+// validateForStatus checks a published listing against the strict publish
+// profile, returning a *models.PublishError when it falls short. Drafts only
+// need the basic checks the repository makes on every write.
+func (s *service) validateForStatus(listing *models.Listing) error {
+	if listing.Status != models.ListingStatusPublished {
+		return nil
+	}
+	if issues := s.publishProfile.Validate(listing); len(issues.Errors) > 0 {
+		return &models.PublishError{Issues: issues.Errors}
+	}
+	return nil
+}
+
+// photoMimeTypes returns the configured photo MIME types, falling back to
+// models.DefaultPhotoMimeTypes when none are set
+func photoMimeTypes(mimeTypes []string) []string {
+	if len(mimeTypes) == 0 {
+		return models.DefaultPhotoMimeTypes
+	}
+	return mimeTypes
+}
+
+// publishProfile builds the publish profile from the configured required fields
+func publishProfile(requiredFields []string) models.ValidationProfile {
+	if len(requiredFields) == 0 {
+		return models.DefaultPublishProfile
+	}
+	return models.ValidationProfile{RequiredFields: requiredFields}
+}
+
+func (s *service) GetListingsByDepositRange(ctx context.Context, minDeposit, maxDeposit int64) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if minDeposit < 0 || maxDeposit < 0 {
+		return nil, errors.New("deposit range must not be negative")
+	}
+	if minDeposit > maxDeposit {
+		return nil, errors.New("minimum deposit must not exceed maximum deposit")
+	}
+	listings, err := s.repo.GetByDepositRange(ctx, minDeposit, maxDeposit)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get listings with deposit between %d and %d", minDeposit, maxDeposit)
+	}
+	return listings, nil
+}
+
+func (s *service) GetListingsByFlags(ctx context.Context, flags models.ListingFlags) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetByFlags(ctx, flags)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings by flags")
+	}
+	return listings, nil
+}
+
+func (s *service) GetListingsInBoundingBox(ctx context.Context, box models.BoundingBox) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := box.Validate(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetByBoundingBox(ctx, box)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings in bounding box")
+	}
+	return listings, nil
+}
+
+// SearchByCity returns the listings whose city contains the search term. The
+// city counts as known if any listing matches or it is in the region lookup.
+func (s *service) SearchByCity(ctx context.Context, city string) (models.CitySearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return models.CitySearchResult{}, err
+	}
+	city = strings.TrimSpace(city)
+	if city == "" {
+		return models.CitySearchResult{}, errors.New("city is required")
+	}
+	listings, err := s.repo.SearchByCity(ctx, city)
+	if err != nil {
+		return models.CitySearchResult{}, errors.Wrapf(err, "failed to search listings by city: %s", city)
+	}
+	_, inLookup := s.regions.RegionForCity(city)
+	return models.CitySearchResult{
+		City:      city,
+		CityKnown: len(listings) > 0 || inLookup,
+		Listings:  listings,
+	}, nil
+}
+
+// SearchListings returns the listings matching every criterion set on the
+// filter. An invalid filter returns a *models.ValidationError.
+func (s *service) SearchListings(ctx context.Context, filter models.ListingFilter) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, &models.ValidationError{Issues: []string{err.Error()}}
+	}
+	listings, err := s.repo.Search(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search listings")
+	}
+	return listings, nil
+}
+
+// GetFilterSchema describes every search criterion, with the current price,
+// bedroom, bathroom and size ranges taken from all listings
+func (s *service) GetFilterSchema(ctx context.Context) ([]models.FilterField, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for filter schema")
+	}
+	return models.BuildFilterSchema(listings), nil
+}
+
+// LookupPostcode validates a UK postcode and returns its shortened postcode
+// with a best-guess city and region. Malformed postcodes return a
+// *models.ValidationError.
+func (s *service) LookupPostcode(ctx context.Context, code string) (models.PostcodeLookup, error) {
+	if err := ctx.Err(); err != nil {
+		return models.PostcodeLookup{}, err
+	}
+	postcode, err := models.ParsePostcode(code)
+	if err != nil {
+		return models.PostcodeLookup{}, &models.ValidationError{Issues: []string{err.Error()}}
+	}
+	return s.regions.LookupPostcode(postcode), nil
+}
+
+// GetNeighbours returns the other listings sharing the listing's shortened
+// postcode, cheapest first
+func (s *service) GetNeighbours(ctx context.Context, id int64) ([]*models.Listing, error) {
+	neighbours, err := s.GetNearby(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(neighbours, func(i, j int) bool {
+		if neighbours[i].PriceInCents != neighbours[j].PriceInCents {
+			return neighbours[i].PriceInCents < neighbours[j].PriceInCents
+		}
+		return neighbours[i].ID < neighbours[j].ID
+	})
+	return neighbours, nil
+}
+
+// GetNearby returns the other listings sharing the listing's shortened
+// postcode, ignoring case, in ID order
+func (s *service) GetNearby(ctx context.Context, id int64) ([]*models.Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get listing with id: %d", id)
+	}
+	// Without a postcode area every other listing lacking one would match
+	if listing.AddressDetails.ShortenedPostcode == "" {
+		return []*models.Listing{}, nil
+	}
+	area, err := s.repo.GetByShortenedPostcode(ctx, listing.AddressDetails.ShortenedPostcode)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get listings near listing with id: %d", id)
+	}
+	nearby := make([]*models.Listing, 0, len(area))
+	for _, other := range area {
+		if other.ID != id {
+			nearby = append(nearby, other)
+		}
+	}
+	return nearby, nil
+}
+
+func (s *service) GetVelocityByRegion(ctx context.Context) ([]models.RegionVelocity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for velocity")
+	}
+	now := s.now()
+	totals := make(map[models.Region]int)
+	counts := make(map[models.Region]int)
+	for _, listing := range listings {
+		days, ok := listing.DaysOnMarket(now)
+		if !ok {
+			continue
+		}
+		totals[listing.AddressDetails.Region] += days
+		counts[listing.AddressDetails.Region]++
+	}
+	velocity := make([]models.RegionVelocity, 0, len(counts))
+	for region, count := range counts {
+		velocity = append(velocity, models.RegionVelocity{
+			Region:              region,
+			ListingCount:        count,
+			AverageDaysOnMarket: float64(totals[region]) / float64(count),
+		})
+	}
+	sort.Slice(velocity, func(i, j int) bool {
+		return velocity[i].Region < velocity[j].Region
+	})
+	return velocity, nil
+}
+
+func (s *service) GetCreatedOverTime(ctx context.Context, bucket models.TimeBucket) ([]models.TimeBucketCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !bucket.IsValid() {
+		return nil, errors.Errorf("invalid time bucket: %s", bucket)
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get listings for creation counts")
+	}
+	counts := make(map[string]int)
+	for _, listing := range listings {
+		if listing.MadeVisibleAt == nil {
+			continue
+		}
+		visibleAt, err := time.Parse(time.RFC3339, *listing.MadeVisibleAt)
+		if err != nil {
+			continue
+		}
+		counts[bucketLabel(visibleAt.UTC(), bucket)]++
+	}
+	result := make([]models.TimeBucketCount, 0, len(counts))
+	for label, count := range counts {
+		result = append(result, models.TimeBucketCount{Bucket: label, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Bucket < result[j].Bucket
+	})
+	return result, nil
+}
+
+func (s *service) WaitForChanges(ctx context.Context, since int64) (models.ListingChanges, error) {
+	if err := ctx.Err(); err != nil {
+		return models.ListingChanges{}, err
+	}
+	if since < 0 {
+		return models.ListingChanges{}, errors.New("since version must not be negative")
+	}
+	changes, err := s.changes.Wait(ctx, since, s.changesPollTimeout)
+	if err != nil {
+		return models.ListingChanges{}, errors.Wrap(err, "failed waiting for listing changes")
+	}
+	return changes, nil
+}
+
+// bucketLabel returns the label of the bucket containing t
+func bucketLabel(t time.Time, bucket models.TimeBucket) string {
+	switch bucket {
+	case models.TimeBucketWeek:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -daysSinceMonday).Format("2006-01-02")
+	case models.TimeBucketMonth:
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
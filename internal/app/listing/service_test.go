@@ -0,0 +1,2691 @@
+package listing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/config"
+	"github.com/getground/interview-backend-golang/internal/pkg/httpclient"
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockListingRepository struct {
+	mock.Mock
+}
+
+var _ models.ListingRepository = (*MockListingRepository)(nil)
+
+func (m *MockListingRepository) listings(args mock.Arguments) ([]*models.Listing, error) {
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingRepository) Create(ctx context.Context, listing *models.Listing) error {
+	args := m.Called(ctx, listing)
+	return args.Error(0)
+}
+
+func (m *MockListingRepository) GetByID(ctx context.Context, id int64) (*models.Listing, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Listing), args.Error(1)
+}
+
+func (m *MockListingRepository) GetAll(ctx context.Context) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx))
+}
+
+func (m *MockListingRepository) Update(ctx context.Context, listing *models.Listing) error {
+	args := m.Called(ctx, listing)
+	return args.Error(0)
+}
+
+func (m *MockListingRepository) Upsert(ctx context.Context, listing *models.Listing) (bool, error) {
+	args := m.Called(ctx, listing)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockListingRepository) CreateIfNotExists(ctx context.Context, listing *models.Listing) (*models.Listing, bool, error) {
+	args := m.Called(ctx, listing)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.Listing), args.Bool(1), args.Error(2)
+}
+
+func (m *MockListingRepository) GetPriceHistory(ctx context.Context, id int64) ([]models.PriceChange, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PriceChange), args.Error(1)
+}
+
+func (m *MockListingRepository) GetByFlags(ctx context.Context, flags models.ListingFlags) ([]*models.Listing, error) {
+	args := m.Called(ctx, flags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingRepository) AddPhoto(ctx context.Context, listingID int64, photo models.Photo) error {
+	args := m.Called(ctx, listingID, photo)
+	return args.Error(0)
+}
+
+func (m *MockListingRepository) RemovePhoto(ctx context.Context, listingID int64, originalURL string) error {
+	args := m.Called(ctx, listingID, originalURL)
+	return args.Error(0)
+}
+
+func (m *MockListingRepository) ListPhotos(ctx context.Context, listingID int64) ([]models.Photo, error) {
+	args := m.Called(ctx, listingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Photo), args.Error(1)
+}
+
+func (m *MockListingRepository) GetAllSorted(ctx context.Context, field models.SortField, desc bool) ([]*models.Listing, error) {
+	args := m.Called(ctx, field, desc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingRepository) GetPriceReduced(ctx context.Context, since time.Time) ([]*models.Listing, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingRepository) Search(ctx context.Context, filter models.ListingFilter) ([]*models.Listing, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Listing), args.Error(1)
+}
+
+func (m *MockListingRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockListingRepository) Restore(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockListingRepository) GetDeleted(ctx context.Context) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx))
+}
+
+func (m *MockListingRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockListingRepository) GetByRegion(ctx context.Context, region string) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, region))
+}
+
+func (m *MockListingRepository) GetByAgent(ctx context.Context, agentID int64) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, agentID))
+}
+
+func (m *MockListingRepository) GetByPropertyType(ctx context.Context, propertyType string) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, propertyType))
+}
+
+func (m *MockListingRepository) CountByPropertyType(ctx context.Context) (map[models.PropertyType]int, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[models.PropertyType]int), args.Error(1)
+}
+
+func (m *MockListingRepository) GetFeatured(ctx context.Context) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx))
+}
+
+func (m *MockListingRepository) SearchByCity(ctx context.Context, city string) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, city))
+}
+
+func (m *MockListingRepository) GetByPriceRange(ctx context.Context, minPrice, maxPrice int64) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, minPrice, maxPrice))
+}
+
+func (m *MockListingRepository) GetByBedroomRange(ctx context.Context, minBedrooms, maxBedrooms int) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, minBedrooms, maxBedrooms))
+}
+
+func (m *MockListingRepository) GetByBathroomRange(ctx context.Context, minBathrooms, maxBathrooms int) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, minBathrooms, maxBathrooms))
+}
+
+func (m *MockListingRepository) GetBySizeRange(ctx context.Context, minSqFt, maxSqFt int) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, minSqFt, maxSqFt))
+}
+
+func (m *MockListingRepository) GetByDepositRange(ctx context.Context, minDeposit, maxDeposit int64) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, minDeposit, maxDeposit))
+}
+
+func (m *MockListingRepository) ReplaceAll(ctx context.Context, listings []*models.Listing, preserveIDs bool) error {
+	args := m.Called(ctx, listings, preserveIDs)
+	return args.Error(0)
+}
+
+func (m *MockListingRepository) GetByBoundingBox(ctx context.Context, box models.BoundingBox) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, box))
+}
+
+func (m *MockListingRepository) GetByShortenedPostcode(ctx context.Context, code string) ([]*models.Listing, error) {
+	return m.listings(m.Called(ctx, code))
+}
+
+func TestService_GetListingPhotos(t *testing.T) {
+	tests := []struct {
+		name           string
+		inputID        int64
+		mockSetup      func(*MockListingRepository)
+		expectedPhotos []models.Photo
+		expectedError  error
+	}{
+		{
+			name:    "listing with photos",
+			inputID: 1,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByID", mock.Anything, int64(1)).
+					Return(&models.Listing{ID: 1, Photos: []models.Photo{{ID: 1, OriginalURL: "https://example.com/a.jpg"}}}, nil)
+			},
+			expectedPhotos: []models.Photo{{ID: 1, OriginalURL: "https://example.com/a.jpg"}},
+		},
+		{
+			name:    "listing without photos",
+			inputID: 2,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByID", mock.Anything, int64(2)).
+					Return(&models.Listing{ID: 2}, nil)
+			},
+			expectedPhotos: []models.Photo{},
+		},
+		{
+			name:    "not found",
+			inputID: 999,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByID", mock.Anything, int64(999)).
+					Return(nil, errors.Wrap(models.ErrNotFound, "listing not found with id: 999"))
+			},
+			expectedError: models.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.GetListingPhotos(context.Background(), tt.inputID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPhotos, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_GetListingsByDepositRange(t *testing.T) {
+	tests := []struct {
+		name          string
+		minDeposit    int64
+		maxDeposit    int64
+		mockSetup     func(*MockListingRepository)
+		expectedCount int
+		expectedError bool
+	}{
+		{
+			name:       "valid range",
+			minDeposit: 1000000,
+			maxDeposit: 3000000,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByDepositRange", mock.Anything, int64(1000000), int64(3000000)).
+					Return([]*models.Listing{{ID: 1}, {ID: 2}}, nil)
+			},
+			expectedCount: 2,
+		},
+		{
+			name:          "negative minimum",
+			minDeposit:    -1,
+			maxDeposit:    3000000,
+			mockSetup:     func(repo *MockListingRepository) {},
+			expectedError: true,
+		},
+		{
+			name:          "minimum above maximum",
+			minDeposit:    3000000,
+			maxDeposit:    1000000,
+			mockSetup:     func(repo *MockListingRepository) {},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.GetListingsByDepositRange(context.Background(), tt.minDeposit, tt.maxDeposit)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedCount)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_GetListingsByFlags(t *testing.T) {
+	shareSale := true
+	flags := models.ListingFlags{IsShareSale: &shareSale}
+
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("GetByFlags", mock.Anything, flags).Return([]*models.Listing{{ID: 1}}, nil).Once()
+	mockRepo.On("GetByFlags", mock.Anything, flags).Return(nil, errors.New("db down")).Once()
+	service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+	result, err := service.GetListingsByFlags(context.Background(), flags)
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+
+	_, err = service.GetListingsByFlags(context.Background(), flags)
+	assert.ErrorContains(t, err, "failed to get listings by flags")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetVelocityByRegion(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	visibleAt := func(daysAgo int) *string {
+		s := now.AddDate(0, 0, -daysAgo).Format(time.RFC3339)
+		return &s
+	}
+
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("GetAll", mock.Anything).Return([]*models.Listing{
+		{ID: 1, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, MadeVisibleAt: visibleAt(10)},
+		{ID: 2, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, MadeVisibleAt: visibleAt(20)},
+		{ID: 3, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, MadeVisibleAt: nil},
+		{ID: 4, AddressDetails: models.AddressDetails{Region: models.RegionScotland}, MadeVisibleAt: visibleAt(5)},
+		{ID: 5, AddressDetails: models.AddressDetails{Region: models.RegionWales}, MadeVisibleAt: nil},
+	}, nil)
+
+	service := &service{repo: mockRepo, regions: NewRegionResolver(config.ListingConfig{}), now: func() time.Time { return now }}
+
+	result, err := service.GetVelocityByRegion(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RegionVelocity{
+		{Region: models.RegionLondon, ListingCount: 2, AverageDaysOnMarket: 15},
+		{Region: models.RegionScotland, ListingCount: 1, AverageDaysOnMarket: 5},
+	}, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_CreateListing_Region(t *testing.T) {
+	validListing := func(address models.AddressDetails) *models.Listing {
+		address.ShortenedPostcode = "M1"
+		return &models.Listing{AddressDetails: address, PropertyType: models.PropertyTypeApartment, PriceInCents: 10000000}
+	}
+
+	tests := []struct {
+		name           string
+		cfg            config.ListingConfig
+		listing        *models.Listing
+		expectedRegion models.Region
+		expectedError  bool
+	}{
+		{
+			name:           "explicit region is kept",
+			cfg:            config.ListingConfig{DefaultRegion: string(models.RegionWales)},
+			listing:        validListing(models.AddressDetails{City: "Manchester", Region: models.RegionLondon}),
+			expectedRegion: models.RegionLondon,
+		},
+		{
+			name:           "mapped city",
+			listing:        validListing(models.AddressDetails{City: " Manchester "}),
+			expectedRegion: models.RegionNorthWest,
+		},
+		{
+			name:           "configured city mapping",
+			cfg:            config.ListingConfig{CityRegions: map[string]string{"Whitstable": string(models.RegionSouthEast)}},
+			listing:        validListing(models.AddressDetails{City: "Whitstable"}),
+			expectedRegion: models.RegionSouthEast,
+		},
+		{
+			name:           "unmapped city with default",
+			cfg:            config.ListingConfig{DefaultRegion: string(models.RegionMidlands)},
+			listing:        validListing(models.AddressDetails{City: "Spooky City"}),
+			expectedRegion: models.RegionMidlands,
+		},
+		{
+			name:          "unmapped city without default",
+			listing:       validListing(models.AddressDetails{City: "Spooky City"}),
+			expectedError: true,
+		},
+		{
+			name:          "strict mode rejects missing region",
+			cfg:           config.ListingConfig{StrictRegion: true, DefaultRegion: string(models.RegionMidlands)},
+			listing:       validListing(models.AddressDetails{City: "Manchester"}),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			if !tt.expectedError {
+				mockRepo.On("Create", mock.Anything, tt.listing).Return(nil)
+			}
+
+			service := NewService(mockRepo, &config.Config{Listing: tt.cfg}, nil, nil, nil)
+
+			result, err := service.CreateListing(context.Background(), tt.listing)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedRegion, result.AddressDetails.Region)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_CreateListing_Owner(t *testing.T) {
+	agents := models.NewAgentRepository()
+	agent := &models.Agent{Name: "Jane Smith", Email: "jane@example.com"}
+	require.NoError(t, agents.Create(context.Background(), agent))
+
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		agentID         int64
+		expectedAgentID int64
+		expectedError   bool
+	}{
+		{
+			name:            "owned by the authenticated agent",
+			ctx:             models.ContextWithAgentID(context.Background(), agent.ID),
+			expectedAgentID: agent.ID,
+		},
+		{
+			name:            "authenticated agent takes precedence over the body",
+			ctx:             models.ContextWithAgentID(context.Background(), agent.ID),
+			agentID:         99,
+			expectedAgentID: agent.ID,
+		},
+		{
+			name:            "no owner",
+			ctx:             context.Background(),
+			expectedAgentID: 0,
+		},
+		{
+			name:            "agent in the body is ignored",
+			ctx:             context.Background(),
+			agentID:         agent.ID,
+			expectedAgentID: 0,
+		},
+		{
+			name:          "non-existent agent",
+			ctx:           models.ContextWithAgentID(context.Background(), 99),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listing := &models.Listing{
+				AgentID:      tt.agentID,
+				PropertyType: models.PropertyTypeApartment,
+				PriceInCents: 10000000,
+				AddressDetails: models.AddressDetails{
+					City:              "London",
+					ShortenedPostcode: "N1",
+					Region:            models.RegionLondon,
+				},
+			}
+			mockRepo := new(MockListingRepository)
+			if !tt.expectedError {
+				mockRepo.On("Create", mock.Anything, listing).Return(nil)
+			}
+
+			service := NewService(mockRepo, &config.Config{}, nil, agents, nil)
+
+			result, err := service.CreateListing(tt.ctx, listing)
+
+			if tt.expectedError {
+				assert.ErrorIs(t, err, models.ErrNotFound)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedAgentID, result.AgentID)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_ImportListings_Owner(t *testing.T) {
+	agents := models.NewAgentRepository()
+	agent := &models.Agent{Name: "Jane Smith", Email: "jane@example.com"}
+	require.NoError(t, agents.Create(context.Background(), agent))
+	newListing := func() *models.Listing {
+		return &models.Listing{
+			AgentID:      agent.ID + 1,
+			PropertyType: models.PropertyTypeApartment,
+			PriceInCents: 10000000,
+			AddressDetails: models.AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "N1",
+				Region:            models.RegionLondon,
+			},
+		}
+	}
+
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	service := NewService(mockRepo, &config.Config{}, nil, agents, nil)
+
+	owned := newListing()
+	results, err := service.ImportListings(models.ContextWithAgentID(context.Background(), agent.ID), []*models.Listing{owned})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, results[0].Status)
+	assert.Equal(t, agent.ID, owned.AgentID)
+
+	unowned := newListing()
+	results, err = service.ImportListings(context.Background(), []*models.Listing{unowned})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, results[0].Status)
+	assert.Zero(t, unowned.AgentID)
+
+	results, err = service.ImportListings(models.ContextWithAgentID(context.Background(), 99), []*models.Listing{newListing()})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, results[0].Status)
+	assert.Len(t, results[0].Errors, 1)
+	mockRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestService_GetListingByID_VsRegionMedian(t *testing.T) {
+	london := []*models.Listing{
+		{ID: 1, PriceInCents: 10000000, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 2, PriceInCents: 20000000, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 3, PriceInCents: 25000000, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+	}
+	wales := []*models.Listing{
+		{ID: 4, PriceInCents: 15000000, AddressDetails: models.AddressDetails{Region: models.RegionWales}},
+	}
+
+	tests := []struct {
+		name             string
+		id               int64
+		expectedVsMedian interface{}
+	}{
+		{name: "above the median", id: 3, expectedVsMedian: 25.0},
+		{name: "below the median", id: 1, expectedVsMedian: -50.0},
+		{name: "at the median", id: 2, expectedVsMedian: 0.0},
+		{name: "only listing in its region", id: 4, expectedVsMedian: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			for _, listing := range append(append([]*models.Listing{}, london...), wales...) {
+				mockRepo.On("GetByID", mock.Anything, listing.ID).Return(listing, nil).Maybe()
+			}
+			mockRepo.On("GetByRegion", mock.Anything, "London").Return(london, nil).Maybe()
+			mockRepo.On("GetByRegion", mock.Anything, "Wales").Return(wales, nil).Maybe()
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.GetListingByID(context.Background(), tt.id)
+
+			require.NoError(t, err)
+			data, err := json.Marshal(result)
+			require.NoError(t, err)
+			var decoded map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, tt.expectedVsMedian, decoded["vsRegionMedian"])
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetByID", mock.Anything, int64(99)).Return(nil, errors.Wrap(models.ErrNotFound, "listing not found with id: 99"))
+
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		_, err := service.GetListingByID(context.Background(), 99)
+
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
+
+func TestService_GetCreatedOverTime(t *testing.T) {
+	visibleAt := func(s string) *string {
+		return &s
+	}
+	listings := []*models.Listing{
+		{ID: 1, MadeVisibleAt: visibleAt("2023-01-30T09:00:00Z")}, // Monday
+		{ID: 2, MadeVisibleAt: visibleAt("2023-02-01T16:42:09Z")}, // Wednesday
+		{ID: 3, MadeVisibleAt: visibleAt("2023-02-01T17:12:25Z")},
+		{ID: 4, MadeVisibleAt: visibleAt("2023-02-06T08:00:00Z")}, // next Monday
+		{ID: 5, MadeVisibleAt: visibleAt("2023-03-16T16:11:14Z")},
+		{ID: 6, MadeVisibleAt: nil},
+	}
+
+	tests := []struct {
+		name          string
+		bucket        models.TimeBucket
+		expected      []models.TimeBucketCount
+		expectedError bool
+	}{
+		{
+			name:   "day",
+			bucket: models.TimeBucketDay,
+			expected: []models.TimeBucketCount{
+				{Bucket: "2023-01-30", Count: 1},
+				{Bucket: "2023-02-01", Count: 2},
+				{Bucket: "2023-02-06", Count: 1},
+				{Bucket: "2023-03-16", Count: 1},
+			},
+		},
+		{
+			name:   "week",
+			bucket: models.TimeBucketWeek,
+			expected: []models.TimeBucketCount{
+				{Bucket: "2023-01-30", Count: 3},
+				{Bucket: "2023-02-06", Count: 1},
+				{Bucket: "2023-03-13", Count: 1},
+			},
+		},
+		{
+			name:   "month",
+			bucket: models.TimeBucketMonth,
+			expected: []models.TimeBucketCount{
+				{Bucket: "2023-01", Count: 1},
+				{Bucket: "2023-02", Count: 3},
+				{Bucket: "2023-03", Count: 1},
+			},
+		},
+		{
+			name:          "invalid bucket",
+			bucket:        models.TimeBucket("year"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			if !tt.expectedError {
+				mockRepo.On("GetAll", mock.Anything).Return(listings, nil)
+			}
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.GetCreatedOverTime(context.Background(), tt.bucket)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_ImportListings(t *testing.T) {
+	valid := &models.Listing{
+		AddressDetails: models.AddressDetails{
+			City:              "London",
+			Postcode:          "W14 9AA",
+			ShortenedPostcode: "W14",
+			Region:            models.RegionLondon,
+		},
+		PropertyType: models.PropertyTypeApartment,
+		PriceInCents: 10000000,
+		SizeSqFt:     500,
+	}
+	withWarnings := &models.Listing{
+		AddressDetails: models.AddressDetails{
+			City:              "Manchester",
+			ShortenedPostcode: "M1",
+		},
+		PropertyType: models.PropertyTypeApartment,
+		PriceInCents: 10000000,
+		SizeSqFt:     5,
+	}
+	invalid := &models.Listing{
+		AddressDetails: models.AddressDetails{
+			ShortenedPostcode: "M1",
+			Region:            models.RegionNorthWest,
+		},
+		PropertyType: models.PropertyTypeApartment,
+		PriceInCents: 0,
+		SizeSqFt:     500,
+	}
+	unpublishable := &models.Listing{
+		AddressDetails: models.AddressDetails{
+			City:              "London",
+			Postcode:          "W14 9AA",
+			ShortenedPostcode: "W14",
+			Region:            models.RegionLondon,
+		},
+		PropertyType: models.PropertyTypeApartment,
+		PriceInCents: 10000000,
+		SizeSqFt:     500,
+		Description:  "Bright flat",
+		Status:       models.ListingStatusPublished,
+	}
+
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("Create", mock.Anything, valid).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Listing).ID = 10
+	}).Return(nil)
+	mockRepo.On("Create", mock.Anything, withWarnings).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Listing).ID = 11
+	}).Return(nil)
+
+	service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+	results, err := service.ImportListings(context.Background(), []*models.Listing{valid, withWarnings, invalid, unpublishable})
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []models.ImportResult{
+		{Index: 0, ID: 10, Status: http.StatusCreated, Created: true, Errors: []string{}, Warnings: []string{}},
+		{
+			Index:    1,
+			ID:       11,
+			Status:   http.StatusCreated,
+			Created:  true,
+			Errors:   []string{},
+			Warnings: []string{"postcode is missing", "size of 5 sq ft looks implausible"},
+		},
+		{
+			Index:    2,
+			Status:   http.StatusBadRequest,
+			Created:  false,
+			Errors:   []string{"city is required", "price must be greater than 0"},
+			Warnings: []string{"postcode is missing"},
+		},
+		{
+			Index:    3,
+			Status:   http.StatusBadRequest,
+			Created:  false,
+			Errors:   []string{"at least one photo is required"},
+			Warnings: []string{},
+		},
+	}, results)
+	assert.Equal(t, models.RegionNorthWest, withWarnings.AddressDetails.Region)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_ImportListings_CreateRules(t *testing.T) {
+	noRegion := &models.Listing{
+		AddressDetails: models.AddressDetails{City: "Leeds", Postcode: "LS1 4AP", ShortenedPostcode: "LS1"},
+		PropertyType:   models.PropertyTypeApartment,
+		PriceInCents:   10000000,
+		SizeSqFt:       500,
+	}
+	highYield := &models.Listing{
+		AddressDetails: models.AddressDetails{
+			City:              "London",
+			Postcode:          "W14 9AA",
+			ShortenedPostcode: "W14",
+			Region:            models.RegionLondon,
+		},
+		PropertyType:               models.PropertyTypeApartment,
+		PriceInCents:               10000000,
+		MonthlyRentalIncomeInCents: 1000000,
+		SizeSqFt:                   500,
+	}
+
+	mockRepo := new(MockListingRepository)
+	service := NewService(mockRepo, &config.Config{Listing: config.ListingConfig{StrictRegion: true}}, nil, nil, nil)
+
+	results, err := service.ImportListings(context.Background(), []*models.Listing{noRegion, highYield})
+
+	assert.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{"region is required"}, results[0].Errors)
+	assert.Equal(t, []string{"gross yield must be between 0 and 1"}, results[1].Errors)
+	for _, result := range results {
+		assert.Equal(t, http.StatusBadRequest, result.Status)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_GetCheapestByRegion(t *testing.T) {
+	listings := []*models.Listing{
+		{ID: 1, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, PriceInCents: 50000000},
+		{ID: 2, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, PriceInCents: 10000000},
+		{ID: 3, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, PriceInCents: 30000000},
+		{ID: 4, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, PriceInCents: 10000000},
+		{ID: 5, AddressDetails: models.AddressDetails{Region: models.RegionLondon}, PriceInCents: 20000000},
+		{ID: 6, AddressDetails: models.AddressDetails{Region: models.RegionScotland}, PriceInCents: 23456700},
+	}
+
+	tests := []struct {
+		name          string
+		n             int
+		expectedIDs   map[models.Region][]int64
+		expectedError bool
+	}{
+		{
+			name: "two per region",
+			n:    2,
+			expectedIDs: map[models.Region][]int64{
+				models.RegionLondon:   {2, 4},
+				models.RegionScotland: {6},
+			},
+		},
+		{
+			name: "three per region",
+			n:    3,
+			expectedIDs: map[models.Region][]int64{
+				models.RegionLondon:   {2, 4, 5},
+				models.RegionScotland: {6},
+			},
+		},
+		{
+			name:          "invalid n",
+			n:             0,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			if !tt.expectedError {
+				mockRepo.On("GetAll", mock.Anything).Return(listings, nil)
+			}
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.GetCheapestByRegion(context.Background(), tt.n)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, len(tt.expectedIDs))
+				for _, group := range result {
+					assert.LessOrEqual(t, len(group.Listings), tt.n)
+					ids := make([]int64, 0, len(group.Listings))
+					for _, listing := range group.Listings {
+						ids = append(ids, listing.ID)
+					}
+					assert.Equal(t, tt.expectedIDs[group.Region], ids)
+				}
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_GetYieldHistogram(t *testing.T) {
+	tests := []struct {
+		name            string
+		yields          []float64
+		buckets         int
+		expectedBuckets []models.YieldBucket
+	}{
+		{
+			name:    "boundary yields fall into the bucket they start",
+			yields:  []float64{0.0625, 0.07, 0.09375, 0.125, 0.15625},
+			buckets: 3,
+			expectedBuckets: []models.YieldBucket{
+				{MinYield: 0.0625, MaxYield: 0.09375, Count: 2},
+				{MinYield: 0.09375, MaxYield: 0.125, Count: 1},
+				{MinYield: 0.125, MaxYield: 0.15625, Count: 2},
+			},
+		},
+		{
+			name:            "identical yields share one bucket",
+			yields:          []float64{0.05, 0.05},
+			buckets:         5,
+			expectedBuckets: []models.YieldBucket{{MinYield: 0.05, MaxYield: 0.05, Count: 2}},
+		},
+		{
+			name:            "empty dataset",
+			yields:          nil,
+			buckets:         5,
+			expectedBuckets: []models.YieldBucket{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listings := make([]*models.Listing, 0, len(tt.yields))
+			for i, yield := range tt.yields {
+				listings = append(listings, &models.Listing{ID: int64(i + 1), GrossYield: yield})
+			}
+			mockRepo := new(MockListingRepository)
+			mockRepo.On("GetAll", mock.Anything).Return(listings, nil)
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.GetYieldHistogram(context.Background(), tt.buckets)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedBuckets, result.Buckets)
+			assert.Equal(t, len(listings), result.Total)
+			sum := 0
+			for _, bucket := range result.Buckets {
+				sum += bucket.Count
+			}
+			assert.Equal(t, result.Total, sum)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("rejects a non-positive bucket count", func(t *testing.T) {
+		service := NewService(new(MockListingRepository), &config.Config{}, nil, nil, nil)
+
+		_, err := service.GetYieldHistogram(context.Background(), 0)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestService_GetPriceBands(t *testing.T) {
+	listings := []*models.Listing{
+		{ID: 1, PriceInCents: 9999999},
+		{ID: 2, PriceInCents: 10000000},
+		{ID: 3, PriceInCents: 24999999},
+		{ID: 4, PriceInCents: 25000000},
+		{ID: 5, PriceInCents: 100000000},
+	}
+
+	tests := []struct {
+		name           string
+		edges          []int64
+		expectedLabels []string
+		expectedIDs    [][]int64
+	}{
+		{
+			name:           "default edges",
+			expectedLabels: []string{"Under £100k", "£100k to £250k", "£250k and over"},
+			expectedIDs:    [][]int64{{1}, {2, 3}, {4, 5}},
+		},
+		{
+			name:           "configured edges out of order",
+			edges:          []int64{100000000, 20000000, 20000000},
+			expectedLabels: []string{"Under £200k", "£200k to £1m", "£1m and over"},
+			expectedIDs:    [][]int64{{1, 2}, {3, 4}, {5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			mockRepo.On("GetAll", mock.Anything).Return(listings, nil)
+
+			service := NewService(mockRepo, &config.Config{Listing: config.ListingConfig{PriceBandEdges: tt.edges}}, nil, nil, nil)
+
+			result, err := service.GetPriceBands(context.Background())
+
+			assert.NoError(t, err)
+			assert.Len(t, result, len(tt.expectedLabels))
+			for i, band := range result {
+				assert.Equal(t, tt.expectedLabels[i], band.Label)
+				assert.Equal(t, len(tt.expectedIDs[i]), band.Count)
+				ids := make([]int64, 0, len(band.Listings))
+				for _, listing := range band.Listings {
+					ids = append(ids, listing.ID)
+				}
+				assert.Equal(t, tt.expectedIDs[i], ids)
+			}
+			assert.Nil(t, result[0].MinPriceInCents)
+			assert.Nil(t, result[len(result)-1].MaxPriceInCents)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_RecomputeDerivedFields(t *testing.T) {
+	tests := []struct {
+		name            string
+		depositRate     float64
+		expectedUpdated int
+		expectedDeposit int64
+	}{
+		{
+			name:            "unchanged config",
+			depositRate:     0.25,
+			expectedUpdated: 0,
+			expectedDeposit: 2500000,
+		},
+		{
+			name:            "deposit rate changed",
+			depositRate:     0.3,
+			expectedUpdated: 1,
+			expectedDeposit: 3000000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := &models.Listing{
+				ID:                         1,
+				PriceInCents:               10000000,
+				MonthlyRentalIncomeInCents: 50000,
+				GrossYield:                 0.06,
+				EstimatedDepositInCents:    2500000,
+			}
+			mockRepo := new(MockListingRepository)
+			mockRepo.On("GetAll", mock.Anything).Return([]*models.Listing{stored}, nil)
+			var updatedListing *models.Listing
+			if tt.expectedUpdated > 0 {
+				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Listing")).
+					Run(func(args mock.Arguments) { updatedListing = args.Get(1).(*models.Listing) }).
+					Return(nil)
+			}
+
+			service := NewService(mockRepo, &config.Config{Listing: config.ListingConfig{DepositRate: tt.depositRate}}, nil, nil, nil)
+
+			updated, err := service.RecomputeDerivedFields(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedUpdated, updated)
+			if tt.expectedUpdated > 0 {
+				assert.Equal(t, tt.expectedDeposit, updatedListing.EstimatedDepositInCents)
+				assert.InDelta(t, 0.06, updatedListing.GrossYield, 1e-9)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_DeleteListingPhoto(t *testing.T) {
+	photos := func() []models.Photo {
+		return []models.Photo{
+			{ID: 10, Position: 0, OriginalURL: "https://example.com/a.jpg"},
+			{ID: 11, Position: 1, OriginalURL: "https://example.com/b.jpg"},
+			{ID: 12, Position: 2, OriginalURL: "https://example.com/c.jpg"},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		inputID        int64
+		photoID        int64
+		mockSetup      func(*MockListingRepository)
+		expectedPhotos []models.Photo
+		expectedError  error
+	}{
+		{
+			name:    "delete middle photo",
+			inputID: 1,
+			photoID: 11,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByID", mock.Anything, int64(1)).
+					Return(&models.Listing{ID: 1, Photos: photos()}, nil)
+				repo.On("Update", mock.Anything, mock.AnythingOfType("*models.Listing")).Return(nil)
+			},
+			expectedPhotos: []models.Photo{
+				{ID: 10, Position: 0, OriginalURL: "https://example.com/a.jpg"},
+				{ID: 12, Position: 1, OriginalURL: "https://example.com/c.jpg"},
+			},
+		},
+		{
+			name:    "delete primary photo promotes the next",
+			inputID: 1,
+			photoID: 10,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByID", mock.Anything, int64(1)).
+					Return(&models.Listing{ID: 1, Photos: photos()}, nil)
+				repo.On("Update", mock.Anything, mock.AnythingOfType("*models.Listing")).Return(nil)
+			},
+			expectedPhotos: []models.Photo{
+				{ID: 11, Position: 0, OriginalURL: "https://example.com/b.jpg"},
+				{ID: 12, Position: 1, OriginalURL: "https://example.com/c.jpg"},
+			},
+		},
+		{
+			name:    "photo not found",
+			inputID: 1,
+			photoID: 99,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByID", mock.Anything, int64(1)).
+					Return(&models.Listing{ID: 1, Photos: photos()}, nil)
+			},
+			expectedError: models.ErrNotFound,
+		},
+		{
+			name:    "listing not found",
+			inputID: 999,
+			photoID: 10,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByID", mock.Anything, int64(999)).
+					Return(nil, errors.Wrap(models.ErrNotFound, "listing not found with id: 999"))
+			},
+			expectedError: models.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.DeleteListingPhoto(context.Background(), tt.inputID, tt.photoID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPhotos, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("last photo of a published listing", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetByID", mock.Anything, int64(1)).Return(&models.Listing{
+			ID:           1,
+			Status:       models.ListingStatusPublished,
+			Description:  "Bright flat",
+			PropertyType: models.PropertyTypeApartment,
+			PriceInCents: 20000000,
+			Photos:       photos()[:1],
+			AddressDetails: models.AddressDetails{
+				City:              "London",
+				Postcode:          "N1 1AA",
+				ShortenedPostcode: "N1",
+				Region:            models.RegionLondon,
+			},
+		}, nil)
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		_, err := service.DeleteListingPhoto(context.Background(), 1, 10)
+
+		var publishErr *models.PublishError
+		require.ErrorAs(t, err, &publishErr)
+		assert.Equal(t, []string{"at least one photo is required"}, publishErr.Issues)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_GetBlendedYield(t *testing.T) {
+	weight := func(w float64) *float64 { return &w }
+
+	tests := []struct {
+		name          string
+		holdings      []models.PortfolioHolding
+		expectedYield float64
+		expectedError bool
+	}{
+		{
+			name:          "equal weights",
+			holdings:      []models.PortfolioHolding{{ListingID: 1}, {ListingID: 2}},
+			expectedYield: 0.06,
+		},
+		{
+			name:          "custom weights",
+			holdings:      []models.PortfolioHolding{{ListingID: 1, Weight: weight(300000)}, {ListingID: 2, Weight: weight(100000)}},
+			expectedYield: 0.05,
+		},
+		{
+			name:          "negative weight",
+			holdings:      []models.PortfolioHolding{{ListingID: 1, Weight: weight(-1)}, {ListingID: 2, Weight: weight(1)}},
+			expectedError: true,
+		},
+		{
+			name:          "mixed weights",
+			holdings:      []models.PortfolioHolding{{ListingID: 1, Weight: weight(1)}, {ListingID: 2}},
+			expectedError: true,
+		},
+		{
+			name:          "no listings",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			mockRepo.On("GetByID", mock.Anything, int64(1)).Return(&models.Listing{ID: 1, GrossYield: 0.04}, nil).Maybe()
+			mockRepo.On("GetByID", mock.Anything, int64(2)).Return(&models.Listing{ID: 2, GrossYield: 0.08}, nil).Maybe()
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.GetBlendedYield(context.Background(), models.BlendedYieldRequest{Holdings: tt.holdings})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.InDelta(t, tt.expectedYield, result.BlendedGrossYield, 1e-9)
+				assert.Len(t, result.Holdings, len(tt.holdings))
+			}
+		})
+	}
+}
+
+func TestService_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		call func(Service) error
+	}{
+		{
+			name: "CreateListing",
+			call: func(s Service) error {
+				_, err := s.CreateListing(ctx, &models.Listing{})
+				return err
+			},
+		},
+		{
+			name: "ImportListings",
+			call: func(s Service) error {
+				_, err := s.ImportListings(ctx, []*models.Listing{{}})
+				return err
+			},
+		},
+		{
+			name: "GetAllListings",
+			call: func(s Service) error {
+				_, err := s.GetAllListings(ctx)
+				return err
+			},
+		},
+		{
+			name: "GetListingPhotos",
+			call: func(s Service) error {
+				_, err := s.GetListingPhotos(ctx, 1)
+				return err
+			},
+		},
+		{
+			name: "GetCheapestByRegion",
+			call: func(s Service) error {
+				_, err := s.GetCheapestByRegion(ctx, 3)
+				return err
+			},
+		},
+		{
+			name: "RecomputeDerivedFields",
+			call: func(s Service) error {
+				_, err := s.RecomputeDerivedFields(ctx)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			err := tt.call(service)
+
+			assert.ErrorIs(t, err, context.Canceled)
+			mockRepo.AssertNotCalled(t, "GetAll", mock.Anything)
+			mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestService_PublishListing(t *testing.T) {
+	draft := func() *models.Listing {
+		return &models.Listing{
+			ID:     1,
+			Status: models.ListingStatusDraft,
+			AddressDetails: models.AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "N1",
+				Region:            models.RegionLondon,
+			},
+			PropertyType: models.PropertyTypeApartment,
+			PriceInCents: 10000000,
+		}
+	}
+
+	t.Run("draft can be saved with missing fields", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Listing")).Return(nil)
+
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		result, err := service.CreateListing(context.Background(), draft())
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.ListingStatusDraft, result.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("incomplete draft cannot be published", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetByID", mock.Anything, int64(1)).Return(draft(), nil)
+
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		result, err := service.PublishListing(context.Background(), 1)
+
+		var publishErr *models.PublishError
+		assert.ErrorAs(t, err, &publishErr)
+		assert.Equal(t, []string{"description is required", "at least one photo is required", "a valid postcode is required"}, publishErr.Issues)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("completed draft is published", func(t *testing.T) {
+		complete := draft()
+		complete.Description = "Bright flat near the station"
+		complete.Photos = []models.Photo{{ID: 1, OriginalURL: "https://example.com/a.jpg"}}
+		complete.AddressDetails.Postcode = "N1 7AA"
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetByID", mock.Anything, int64(1)).Return(complete, nil)
+		mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Listing")).Return(nil)
+
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		result, err := service.PublishListing(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.ListingStatusPublished, result.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("configured required fields", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetByID", mock.Anything, int64(1)).Return(draft(), nil)
+
+		service := NewService(mockRepo, &config.Config{Listing: config.ListingConfig{PublishRequiredFields: []string{"description"}}}, nil, nil, nil)
+
+		_, err := service.PublishListing(context.Background(), 1)
+
+		var publishErr *models.PublishError
+		assert.ErrorAs(t, err, &publishErr)
+		assert.Equal(t, []string{"description is required"}, publishErr.Issues)
+	})
+
+	t.Run("published listing cannot be created incomplete", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		listing := draft()
+		listing.Status = models.ListingStatusPublished
+		_, err := service.CreateListing(context.Background(), listing)
+
+		var publishErr *models.PublishError
+		assert.ErrorAs(t, err, &publishErr)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_GetMedianPrice(t *testing.T) {
+	price := func(p float64) *float64 { return &p }
+
+	tests := []struct {
+		name           string
+		region         models.Region
+		mockSetup      func(*MockListingRepository)
+		expectedMedian models.PriceMedian
+	}{
+		{
+			name: "odd count",
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetAll", mock.Anything).Return([]*models.Listing{
+					{ID: 1, PriceInCents: 100000000},
+					{ID: 2, PriceInCents: 10000000},
+					{ID: 3, PriceInCents: 15000000},
+				}, nil)
+			},
+			expectedMedian: models.PriceMedian{Count: 3, MedianPriceInCents: price(15000000)},
+		},
+		{
+			name: "even count",
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetAll", mock.Anything).Return([]*models.Listing{
+					{ID: 1, PriceInCents: 100000000},
+					{ID: 2, PriceInCents: 10000000},
+					{ID: 3, PriceInCents: 15000000},
+					{ID: 4, PriceInCents: 12500001},
+				}, nil)
+			},
+			expectedMedian: models.PriceMedian{Count: 4, MedianPriceInCents: price(13750000.5)},
+		},
+		{
+			name:   "filtered by region",
+			region: models.RegionLondon,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByRegion", mock.Anything, "London").Return([]*models.Listing{
+					{ID: 1, PriceInCents: 30000000},
+				}, nil)
+			},
+			expectedMedian: models.PriceMedian{Region: models.RegionLondon, Count: 1, MedianPriceInCents: price(30000000)},
+		},
+		{
+			name: "no listings",
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetAll", mock.Anything).Return([]*models.Listing{}, nil)
+			},
+			expectedMedian: models.PriceMedian{Count: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.GetMedianPrice(context.Background(), tt.region)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedMedian, result)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_GetMedianPriceByRegion(t *testing.T) {
+	price := func(p float64) *float64 { return &p }
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("GetAll", mock.Anything).Return([]*models.Listing{
+		{ID: 1, PriceInCents: 20000000, AddressDetails: models.AddressDetails{Region: models.RegionNorthWest}},
+		{ID: 2, PriceInCents: 50000000, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 3, PriceInCents: 10000000, AddressDetails: models.AddressDetails{Region: models.RegionNorthWest}},
+	}, nil)
+
+	service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+	result, err := service.GetMedianPriceByRegion(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.PriceMedian{
+		{Region: models.RegionLondon, Count: 1, MedianPriceInCents: price(50000000)},
+		{Region: models.RegionNorthWest, Count: 2, MedianPriceInCents: price(15000000)},
+	}, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetPricePerSqFtByRegion(t *testing.T) {
+	average := func(a float64) *float64 { return &a }
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("GetAll", mock.Anything).Return([]*models.Listing{
+		{ID: 1, PriceInCents: 50000000, SizeSqFt: 1000, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 2, PriceInCents: 30000000, SizeSqFt: 500, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 3, PriceInCents: 90000000, SizeSqFt: 0, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 4, PriceInCents: 20000000, SizeSqFt: 0, AddressDetails: models.AddressDetails{Region: models.RegionWales}},
+		{ID: 5, PriceInCents: 15000000, SizeSqFt: 600, AddressDetails: models.AddressDetails{Region: models.RegionScotland}},
+	}, nil)
+
+	service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+	result, err := service.GetPricePerSqFtByRegion(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, result, len(models.Regions))
+	byRegion := make(map[models.Region]models.RegionPricePerSqFt, len(result))
+	for _, entry := range result {
+		byRegion[entry.Region] = entry
+	}
+	// (50000 + 60000) / 2, leaving out the listing without a size
+	assert.Equal(t, models.RegionPricePerSqFt{
+		Region: models.RegionLondon, Count: 2, ExcludedCount: 1, AveragePricePerSqFtInCents: average(55000),
+	}, byRegion[models.RegionLondon])
+	assert.Equal(t, models.RegionPricePerSqFt{
+		Region: models.RegionScotland, Count: 1, AveragePricePerSqFtInCents: average(25000),
+	}, byRegion[models.RegionScotland])
+	assert.Equal(t, models.RegionPricePerSqFt{Region: models.RegionWales, ExcludedCount: 1}, byRegion[models.RegionWales])
+	assert.Equal(t, models.RegionPricePerSqFt{Region: models.RegionMidlands}, byRegion[models.RegionMidlands])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetListingsInBoundingBox(t *testing.T) {
+	tests := []struct {
+		name          string
+		box           models.BoundingBox
+		mockSetup     func(*MockListingRepository)
+		expectedCount int
+		expectedError bool
+	}{
+		{
+			name: "valid box",
+			box:  models.BoundingBox{North: 51.7, South: 51.3, East: 0.3, West: -0.5},
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByBoundingBox", mock.Anything, models.BoundingBox{North: 51.7, South: 51.3, East: 0.3, West: -0.5}).
+					Return([]*models.Listing{{ID: 1}}, nil)
+			},
+			expectedCount: 1,
+		},
+		{
+			name:          "north below south",
+			box:           models.BoundingBox{North: 51.3, South: 51.7, East: 0.3, West: -0.5},
+			mockSetup:     func(repo *MockListingRepository) {},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.GetListingsInBoundingBox(context.Background(), tt.box)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedCount)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_SearchByCity(t *testing.T) {
+	tests := []struct {
+		name           string
+		city           string
+		cityRegions    map[string]string
+		listings       []*models.Listing
+		expectedResult models.CitySearchResult
+	}{
+		{
+			name:     "city with listings",
+			city:     "Bath",
+			listings: []*models.Listing{{ID: 1}},
+			expectedResult: models.CitySearchResult{
+				City:      "Bath",
+				CityKnown: true,
+				Listings:  []*models.Listing{{ID: 1}},
+			},
+		},
+		{
+			name:     "known city with no listings",
+			city:     "Leeds",
+			listings: []*models.Listing{},
+			expectedResult: models.CitySearchResult{
+				City:      "Leeds",
+				CityKnown: true,
+				Listings:  []*models.Listing{},
+			},
+		},
+		{
+			name:        "configured city with no listings",
+			city:        "Truro",
+			cityRegions: map[string]string{"Truro": "South West"},
+			listings:    []*models.Listing{},
+			expectedResult: models.CitySearchResult{
+				City:      "Truro",
+				CityKnown: true,
+				Listings:  []*models.Listing{},
+			},
+		},
+		{
+			name:     "unknown city",
+			city:     "Atlantis",
+			listings: []*models.Listing{},
+			expectedResult: models.CitySearchResult{
+				City:      "Atlantis",
+				CityKnown: false,
+				Listings:  []*models.Listing{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			mockRepo.On("SearchByCity", mock.Anything, tt.city).Return(tt.listings, nil)
+
+			service := NewService(mockRepo, &config.Config{Listing: config.ListingConfig{CityRegions: tt.cityRegions}}, nil, nil, nil)
+
+			result, err := service.SearchByCity(context.Background(), tt.city)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_GetNeighbours(t *testing.T) {
+	tests := []struct {
+		name          string
+		inputID       int64
+		mockSetup     func(*MockListingRepository)
+		expectedIDs   []int64
+		expectedError error
+	}{
+		{
+			name:    "neighbours sorted by price excluding itself",
+			inputID: 2,
+			mockSetup: func(repo *MockListingRepository) {
+				target := &models.Listing{ID: 2, PriceInCents: 15000000, AddressDetails: models.AddressDetails{ShortenedPostcode: "PR1"}}
+				repo.On("GetByID", mock.Anything, int64(2)).Return(target, nil)
+				repo.On("GetByShortenedPostcode", mock.Anything, "PR1").Return([]*models.Listing{
+					{ID: 5, PriceInCents: 20000000},
+					target,
+					{ID: 3, PriceInCents: 12000000},
+					{ID: 1, PriceInCents: 20000000},
+				}, nil)
+			},
+			expectedIDs: []int64{3, 1, 5},
+		},
+		{
+			name:    "only listing in area",
+			inputID: 2,
+			mockSetup: func(repo *MockListingRepository) {
+				target := &models.Listing{ID: 2, AddressDetails: models.AddressDetails{ShortenedPostcode: "ZZ9"}}
+				repo.On("GetByID", mock.Anything, int64(2)).Return(target, nil)
+				repo.On("GetByShortenedPostcode", mock.Anything, "ZZ9").Return([]*models.Listing{target}, nil)
+			},
+			expectedIDs: []int64{},
+		},
+		{
+			name:    "listing without a postcode area",
+			inputID: 2,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByID", mock.Anything, int64(2)).Return(&models.Listing{ID: 2}, nil)
+			},
+			expectedIDs: []int64{},
+		},
+		{
+			name:    "not found",
+			inputID: 999,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByID", mock.Anything, int64(999)).
+					Return(nil, errors.Wrap(models.ErrNotFound, "listing not found with id: 999"))
+			},
+			expectedError: models.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.GetNeighbours(context.Background(), tt.inputID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				ids := make([]int64, 0, len(result))
+				for _, listing := range result {
+					ids = append(ids, listing.ID)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_GetNearby(t *testing.T) {
+	service := NewService(models.NewListingRepository(), &config.Config{}, nil, nil, nil)
+
+	// 66, 71 and 72 share PR1 in the sample data
+	for id, expectedIDs := range map[int64][]int64{66: {71, 72}, 71: {66, 72}} {
+		nearby, err := service.GetNearby(context.Background(), id)
+		require.NoError(t, err)
+		ids := make([]int64, 0, len(nearby))
+		for _, listing := range nearby {
+			ids = append(ids, listing.ID)
+		}
+		assert.Equal(t, expectedIDs, ids, "listing %d", id)
+	}
+
+	_, err := service.GetNearby(context.Background(), 999)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestService_GetPivot(t *testing.T) {
+	listings := []*models.Listing{
+		{ID: 1, Bedrooms: 2, PropertyType: models.PropertyTypeApartment, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 2, Bedrooms: 10, PropertyType: models.PropertyTypeApartment, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 3, Bedrooms: 2, PropertyType: models.PropertyTypeDetached, AddressDetails: models.AddressDetails{Region: models.RegionWales}},
+		{ID: 4, Bedrooms: 3, PropertyType: models.PropertyTypeApartment, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+	}
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("GetAll", mock.Anything).Return(listings, nil)
+
+	service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+	table, err := service.GetPivot(context.Background(), models.PivotDimensionBedrooms, models.PivotDimensionRegion)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "3", "10"}, table.RowKeys)
+	assert.Equal(t, []string{"London", "Wales"}, table.ColKeys)
+	assert.Equal(t, [][]int{{1, 1}, {1, 0}, {1, 0}}, table.Counts)
+	total := 0
+	for _, row := range table.Counts {
+		for _, count := range row {
+			total += count
+		}
+	}
+	assert.Equal(t, len(listings), total)
+	assert.Equal(t, len(listings), table.Total)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetMultiStats(t *testing.T) {
+	listings := []*models.Listing{
+		{ID: 1, PriceInCents: 20000000, GrossYield: 0.05, PropertyType: models.PropertyTypeApartment, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 2, PriceInCents: 30000000, GrossYield: 0.04, PropertyType: models.PropertyTypeApartment, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 3, PriceInCents: 50000000, GrossYield: 0.03, PropertyType: models.PropertyTypeDetached, AddressDetails: models.AddressDetails{Region: models.RegionLondon}},
+		{ID: 4, PriceInCents: 12000000, GrossYield: 0.08, PropertyType: models.PropertyTypeTerraced, AddressDetails: models.AddressDetails{Region: models.RegionNorthWest}},
+	}
+	filters := []models.NamedStatsFilter{
+		{Name: "London apartments", Region: models.RegionLondon, PropertyType: models.PropertyTypeApartment},
+		{Name: "North West terraces", Region: models.RegionNorthWest, PropertyType: models.PropertyTypeTerraced},
+		{Name: "Welsh anything", Region: models.RegionWales},
+		{Name: "Everything"},
+	}
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("GetAll", mock.Anything).Return(listings, nil)
+
+	service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+	result, err := service.GetMultiStats(context.Background(), models.MultiStatsRequest{Filters: filters})
+
+	require.NoError(t, err)
+	require.Len(t, result, len(filters))
+	assert.Equal(t, "London apartments", result[0].Name)
+	assert.Equal(t, 2, result[0].Count)
+	assert.Equal(t, 25000000.0, *result[0].MedianPriceInCents)
+	assert.InDelta(t, 0.045, *result[0].AverageGrossYield, 1e-9)
+	assert.Equal(t, models.ListingStats{Name: "Welsh anything"}, result[2])
+	assert.Equal(t, len(listings), result[3].Count)
+
+	// Each named result matches asking for that filter on its own
+	for i, filter := range filters {
+		single, err := service.GetMultiStats(context.Background(), models.MultiStatsRequest{Filters: []models.NamedStatsFilter{filter}})
+		require.NoError(t, err)
+		assert.Equal(t, []models.ListingStats{result[i]}, single)
+	}
+	mockRepo.AssertNumberOfCalls(t, "GetAll", 1+len(filters))
+}
+
+func TestService_UpsertListing(t *testing.T) {
+	t.Run("reports whether the listing was created", func(t *testing.T) {
+		for _, created := range []bool{true, false} {
+			listing := &models.Listing{
+				PropertyType:   models.PropertyTypeApartment,
+				PriceInCents:   10000000,
+				AddressDetails: models.AddressDetails{City: "Manchester", ShortenedPostcode: "M1"},
+			}
+			mockRepo := new(MockListingRepository)
+			if created {
+				mockRepo.On("GetByID", mock.Anything, int64(42)).Return(nil, errors.Wrap(models.ErrNotFound, "listing not found with id: 42"))
+			} else {
+				mockRepo.On("GetByID", mock.Anything, int64(42)).Return(&models.Listing{ID: 42}, nil)
+			}
+			mockRepo.On("Upsert", mock.Anything, listing).Return(created, nil)
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, wasCreated, err := service.UpsertListing(context.Background(), 42, listing)
+
+			require.NoError(t, err)
+			assert.Equal(t, created, wasCreated)
+			assert.Equal(t, int64(42), result.ID)
+			assert.Equal(t, models.RegionNorthWest, result.AddressDetails.Region)
+			mockRepo.AssertExpectations(t)
+		}
+	})
+
+	t.Run("rejects an invalid listing", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		_, _, err := service.UpsertListing(context.Background(), 42, &models.Listing{AddressDetails: models.AddressDetails{Region: models.RegionLondon}})
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, validationErr.Issues, "city is required")
+		mockRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_UpsertListing_Owner(t *testing.T) {
+	ctx := context.Background()
+	agents := models.NewAgentRepository()
+	owner := &models.Agent{Name: "Jane Smith", Email: "jane@example.com"}
+	other := &models.Agent{Name: "John Doe", Email: "john@example.com"}
+	require.NoError(t, agents.Create(ctx, owner))
+	require.NoError(t, agents.Create(ctx, other))
+	repo := models.NewListingRepository()
+	service := NewService(repo, &config.Config{}, nil, agents, nil)
+	newListing := func(agentID int64) *models.Listing {
+		return &models.Listing{
+			AgentID:      agentID,
+			PropertyType: models.PropertyTypeApartment,
+			PriceInCents: 10000000,
+			AddressDetails: models.AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "N1",
+				Region:            models.RegionLondon,
+			},
+		}
+	}
+	asOwner := models.ContextWithAgentID(ctx, owner.ID)
+	asOther := models.ContextWithAgentID(ctx, other.ID)
+
+	result, created, err := service.UpsertListing(asOwner, 500, newListing(0))
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, owner.ID, result.AgentID)
+
+	result, created, err = service.UpsertListing(asOwner, 500, newListing(other.ID))
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, owner.ID, result.AgentID, "the body can't hand the listing to another agent")
+
+	for name, caller := range map[string]context.Context{"another agent": asOther, "no agent": ctx} {
+		_, _, err = service.UpsertListing(caller, 500, newListing(0))
+		assert.ErrorIs(t, err, models.ErrConflict, name)
+		_, err = service.UpdateListing(caller, 500, newListing(0))
+		assert.ErrorIs(t, err, models.ErrConflict, name)
+	}
+	stored, err := repo.GetByID(ctx, 500)
+	require.NoError(t, err)
+	assert.Equal(t, owner.ID, stored.AgentID)
+
+	_, _, err = service.UpsertListing(ctx, 501, newListing(0))
+	require.NoError(t, err)
+	result, err = service.UpdateListing(asOther, 501, newListing(0))
+	require.NoError(t, err)
+	assert.Zero(t, result.AgentID, "an unowned listing stays unowned")
+}
+
+func TestService_GetAgentListingIssues(t *testing.T) {
+	agents := models.NewAgentRepository()
+	agent := &models.Agent{Name: "Jane Smith", Email: "jane@example.com"}
+	require.NoError(t, agents.Create(context.Background(), agent))
+
+	validListing := func(id int64) *models.Listing {
+		return &models.Listing{
+			ID:           id,
+			AgentID:      agent.ID,
+			PropertyType: models.PropertyTypeApartment,
+			PriceInCents: 25000000,
+			Description:  "Bright two bed flat",
+			SizeSqFt:     750,
+			Photos:       []models.Photo{{ID: 1}},
+			AddressDetails: models.AddressDetails{
+				City:              "Manchester",
+				Postcode:          "M1 1AA",
+				ShortenedPostcode: "M1",
+				Region:            models.RegionNorthWest,
+			},
+		}
+	}
+	complete := validListing(1)
+	incomplete := validListing(2)
+	incomplete.Description = ""
+	incomplete.Photos = nil
+	incomplete.SizeSqFt = 5
+
+	tests := []struct {
+		name            string
+		agentID         int64
+		mockSetup       func(*MockListingRepository)
+		expectedReports []models.ListingQualityReport
+		expectedError   error
+	}{
+		{
+			name:    "reports the agent's listings with issues",
+			agentID: agent.ID,
+			mockSetup: func(repo *MockListingRepository) {
+				repo.On("GetByAgent", mock.Anything, agent.ID).Return([]*models.Listing{incomplete, complete}, nil)
+			},
+			expectedReports: []models.ListingQualityReport{
+				{
+					ListingID: 2,
+					Issues: []string{
+						"description is required",
+						"at least one photo is required",
+						"size of 5 sq ft looks implausible",
+					},
+				},
+			},
+		},
+		{
+			name:          "unknown agent",
+			agentID:       99,
+			mockSetup:     func(repo *MockListingRepository) {},
+			expectedError: models.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			tt.mockSetup(mockRepo)
+			service := NewService(mockRepo, &config.Config{}, nil, agents, nil)
+
+			reports, err := service.GetAgentListingIssues(context.Background(), tt.agentID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedReports, reports)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_CreateListing_BusinessRules(t *testing.T) {
+	valid := func() *models.Listing {
+		return &models.Listing{
+			PropertyType: models.PropertyTypeApartment,
+			PriceInCents: 10000000,
+			AddressDetails: models.AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "N1",
+				Region:            models.RegionLondon,
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		modify         func(*models.Listing)
+		expectedIssues []string
+	}{
+		{
+			name:   "valid listing",
+			modify: func(l *models.Listing) { l.MonthlyRentalIncomeInCents = 50000; l.MinimumDepositInCents = 2500000 },
+		},
+		{
+			name:           "negative gross yield",
+			modify:         func(l *models.Listing) { l.MonthlyRentalIncomeInCents = -1 },
+			expectedIssues: []string{"gross yield must be between 0 and 1"},
+		},
+		{
+			name:           "gross yield above one",
+			modify:         func(l *models.Listing) { l.MonthlyRentalIncomeInCents = 1000000 },
+			expectedIssues: []string{"gross yield must be between 0 and 1"},
+		},
+		{
+			name:           "deposit above price",
+			modify:         func(l *models.Listing) { l.MinimumDepositInCents = 10000001 },
+			expectedIssues: []string{"minimum deposit cannot be greater than the price"},
+		},
+		{
+			name: "structural and business problems together",
+			modify: func(l *models.Listing) {
+				l.PropertyType = ""
+				l.MonthlyRentalIncomeInCents = 2000000
+			},
+			expectedIssues: []string{"property type is required", "gross yield must be between 0 and 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listing := valid()
+			tt.modify(listing)
+			mockRepo := new(MockListingRepository)
+			if tt.expectedIssues == nil {
+				mockRepo.On("Create", mock.Anything, listing).Return(nil)
+			}
+
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			result, err := service.CreateListing(context.Background(), listing)
+
+			if tt.expectedIssues != nil {
+				var validationErr *models.ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, tt.expectedIssues, validationErr.Issues)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, listing, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_CreateListing_MinimumDepositRatio(t *testing.T) {
+	newListing := func(minimumDeposit int64) *models.Listing {
+		return &models.Listing{
+			PropertyType:          models.PropertyTypeApartment,
+			PriceInCents:          10000000,
+			MinimumDepositInCents: minimumDeposit,
+			AddressDetails: models.AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "N1",
+				Region:            models.RegionLondon,
+			},
+		}
+	}
+	cfg := &config.Config{Listing: config.ListingConfig{MaxMinimumDepositRatio: 0.5}}
+
+	t.Run("within the ratio", func(t *testing.T) {
+		listing := newListing(5000000)
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("Create", mock.Anything, listing).Return(nil)
+
+		_, err := NewService(mockRepo, cfg, nil, nil, nil).CreateListing(context.Background(), listing)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("above the ratio", func(t *testing.T) {
+		_, err := NewService(new(MockListingRepository), cfg, nil, nil, nil).CreateListing(context.Background(), newListing(5000001))
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"minimum deposit cannot be more than 50% of the price"}, validationErr.Issues)
+	})
+
+	t.Run("above the price", func(t *testing.T) {
+		_, err := NewService(new(MockListingRepository), cfg, nil, nil, nil).CreateListing(context.Background(), newListing(10000001))
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"minimum deposit cannot be greater than the price"}, validationErr.Issues)
+	})
+}
+
+func TestService_CreateListing_Photos(t *testing.T) {
+	photo := func(name, mimeType string) models.Photo {
+		return models.Photo{
+			OriginalURL:  "https://example.com/" + name,
+			StandardURL:  "https://example.com/standard/" + name,
+			ThumbnailURL: "https://example.com/thumbnail/" + name,
+			MimeType:     mimeType,
+		}
+	}
+	newListing := func(photos ...models.Photo) *models.Listing {
+		return &models.Listing{
+			PropertyType: models.PropertyTypeApartment,
+			PriceInCents: 10000000,
+			AddressDetails: models.AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "N1",
+				Region:            models.RegionLondon,
+			},
+			Photos: photos,
+		}
+	}
+	emptyURL := photo("b.png", "image/png")
+	emptyURL.StandardURL = ""
+
+	tests := []struct {
+		name           string
+		mimeTypes      []string
+		photos         []models.Photo
+		expectedIssues []string
+	}{
+		{
+			name:   "valid photo set",
+			photos: []models.Photo{photo("a.jpg", "image/jpeg"), photo("b.png", "image/png"), photo("c.webp", "image/webp")},
+		},
+		{
+			name:           "empty URL",
+			photos:         []models.Photo{photo("a.jpg", "image/jpeg"), emptyURL},
+			expectedIssues: []string{"photos[1]: standardURL is required"},
+		},
+		{
+			name:           "malformed URL",
+			photos:         []models.Photo{{OriginalURL: "a.jpg", StandardURL: "https://example.com/a.jpg", ThumbnailURL: "https://example.com/a.jpg", MimeType: "image/jpeg"}},
+			expectedIssues: []string{"photos[0]: originalURL must be an absolute http or https URL"},
+		},
+		{
+			name:           "disallowed MIME type",
+			photos:         []models.Photo{photo("a.html", "text/html")},
+			expectedIssues: []string{`photos[0]: mimeType "text/html" is not one of image/jpeg, image/png, image/webp`},
+		},
+		{
+			name:      "configured MIME types",
+			mimeTypes: []string{"image/gif"},
+			photos:    []models.Photo{photo("a.gif", "image/gif")},
+		},
+		{
+			name:           "type outside the configured MIME types",
+			mimeTypes:      []string{"image/gif"},
+			photos:         []models.Photo{photo("a.jpg", "image/jpeg")},
+			expectedIssues: []string{`photos[0]: mimeType "image/jpeg" is not one of image/gif`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			if tt.expectedIssues == nil {
+				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Listing")).Return(nil)
+			}
+			cfg := &config.Config{Listing: config.ListingConfig{PhotoMimeTypes: tt.mimeTypes}}
+
+			_, err := NewService(mockRepo, cfg, nil, nil, nil).CreateListing(context.Background(), newListing(tt.photos...))
+
+			if tt.expectedIssues == nil {
+				assert.NoError(t, err)
+			} else {
+				var validationErr *models.ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, tt.expectedIssues, validationErr.Issues)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_SampleListingsPassBusinessRules(t *testing.T) {
+	// With the default rules every sample listing must stay writable
+	cfg := &config.Config{Listing: config.ListingConfig{MaxMinimumDepositRatio: 0.5}}
+	svc := NewService(models.NewListingRepository(), cfg, nil, nil, nil).(*service)
+	listings, err := svc.repo.GetAll(context.Background())
+	require.NoError(t, err)
+	for _, listing := range listings {
+		assert.NoError(t, svc.validate(listing), "listing %d", listing.ID)
+	}
+
+	_, err = svc.AddTags(context.Background(), 66, []string{"garden"})
+	assert.NoError(t, err)
+}
+
+func TestService_UpdateListing(t *testing.T) {
+	listing := func() *models.Listing {
+		return &models.Listing{
+			PropertyType: models.PropertyTypeApartment,
+			PriceInCents: 10000000,
+			AddressDetails: models.AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "N1",
+				Region:            models.RegionLondon,
+			},
+		}
+	}
+
+	t.Run("updates an existing listing", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetByID", mock.Anything, int64(187)).Return(&models.Listing{ID: 187}, nil)
+		mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Listing")).Return(nil)
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		result, err := service.UpdateListing(context.Background(), 187, listing())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(187), result.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("missing listing", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetByID", mock.Anything, int64(999)).Return(nil, errors.Wrap(models.ErrNotFound, "listing not found with id: 999"))
+		mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Listing")).
+			Return(errors.Wrap(models.ErrNotFound, "listing not found with id: 999"))
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		_, err := service.UpdateListing(context.Background(), 999, listing())
+
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("invalid listing is not stored", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+		invalid := listing()
+		invalid.MinimumDepositInCents = invalid.PriceInCents + 1
+
+		_, err := service.UpdateListing(context.Background(), 187, invalid)
+
+		var validationErr *models.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid photo is not stored", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+		invalid := listing()
+		invalid.Photos = []models.Photo{{OriginalURL: "https://example.com/a.jpg", MimeType: "image/jpeg"}}
+
+		_, err := service.UpdateListing(context.Background(), 187, invalid)
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"photos[0]: standardURL is required", "photos[0]: thumbnailURL is required"}, validationErr.Issues)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_LookupPostcode(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           string
+		expectedLookup models.PostcodeLookup
+		expectedError  bool
+	}{
+		{
+			name:           "single letter area",
+			code:           "M1 1AE",
+			expectedLookup: models.PostcodeLookup{Postcode: "M1 1AE", ShortenedPostcode: "M1", City: "Manchester", Region: models.RegionNorthWest},
+		},
+		{
+			name:           "lower case without a space",
+			code:           "sw1a1aa",
+			expectedLookup: models.PostcodeLookup{Postcode: "SW1A 1AA", ShortenedPostcode: "SW1A", City: "London", Region: models.RegionLondon},
+		},
+		{
+			name:           "two digit district",
+			code:           " CF10  1EP ",
+			expectedLookup: models.PostcodeLookup{Postcode: "CF10 1EP", ShortenedPostcode: "CF10", City: "Cardiff", Region: models.RegionWales},
+		},
+		{
+			name:           "unknown area has no region",
+			code:           "YO1 7HH",
+			expectedLookup: models.PostcodeLookup{Postcode: "YO1 7HH", ShortenedPostcode: "YO1"},
+		},
+		{
+			name:          "malformed",
+			code:          "12345",
+			expectedError: true,
+		},
+		{
+			name:          "outward code only",
+			code:          "M1",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(new(MockListingRepository), &config.Config{}, nil, nil, nil)
+
+			lookup, err := service.LookupPostcode(context.Background(), tt.code)
+
+			if tt.expectedError {
+				var validationErr *models.ValidationError
+				assert.ErrorAs(t, err, &validationErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedLookup, lookup)
+		})
+	}
+}
+
+func TestService_Tags(t *testing.T) {
+	repo := models.NewListingRepository()
+	service := NewService(repo, &config.Config{}, nil, nil, nil)
+	ctx := context.Background()
+
+	tags, err := service.AddTags(ctx, 187, []string{"Investor Favourite", "price  reduced"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"investor favourite", "price reduced"}, tags)
+
+	tags, err = service.AddTags(ctx, 187, []string{"PRICE REDUCED", "garden"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"investor favourite", "price reduced", "garden"}, tags)
+
+	tags, err = service.RemoveTag(ctx, 187, "Price Reduced")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"investor favourite", "garden"}, tags)
+
+	stored, err := repo.GetByID(ctx, 187)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"investor favourite", "garden"}, stored.Tags)
+
+	_, err = service.RemoveTag(ctx, 187, "price reduced")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	_, err = service.AddTags(ctx, 999999, []string{"garden"})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	var validationErr *models.ValidationError
+	_, err = service.AddTags(ctx, 187, []string{" "})
+	assert.ErrorAs(t, err, &validationErr)
+	_, err = service.AddTags(ctx, 187, []string{strings.Repeat("x", 51)})
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestService_GetAllListingsSorted(t *testing.T) {
+	sorted := []*models.Listing{{ID: 2}, {ID: 1}}
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("GetAllSorted", mock.Anything, models.SortByYield, true).Return(sorted, nil)
+	service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+	result, err := service.GetAllListingsSorted(context.Background(), models.SortByYield, true)
+	require.NoError(t, err)
+	assert.Equal(t, sorted, result)
+
+	var validationErr *models.ValidationError
+	_, err = service.GetAllListingsSorted(context.Background(), models.SortField("colour"), false)
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{`unknown sort field "colour"`}, validationErr.Issues)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetDepositDemand(t *testing.T) {
+	listing := func(region models.Region, status models.ListingStatus, deposit int64) *models.Listing {
+		return &models.Listing{
+			Status:                status,
+			MinimumDepositInCents: deposit,
+			AddressDetails:        models.AddressDetails{Region: region},
+		}
+	}
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("GetAll", mock.Anything).Return([]*models.Listing{
+		listing(models.RegionLondon, models.ListingStatusPublished, 5000000),
+		listing(models.RegionWales, models.ListingStatusPublished, 1500000),
+		listing(models.RegionLondon, models.ListingStatusPublished, 7500000),
+		listing(models.RegionLondon, models.ListingStatusDraft, 9000000),
+		listing(models.RegionScotland, models.ListingStatusDraft, 2000000),
+	}, nil)
+	service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+	demand, err := service.GetDepositDemand(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.RegionDepositDemand{
+		{Region: models.RegionLondon, ListingCount: 2, TotalMinimumDepositInCents: 12500000},
+		{Region: models.RegionWales, ListingCount: 1, TotalMinimumDepositInCents: 1500000},
+	}, demand)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_DeleteListings(t *testing.T) {
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("Delete", mock.Anything, int64(1)).Return(nil)
+	mockRepo.On("Delete", mock.Anything, int64(2)).Return(errors.Wrap(models.ErrNotFound, "listing not found with id: 2"))
+	mockRepo.On("Delete", mock.Anything, int64(3)).Return(errors.New("disk full"))
+	service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+	results, err := service.DeleteListings(context.Background(), []int64{1, 2, 3})
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.DeleteResult{
+		{ID: 1, Status: http.StatusOK},
+		{ID: 2, Status: http.StatusNotFound, Error: "listing not found"},
+		{ID: 3, Status: http.StatusInternalServerError, Error: "failed to delete listing"},
+	}, results)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_RestoreListing(t *testing.T) {
+	t.Run("returns the restored listing", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("Restore", mock.Anything, int64(1)).Return(nil)
+		mockRepo.On("GetByID", mock.Anything, int64(1)).Return(&models.Listing{ID: 1}, nil)
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		restored, err := service.RestoreListing(context.Background(), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), restored.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("listing that isn't deleted", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("Restore", mock.Anything, int64(2)).
+			Return(errors.Wrap(models.ErrNotFound, "deleted listing not found with id: 2"))
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		_, err := service.RestoreListing(context.Background(), 2)
+
+		assert.ErrorIs(t, err, models.ErrNotFound)
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_GetCityGroups(t *testing.T) {
+	listing := func(id int64, city string, price int64) *models.Listing {
+		return &models.Listing{ID: id, PriceInCents: price, AddressDetails: models.AddressDetails{City: city}}
+	}
+	mockRepo := new(MockListingRepository)
+	mockRepo.On("GetAll", mock.Anything).Return([]*models.Listing{
+		listing(1, "Leeds", 10000000),
+		listing(2, "London", 50000000),
+		listing(3, " leeds ", 20000000),
+		listing(4, "Bristol", 30000000),
+		listing(5, "", 40000000),
+	}, nil)
+	service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+	groups, err := service.GetCityGroups(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.CityGroup{
+		{City: "Leeds", ListingCount: 2, AveragePriceInCents: 15000000},
+		{City: "Bristol", ListingCount: 1, AveragePriceInCents: 30000000},
+		{City: "London", ListingCount: 1, AveragePriceInCents: 50000000},
+	}, groups)
+	mockRepo.AssertExpectations(t)
+}
+
+// geocoderFunc adapts a function to the Geocoder interface
+type geocoderFunc func(ctx context.Context, city string) (models.Coordinates, error)
+
+func (f geocoderFunc) Geocode(ctx context.Context, city string) (models.Coordinates, error) {
+	return f(ctx, city)
+}
+
+// roundTripFunc lets a function stand in for the outbound client's transport
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestHTTPGeocoder(t *testing.T) {
+	var requested []string
+	client := httpclient.WithTransport(
+		httpclient.New(&config.Config{HTTPClient: config.HTTPClientConfig{Timeout: 5 * time.Second}}),
+		roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requested = append(requested, req.URL.String())
+			status, body := http.StatusNotFound, ""
+			switch req.URL.Query().Get("city") {
+			case "Testville":
+				status, body = http.StatusOK, `{"latitude":51,"longitude":0}`
+			case "Broken":
+				status = http.StatusInternalServerError
+			}
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}, nil
+		}),
+	)
+	geocoder := NewHTTPGeocoder(client, "https://geocoder.example.com/centre")
+
+	t.Run("near-city searches go through the outbound client", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetAll", mock.Anything).Return([]*models.Listing{
+			{ID: 1, AddressDetails: models.AddressDetails{Coordinates: &models.Coordinates{Latitude: 51.02}}},
+		}, nil)
+		service := NewService(mockRepo, &config.Config{}, nil, nil, geocoder)
+
+		result, err := service.GetListingsNearCity(context.Background(), "Testville", 10)
+
+		require.NoError(t, err)
+		assert.False(t, result.RegionFallback)
+		assert.Equal(t, &models.Coordinates{Latitude: 51, Longitude: 0}, result.Centre)
+		assert.Equal(t, []string{"https://geocoder.example.com/centre?city=Testville"}, requested)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown city", func(t *testing.T) {
+		_, err := geocoder.Geocode(context.Background(), "Nowhere")
+		assert.ErrorIs(t, err, ErrCityNotFound)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		_, err := geocoder.Geocode(context.Background(), "Broken")
+		assert.EqualError(t, err, `geocoder responded 500 for "Broken"`)
+	})
+}
+
+func TestService_GetListingsNearCity(t *testing.T) {
+	located := func(id int64, latitude float64) *models.Listing {
+		return &models.Listing{ID: id, AddressDetails: models.AddressDetails{
+			Coordinates: &models.Coordinates{Latitude: latitude, Longitude: 0},
+		}}
+	}
+	geocoder := NewStaticGeocoder(map[string]models.Coordinates{"Testville": {Latitude: 51, Longitude: 0}})
+
+	t.Run("nearest first within the radius", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetAll", mock.Anything).Return([]*models.Listing{
+			located(1, 51.1),
+			located(2, 51.02),
+			located(3, 52),
+			{ID: 4},
+			located(5, 50.95),
+		}, nil)
+		service := NewService(mockRepo, &config.Config{}, nil, nil, geocoder)
+
+		result, err := service.GetListingsNearCity(context.Background(), " testville ", 10)
+
+		require.NoError(t, err)
+		assert.False(t, result.RegionFallback)
+		assert.Equal(t, &models.Coordinates{Latitude: 51, Longitude: 0}, result.Centre)
+		ids := make([]int64, len(result.Listings))
+		for i, nearby := range result.Listings {
+			ids[i] = nearby.Listing.ID
+			require.NotNil(t, nearby.DistanceMiles)
+			assert.LessOrEqual(t, *nearby.DistanceMiles, 10.0)
+		}
+		assert.Equal(t, []int64{2, 5, 1}, ids)
+		assert.InDelta(t, 1.38, *result.Listings[0].DistanceMiles, 0.01)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("falls back to the region without a geocoder", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetByRegion", mock.Anything, string(models.RegionNorthEast)).
+			Return([]*models.Listing{{ID: 7}}, nil)
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		result, err := service.GetListingsNearCity(context.Background(), "Leeds", 10)
+
+		require.NoError(t, err)
+		assert.True(t, result.RegionFallback)
+		assert.Equal(t, models.RegionNorthEast, result.Region)
+		assert.Nil(t, result.Centre)
+		assert.Equal(t, []models.NearbyListing{{Listing: &models.Listing{ID: 7}}}, result.Listings)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("falls back to the region when the geocoder fails", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetByRegion", mock.Anything, string(models.RegionWales)).Return([]*models.Listing{}, nil)
+		failing := geocoderFunc(func(ctx context.Context, city string) (models.Coordinates, error) {
+			return models.Coordinates{}, errors.New("geocoder unreachable")
+		})
+		service := NewService(mockRepo, &config.Config{}, nil, nil, failing)
+
+		result, err := service.GetListingsNearCity(context.Background(), "Cardiff", 10)
+
+		require.NoError(t, err)
+		assert.True(t, result.RegionFallback)
+		assert.Empty(t, result.Listings)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown city", func(t *testing.T) {
+		service := NewService(new(MockListingRepository), &config.Config{}, nil, nil, geocoder)
+
+		_, err := service.GetListingsNearCity(context.Background(), "Atlantis", 10)
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{`unknown city "Atlantis"`}, validationErr.Issues)
+	})
+
+	t.Run("geocoder unavailable for a city with no region", func(t *testing.T) {
+		failing := geocoderFunc(func(ctx context.Context, city string) (models.Coordinates, error) {
+			return models.Coordinates{}, errors.New("geocoder unreachable")
+		})
+		service := NewService(new(MockListingRepository), &config.Config{}, nil, nil, failing)
+
+		_, err := service.GetListingsNearCity(context.Background(), "Atlantis", 10)
+
+		require.Error(t, err)
+		var validationErr *models.ValidationError
+		assert.False(t, errors.As(err, &validationErr))
+	})
+
+	t.Run("radius must be positive", func(t *testing.T) {
+		service := NewService(new(MockListingRepository), &config.Config{}, nil, nil, geocoder)
+
+		_, err := service.GetListingsNearCity(context.Background(), "Testville", 0)
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+	})
+}
+
+func TestService_GetDepositAnomalies(t *testing.T) {
+	listing := func(id, price, deposit int64) *models.Listing {
+		return &models.Listing{ID: id, PriceInCents: price, EstimatedDepositInCents: deposit}
+	}
+
+	t.Run("flags deposits outside the default band", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetAll", mock.Anything).Return([]*models.Listing{
+			listing(1, 20000000, 5000000),
+			listing(2, 20000000, 19000000),
+			listing(3, 20000000, 100),
+			listing(4, 0, 5000000),
+			listing(5, 20000000, 8000000),
+		}, nil)
+		service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+		anomalies, err := service.GetDepositAnomalies(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.DepositAnomaly{
+			{ID: 2, PriceInCents: 20000000, EstimatedDepositInCents: 19000000, DepositRatio: 0.95, Reason: models.DepositAnomalyAboveMaximum},
+			{ID: 3, PriceInCents: 20000000, EstimatedDepositInCents: 100, DepositRatio: 0.000005, Reason: models.DepositAnomalyBelowMinimum},
+		}, anomalies)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("uses the configured band", func(t *testing.T) {
+		mockRepo := new(MockListingRepository)
+		mockRepo.On("GetAll", mock.Anything).Return([]*models.Listing{
+			listing(1, 20000000, 5000000),
+			listing(2, 20000000, 8000000),
+		}, nil)
+		cfg := &config.Config{Listing: config.ListingConfig{DepositRatioMin: 0.1, DepositRatioMax: 0.3}}
+		service := NewService(mockRepo, cfg, nil, nil, nil)
+
+		anomalies, err := service.GetDepositAnomalies(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, anomalies, 1)
+		assert.Equal(t, int64(2), anomalies[0].ID)
+		assert.Equal(t, models.DepositAnomalyAboveMaximum, anomalies[0].Reason)
+	})
+
+	t.Run("flags the off sample listings", func(t *testing.T) {
+		service := NewService(models.NewListingRepository(), &config.Config{}, nil, nil, nil)
+
+		anomalies, err := service.GetDepositAnomalies(context.Background())
+
+		require.NoError(t, err)
+		ids := make([]int64, len(anomalies))
+		for i, anomaly := range anomalies {
+			ids[i] = anomaly.ID
+		}
+		assert.Contains(t, ids, int64(80))
+		assert.NotContains(t, ids, int64(187))
+	})
+}
+
+func TestService_CountByPropertyType(t *testing.T) {
+	tests := []struct {
+		name         string
+		includeEmpty bool
+		expected     map[models.PropertyType]int
+	}{
+		{
+			name:         "with empty types",
+			includeEmpty: true,
+			expected: map[models.PropertyType]int{
+				models.PropertyTypeApartment:    2,
+				models.PropertyTypeDetached:     0,
+				models.PropertyTypeSemiDetached: 0,
+				models.PropertyTypeTerraced:     1,
+				models.PropertyTypeEndTerrace:   0,
+			},
+		},
+		{
+			name:     "without empty types",
+			expected: map[models.PropertyType]int{models.PropertyTypeApartment: 2, models.PropertyTypeTerraced: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockListingRepository)
+			mockRepo.On("CountByPropertyType", mock.Anything).
+				Return(map[models.PropertyType]int{models.PropertyTypeApartment: 2, models.PropertyTypeTerraced: 1}, nil)
+			service := NewService(mockRepo, &config.Config{}, nil, nil, nil)
+
+			counts, err := service.CountByPropertyType(context.Background(), tt.includeEmpty)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, counts)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
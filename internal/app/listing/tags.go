@@ -0,0 +1,56 @@
+package listing
+
+import (
+	"context"
+	"slices"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// AddTags adds the tags to a listing, ignoring any it already has, and returns
+// its tags. Invalid tags return a *models.ValidationError.
+func (s *service) AddTags(ctx context.Context, id int64, tags []string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tags = models.NormalizeTags(tags)
+	if len(tags) == 0 {
+		return nil, &models.ValidationError{Issues: []string{"at least one tag is required"}}
+	}
+	listing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get listing with id: %d", id)
+	}
+	updated := *listing
+	updated.Tags = models.NormalizeTags(append(slices.Clone(listing.Tags), tags...))
+	if err := s.validate(&updated); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, &updated); err != nil {
+		return nil, errors.Wrapf(err, "failed to tag listing with id: %d", id)
+	}
+	return updated.Tags, nil
+}
+
+// RemoveTag removes a tag from a listing and returns its remaining tags
+func (s *service) RemoveTag(ctx context.Context, id int64, tag string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	listing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get listing with id: %d", id)
+	}
+	if !listing.HasTag(tag) {
+		return nil, errors.Wrapf(models.ErrNotFound, "tag %q not found on listing with id: %d", tag, id)
+	}
+	updated := *listing
+	updated.Tags = slices.DeleteFunc(slices.Clone(listing.Tags), func(t string) bool {
+		return t == models.NormalizeTag(tag)
+	})
+	if err := s.repo.Update(ctx, &updated); err != nil {
+		return nil, errors.Wrapf(err, "failed to untag listing with id: %d", id)
+	}
+	return updated.Tags, nil
+}
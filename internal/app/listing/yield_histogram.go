@@ -0,0 +1,56 @@
+package listing
+
+import (
+	"context"
+	"sort"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/pkg/errors"
+)
+
+// GetYieldHistogram splits the range from the lowest to the highest gross
+// yield into the given number of equal-width buckets and counts the listings
+// in each. An empty dataset has no buckets.
+func (s *service) GetYieldHistogram(ctx context.Context, buckets int) (models.YieldHistogram, error) {
+	if err := ctx.Err(); err != nil {
+		return models.YieldHistogram{}, err
+	}
+	if buckets <= 0 {
+		return models.YieldHistogram{}, errors.Errorf("bucket count must be positive, got %d", buckets)
+	}
+	listings, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return models.YieldHistogram{}, errors.Wrap(err, "failed to get listings for yield histogram")
+	}
+	histogram := models.YieldHistogram{Total: len(listings), Buckets: []models.YieldBucket{}}
+	if len(listings) == 0 {
+		return histogram, nil
+	}
+
+	minYield, maxYield := listings[0].GrossYield, listings[0].GrossYield
+	for _, listing := range listings[1:] {
+		minYield = min(minYield, listing.GrossYield)
+		maxYield = max(maxYield, listing.GrossYield)
+	}
+	if minYield == maxYield {
+		// Every listing has the same yield, so one bucket holds them all
+		buckets = 1
+	}
+	width := (maxYield - minYield) / float64(buckets)
+	histogram.Buckets = make([]models.YieldBucket, buckets)
+	for i := range histogram.Buckets {
+		histogram.Buckets[i].MinYield = minYield + float64(i)*width
+		histogram.Buckets[i].MaxYield = minYield + float64(i+1)*width
+	}
+	histogram.Buckets[buckets-1].MaxYield = maxYield
+
+	for _, listing := range listings {
+		// The first bucket starting above the yield follows the one it belongs
+		// to, so a yield on a boundary falls into the bucket that starts at it
+		i := sort.Search(buckets, func(i int) bool {
+			return histogram.Buckets[i].MinYield > listing.GrossYield
+		})
+		histogram.Buckets[max(i-1, 0)].Count++
+	}
+	return histogram, nil
+}
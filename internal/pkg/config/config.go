@@ -2,19 +2,118 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server ServerConfig `mapstructure:"server"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Listing    ListingConfig    `mapstructure:"listing"`
+	Repository RepositoryConfig `mapstructure:"repository"`
+	Admin      AdminConfig      `mapstructure:"admin"`
+	HTTPClient HTTPClientConfig `mapstructure:"http_client"`
 }
 
 type ServerConfig struct {
 	Port         string        `mapstructure:"port"`
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// IdleTimeout is how long a keep-alive connection may wait for its next request
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+	// ShutdownTimeout is how long in-flight requests may take to finish when
+	// the server is stopped before their connections are closed
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// GzipMinBytes is the smallest response body worth compressing
+	GzipMinBytes int `mapstructure:"gzip_min_bytes"`
+	// MaxQueryParams is how many filter and sort values a listings request may
+	// combine; 0 disables the limit
+	MaxQueryParams int `mapstructure:"max_query_params"`
+	// DisabledQueryParams are query parameters rejected on every endpoint,
+	// on top of those an endpoint doesn't recognise
+	DisabledQueryParams []string `mapstructure:"disabled_query_params"`
+	// DisabledSortFields are the sort fields rejected on every endpoint
+	DisabledSortFields []string `mapstructure:"disabled_sort_fields"`
+	// MaxBodyBytes is the largest request body accepted; 0 disables the limit
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes"`
+}
+
+// Timeouts applied by Validate when the server's are left at zero
+const (
+	DefaultReadTimeout     = 30 * time.Second
+	DefaultWriteTimeout    = 30 * time.Second
+	DefaultIdleTimeout     = 60 * time.Second
+	DefaultShutdownTimeout = 15 * time.Second
+)
+
+type ListingConfig struct {
+	// DefaultRegion is used for a listing with no region whose city can't be mapped
+	DefaultRegion string `mapstructure:"default_region"`
+	// StrictRegion rejects listings with no region instead of inferring one
+	StrictRegion bool `mapstructure:"strict_region"`
+	// CityRegions adds to or overrides the built-in city to region lookup
+	CityRegions map[string]string `mapstructure:"city_regions"`
+	// PriceBandEdges are the prices in cents separating the price bands
+	PriceBandEdges []int64 `mapstructure:"price_band_edges"`
+	// DepositRate is the fraction of the price used for the estimated deposit
+	DepositRate float64 `mapstructure:"deposit_rate"`
+	// RoundingMode is how the estimated deposit is rounded to the cent:
+	// half_up, half_even or down
+	RoundingMode string `mapstructure:"rounding_mode"`
+	// ChangesPollTimeout is how long a changes poll waits before returning empty
+	ChangesPollTimeout time.Duration `mapstructure:"changes_poll_timeout"`
+	// PublishRequiredFields must be filled in before a listing can be published
+	PublishRequiredFields []string `mapstructure:"publish_required_fields"`
+	// GrossYieldDecimals is how many decimals grossYieldPercent is rounded to
+	GrossYieldDecimals int `mapstructure:"gross_yield_decimals"`
+	// NewBuildMaxAgeYears is how old a property can be and still count as a new build
+	NewBuildMaxAgeYears int `mapstructure:"new_build_max_age_years"`
+	// DepositRatioMin and DepositRatioMax bound the plausible estimated
+	// deposit as a fraction of the price; listings outside are anomalies
+	DepositRatioMin float64 `mapstructure:"deposit_ratio_min"`
+	DepositRatioMax float64 `mapstructure:"deposit_ratio_max"`
+	// MaxMinimumDepositRatio caps the minimum deposit as a fraction of the
+	// price; listings above it are rejected. 0 disables the check.
+	MaxMinimumDepositRatio float64 `mapstructure:"max_minimum_deposit_ratio"`
+	// PhotoMimeTypes are the image types a listing photo may have
+	PhotoMimeTypes []string `mapstructure:"photo_mime_types"`
+	// GeocoderURL is the city lookup service used for near-city searches.
+	// When empty the built-in table of city centres is used.
+	GeocoderURL string `mapstructure:"geocoder_url"`
+}
+
+type RepositoryConfig struct {
+	// SlowThreshold is how long an operation may take before a warning is logged
+	SlowThreshold time.Duration `mapstructure:"slow_threshold"`
+	// Driver selects where listings are stored: memory or postgres
+	Driver string `mapstructure:"driver"`
+	// DSN is the PostgreSQL connection string used by the postgres driver
+	DSN string `mapstructure:"dsn"`
+}
+
+// Repository drivers accepted by RepositoryConfig.Driver
+const (
+	RepositoryDriverMemory   = "memory"
+	RepositoryDriverPostgres = "postgres"
+)
+
+type AdminConfig struct {
+	// Enabled exposes the /api/v1/admin endpoints
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// HTTPClientConfig configures the client shared by all outbound HTTP calls
+type HTTPClientConfig struct {
+	// Timeout bounds a whole outbound request, including reading the body
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxIdleConns caps the idle connections kept open across all hosts
+	MaxIdleConns int `mapstructure:"max_idle_conns"`
+	// MaxIdleConnsPerHost caps the idle connections kept open to each host
+	MaxIdleConnsPerHost int `mapstructure:"max_idle_conns_per_host"`
+	// IdleConnTimeout is how long an idle connection is kept before closing
+	IdleConnTimeout time.Duration `mapstructure:"idle_conn_timeout"`
 }
 
 func Load() (*Config, error) {
@@ -27,8 +126,37 @@ func Load() (*Config, error) {
 	viper.SetEnvPrefix("APP")
 
 	viper.SetDefault("server.port", "3001")
-	viper.SetDefault("server.read_timeout", "30s")
-	viper.SetDefault("server.write_timeout", "30s")
+	viper.SetDefault("server.read_timeout", DefaultReadTimeout)
+	viper.SetDefault("server.write_timeout", DefaultWriteTimeout)
+	viper.SetDefault("server.idle_timeout", DefaultIdleTimeout)
+	viper.SetDefault("server.shutdown_timeout", DefaultShutdownTimeout)
+	viper.SetDefault("server.gzip_min_bytes", 1024)
+	viper.SetDefault("server.max_query_params", 20)
+	viper.SetDefault("server.disabled_query_params", []string{})
+	viper.SetDefault("server.disabled_sort_fields", []string{})
+	viper.SetDefault("server.max_body_bytes", 1<<20)
+	viper.SetDefault("listing.default_region", "")
+	viper.SetDefault("listing.strict_region", false)
+	viper.SetDefault("listing.price_band_edges", []int64{10000000, 25000000})
+	viper.SetDefault("listing.deposit_rate", 0.25)
+	viper.SetDefault("listing.rounding_mode", "half_up")
+	viper.SetDefault("listing.changes_poll_timeout", "25s")
+	viper.SetDefault("listing.publish_required_fields", []string{"description", "photos", "postcode"})
+	viper.SetDefault("listing.gross_yield_decimals", 2)
+	viper.SetDefault("listing.new_build_max_age_years", 2)
+	viper.SetDefault("listing.deposit_ratio_min", 0.05)
+	viper.SetDefault("listing.deposit_ratio_max", 0.40)
+	viper.SetDefault("listing.max_minimum_deposit_ratio", 0.5)
+	viper.SetDefault("listing.photo_mime_types", []string{"image/jpeg", "image/png", "image/webp"})
+	viper.SetDefault("listing.geocoder_url", "")
+	viper.SetDefault("repository.slow_threshold", "200ms")
+	viper.SetDefault("repository.driver", RepositoryDriverMemory)
+	viper.SetDefault("repository.dsn", "")
+	viper.SetDefault("admin.enabled", false)
+	viper.SetDefault("http_client.timeout", "10s")
+	viper.SetDefault("http_client.max_idle_conns", 100)
+	viper.SetDefault("http_client.max_idle_conns_per_host", 10)
+	viper.SetDefault("http_client.idle_conn_timeout", "90s")
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -40,6 +168,43 @@ func Load() (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 
 	return &config, nil
 }
+
+// Validate checks the server settings, returning an error for a missing or
+// out of range port or a negative timeout. Timeouts left at zero are set to
+// their defaults rather than disabling the timeout.
+func (c *Config) Validate() error {
+	port := strings.TrimSpace(c.Server.Port)
+	if port == "" {
+		return fmt.Errorf("server.port is required")
+	}
+	if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+		return fmt.Errorf("server.port must be a number between 1 and 65535, got %q", c.Server.Port)
+	}
+	c.Server.Port = port
+
+	timeouts := []struct {
+		name     string
+		value    *time.Duration
+		fallback time.Duration
+	}{
+		{"server.read_timeout", &c.Server.ReadTimeout, DefaultReadTimeout},
+		{"server.write_timeout", &c.Server.WriteTimeout, DefaultWriteTimeout},
+		{"server.idle_timeout", &c.Server.IdleTimeout, DefaultIdleTimeout},
+		{"server.shutdown_timeout", &c.Server.ShutdownTimeout, DefaultShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if *timeout.value < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", timeout.name, *timeout.value)
+		}
+		if *timeout.value == 0 {
+			*timeout.value = timeout.fallback
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		server        ServerConfig
+		expected      ServerConfig
+		expectedError string
+	}{
+		{
+			name:     "zero timeouts get defaults",
+			server:   ServerConfig{Port: "3001"},
+			expected: ServerConfig{Port: "3001", ReadTimeout: DefaultReadTimeout, WriteTimeout: DefaultWriteTimeout, IdleTimeout: DefaultIdleTimeout, ShutdownTimeout: DefaultShutdownTimeout},
+		},
+		{
+			name:     "set timeouts are kept",
+			server:   ServerConfig{Port: " 8080 ", ReadTimeout: time.Second, WriteTimeout: 2 * time.Second, IdleTimeout: 3 * time.Second, ShutdownTimeout: 4 * time.Second},
+			expected: ServerConfig{Port: "8080", ReadTimeout: time.Second, WriteTimeout: 2 * time.Second, IdleTimeout: 3 * time.Second, ShutdownTimeout: 4 * time.Second},
+		},
+		{
+			name:          "missing port",
+			server:        ServerConfig{},
+			expectedError: "server.port is required",
+		},
+		{
+			name:          "port is not a number",
+			server:        ServerConfig{Port: ":3001"},
+			expectedError: `server.port must be a number between 1 and 65535, got ":3001"`,
+		},
+		{
+			name:          "port out of range",
+			server:        ServerConfig{Port: "70000"},
+			expectedError: `server.port must be a number between 1 and 65535, got "70000"`,
+		},
+		{
+			name:          "negative timeout",
+			server:        ServerConfig{Port: "3001", WriteTimeout: -time.Second},
+			expectedError: "server.write_timeout must not be negative, got -1s",
+		},
+		{
+			name:          "negative shutdown timeout",
+			server:        ServerConfig{Port: "3001", ShutdownTimeout: -time.Second},
+			expectedError: "server.shutdown_timeout must not be negative, got -1s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: tt.server}
+
+			err := cfg.Validate()
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.Server)
+		})
+	}
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, "3001", cfg.Server.Port)
+	assert.Equal(t, DefaultReadTimeout, cfg.Server.ReadTimeout)
+	assert.Equal(t, DefaultWriteTimeout, cfg.Server.WriteTimeout)
+	assert.Equal(t, DefaultIdleTimeout, cfg.Server.IdleTimeout)
+	assert.Equal(t, DefaultShutdownTimeout, cfg.Server.ShutdownTimeout)
+	assert.Equal(t, int64(1<<20), cfg.Server.MaxBodyBytes)
+	assert.Empty(t, cfg.Server.DisabledQueryParams)
+	assert.Empty(t, cfg.Server.DisabledSortFields)
+	assert.Equal(t, []string{"image/jpeg", "image/png", "image/webp"}, cfg.Listing.PhotoMimeTypes)
+	assert.Empty(t, cfg.Listing.GeocoderURL)
+}
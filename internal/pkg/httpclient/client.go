@@ -0,0 +1,30 @@
+package httpclient
+
+import (
+	"net/http"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/config"
+)
+
+// New returns the HTTP client for outbound calls, configured from
+// cfg.HTTPClient. Features that call other services should take it as a
+// dependency rather than use http.DefaultClient, so they share one connection
+// pool and one set of timeouts and can be given a fake transport in tests.
+func New(cfg *config.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = cfg.HTTPClient.MaxIdleConns
+	transport.MaxIdleConnsPerHost = cfg.HTTPClient.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = cfg.HTTPClient.IdleConnTimeout
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.HTTPClient.Timeout,
+	}
+}
+
+// WithTransport returns a copy of client that sends requests through
+// transport, keeping its other settings
+func WithTransport(client *http.Client, transport http.RoundTripper) *http.Client {
+	copied := *client
+	copied.Transport = transport
+	return &copied
+}
@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripFunc lets a function stand in for a transport
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestNew(t *testing.T) {
+	client := New(&config.Config{HTTPClient: config.HTTPClientConfig{
+		Timeout:             5 * time.Second,
+		MaxIdleConns:        50,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     time.Minute,
+	}})
+
+	assert.Equal(t, 5*time.Second, client.Timeout)
+	transport, ok := client.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.Equal(t, 50, transport.MaxIdleConns)
+	assert.Equal(t, 10, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.NotSame(t, http.DefaultTransport, client.Transport)
+}
+
+func TestWithTransport(t *testing.T) {
+	client := New(&config.Config{HTTPClient: config.HTTPClientConfig{Timeout: 5 * time.Second}})
+	var requested []string
+	mock := WithTransport(client, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.String())
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"ok":true}`)),
+			Header:     make(http.Header),
+		}, nil
+	}))
+
+	resp, err := mock.Get("https://geocoder.example.com/lookup?q=SW1A")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"https://geocoder.example.com/lookup?q=SW1A"}, requested)
+	assert.Equal(t, 5*time.Second, mock.Timeout)
+	assert.IsType(t, &http.Transport{}, client.Transport, "the original client is left untouched")
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AgentIDHeader carries the ID of the agent a request is made on behalf of. It
+// is set by the authenticating proxy in front of the service.
+const AgentIDHeader = "X-Agent-ID"
+
+// AgentIdentity puts the agent named by the X-Agent-ID header on the request's
+// context.Context, where models.AgentIDFromContext finds it. Requests without
+// the header carry no identity; a malformed header is rejected with 400.
+func AgentIdentity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(AgentIDHeader)
+		if header == "" {
+			c.Next()
+			return
+		}
+		agentID, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || agentID <= 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid " + AgentIDHeader + " header"})
+			return
+		}
+		c.Request = c.Request.WithContext(models.ContextWithAgentID(c.Request.Context(), agentID))
+		c.Next()
+	}
+}
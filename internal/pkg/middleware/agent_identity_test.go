@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentIdentity(t *testing.T) {
+	tests := []struct {
+		name            string
+		header          string
+		expectedStatus  int
+		expectedAgentID int64
+		expectedOK      bool
+	}{
+		{name: "header sets the identity", header: "7", expectedStatus: http.StatusNoContent, expectedAgentID: 7, expectedOK: true},
+		{name: "missing header carries no identity", header: "", expectedStatus: http.StatusNoContent},
+		{name: "malformed header", header: "abc", expectedStatus: http.StatusBadRequest},
+		{name: "non-positive header", header: "0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(AgentIdentity())
+			var agentID int64
+			var ok bool
+			router.GET("/", func(c *gin.Context) {
+				agentID, ok = models.AgentIDFromContext(c.Request.Context())
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(AgentIDHeader, tt.header)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedAgentID, agentID)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
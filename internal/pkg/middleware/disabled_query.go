@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DisabledQuery rejects requests using any of the disabled query parameters,
+// or sorting by any of the disabled sort fields, with a 400. It narrows the
+// allowlists the handlers check, so a filter or sort that has become too
+// expensive can be switched off through configuration.
+func DisabledQuery(params, sortFields []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		for _, param := range params {
+			if query.Has(param) {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Query parameter is disabled: " + param})
+				return
+			}
+		}
+		for _, field := range sortFields {
+			if slices.Contains(query["sort"], field) {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Sort field is disabled: " + field})
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisabledQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "no parameters", query: "", expectedStatus: http.StatusOK},
+		{name: "enabled parameters", query: "?region=London&sort=price", expectedStatus: http.StatusOK},
+		{
+			name:           "disabled parameter",
+			query:          "?region=London&tag=garden",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Query parameter is disabled: tag"}`,
+		},
+		{
+			name:           "disabled parameter without a value",
+			query:          "?tag=",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Query parameter is disabled: tag"}`,
+		},
+		{
+			name:           "disabled sort field",
+			query:          "?sort=pricePerSqFt&order=desc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Sort field is disabled: pricePerSqFt"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(DisabledQuery([]string{"tag"}, []string{"pricePerSqFt"}))
+			router.GET("/listings", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			})
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/listings"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			}
+		})
+	}
+
+	t.Run("nothing disabled", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(DisabledQuery(nil, nil))
+		router.GET("/listings", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		})
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/listings?tag=garden&sort=pricePerSqFt", nil))
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+}
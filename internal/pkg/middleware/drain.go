@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DrainSwitch records that the server has started shutting down. The shutdown
+// hook flips it and RejectWhileDraining reads it, so it must be shared.
+type DrainSwitch struct {
+	draining atomic.Bool
+}
+
+// NewDrainSwitch creates a switch that is not draining
+func NewDrainSwitch() *DrainSwitch {
+	return &DrainSwitch{}
+}
+
+// StartDraining makes RejectWhileDraining turn away new requests. It can't be undone.
+func (d *DrainSwitch) StartDraining() {
+	d.draining.Store(true)
+}
+
+// Draining reports whether StartDraining has been called
+func (d *DrainSwitch) Draining() bool {
+	return d.draining.Load()
+}
+
+// RejectWhileDraining answers new requests with a 503 once the switch is
+// draining, asking the client to close the connection so its retry goes to
+// another instance. Requests already past the middleware run to completion.
+func RejectWhileDraining(drain *DrainSwitch) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if drain.Draining() {
+			c.Header("Connection", "close")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Server is shutting down"})
+			return
+		}
+		c.Next()
+	}
+}
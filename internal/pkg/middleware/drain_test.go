@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectWhileDraining(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	drain := NewDrainSwitch()
+	router := gin.New()
+	router.Use(RejectWhileDraining(drain))
+
+	inFlight := make(chan struct{})
+	release := make(chan struct{})
+	router.GET("/slow", func(c *gin.Context) {
+		close(inFlight)
+		<-release
+		c.Status(http.StatusOK)
+	})
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		return resp
+	}
+
+	assert.Equal(t, http.StatusOK, get("/").Code)
+
+	slow := make(chan *httptest.ResponseRecorder)
+	go func() { slow <- get("/slow") }()
+	<-inFlight
+
+	drain.StartDraining()
+	assert.True(t, drain.Draining())
+
+	rejected := get("/")
+	assert.Equal(t, http.StatusServiceUnavailable, rejected.Code)
+	assert.Equal(t, "close", rejected.Header().Get("Connection"))
+	assert.JSONEq(t, `{"error":"Server is shutting down"}`, rejected.Body.String())
+
+	// The request that started before draining still completes
+	close(release)
+	assert.Equal(t, http.StatusOK, (<-slow).Code)
+}
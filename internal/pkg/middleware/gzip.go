@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compressibleContentTypes are the media types worth gzipping. Images, PDFs
+// and other already compressed formats are left alone.
+var compressibleContentTypes = []string{
+	"application/json",
+	"text/csv",
+	"text/plain",
+	"text/html",
+}
+
+// Gzip compresses responses for clients that accept gzip, but only when the
+// body is at least minBytes long and has a compressible content type.
+// Responses are buffered so their size is known before choosing. A handler
+// that flushes is streaming, so from its first flush the response goes out as
+// it is written, compressed whenever the content type allows.
+func Gzip(minBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !acceptsGzip(c.Request) {
+			c.Next()
+			return
+		}
+		original := c.Writer
+		writer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		defer func() { c.Writer = original }()
+
+		c.Next()
+
+		if writer.streaming {
+			if writer.gz != nil {
+				_ = writer.gz.Close()
+			}
+			return
+		}
+		body := writer.body.Bytes()
+		header := original.Header()
+		header.Add("Vary", "Accept-Encoding")
+		if len(body) < minBytes || !isCompressible(header.Get("Content-Type")) || header.Get("Content-Encoding") != "" {
+			original.WriteHeader(writer.status)
+			_, _ = original.Write(body)
+			return
+		}
+
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		_, _ = gz.Write(body)
+		_ = gz.Close()
+		header.Set("Content-Encoding", "gzip")
+		header.Set("Content-Length", strconv.Itoa(compressed.Len()))
+		original.WriteHeader(writer.status)
+		_, _ = original.Write(compressed.Bytes())
+	}
+}
+
+// acceptsGzip reports whether the client listed gzip in Accept-Encoding
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(name, "gzip") {
+			return true
+		}
+	}
+	return false
+}
+
+// isCompressible reports whether the content type is in the allowlist
+func isCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && slices.Contains(compressibleContentTypes, mediaType)
+}
+
+// bufferedWriter holds back the status and body until the handler finishes
+// or flushes
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+	// streaming is set by the first Flush, after which writes go straight
+	// to the client, through gz if the response is being compressed
+	streaming bool
+	gz        *gzip.Writer
+}
+
+// Flush sends the headers and everything buffered so far, switching the
+// writer to streaming
+func (w *bufferedWriter) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		header := w.ResponseWriter.Header()
+		header.Add("Vary", "Accept-Encoding")
+		if isCompressible(header.Get("Content-Type")) && header.Get("Content-Encoding") == "" {
+			header.Set("Content-Encoding", "gzip")
+			header.Del("Content-Length")
+			w.gz = gzip.NewWriter(w.ResponseWriter)
+		}
+		w.ResponseWriter.WriteHeader(w.status)
+		_, _ = w.output().Write(w.body.Bytes())
+		w.body.Reset()
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// output is where a streaming writer sends the body
+func (w *bufferedWriter) output() io.Writer {
+	if w.gz != nil {
+		return w.gz
+	}
+	return w.ResponseWriter
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	if w.streaming {
+		return w.output().Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.streaming || w.body.Len() > 0
+}
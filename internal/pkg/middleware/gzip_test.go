@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGzipTestRouter(minBytes int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Gzip(minBytes))
+	router.GET("/small.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	router.GET("/large.json", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"description": strings.Repeat("spacious flat ", 200)})
+	})
+	router.GET("/brochure.pdf", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/pdf", []byte(strings.Repeat("%PDF", 1000)))
+	})
+	return router
+}
+
+func TestGzip(t *testing.T) {
+	tests := []struct {
+		name             string
+		path             string
+		acceptEncoding   string
+		expectedStatus   int
+		expectCompressed bool
+	}{
+		{name: "large JSON", path: "/large.json", acceptEncoding: "gzip, deflate", expectedStatus: http.StatusCreated, expectCompressed: true},
+		{name: "small JSON", path: "/small.json", acceptEncoding: "gzip", expectedStatus: http.StatusOK},
+		{name: "PDF", path: "/brochure.pdf", acceptEncoding: "gzip", expectedStatus: http.StatusOK},
+		{name: "client without gzip", path: "/large.json", acceptEncoding: "", expectedStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupGzipTestRouter(1024)
+			uncompressed := httptest.NewRecorder()
+			router.ServeHTTP(uncompressed, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			body := resp.Body.Bytes()
+			if tt.expectCompressed {
+				assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+				assert.Less(t, len(body), uncompressed.Body.Len())
+				reader, err := gzip.NewReader(resp.Body)
+				require.NoError(t, err)
+				body, err = io.ReadAll(reader)
+				require.NoError(t, err)
+			} else {
+				assert.Empty(t, resp.Header().Get("Content-Encoding"))
+			}
+			assert.Equal(t, uncompressed.Body.Bytes(), body)
+		})
+	}
+}
+
+func TestGzip_Flush(t *testing.T) {
+	tests := []struct {
+		name             string
+		contentType      string
+		expectCompressed bool
+	}{
+		{name: "compressible stream", contentType: "text/csv", expectCompressed: true},
+		{name: "incompressible stream", contentType: "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(Gzip(1024))
+			var flushedBeforeEnd bool
+			resp := httptest.NewRecorder()
+			router.GET("/stream", func(c *gin.Context) {
+				c.Header("Content-Type", tt.contentType)
+				c.Status(http.StatusOK)
+				_, _ = c.Writer.WriteString("first,")
+				c.Writer.Flush()
+				flushedBeforeEnd = resp.Flushed && resp.Body.Len() > 0
+				_, _ = c.Writer.WriteString("second")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.True(t, flushedBeforeEnd)
+			body := resp.Body.Bytes()
+			if tt.expectCompressed {
+				assert.Equal(t, "gzip", resp.Result().Header.Get("Content-Encoding"))
+				reader, err := gzip.NewReader(resp.Body)
+				require.NoError(t, err)
+				body, err = io.ReadAll(reader)
+				require.NoError(t, err)
+			} else {
+				assert.Empty(t, resp.Result().Header.Get("Content-Encoding"))
+			}
+			assert.Equal(t, "first,second", string(body))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBodyBytes limits request bodies to max bytes, so a huge payload can't
+// exhaust memory while it is decoded. A request declaring a longer body is
+// rejected with a 413 straight away; otherwise reading past the limit fails
+// with an *http.MaxBytesError for the handler to turn into a 413. A max of 0
+// or less disables the limit.
+func MaxBodyBytes(max int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if max <= 0 {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > max {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+		c.Next()
+	}
+}
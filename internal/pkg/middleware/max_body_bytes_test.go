@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxBodyBytes(t *testing.T) {
+	tests := []struct {
+		name           string
+		max            int64
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{name: "within the limit", max: 10, body: "0123456789", expectedStatus: http.StatusOK},
+		{name: "declared length over the limit", max: 10, body: "0123456789a", expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "chunked body over the limit", max: 10, body: "0123456789a", chunked: true, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "disabled", max: 0, body: strings.Repeat("x", 100), expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(MaxBodyBytes(tt.max))
+			router.POST("/listings", func(c *gin.Context) {
+				if _, err := io.ReadAll(c.Request.Body); err != nil {
+					c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+					return
+				}
+				c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/listings", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxQueryParams rejects requests carrying more than max filter and sort
+// values with a 400, so a single request can't fan out into an arbitrarily
+// expensive query. Every value counts, whether a parameter is repeated
+// (?region=London&region=Wales) or comma-separated (?region=London,Wales).
+// A max of 0 or less disables the limit.
+func MaxQueryParams(max int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if max <= 0 {
+			c.Next()
+			return
+		}
+		count := 0
+		for _, values := range c.Request.URL.Query() {
+			for _, value := range values {
+				count += strings.Count(value, ",") + 1
+			}
+		}
+		if count > max {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Too many query parameters: %d given, at most %d allowed", count, max),
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxQueryParams(t *testing.T) {
+	tests := []struct {
+		name           string
+		max            int
+		query          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "no parameters", max: 3, query: "", expectedStatus: http.StatusOK},
+		{name: "at the limit", max: 3, query: "?region=London&sort=price&order=desc", expectedStatus: http.StatusOK},
+		{
+			name:           "over the limit",
+			max:            3,
+			query:          "?region=London&propertyType=apartment&sort=price&order=desc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Too many query parameters: 4 given, at most 3 allowed"}`,
+		},
+		{
+			name:           "repeated and comma-separated values count individually",
+			max:            3,
+			query:          "?region=London&region=Wales&propertyType=apartment,detached",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Too many query parameters: 4 given, at most 3 allowed"}`,
+		},
+		{name: "disabled", max: 0, query: "?a=1&b=2&c=3&d=4", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(MaxQueryParams(tt.max))
+			router.GET("/listings", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			})
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/listings"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			}
+		})
+	}
+}
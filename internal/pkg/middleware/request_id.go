@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/getground/interview-backend-golang/internal/pkg/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDKey is the gin context key holding the request ID
+const RequestIDKey = "requestID"
+
+// RequestID gives every request a correlation ID, reusing the client's
+// X-Request-ID header when it is valid and generating a UUID otherwise. The ID
+// is stored on the gin context and the request's context.Context, where
+// requestid.RequestIDFromContext finds it, and echoed in the response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.IsValid(id) {
+			id = requestid.New()
+		}
+		c.Set(RequestIDKey, id)
+		c.Request = c.Request.WithContext(requestid.WithRequestID(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+		c.Next()
+	}
+}
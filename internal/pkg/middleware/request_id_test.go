@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		expectSame bool
+	}{
+		{name: "supplied header is preserved", header: "req-123.abc_XYZ", expectSame: true},
+		{name: "missing header is generated", header: ""},
+		{name: "unsafe header is replaced", header: "bad id\nwith newline"},
+		{name: "overlong header is replaced", header: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(RequestID())
+			var fromContext, fromGin string
+			router.GET("/", func(c *gin.Context) {
+				fromContext = requestid.RequestIDFromContext(c.Request.Context())
+				fromGin = c.GetString(RequestIDKey)
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(requestid.Header, tt.header)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			echoed := resp.Header().Get(requestid.Header)
+			if tt.expectSame {
+				assert.Equal(t, tt.header, echoed)
+			} else {
+				assert.Regexp(t, uuidPattern, echoed)
+			}
+			assert.Equal(t, echoed, fromContext)
+			assert.Equal(t, echoed, fromGin)
+		})
+	}
+}
+
+func TestRequestID_UniquePerRequest(t *testing.T) {
+	assert.NotEqual(t, requestid.New(), requestid.New())
+	assert.Empty(t, requestid.RequestIDFromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()))
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+)
+
+// UserIDHeader identifies the user making a request until real
+// authentication exists
+const UserIDHeader = "X-User-ID"
+
+// UserIdentity puts the user named by the X-User-ID header on the request's
+// context.Context, where models.UserIDFromContext finds it. It guards routes
+// that act for a user, so a missing or malformed header is rejected with 401.
+func UserIdentity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.ParseInt(c.GetHeader(UserIDHeader), 10, 64)
+		if err != nil || userID <= 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "A valid " + UserIDHeader + " header is required"})
+			return
+		}
+		c.Request = c.Request.WithContext(models.ContextWithUserID(c.Request.Context(), userID))
+		c.Next()
+	}
+}
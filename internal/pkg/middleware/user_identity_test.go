@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserIdentity(t *testing.T) {
+	tests := []struct {
+		name           string
+		header         string
+		expectedStatus int
+		expectedUserID int64
+		expectedOK     bool
+	}{
+		{name: "header sets the identity", header: "7", expectedStatus: http.StatusNoContent, expectedUserID: 7, expectedOK: true},
+		{name: "missing header", header: "", expectedStatus: http.StatusUnauthorized},
+		{name: "malformed header", header: "abc", expectedStatus: http.StatusUnauthorized},
+		{name: "non-positive header", header: "0", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(UserIdentity())
+			var userID int64
+			var ok bool
+			router.GET("/", func(c *gin.Context) {
+				userID, ok = models.UserIDFromContext(c.Request.Context())
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(UserIDHeader, tt.header)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.JSONEq(t, `{"error":"A valid X-User-ID header is required"}`, resp.Body.String())
+			}
+			assert.Equal(t, tt.expectedUserID, userID)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
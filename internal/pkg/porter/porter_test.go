@@ -0,0 +1,147 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/getground/interview-backend-golang/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportedListings returns the listings part of an export, for comparing
+// datasets without the export time. Unless withTimestamps is set the listings'
+// creation and update times are left out too.
+func exportedListings(t *testing.T, data []byte, withTimestamps bool) string {
+	t.Helper()
+	var doc struct {
+		Listings []map[string]json.RawMessage `json:"listings"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	if !withTimestamps {
+		for _, listing := range doc.Listings {
+			delete(listing, "createdAt")
+			delete(listing, "updatedAt")
+		}
+	}
+	listings, err := json.Marshal(doc.Listings)
+	require.NoError(t, err)
+	return string(listings)
+}
+
+func TestPorter_RoundTrip(t *testing.T) {
+	for _, replace := range []bool{true, false} {
+		name := "merge"
+		if replace {
+			name = "replace"
+		}
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := models.NewListingRepository()
+			porter := New(repo)
+
+			// The sample data isn't normalized the way stored listings are, so
+			// store it once before comparing
+			seed, err := porter.ExportJSON(ctx)
+			require.NoError(t, err)
+			require.NoError(t, porter.ImportJSON(ctx, seed, true))
+
+			exported, err := porter.ExportJSON(ctx)
+			require.NoError(t, err)
+
+			require.NoError(t, repo.ReplaceAll(ctx, []*models.Listing{}, true))
+			empty, err := repo.GetAll(ctx)
+			require.NoError(t, err)
+			require.Empty(t, empty)
+
+			require.NoError(t, porter.ImportJSON(ctx, exported, replace))
+
+			reexported, err := porter.ExportJSON(ctx)
+			require.NoError(t, err)
+			// Only a replace restores the times; a merge writes the listings anew
+			assert.JSONEq(t, exportedListings(t, exported, replace), exportedListings(t, reexported, replace))
+
+			// New listings must not collide with the imported IDs
+			listings, err := repo.GetAll(ctx)
+			require.NoError(t, err)
+			maxID := listings[len(listings)-1].ID
+			created := &models.Listing{
+				AddressDetails: models.AddressDetails{City: "London", ShortenedPostcode: "N1", Region: models.RegionLondon},
+				PropertyType:   models.PropertyTypeApartment,
+				PriceInCents:   10000000,
+			}
+			require.NoError(t, repo.Create(ctx, created))
+			assert.Greater(t, created.ID, maxID)
+		})
+	}
+}
+
+func TestPorter_ImportJSON(t *testing.T) {
+	listing := func(id int64, price int64) *models.Listing {
+		return &models.Listing{
+			ID:             id,
+			AddressDetails: models.AddressDetails{City: "London", ShortenedPostcode: "N1", Region: models.RegionLondon},
+			PropertyType:   models.PropertyTypeApartment,
+			PriceInCents:   price,
+		}
+	}
+	encode := func(listings ...*models.Listing) []byte {
+		data, err := json.Marshal(document{Version: FormatVersion, Listings: listings})
+		require.NoError(t, err)
+		return data
+	}
+	newRepo := func() models.ListingRepository {
+		repo := models.NewListingRepository()
+		require.NoError(t, repo.ReplaceAll(context.Background(), []*models.Listing{listing(1, 10000000), listing(2, 20000000)}, true))
+		return repo
+	}
+	prices := func(repo models.ListingRepository) map[int64]int64 {
+		listings, err := repo.GetAll(context.Background())
+		require.NoError(t, err)
+		result := make(map[int64]int64, len(listings))
+		for _, l := range listings {
+			result[l.ID] = l.PriceInCents
+		}
+		return result
+	}
+
+	t.Run("merge updates by id and keeps the rest", func(t *testing.T) {
+		repo := newRepo()
+		require.NoError(t, New(repo).ImportJSON(context.Background(), encode(listing(2, 25000000), listing(7, 30000000)), false))
+		assert.Equal(t, map[int64]int64{1: 10000000, 2: 25000000, 7: 30000000}, prices(repo))
+	})
+
+	t.Run("replace drops listings missing from the import", func(t *testing.T) {
+		repo := newRepo()
+		require.NoError(t, New(repo).ImportJSON(context.Background(), encode(listing(7, 30000000)), true))
+		assert.Equal(t, map[int64]int64{7: 30000000}, prices(repo))
+	})
+
+	t.Run("an invalid listing stops the whole import", func(t *testing.T) {
+		for _, replace := range []bool{true, false} {
+			repo := newRepo()
+			err := New(repo).ImportJSON(context.Background(), encode(listing(2, 25000000), listing(8, 0)), replace)
+			assert.ErrorContains(t, err, "listing at index 1 is invalid: price must be greater than 0")
+			assert.Equal(t, map[int64]int64{1: 10000000, 2: 20000000}, prices(repo))
+		}
+	})
+
+	t.Run("listings need ids", func(t *testing.T) {
+		repo := newRepo()
+		err := New(repo).ImportJSON(context.Background(), encode(listing(0, 25000000)), false)
+		assert.ErrorContains(t, err, "listing at index 0 has invalid id: 0")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		err := New(newRepo()).ImportJSON(context.Background(), []byte(`{"version":2,"listings":[]}`), true)
+		assert.EqualError(t, err, "unsupported export version 2, expected 1")
+		assert.ErrorIs(t, err, models.ErrValidation)
+	})
+
+	t.Run("malformed document", func(t *testing.T) {
+		err := New(newRepo()).ImportJSON(context.Background(), []byte(`{"listings":`), true)
+		assert.ErrorContains(t, err, "failed to decode listings")
+		assert.ErrorIs(t, err, models.ErrValidation)
+	})
+}
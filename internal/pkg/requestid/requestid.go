@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// Header carries the request ID in both directions
+const Header = "X-Request-ID"
+
+// maxLength bounds a client-supplied ID so it can't bloat every log line
+const maxLength = 128
+
+type contextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored on ctx, or "" if there is
+// none, e.g. for work not started by a request
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// New returns a random version 4 UUID
+func New() string {
+	var b [16]byte
+	// crypto/rand.Read never returns an error on supported platforms
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// IsValid reports whether a client-supplied ID is safe to reuse: non-empty,
+// at most 128 characters, and only letters, digits, '-', '_' and '.'
+func IsValid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
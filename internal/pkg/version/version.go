@@ -0,0 +1,8 @@
+package version
+
+// Build information, overridden at build time via -ldflags "-X ..." (see README)
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Agent is an estate agent who can own listings
+type Agent struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// AgentRepository stores agents
+type AgentRepository interface {
+	Create(ctx context.Context, agent *Agent) error
+	GetByID(ctx context.Context, id int64) (*Agent, error)
+	GetAll(ctx context.Context) ([]*Agent, error)
+}
+
+type AgentRepositoryImpl struct {
+	data map[int64]*Agent
+	mu   sync.RWMutex
+	ids  IDGenerator
+}
+
+func NewAgentRepository() AgentRepository {
+	return &AgentRepositoryImpl{
+		data: make(map[int64]*Agent),
+		ids:  NewSequentialIDGenerator(),
+	}
+}
+
+// Create adds an agent, rejecting a missing name or email and an email that
+// another agent already uses
+func (r *AgentRepositoryImpl) Create(ctx context.Context, agent *Agent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if strings.TrimSpace(agent.Name) == "" {
+		return ValidationErrorf("name is required")
+	}
+	if strings.TrimSpace(agent.Email) == "" {
+		return ValidationErrorf("email is required")
+	}
+	for _, existing := range r.data {
+		if strings.EqualFold(existing.Email, agent.Email) {
+			return ConflictErrorf("email already exists")
+		}
+	}
+	id, err := r.ids.NextID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate agent id")
+	}
+	agent.ID = id
+	stored := *agent
+	r.data[id] = &stored
+	return nil
+}
+
+func (r *AgentRepositoryImpl) GetByID(ctx context.Context, id int64) (*Agent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	agent, exists := r.data[id]
+	if !exists {
+		return nil, errors.Wrapf(ErrNotFound, "agent not found with id: %d", id)
+	}
+	found := *agent
+	return &found, nil
+}
+
+func (r *AgentRepositoryImpl) GetAll(ctx context.Context) ([]*Agent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	agents := make([]*Agent, 0, len(r.data))
+	for _, agent := range r.data {
+		found := *agent
+		agents = append(agents, &found)
+	}
+	return agents, nil
+}
+
+// agentIDKey is the context key holding the ID of the authenticated agent
+type agentIDKey struct{}
+
+// ContextWithAgentID returns a copy of ctx carrying the authenticated agent's ID
+func ContextWithAgentID(ctx context.Context, agentID int64) context.Context {
+	return context.WithValue(ctx, agentIDKey{}, agentID)
+}
+
+// AgentIDFromContext returns the authenticated agent's ID, and false if the
+// request is not made on behalf of an agent
+func AgentIDFromContext(ctx context.Context) (int64, bool) {
+	agentID, ok := ctx.Value(agentIDKey{}).(int64)
+	return agentID, ok && agentID > 0
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentRepository_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      []*Agent
+		agent         *Agent
+		expectedError string
+	}{
+		{
+			name:  "valid agent",
+			agent: &Agent{Name: "Jane Smith", Email: "jane@example.com"},
+		},
+		{
+			name:          "missing name",
+			agent:         &Agent{Email: "jane@example.com"},
+			expectedError: "name is required",
+		},
+		{
+			name:          "missing email",
+			agent:         &Agent{Name: "Jane Smith"},
+			expectedError: "email is required",
+		},
+		{
+			name:          "duplicate email",
+			existing:      []*Agent{{Name: "Jane Smith", Email: "jane@example.com"}},
+			agent:         &Agent{Name: "Janet Smith", Email: "JANE@example.com"},
+			expectedError: "email already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewAgentRepository()
+			for _, agent := range tt.existing {
+				require.NoError(t, repo.Create(context.Background(), agent))
+			}
+
+			err := repo.Create(context.Background(), tt.agent)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			stored, err := repo.GetByID(context.Background(), tt.agent.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.agent, stored)
+		})
+	}
+}
+
+func TestAgentRepository_GetByID_NotFound(t *testing.T) {
+	repo := NewAgentRepository()
+
+	_, err := repo.GetByID(context.Background(), 42)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestAgentIDFromContext(t *testing.T) {
+	_, ok := AgentIDFromContext(context.Background())
+	assert.False(t, ok)
+
+	agentID, ok := AgentIDFromContext(ContextWithAgentID(context.Background(), 7))
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), agentID)
+}
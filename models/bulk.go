@@ -0,0 +1,39 @@
+package models
+
+// BulkItem is the outcome of one item in a bulk operation, carrying the HTTP
+// status the item would have had as a request of its own
+type BulkItem interface {
+	ItemStatus() int
+}
+
+// BulkResponse is the multi-status body returned by bulk endpoints. The batch
+// is answered with a 200 once it has been processed, even if some items
+// failed; each result carries its own status.
+type BulkResponse[T BulkItem] struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Results   []T `json:"results"`
+}
+
+// NewBulkResponse wraps results, counting an item with a status below 400 as
+// succeeded
+func NewBulkResponse[T BulkItem](results []T) BulkResponse[T] {
+	response := BulkResponse[T]{Results: results}
+	for _, result := range results {
+		if result.ItemStatus() < 400 {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	return response
+}
+
+// DeleteResult reports the outcome of deleting a single listing in a batch
+type DeleteResult struct {
+	ID     int64  `json:"id"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (r DeleteResult) ItemStatus() int { return r.Status }
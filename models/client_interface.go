@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"encoding/json"
 )
 
 type ClientInterface interface {
@@ -12,13 +13,33 @@ type ExampleModel struct {
 	ID        int64  `json:"id"`
 	Name      string `json:"name"`
 	Email     string `json:"email"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// MarshalJSON encodes the example with camelCase keys. The snake_case
+// created_at and updated_at keys examples used to return are still written
+// alongside them for consumers that haven't moved over yet; they are
+// deprecated and will be dropped.
+func (e ExampleModel) MarshalJSON() ([]byte, error) {
+	type exampleAlias ExampleModel
+	return json.Marshal(struct {
+		exampleAlias
+		DeprecatedCreatedAt string `json:"created_at"`
+		DeprecatedUpdatedAt string `json:"updated_at"`
+	}{
+		exampleAlias:        exampleAlias(e),
+		DeprecatedCreatedAt: e.CreatedAt,
+		DeprecatedUpdatedAt: e.UpdatedAt,
+	})
 }
 
 type ExampleRepository interface {
 	Create(ctx context.Context, example *ExampleModel) error
 	GetByID(ctx context.Context, id int64) (*ExampleModel, error)
+	// GetByEmail matches the email ignoring case, as stored emails are unique
+	// regardless of case
+	GetByEmail(ctx context.Context, email string) (*ExampleModel, error)
 	GetAll(ctx context.Context) ([]*ExampleModel, error)
 	Update(ctx context.Context, example *ExampleModel) error
 	Delete(ctx context.Context, id int64) error
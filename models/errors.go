@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned by repositories when a record does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrIDsExhausted is returned by an IDGenerator once the largest possible ID
+// has been taken
+var ErrIDsExhausted = errors.New("no ids left")
+
+// ErrValidation is matched by errors.Is for a record rejected as invalid,
+// including a *ValidationError
+var ErrValidation = errors.New("validation failed")
+
+// ErrConflict is matched by errors.Is for a record that clashes with one
+// already stored, such as an example reusing another's email
+var ErrConflict = errors.New("conflict")
+
+// kindError gives one of the sentinel errors a specific message. Unlike
+// errors.Wrap it doesn't append the sentinel's own text, so the message can be
+// shown to clients as it is.
+type kindError struct {
+	kind    error
+	message string
+}
+
+func (e *kindError) Error() string { return e.message }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// ValidationErrorf returns an error with the formatted message that matches
+// ErrValidation
+func ValidationErrorf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrValidation, message: fmt.Sprintf(format, args...)}
+}
+
+// ConflictErrorf returns an error with the formatted message that matches
+// ErrConflict
+func ConflictErrorf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrConflict, message: fmt.Sprintf(format, args...)}
+}
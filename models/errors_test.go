@@ -0,0 +1,34 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainErrors(t *testing.T) {
+	ctx := context.Background()
+	repo := NewListingRepository()
+
+	err := repo.Create(ctx, &Listing{})
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.NotErrorIs(t, err, ErrConflict)
+
+	_, err = repo.Upsert(ctx, &Listing{ID: -1})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	_, err = repo.GetAllSorted(ctx, SortField("colour"), false)
+	assert.ErrorIs(t, err, ErrValidation)
+
+	_, err = repo.GetByID(ctx, 999)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var validationErr error = &ValidationError{Issues: []string{"price is required"}}
+	assert.ErrorIs(t, errors.Wrap(validationErr, "failed to create listing"), ErrValidation)
+
+	conflict := errors.Wrap(ConflictErrorf("email already exists"), "failed to create example")
+	assert.ErrorIs(t, conflict, ErrConflict)
+	assert.EqualError(t, errors.Cause(conflict), "email already exists")
+}
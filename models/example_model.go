@@ -2,22 +2,31 @@ package models
 
 import (
 	"context"
+	"net/mail"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 )
 
+// ExampleRepositoryImpl stores examples in memory. It is safe for concurrent
+// use: mu guards data, and examples are copied on the way in and out so
+// callers never share one with the repository.
 type ExampleRepositoryImpl struct {
-	data   map[int64]*ExampleModel
-	mu     sync.RWMutex
-	nextID int64
+	data map[int64]*ExampleModel
+	mu   sync.RWMutex
+	ids  IDGenerator
 }
 
 func NewExampleRepository() ExampleRepository {
+	return NewExampleRepositoryWithIDGenerator(NewSequentialIDGenerator())
+}
+
+func NewExampleRepositoryWithIDGenerator(ids IDGenerator) ExampleRepository {
 	return &ExampleRepositoryImpl{
-		data:   make(map[int64]*ExampleModel),
-		nextID: 1,
+		data: make(map[int64]*ExampleModel),
+		ids:  ids,
 	}
 }
 
@@ -26,22 +35,26 @@ func (r *ExampleRepositoryImpl) Create(ctx context.Context, example *ExampleMode
 	defer r.mu.Unlock()
 
 	if example.Name == "" {
-		return errors.New("name is required")
+		return ValidationErrorf("name is required")
 	}
-	if example.Email == "" {
-		return errors.New("email is required")
+	if err := validateEmail(example.Email); err != nil {
+		return err
 	}
 	for _, existing := range r.data {
-		if existing.Email == example.Email {
-			return errors.New("email already exists")
+		if strings.EqualFold(existing.Email, example.Email) {
+			return ConflictErrorf("email already exists")
 		}
 	}
-	example.ID = r.nextID
+	id, err := r.ids.NextID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate example id")
+	}
+	example.ID = id
 	now := time.Now().Format(time.RFC3339)
 	example.CreatedAt = now
 	example.UpdatedAt = now
-	r.data[example.ID] = example
-	r.nextID++
+	stored := *example
+	r.data[example.ID] = &stored
 	return nil
 }
 
@@ -50,7 +63,7 @@ func (r *ExampleRepositoryImpl) GetByID(ctx context.Context, id int64) (*Example
 	defer r.mu.RUnlock()
 	example, exists := r.data[id]
 	if !exists {
-		return nil, errors.Wrapf(errors.New("not found"), "example not found with id: %d", id)
+		return nil, errors.Wrapf(ErrNotFound, "example not found with id: %d", id)
 	}
 	return &ExampleModel{
 		ID:        example.ID,
@@ -61,6 +74,19 @@ func (r *ExampleRepositoryImpl) GetByID(ctx context.Context, id int64) (*Example
 	}, nil
 }
 
+// GetByEmail returns the example whose email matches, ignoring case
+func (r *ExampleRepositoryImpl) GetByEmail(ctx context.Context, email string) (*ExampleModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, example := range r.data {
+		if strings.EqualFold(example.Email, email) {
+			found := *example
+			return &found, nil
+		}
+	}
+	return nil, errors.Wrapf(ErrNotFound, "example not found with email: %s", email)
+}
+
 func (r *ExampleRepositoryImpl) GetAll(ctx context.Context) ([]*ExampleModel, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -81,23 +107,24 @@ func (r *ExampleRepositoryImpl) Update(ctx context.Context, example *ExampleMode
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if example.Name == "" {
-		return errors.New("name is required")
+		return ValidationErrorf("name is required")
 	}
-	if example.Email == "" {
-		return errors.New("email is required")
+	if err := validateEmail(example.Email); err != nil {
+		return err
 	}
 	existing, exists := r.data[example.ID]
 	if !exists {
-		return errors.Wrapf(errors.New("not found"), "example not found with id: %d", example.ID)
+		return errors.Wrapf(ErrNotFound, "example not found with id: %d", example.ID)
 	}
 	for id, other := range r.data {
-		if id != example.ID && other.Email == example.Email {
-			return errors.New("email already exists")
+		if id != example.ID && strings.EqualFold(other.Email, example.Email) {
+			return ConflictErrorf("email already exists")
 		}
 	}
 	example.CreatedAt = existing.CreatedAt
 	example.UpdatedAt = time.Now().Format(time.RFC3339)
-	r.data[example.ID] = example
+	stored := *example
+	r.data[example.ID] = &stored
 	return nil
 }
 
@@ -105,8 +132,22 @@ func (r *ExampleRepositoryImpl) Delete(ctx context.Context, id int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if _, exists := r.data[id]; !exists {
-		return errors.Wrapf(errors.New("not found"), "example not found with id: %d", id)
+		return errors.Wrapf(ErrNotFound, "example not found with id: %d", id)
 	}
 	delete(r.data, id)
 	return nil
 }
+
+// validateEmail checks that email is a bare address such as "jo@example.com".
+// Display-name forms like "Jo <jo@example.com>" are rejected too, as only the
+// address is stored.
+func validateEmail(email string) error {
+	if email == "" {
+		return ValidationErrorf("email is required")
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return ValidationErrorf("invalid email format")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExampleRepository_EmailValidation(t *testing.T) {
+	tests := []struct {
+		name          string
+		email         string
+		expectedError string
+	}{
+		{name: "valid address", email: "john@example.com"},
+		{name: "missing at sign", email: "john.example.com", expectedError: "invalid email format"},
+		{name: "contains spaces", email: "john doe@example.com", expectedError: "invalid email format"},
+		{name: "display name", email: "John <john@example.com>", expectedError: "invalid email format"},
+		{name: "empty", email: "", expectedError: "email is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := NewExampleRepository()
+
+			err := repo.Create(ctx, &ExampleModel{Name: "John", Email: tt.email})
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				assert.ErrorIs(t, err, ErrValidation)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			existing := &ExampleModel{Name: "Jane", Email: "jane@example.com"}
+			require.NoError(t, repo.Create(ctx, existing))
+			err = repo.Update(ctx, &ExampleModel{ID: existing.ID, Name: "Jane", Email: tt.email})
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				// The valid address was taken by the first Create
+				assert.EqualError(t, err, "email already exists")
+				assert.ErrorIs(t, err, ErrConflict)
+			}
+		})
+	}
+}
+
+func TestExampleRepository_GetByEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := NewExampleRepository()
+	john := &ExampleModel{Name: "John", Email: "John.Doe@example.com"}
+	require.NoError(t, repo.Create(ctx, john))
+	require.NoError(t, repo.Create(ctx, &ExampleModel{Name: "Jane", Email: "jane@example.com"}))
+
+	found, err := repo.GetByEmail(ctx, "John.Doe@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, john.ID, found.ID)
+
+	found, err = repo.GetByEmail(ctx, "JOHN.DOE@EXAMPLE.COM")
+	require.NoError(t, err)
+	assert.Equal(t, john.ID, found.ID)
+	assert.Equal(t, "John.Doe@example.com", found.Email)
+
+	_, err = repo.GetByEmail(ctx, "nobody@example.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Uniqueness ignores case too, so the lookup can only ever match one
+	err = repo.Create(ctx, &ExampleModel{Name: "Johnny", Email: "john.doe@EXAMPLE.com"})
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+// TestExampleRepository_ConcurrentAccess is mainly useful under go test -race
+func TestExampleRepository_ConcurrentAccess(t *testing.T) {
+	const workers = 16
+	const iterations = 50
+	repo := NewExampleRepository()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				example := &ExampleModel{Name: "Jo", Email: fmt.Sprintf("jo.%d.%d@example.com", w, i)}
+				if !assert.NoError(t, repo.Create(ctx, example)) {
+					return
+				}
+				// Keep using the example after handing it over, as callers do
+				example.Name = "Joanna"
+				if !assert.NoError(t, repo.Update(ctx, example)) {
+					return
+				}
+				example.Name = "changed after update"
+
+				if _, err := repo.GetByID(ctx, example.ID); !assert.NoError(t, err) {
+					return
+				}
+				if _, err := repo.GetAll(ctx); !assert.NoError(t, err) {
+					return
+				}
+				if i%2 == 0 {
+					assert.NoError(t, repo.Delete(ctx, example.ID))
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	all, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, workers*iterations/2)
+	for _, example := range all {
+		assert.Equal(t, "Joanna", example.Name)
+	}
+}
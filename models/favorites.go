@@ -0,0 +1,72 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FavoritesStore keeps the listings each user has saved
+type FavoritesStore interface {
+	// Add saves a listing for the user, returning false if it was already saved
+	Add(ctx context.Context, userID, listingID int64) (bool, error)
+	// Remove unsaves a listing, returning ErrNotFound if it was not saved
+	Remove(ctx context.Context, userID, listingID int64) error
+	// List returns the IDs of the user's saved listings in the order they were added
+	List(ctx context.Context, userID int64) ([]int64, error)
+}
+
+type FavoritesStoreImpl struct {
+	data map[int64][]int64
+	mu   sync.RWMutex
+}
+
+func NewFavoritesStore() FavoritesStore {
+	return &FavoritesStoreImpl{
+		data: make(map[int64][]int64),
+	}
+}
+
+func (s *FavoritesStoreImpl) Add(ctx context.Context, userID, listingID int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.Contains(s.data[userID], listingID) {
+		return false, nil
+	}
+	s.data[userID] = append(s.data[userID], listingID)
+	return true, nil
+}
+
+func (s *FavoritesStoreImpl) Remove(ctx context.Context, userID, listingID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.Index(s.data[userID], listingID)
+	if i < 0 {
+		return errors.Wrapf(ErrNotFound, "listing %d is not a favorite of user %d", listingID, userID)
+	}
+	s.data[userID] = slices.Delete(s.data[userID], i, i+1)
+	return nil
+}
+
+func (s *FavoritesStoreImpl) List(ctx context.Context, userID int64) ([]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.data[userID]), nil
+}
+
+// userIDKey is the context key holding the ID of the user making a request
+type userIDKey struct{}
+
+// ContextWithUserID returns a copy of ctx carrying the requesting user's ID
+func ContextWithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the requesting user's ID, and false if the request
+// does not identify a user
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(int64)
+	return userID, ok && userID > 0
+}
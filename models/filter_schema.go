@@ -0,0 +1,71 @@
+package models
+
+// FilterFieldType says how a filterable field is set
+type FilterFieldType string
+
+const (
+	// FilterFieldEnum takes one of a fixed set of values
+	FilterFieldEnum FilterFieldType = "enum"
+	// FilterFieldRange takes an inclusive minimum, maximum or both
+	FilterFieldRange FilterFieldType = "range"
+	// FilterFieldBoolean takes true or false
+	FilterFieldBoolean FilterFieldType = "boolean"
+	// FilterFieldText takes free text
+	FilterFieldText FilterFieldType = "text"
+)
+
+// FilterField describes one criterion of ListingFilter and the query
+// parameters that set it. Values lists the choices for an enum; Min and Max
+// give the range currently covered by the listings, and are left out when
+// there are none.
+type FilterField struct {
+	Field  string          `json:"field"`
+	Type   FilterFieldType `json:"type"`
+	Params []string        `json:"params"`
+	Values []string        `json:"values,omitempty"`
+	Min    *int64          `json:"min,omitempty"`
+	Max    *int64          `json:"max,omitempty"`
+}
+
+// BuildFilterSchema describes every ListingFilter criterion, taking the
+// bounds of the range criteria from listings
+func BuildFilterSchema(listings []*Listing) []FilterField {
+	regions := make([]string, 0, len(Regions))
+	for _, region := range Regions {
+		regions = append(regions, string(region))
+	}
+	propertyTypes := make([]string, 0, len(PropertyTypes))
+	for _, propertyType := range PropertyTypes {
+		propertyTypes = append(propertyTypes, string(propertyType))
+	}
+
+	schema := []FilterField{
+		{Field: "region", Type: FilterFieldEnum, Params: []string{"region"}, Values: regions},
+		{Field: "propertyType", Type: FilterFieldEnum, Params: []string{"propertyType"}, Values: propertyTypes},
+		{Field: "city", Type: FilterFieldText, Params: []string{"city"}},
+		rangeField("price", "minPrice", "maxPrice", listings, func(l *Listing) int64 { return l.PriceInCents }),
+		rangeField("bedrooms", "minBedrooms", "maxBedrooms", listings, func(l *Listing) int64 { return int64(l.Bedrooms) }),
+		rangeField("bathrooms", "minBathrooms", "maxBathrooms", listings, func(l *Listing) int64 { return int64(l.Bathrooms) }),
+		rangeField("size", "minSize", "maxSize", listings, func(l *Listing) int64 { return int64(l.SizeSqFt) }),
+	}
+	for _, flag := range []string{"isTenanted", "isCashOnly", "isNewBuild", "isShareSale", "isCompany"} {
+		schema = append(schema, FilterField{Field: flag, Type: FilterFieldBoolean, Params: []string{flag}})
+	}
+	return schema
+}
+
+// rangeField describes a range criterion set by minParam and maxParam, bounded
+// by the lowest and highest value among the listings
+func rangeField(field, minParam, maxParam string, listings []*Listing, value func(*Listing) int64) FilterField {
+	result := FilterField{Field: field, Type: FilterFieldRange, Params: []string{minParam, maxParam}}
+	if len(listings) == 0 {
+		return result
+	}
+	lowest, highest := value(listings[0]), value(listings[0])
+	for _, listing := range listings[1:] {
+		lowest = min(lowest, value(listing))
+		highest = max(highest, value(listing))
+	}
+	result.Min, result.Max = &lowest, &highest
+	return result
+}
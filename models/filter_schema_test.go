@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildFilterSchema(t *testing.T) {
+	listings := []*Listing{
+		{PriceInCents: 30000000, Bedrooms: 3, Bathrooms: 2, SizeSqFt: 32},
+		{PriceInCents: 15000000, Bedrooms: 1, Bathrooms: 1, SizeSqFt: 2342},
+		{PriceInCents: 45000000, Bedrooms: 2, Bathrooms: 3, SizeSqFt: 600},
+	}
+
+	schema := BuildFilterSchema(listings)
+
+	types := make(map[string]FilterFieldType, len(schema))
+	fields := make(map[string]FilterField, len(schema))
+	for _, field := range schema {
+		types[field.Field] = field.Type
+		fields[field.Field] = field
+	}
+	assert.Equal(t, map[string]FilterFieldType{
+		"region":       FilterFieldEnum,
+		"propertyType": FilterFieldEnum,
+		"city":         FilterFieldText,
+		"price":        FilterFieldRange,
+		"bedrooms":     FilterFieldRange,
+		"bathrooms":    FilterFieldRange,
+		"size":         FilterFieldRange,
+		"isTenanted":   FilterFieldBoolean,
+		"isCashOnly":   FilterFieldBoolean,
+		"isNewBuild":   FilterFieldBoolean,
+		"isShareSale":  FilterFieldBoolean,
+		"isCompany":    FilterFieldBoolean,
+	}, types)
+
+	assert.Len(t, fields["region"].Values, len(Regions))
+	assert.Contains(t, fields["propertyType"].Values, string(PropertyTypeApartment))
+
+	price := fields["price"]
+	assert.Equal(t, []string{"minPrice", "maxPrice"}, price.Params)
+	require.NotNil(t, price.Min)
+	require.NotNil(t, price.Max)
+	assert.Equal(t, int64(15000000), *price.Min)
+	assert.Equal(t, int64(45000000), *price.Max)
+	assert.Equal(t, int64(1), *fields["bedrooms"].Min)
+	assert.Equal(t, int64(3), *fields["bathrooms"].Max)
+	assert.Equal(t, []string{"minSize", "maxSize"}, fields["size"].Params)
+	assert.Equal(t, int64(32), *fields["size"].Min)
+	assert.Equal(t, int64(2342), *fields["size"].Max)
+}
+
+func TestBuildFilterSchema_NoListings(t *testing.T) {
+	for _, field := range BuildFilterSchema(nil) {
+		assert.Nil(t, field.Min, field.Field)
+		assert.Nil(t, field.Max, field.Field)
+	}
+}
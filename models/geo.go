@@ -0,0 +1,78 @@
+package models
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// Coordinates is a point on the map in decimal degrees
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// BoundingBox is a map viewport in decimal degrees. Boxes crossing the
+// antimeridian are not supported, which is fine for UK listings.
+type BoundingBox struct {
+	North float64 `json:"north"`
+	South float64 `json:"south"`
+	East  float64 `json:"east"`
+	West  float64 `json:"west"`
+}
+
+// Validate checks the box has its edges within range and the right way round
+func (b BoundingBox) Validate() error {
+	if b.North > 90 || b.South < -90 {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if b.East > 180 || b.West < -180 {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	if b.North <= b.South {
+		return errors.New("north must be greater than south")
+	}
+	if b.East <= b.West {
+		return errors.New("east must be greater than west")
+	}
+	return nil
+}
+
+// Contains reports whether c lies within the box, including its edges
+func (b BoundingBox) Contains(c Coordinates) bool {
+	return c.Latitude >= b.South && c.Latitude <= b.North &&
+		c.Longitude >= b.West && c.Longitude <= b.East
+}
+
+// earthRadiusMiles is the mean radius of the Earth
+const earthRadiusMiles = 3958.8
+
+// DistanceMiles returns the great-circle distance between a and b in miles,
+// using the haversine formula
+func DistanceMiles(a, b Coordinates) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	latA, latB := toRadians(a.Latitude), toRadians(b.Latitude)
+	dLat := latB - latA
+	dLon := toRadians(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(latA)*math.Cos(latB)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Sqrt(h))
+}
+
+// NearbyListing is a listing with its distance from a search point, nil when
+// the distance is unknown
+type NearbyListing struct {
+	Listing       *Listing `json:"listing"`
+	DistanceMiles *float64 `json:"distanceMiles"`
+}
+
+// NearCityResult holds the listings within a radius of a city centre, nearest
+// first. When the city can't be geocoded RegionFallback is set and Listings
+// holds every listing in the city's region instead, by ID and without distances.
+type NearCityResult struct {
+	City           string          `json:"city"`
+	RadiusMiles    float64         `json:"radiusMiles"`
+	Centre         *Coordinates    `json:"centre,omitempty"`
+	RegionFallback bool            `json:"regionFallback"`
+	Region         Region          `json:"region,omitempty"`
+	Listings       []NearbyListing `json:"listings"`
+}
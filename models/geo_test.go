@@ -0,0 +1,18 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistanceMiles(t *testing.T) {
+	london := Coordinates{Latitude: 51.5074, Longitude: -0.1278}
+	manchester := Coordinates{Latitude: 53.4808, Longitude: -2.2426}
+
+	assert.InDelta(t, 163, DistanceMiles(london, manchester), 1)
+	assert.InDelta(t, DistanceMiles(london, manchester), DistanceMiles(manchester, london), 1e-9)
+	assert.Zero(t, DistanceMiles(london, london))
+	// A degree of latitude is about 69 miles anywhere
+	assert.InDelta(t, 69.1, DistanceMiles(Coordinates{Latitude: 0}, Coordinates{Latitude: 1}), 0.1)
+}
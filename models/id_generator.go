@@ -0,0 +1,57 @@
+package models
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// IDGenerator assigns IDs to new records. Implementations must be safe for
+// concurrent use.
+type IDGenerator interface {
+	// NextID returns an ID that has not been handed out or reserved before
+	NextID(ctx context.Context) (int64, error)
+	// Reserve marks id as taken, e.g. by seeded data, so NextID never returns it
+	Reserve(id int64)
+}
+
+// SequentialIDGenerator hands out increasing IDs starting from 1. It is the
+// default for the in-memory repositories. Once math.MaxInt64 is handed out or
+// reserved it saturates, and NextID returns ErrIDsExhausted rather than wrap.
+type SequentialIDGenerator struct {
+	mu        sync.Mutex
+	next      int64
+	exhausted bool
+}
+
+// NewSequentialIDGenerator creates a generator whose first ID is 1
+func NewSequentialIDGenerator() *SequentialIDGenerator {
+	return &SequentialIDGenerator{next: 1}
+}
+
+func (g *SequentialIDGenerator) NextID(ctx context.Context) (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.exhausted {
+		return 0, ErrIDsExhausted
+	}
+	id := g.next
+	if id == math.MaxInt64 {
+		g.exhausted = true
+	} else {
+		g.next++
+	}
+	return id, nil
+}
+
+func (g *SequentialIDGenerator) Reserve(id int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case g.exhausted || id < g.next:
+	case id == math.MaxInt64:
+		g.exhausted = true
+	default:
+		g.next = id + 1
+	}
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIDGenerator hands out a fixed list of IDs and then fails
+type fakeIDGenerator struct {
+	ids      []int64
+	reserved []int64
+}
+
+func (g *fakeIDGenerator) NextID(ctx context.Context) (int64, error) {
+	if len(g.ids) == 0 {
+		return 0, errors.New("no ids left")
+	}
+	id := g.ids[0]
+	g.ids = g.ids[1:]
+	return id, nil
+}
+
+func (g *fakeIDGenerator) Reserve(id int64) {
+	g.reserved = append(g.reserved, id)
+}
+
+func TestSequentialIDGenerator(t *testing.T) {
+	ids := NewSequentialIDGenerator()
+
+	first, err := ids.NextID(context.Background())
+	require.NoError(t, err)
+	ids.Reserve(10)
+	ids.Reserve(5)
+	next, err := ids.NextID(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), first)
+	assert.Equal(t, int64(11), next)
+}
+
+func TestSequentialIDGenerator_Exhausted(t *testing.T) {
+	ids := NewSequentialIDGenerator()
+	ids.Reserve(math.MaxInt64 - 1)
+
+	last, err := ids.NextID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(math.MaxInt64), last)
+	_, err = ids.NextID(context.Background())
+	assert.ErrorIs(t, err, ErrIDsExhausted)
+
+	reserved := NewSequentialIDGenerator()
+	reserved.Reserve(math.MaxInt64)
+	reserved.Reserve(5)
+	_, err = reserved.NextID(context.Background())
+	assert.ErrorIs(t, err, ErrIDsExhausted)
+}
+
+func TestListingRepository_Upsert_MaxID(t *testing.T) {
+	repo := NewListingRepository()
+	listing := &Listing{
+		ID: math.MaxInt64,
+		AddressDetails: AddressDetails{
+			City:              "London",
+			ShortenedPostcode: "W1",
+			Region:            RegionLondon,
+		},
+		PropertyType: PropertyTypeApartment,
+		PriceInCents: 10000000,
+	}
+	created, err := repo.Upsert(context.Background(), listing)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	next := *listing
+	next.ID = 0
+	err = repo.Create(context.Background(), &next)
+	assert.ErrorIs(t, err, ErrIDsExhausted)
+	// Nothing was stored under a wrapped ID
+	_, err = repo.GetByID(context.Background(), math.MinInt64)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestListingRepository_IDGenerator(t *testing.T) {
+	ids := &fakeIDGenerator{ids: []int64{42, 7}}
+	repo := &ListingRepositoryImpl{
+		data: make(map[int64]*Listing),
+		ids:  ids,
+	}
+	newListing := func() *Listing {
+		return &Listing{
+			AddressDetails: AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "W1",
+				Region:            RegionLondon,
+			},
+			PropertyType: PropertyTypeApartment,
+			PriceInCents: 10000000,
+		}
+	}
+
+	first, second, third := newListing(), newListing(), newListing()
+	require.NoError(t, repo.Create(context.Background(), first))
+	require.NoError(t, repo.Create(context.Background(), second))
+	err := repo.Create(context.Background(), third)
+
+	assert.Equal(t, int64(42), first.ID)
+	assert.Equal(t, int64(7), second.ID)
+	assert.Error(t, err)
+	all, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestExampleRepository_IDGenerator(t *testing.T) {
+	repo := NewExampleRepositoryWithIDGenerator(&fakeIDGenerator{ids: []int64{100}})
+
+	example := &ExampleModel{Name: "John Doe", Email: "john@example.com"}
+	err := repo.Create(context.Background(), example)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), example.ID)
+}
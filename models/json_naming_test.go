@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// camelCaseKey matches the JSON keys every model uses, e.g. priceInCents or originalURL
+var camelCaseKey = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+
+// deprecatedKeys are the snake_case keys still written for older consumers
+var deprecatedKeys = []string{"created_at", "updated_at"}
+
+func TestJSONFieldNaming_Golden(t *testing.T) {
+	madeVisibleAt := "2024-01-02T03:04:05Z"
+	deletedAt := "2024-03-01T12:00:00Z"
+	tests := []struct {
+		name   string
+		golden string
+		value  interface{}
+	}{
+		{
+			name:   "example",
+			golden: "example.golden.json",
+			value: &ExampleModel{
+				ID:        1,
+				Name:      "John",
+				Email:     "john@example.com",
+				CreatedAt: "2023-10-27T10:00:00Z",
+				UpdatedAt: "2023-10-27T11:00:00Z",
+			},
+		},
+		{
+			name:   "listing",
+			golden: "listing.golden.json",
+			value: &Listing{
+				ID:     1,
+				Status: ListingStatusPublished,
+				AddressDetails: AddressDetails{
+					AddressLine1:      "1 High Street",
+					AddressLine2:      "Flat 2",
+					City:              "London",
+					Postcode:          "N1 7AA",
+					ShortenedPostcode: "N1",
+					Country:           "UK",
+					Region:            RegionLondon,
+					Coordinates:       &Coordinates{Latitude: 51.5, Longitude: -0.1},
+				},
+				Bedrooms:                   2,
+				Bathrooms:                  1,
+				Description:                "Bright flat",
+				GrossYield:                 0.06,
+				IsTenanted:                 true,
+				MadeVisibleAt:              &madeVisibleAt,
+				EstimatedDepositInCents:    5000000,
+				MinimumDepositInCents:      2000000,
+				Photos:                     []Photo{{ID: 1, OriginalURL: "https://example.com/a.jpg", MimeType: "image/jpeg"}},
+				PriceInCents:               20000000,
+				PropertyType:               PropertyTypeApartment,
+				MonthlyRentalIncomeInCents: 100000,
+				SizeSqFt:                   600,
+				BuildYear:                  2010,
+				AgentID:                    3,
+				Tags:                       []string{"garden"},
+				CreatedAt:                  madeVisibleAt,
+				UpdatedAt:                  "2024-02-01T09:30:00Z",
+				DeletedAt:                  &deletedAt,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := json.MarshalIndent(tt.value, "", "  ")
+			require.NoError(t, err)
+
+			path := filepath.Join("testdata", tt.golden)
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(path, append(encoded, '\n'), 0o644))
+			}
+			golden, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(golden), string(encoded))
+
+			var decoded interface{}
+			require.NoError(t, json.Unmarshal(encoded, &decoded))
+			for _, key := range jsonKeys(decoded) {
+				if !slices.Contains(deprecatedKeys, key) {
+					assert.Regexp(t, camelCaseKey, key)
+				}
+			}
+		})
+	}
+}
+
+// jsonKeys returns every object key in a decoded JSON value, at any depth
+func jsonKeys(value interface{}) []string {
+	var keys []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			keys = append(keys, key)
+			keys = append(keys, jsonKeys(child)...)
+		}
+	case []interface{}:
+		for _, child := range v {
+			keys = append(keys, jsonKeys(child)...)
+		}
+	}
+	return keys
+}
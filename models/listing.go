@@ -2,6 +2,8 @@ package models
 
 import (
 	"context"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -23,6 +25,28 @@ const (
 	RegionWales     Region = "Wales"
 )
 
+// Regions lists every valid region
+var Regions = []Region{
+	RegionNorthWest,
+	RegionLondon,
+	RegionNorthEast,
+	RegionSouthWest,
+	RegionSouthEast,
+	RegionMidlands,
+	RegionScotland,
+	RegionWales,
+}
+
+// IsValid reports whether r is one of the known regions
+func (r Region) IsValid() bool {
+	for _, region := range Regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
 // PropertyType represents valid property types
 type PropertyType string
 
@@ -34,6 +58,42 @@ const (
 	PropertyTypeEndTerrace   PropertyType = "end-terrace"
 )
 
+// PropertyTypes lists every valid property type
+var PropertyTypes = []PropertyType{
+	PropertyTypeApartment,
+	PropertyTypeDetached,
+	PropertyTypeSemiDetached,
+	PropertyTypeTerraced,
+	PropertyTypeEndTerrace,
+}
+
+// IsValid reports whether p is one of the known property types
+func (p PropertyType) IsValid() bool {
+	for _, propertyType := range PropertyTypes {
+		if p == propertyType {
+			return true
+		}
+	}
+	return false
+}
+
+// ListingStatus represents where a listing is in its lifecycle
+type ListingStatus string
+
+const (
+	ListingStatusDraft     ListingStatus = "draft"
+	ListingStatusPublished ListingStatus = "published"
+)
+
+// IsValid reports whether s is a known listing status
+func (s ListingStatus) IsValid() bool {
+	switch s {
+	case ListingStatusDraft, ListingStatusPublished:
+		return true
+	}
+	return false
+}
+
 // AddressDetails represents the address information for a listing
 type AddressDetails struct {
 	AddressLine1      string `json:"addressLine1"`
@@ -43,10 +103,14 @@ type AddressDetails struct {
 	ShortenedPostcode string `json:"shortenedPostcode"`
 	Country           string `json:"country"`
 	Region            Region `json:"region"`
+	// Coordinates are nil until the listing has been located on the map
+	Coordinates *Coordinates `json:"coordinates"`
 }
 
 // Photo represents a property photo
 type Photo struct {
+	ID           int64  `json:"id"`
+	Position     int    `json:"position"`
 	OriginalURL  string `json:"originalURL"`
 	StandardURL  string `json:"standardURL"`
 	ThumbnailURL string `json:"thumbnailURL"`
@@ -56,6 +120,7 @@ type Photo struct {
 // Listing represents a property listing
 type Listing struct {
 	ID                         int64          `json:"id"`
+	Status                     ListingStatus  `json:"status"`
 	AddressDetails             AddressDetails `json:"addressDetails"`
 	Bedrooms                   int            `json:"bedrooms"`
 	Bathrooms                  int            `json:"bathrooms"`
@@ -63,6 +128,7 @@ type Listing struct {
 	GrossYield                 float64        `json:"grossYield"`
 	IsCashOnly                 bool           `json:"isCashOnly"`
 	IsCompany                  bool           `json:"isCompany"`
+	IsFeatured                 bool           `json:"isFeatured"`
 	IsNewBuild                 bool           `json:"isNewBuild"`
 	IsShareSale                bool           `json:"isShareSale"`
 	IsTenanted                 bool           `json:"isTenanted"`
@@ -74,15 +140,132 @@ type Listing struct {
 	PropertyType               PropertyType   `json:"propertyType"`
 	MonthlyRentalIncomeInCents int64          `json:"monthlyRentalIncomeInCents"`
 	SizeSqFt                   int            `json:"sizeSqFt"`
+	// BuildYear is the year the property was built, or 0 if unknown
+	BuildYear int `json:"buildYear"`
+	// AgentID is the agent who owns the listing, or 0 if it has no owner
+	AgentID int64 `json:"agentId,omitempty"`
+	// Tags are free-form labels stored normalized, see NormalizeTag
+	Tags []string `json:"tags,omitempty"`
+	// CreatedAt and UpdatedAt are RFC 3339 times set by the repository when
+	// the listing is created and each time it is updated. Unlike MadeVisibleAt
+	// any value supplied by the client is ignored.
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+	// DeletedAt is the RFC 3339 time the listing was soft-deleted. It is only
+	// set on the listings returned by GetDeleted and cleared by Restore.
+	DeletedAt *string `json:"deletedAt,omitempty"`
+
+	// newBuildExplicit records that isNewBuild was supplied when decoding, so
+	// it is not overwritten by DeriveNewBuild
+	newBuildExplicit bool
+	// vsRegionMedian is set by WithVsRegionMedian and only encoded when set
+	vsRegionMedian *float64
+}
+
+// clone returns a deep copy of the listing, so the repository can hand out
+// listings without callers being able to modify the stored ones
+func (l *Listing) clone() *Listing {
+	copied := *l
+	copied.Photos = slices.Clone(l.Photos)
+	copied.Tags = slices.Clone(l.Tags)
+	copied.MadeVisibleAt = clonePointer(l.MadeVisibleAt)
+	copied.DeletedAt = clonePointer(l.DeletedAt)
+	copied.AddressDetails.Coordinates = clonePointer(l.AddressDetails.Coordinates)
+	copied.vsRegionMedian = clonePointer(l.vsRegionMedian)
+	return &copied
+}
+
+// clonePointer returns a pointer to a copy of *p, or nil if p is nil
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	copied := *p
+	return &copied
 }
 
-// ListingResponse represents the top-level response structure
+// DaysOnMarket returns the number of whole days between MadeVisibleAt and now,
+// and false if the listing has never been made visible
+func (l *Listing) DaysOnMarket(now time.Time) (int, bool) {
+	if l.MadeVisibleAt == nil {
+		return 0, false
+	}
+	visibleAt, err := time.Parse(time.RFC3339, *l.MadeVisibleAt)
+	if err != nil {
+		return 0, false
+	}
+	return int(now.Sub(visibleAt).Hours() / 24), true
+}
+
+// AgeYears returns how many years ago the listing was built, and false if the
+// build year is unknown
+func (l *Listing) AgeYears(now time.Time) (int, bool) {
+	if l.BuildYear == 0 {
+		return 0, false
+	}
+	return now.Year() - l.BuildYear, true
+}
+
+// DeriveNewBuild sets IsNewBuild from the build year, marking the listing as a
+// new build when it was built within maxAgeYears of now. An isNewBuild flag
+// supplied in the listing's JSON is authoritative and left untouched, as is
+// the flag on a listing with no build year.
+func (l *Listing) DeriveNewBuild(now time.Time, maxAgeYears int) {
+	if l.newBuildExplicit {
+		return
+	}
+	if age, ok := l.AgeYears(now); ok {
+		l.IsNewBuild = age <= maxAgeYears
+	}
+}
+
+// IsMortgageable reports whether the listing can be bought with a mortgage
+func (l *Listing) IsMortgageable() bool {
+	return !l.IsCashOnly
+}
+
+// IsAffordable reports whether a buyer with the given deposit can cover the
+// listing's minimum deposit
+func (l *Listing) IsAffordable(depositInCents int64) bool {
+	return l.MinimumDepositInCents <= depositInCents
+}
+
+// Values of ListingResponse.Type
+const (
+	ResponseTypeListing     = "listing"
+	ResponseTypeDevelopment = "development"
+)
+
+// ListingResponse represents the top-level response structure. Exactly one of
+// Listing and Development is set, matching Type; the other encodes as null.
+// Build it with NewListingResponse or NewDevelopmentResponse.
 type ListingResponse struct {
 	Type        string       `json:"type"`
 	Listing     *Listing     `json:"listing"`
 	Development *Development `json:"development"`
 }
 
+// NewListingResponse wraps a listing, leaving development null
+func NewListingResponse(listing *Listing) ListingResponse {
+	return ListingResponse{Type: ResponseTypeListing, Listing: listing}
+}
+
+// NewDevelopmentResponse wraps a development, leaving listing null
+func NewDevelopmentResponse(development *Development) ListingResponse {
+	return ListingResponse{Type: ResponseTypeDevelopment, Development: development}
+}
+
+// NewDetailResponse builds the envelope for the detail endpoint: the
+// development branch when there is a development, the listing branch
+// otherwise. Developments aren't stored yet, so for now the endpoint always
+// passes a nil development.
+func NewDetailResponse(listing *Listing, development *Development) ListingResponse {
+	if development != nil {
+		return NewDevelopmentResponse(development)
+	}
+	return NewListingResponse(listing)
+}
+
 // Development represents a property development (can be null)
 type Development struct {
 	// Add development fields as needed
@@ -95,28 +278,92 @@ type ListingRepository interface {
 	GetByID(ctx context.Context, id int64) (*Listing, error)
 	GetAll(ctx context.Context) ([]*Listing, error)
 	Update(ctx context.Context, listing *Listing) error
+	// Upsert stores the listing under its own ID, creating it if the ID is
+	// unused and updating it otherwise. It reports whether it was created.
+	Upsert(ctx context.Context, listing *Listing) (bool, error)
+	// CreateIfNotExists creates the listing unless one already exists at the
+	// same address, returning the stored listing and whether it was created
+	CreateIfNotExists(ctx context.Context, listing *Listing) (*Listing, bool, error)
+	// Delete soft-deletes the listing, hiding it from every other method
+	// but GetDeleted and Restore. Its ID stays reserved so it can be restored.
 	Delete(ctx context.Context, id int64) error
+	// Restore brings back a soft-deleted listing, or returns ErrNotFound if
+	// there is no deleted listing with the ID
+	Restore(ctx context.Context, id int64) error
+	// GetDeleted returns the soft-deleted listings ordered by ID
+	GetDeleted(ctx context.Context) ([]*Listing, error)
+	// PurgeDeleted permanently removes the listings soft-deleted before the
+	// given time, returning how many were removed
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
 	GetByRegion(ctx context.Context, region string) ([]*Listing, error)
 	GetByPropertyType(ctx context.Context, propertyType string) ([]*Listing, error)
+	// GetByAgent returns the listings owned by the agent ordered by ID
+	GetByAgent(ctx context.Context, agentID int64) ([]*Listing, error)
+	// CountByPropertyType returns how many listings there are of each
+	// property type. Types without listings are absent.
+	CountByPropertyType(ctx context.Context) (map[PropertyType]int, error)
 	GetFeatured(ctx context.Context) ([]*Listing, error)
 	SearchByCity(ctx context.Context, city string) ([]*Listing, error)
 	GetByPriceRange(ctx context.Context, minPrice, maxPrice int64) ([]*Listing, error)
 	GetByBedroomRange(ctx context.Context, minBedrooms, maxBedrooms int) ([]*Listing, error)
 	GetByBathroomRange(ctx context.Context, minBathrooms, maxBathrooms int) ([]*Listing, error)
+	GetBySizeRange(ctx context.Context, minSqFt, maxSqFt int) ([]*Listing, error)
+	GetByDepositRange(ctx context.Context, minDeposit, maxDeposit int64) ([]*Listing, error)
+	ReplaceAll(ctx context.Context, listings []*Listing, preserveIDs bool) error
+	GetByBoundingBox(ctx context.Context, box BoundingBox) ([]*Listing, error)
+	GetByShortenedPostcode(ctx context.Context, code string) ([]*Listing, error)
+	// Search returns the listings matching every criterion set on the filter
+	Search(ctx context.Context, filter ListingFilter) ([]*Listing, error)
+	// GetByFlags returns the listings having every boolean flag that is set
+	GetByFlags(ctx context.Context, flags ListingFlags) ([]*Listing, error)
+	// GetPriceHistory returns the listing's price changes, oldest first
+	GetPriceHistory(ctx context.Context, id int64) ([]PriceChange, error)
+	// GetPriceReduced returns the listings repriced below an earlier price
+	// by a change made at or after since
+	GetPriceReduced(ctx context.Context, since time.Time) ([]*Listing, error)
+	// GetAllSorted returns every listing ordered by field, with ties broken
+	// by ascending ID. It returns an error for an unsupported field.
+	GetAllSorted(ctx context.Context, field SortField, desc bool) ([]*Listing, error)
+	// AddPhoto checks the photo's URLs and adds it as the listing's last
+	// photo with the next free photo ID. A photo with the same original URL
+	// already on the listing is a conflict. The MIME type is left to callers,
+	// which know the allowed types.
+	AddPhoto(ctx context.Context, listingID int64, photo Photo) error
+	// RemovePhoto removes the listing's photo with the original URL,
+	// renumbering the rest, or returns ErrNotFound if it has no such photo
+	RemovePhoto(ctx context.Context, listingID int64, originalURL string) error
+	// ListPhotos returns the listing's photos in position order
+	ListPhotos(ctx context.Context, listingID int64) ([]Photo, error)
 }
 
-// ListingRepositoryImpl implements the ListingRepository interface
+// ListingRepositoryImpl implements the ListingRepository interface in memory.
+// It is safe for concurrent use: mu guards data, deleted, ids and priceHistory, reads
+// take it shared and writes exclusively. The repository never shares a
+// listing with its callers. Writes store a clone of the listing passed in,
+// which the caller may go on changing, and reads hand out clones, so nothing
+// outside the repository can reach a stored listing without holding mu.
 type ListingRepositoryImpl struct {
-	data   map[int64]*Listing
-	mu     sync.RWMutex
-	nextID int64
+	data map[int64]*Listing
+	mu   sync.RWMutex
+	ids  IDGenerator
+	// priceHistory holds each listing's price changes, oldest first
+	priceHistory map[int64][]PriceChange
+	// deleted holds the soft-deleted listings, out of reach of every query
+	// until they are restored
+	deleted map[int64]*Listing
 }
 
-// NewListingRepository creates a new listing repository
+// NewListingRepository creates a new listing repository with sequential IDs
 func NewListingRepository() ListingRepository {
+	return NewListingRepositoryWithIDGenerator(NewSequentialIDGenerator())
+}
+
+// NewListingRepositoryWithIDGenerator creates a new listing repository that
+// takes the IDs of new listings from ids
+func NewListingRepositoryWithIDGenerator(ids IDGenerator) ListingRepository {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  ids,
 	}
 
 	// Add some sample data for testing
@@ -283,6 +530,7 @@ func (r *ListingRepositoryImpl) addSampleData() {
 			IsCashOnly:                 true,
 			IsNewBuild:                 false,
 			IsCompany:                  false,
+			IsFeatured:                 true,
 			IsShareSale:                true,
 			Description:                "Share Sale Test",
 			Photos: []Photo{
@@ -480,13 +728,14 @@ func (r *ListingRepositoryImpl) addSampleData() {
 			Bathrooms:                  1,
 			SizeSqFt:                   686,
 			PriceInCents:               3995000,
-			MinimumDepositInCents:      3995000,
+			MinimumDepositInCents:      998750,
 			EstimatedDepositInCents:    998750,
 			MonthlyRentalIncomeInCents: 38000,
 			IsTenanted:                 true,
 			IsCashOnly:                 false,
 			IsNewBuild:                 false,
 			IsCompany:                  false,
+			IsFeatured:                 true,
 			IsShareSale:                false,
 			Description:                "2 bed flat in Preston with a rear terrace space and large garden area.",
 			Photos: []Photo{
@@ -956,8 +1205,8 @@ func (r *ListingRepositoryImpl) addSampleData() {
 				AddressLine1:      "3 Buckingham Palace Road",
 				AddressLine2:      "",
 				City:              "London",
-				Postcode:          "",
-				ShortenedPostcode: "W14 8FF",
+				Postcode:          "W14 8FF",
+				ShortenedPostcode: "W14",
 				Country:           "UK",
 				Region:            RegionLondon,
 			},
@@ -1150,11 +1399,21 @@ func (r *ListingRepositoryImpl) addSampleData() {
 		},
 	}
 
+	loadedAt := time.Now().Format(time.RFC3339)
 	for _, listing := range sampleListings {
-		r.data[listing.ID] = listing
-		if listing.ID >= r.nextID {
-			r.nextID = listing.ID + 1
+		if listing.Status == "" {
+			listing.Status = ListingStatusPublished
 		}
+		listing.Photos = normalizePhotos(listing.Photos)
+		listing.Tags = NormalizeTags(listing.Tags)
+		// Date the samples from when they went live where that is known
+		listing.CreatedAt = loadedAt
+		if listing.MadeVisibleAt != nil {
+			listing.CreatedAt = *listing.MadeVisibleAt
+		}
+		listing.UpdatedAt = listing.CreatedAt
+		r.data[listing.ID] = listing
+		r.ids.Reserve(listing.ID)
 	}
 }
 
@@ -1163,35 +1422,112 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+// normalizePhotos orders photos by Position (keeping input order for ties),
+// renumbers positions from 0 and assigns an ID to any photo that lacks one.
+// The photo at position 0 is the primary photo. The photos are returned in a
+// new slice, leaving the caller's untouched.
+func normalizePhotos(photos []Photo) []Photo {
+	if photos == nil {
+		return nil
+	}
+	photos = slices.Clone(photos)
+	sort.SliceStable(photos, func(i, j int) bool {
+		return photos[i].Position < photos[j].Position
+	})
+	var maxID int64
+	for _, photo := range photos {
+		if photo.ID > maxID {
+			maxID = photo.ID
+		}
+	}
+	for i := range photos {
+		photos[i].Position = i
+		if photos[i].ID == 0 {
+			maxID++
+			photos[i].ID = maxID
+		}
+	}
+	return photos
+}
+
 // Create adds a new listing to the repository
 func (r *ListingRepositoryImpl) Create(ctx context.Context, listing *Listing) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	return r.create(ctx, listing)
+}
+
+// CreateIfNotExists creates the listing unless one already exists at the same
+// address, matched on the normalized address line 1 and shortened postcode. It
+// returns the stored listing and whether it was created. Listings without an
+// address line 1 are always created.
+func (r *ListingRepositoryImpl) CreateIfNotExists(ctx context.Context, listing *Listing) (*Listing, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	if listing.AddressDetails.City == "" {
-		return errors.New("city is required")
+	listing.AddressDetails.NormalizePostcodes()
+	if key, ok := listing.AddressDetails.dedupeKey(); ok {
+		for _, existing := range r.data {
+			if existingKey, ok := existing.AddressDetails.dedupeKey(); ok && existingKey == key {
+				return existing.clone(), false, nil
+			}
+		}
 	}
-	if listing.AddressDetails.ShortenedPostcode == "" {
-		return errors.New("shortened postcode is required")
+	if err := r.create(ctx, listing); err != nil {
+		return nil, false, err
 	}
-	if listing.AddressDetails.Region == "" {
-		return errors.New("region is required")
+	return listing, true, nil
+}
+
+// dedupeKey identifies the address for CreateIfNotExists, ignoring case and
+// spacing, and is false when there is no address line 1 to match on
+func (a AddressDetails) dedupeKey() (string, bool) {
+	line := strings.ToLower(strings.Join(strings.Fields(a.AddressLine1), " "))
+	if line == "" {
+		return "", false
 	}
-	if listing.PropertyType == "" {
-		return errors.New("property type is required")
+	postcode := strings.ToUpper(strings.Join(strings.Fields(a.ShortenedPostcode), ""))
+	return line + "|" + postcode, true
+}
+
+// create validates the listing and stores it under a newly generated ID. r.mu
+// must be held.
+func (r *ListingRepositoryImpl) create(ctx context.Context, listing *Listing) error {
+	listing.AddressDetails.NormalizePostcodes()
+	if issues := ValidateListing(listing); len(issues.Errors) > 0 {
+		return ValidationErrorf("%s", issues.Errors[0])
 	}
-	if listing.PriceInCents <= 0 {
-		return errors.New("price must be greater than 0")
+
+	id, err := r.ids.NextID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate listing id")
 	}
+	if _, exists := r.data[id]; exists || r.deleted[id] != nil {
+		return ConflictErrorf("generated listing id %d is already in use", id)
+	}
+	listing.ID = id
+	prepareNew(listing, time.Now().Format(time.RFC3339))
+	r.data[listing.ID] = listing.clone()
+	return nil
+}
 
-	listing.ID = r.nextID
-	now := time.Now().Format(time.RFC3339)
+// prepareNew fills in the defaults of a listing about to be stored for the
+// first time: draft status, normalized photos and tags, the derived yield and
+// a visibility date of now
+func prepareNew(listing *Listing, now string) {
+	if listing.Status == "" {
+		listing.Status = ListingStatusDraft
+	}
+	listing.Photos = normalizePhotos(listing.Photos)
+	listing.Tags = NormalizeTags(listing.Tags)
+	// The yield is always derived from the price and rent, never trusted from input
+	listing.GrossYield = listing.ComputeGrossYield()
 	if listing.MadeVisibleAt == nil {
 		listing.MadeVisibleAt = &now
 	}
-	r.data[listing.ID] = listing
-	r.nextID++
-	return nil
+	listing.CreatedAt = now
+	listing.UpdatedAt = now
+	listing.DeletedAt = nil
 }
 
 // GetByID retrieves a listing by its ID
@@ -1200,9 +1536,9 @@ func (r *ListingRepositoryImpl) GetByID(ctx context.Context, id int64) (*Listing
 	defer r.mu.RUnlock()
 	listing, exists := r.data[id]
 	if !exists {
-		return nil, errors.Wrapf(errors.New("not found"), "listing not found with id: %d", id)
+		return nil, errors.Wrapf(ErrNotFound, "listing not found with id: %d", id)
 	}
-	return listing, nil
+	return listing.clone(), nil
 }
 
 // GetAll retrieves all listings
@@ -1211,9 +1547,16 @@ func (r *ListingRepositoryImpl) GetAll(ctx context.Context) ([]*Listing, error)
 	defer r.mu.RUnlock()
 	listings := make([]*Listing, 0, len(r.data))
 	for _, listing := range r.data {
-		listings = append(listings, listing)
+		listings = append(listings, listing.clone())
 	}
-	return listings, nil
+	return sortByID(listings), nil
+}
+
+// sortByID orders listings by ascending ID so query results don't depend on
+// map iteration order
+func sortByID(listings []*Listing) []*Listing {
+	sort.Slice(listings, func(i, j int) bool { return listings[i].ID < listings[j].ID })
+	return listings
 }
 
 // Update updates an existing listing
@@ -1221,47 +1564,187 @@ func (r *ListingRepositoryImpl) Update(ctx context.Context, listing *Listing) er
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if listing.AddressDetails.City == "" {
-		return errors.New("city is required")
-	}
-	if listing.AddressDetails.ShortenedPostcode == "" {
-		return errors.New("shortened postcode is required")
-	}
-	if listing.AddressDetails.Region == "" {
-		return errors.New("region is required")
-	}
-	if listing.PropertyType == "" {
-		return errors.New("property type is required")
-	}
-	if listing.PriceInCents <= 0 {
-		return errors.New("price must be greater than 0")
+	listing.AddressDetails.NormalizePostcodes()
+	if issues := ValidateListing(listing); len(issues.Errors) > 0 {
+		return ValidationErrorf("%s", issues.Errors[0])
 	}
 
 	existing, exists := r.data[listing.ID]
 	if !exists {
-		return errors.Wrapf(errors.New("not found"), "listing not found with id: %d", listing.ID)
+		return errors.Wrapf(ErrNotFound, "listing not found with id: %d", listing.ID)
 	}
+	r.update(existing, listing)
+	return nil
+}
+
+// update replaces existing with listing, keeping the visibility date, status
+// and photos of existing when listing leaves them out. r.mu must be held.
+func (r *ListingRepositoryImpl) update(existing, listing *Listing) {
+	r.recordPriceChange(existing, listing)
+	prepareUpdate(existing, listing, time.Now())
+	r.data[listing.ID] = listing.clone()
+}
+
+// prepareUpdate readies listing to replace existing at now, carrying over the
+// creation time, and the visibility date, status, owner, photos and tags that
+// listing leaves out
+func prepareUpdate(existing, listing *Listing, now time.Time) {
+	listing.CreatedAt = existing.CreatedAt
+	listing.UpdatedAt = nextUpdatedAt(existing.UpdatedAt, now)
+	listing.DeletedAt = nil
 
 	// Preserve the original MadeVisibleAt if it exists
 	if existing.MadeVisibleAt != nil && listing.MadeVisibleAt == nil {
 		listing.MadeVisibleAt = existing.MadeVisibleAt
 	}
 
-	r.data[listing.ID] = listing
-	return nil
+	if listing.Status == "" {
+		listing.Status = existing.Status
+	}
+	if listing.AgentID == 0 {
+		listing.AgentID = existing.AgentID
+	}
+
+	// Keep the existing photos and tags when none are supplied
+	if listing.Photos == nil {
+		listing.Photos = existing.Photos
+	}
+	if listing.Tags == nil {
+		listing.Tags = existing.Tags
+	}
+	listing.Photos = normalizePhotos(listing.Photos)
+	listing.Tags = NormalizeTags(listing.Tags)
+	listing.GrossYield = listing.ComputeGrossYield()
 }
 
-// Delete removes a listing by its ID
+// nextUpdatedAt returns now as an RFC 3339 time, or the second after previous
+// if now is not past it. RFC 3339 times only have whole seconds, so without
+// this updates within the same second would leave UpdatedAt unchanged.
+func nextUpdatedAt(previous string, now time.Time) string {
+	now = now.Truncate(time.Second)
+	if last, err := time.Parse(time.RFC3339, previous); err == nil && !now.After(last) {
+		now = last.Add(time.Second)
+	}
+	return now.Format(time.RFC3339)
+}
+
+// Upsert creates the listing under its own ID if no listing has it, reserving
+// the ID so it is never generated, or updates the existing listing otherwise.
+// The ID of a deleted listing is a conflict until the listing is restored.
+func (r *ListingRepositoryImpl) Upsert(ctx context.Context, listing *Listing) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if listing.ID <= 0 {
+		return false, ValidationErrorf("invalid listing id: %d", listing.ID)
+	}
+	listing.AddressDetails.NormalizePostcodes()
+	if issues := ValidateListing(listing); len(issues.Errors) > 0 {
+		return false, ValidationErrorf("%s", issues.Errors[0])
+	}
+
+	if existing, exists := r.data[listing.ID]; exists {
+		r.update(existing, listing)
+		return false, nil
+	}
+	if _, deleted := r.deleted[listing.ID]; deleted {
+		return false, deletedIDConflict(listing.ID)
+	}
+	r.ids.Reserve(listing.ID)
+	prepareNew(listing, time.Now().Format(time.RFC3339))
+	r.data[listing.ID] = listing.clone()
+	return true, nil
+}
+
+// Delete soft-deletes a listing by its ID, moving it to deleted with its
+// DeletedAt set. Its price history is kept for when it is restored.
 func (r *ListingRepositoryImpl) Delete(ctx context.Context, id int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, exists := r.data[id]; !exists {
-		return errors.Wrapf(errors.New("not found"), "listing not found with id: %d", id)
+	listing, exists := r.data[id]
+	if !exists {
+		return errors.Wrapf(ErrNotFound, "listing not found with id: %d", id)
+	}
+	deleted := listing.clone()
+	now := time.Now().Format(time.RFC3339)
+	deleted.DeletedAt = &now
+	if r.deleted == nil {
+		r.deleted = make(map[int64]*Listing)
 	}
+	r.deleted[id] = deleted
 	delete(r.data, id)
 	return nil
 }
 
+// Restore moves a soft-deleted listing back, clearing DeletedAt and counting
+// the restore as an update. It is a conflict if another listing has since
+// been created at the same address.
+func (r *ListingRepositoryImpl) Restore(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	listing, exists := r.deleted[id]
+	if !exists {
+		return errors.Wrapf(ErrNotFound, "deleted listing not found with id: %d", id)
+	}
+	if key, ok := listing.AddressDetails.dedupeKey(); ok {
+		for _, existing := range r.data {
+			if existingKey, ok := existing.AddressDetails.dedupeKey(); ok && existingKey == key {
+				return restoreConflict(id, existing.ID)
+			}
+		}
+	}
+	restored := listing.clone()
+	restored.DeletedAt = nil
+	restored.UpdatedAt = nextUpdatedAt(restored.UpdatedAt, time.Now())
+	r.data[id] = restored
+	delete(r.deleted, id)
+	return nil
+}
+
+// GetDeleted returns the soft-deleted listings
+func (r *ListingRepositoryImpl) GetDeleted(ctx context.Context) ([]*Listing, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listings := make([]*Listing, 0, len(r.deleted))
+	for _, listing := range r.deleted {
+		listings = append(listings, listing.clone())
+	}
+	return sortByID(listings), nil
+}
+
+// restoreConflict is returned when restoring a listing whose address another
+// listing now has
+func restoreConflict(id, existingID int64) error {
+	return ConflictErrorf("listing %d cannot be restored as listing %d has the same address", id, existingID)
+}
+
+// PurgeDeleted permanently removes the listings deleted before the given
+// time along with their price history. Their IDs stay reserved.
+func (r *ListingRepositoryImpl) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	purged := 0
+	for id, listing := range r.deleted {
+		deletedAt, err := time.Parse(time.RFC3339, *listing.DeletedAt)
+		if err != nil {
+			return purged, errors.Wrapf(err, "failed to parse deletion time of listing %d", id)
+		}
+		if !deletedAt.Before(before) {
+			continue
+		}
+		delete(r.deleted, id)
+		delete(r.priceHistory, id)
+		purged++
+	}
+	return purged, nil
+}
+
+// deletedIDConflict is returned when writing a new listing under the ID of a
+// soft-deleted one
+func deletedIDConflict(id int64) error {
+	return ConflictErrorf("listing %d is deleted and must be restored before it is replaced", id)
+}
+
 // GetByRegion retrieves all listings in a specific region
 func (r *ListingRepositoryImpl) GetByRegion(ctx context.Context, region string) ([]*Listing, error) {
 	r.mu.RLock()
@@ -1269,10 +1752,10 @@ func (r *ListingRepositoryImpl) GetByRegion(ctx context.Context, region string)
 	listings := make([]*Listing, 0)
 	for _, listing := range r.data {
 		if string(listing.AddressDetails.Region) == region {
-			listings = append(listings, listing)
+			listings = append(listings, listing.clone())
 		}
 	}
-	return listings, nil
+	return sortByID(listings), nil
 }
 
 // GetByPropertyType retrieves all listings of a specific property type
@@ -1282,19 +1765,50 @@ func (r *ListingRepositoryImpl) GetByPropertyType(ctx context.Context, propertyT
 	listings := make([]*Listing, 0)
 	for _, listing := range r.data {
 		if string(listing.PropertyType) == propertyType {
-			listings = append(listings, listing)
+			listings = append(listings, listing.clone())
 		}
 	}
-	return listings, nil
+	return sortByID(listings), nil
 }
 
-// GetFeatured retrieves all featured listings (deprecated - returns all listings)
+// GetByAgent retrieves all listings owned by an agent
+func (r *ListingRepositoryImpl) GetByAgent(ctx context.Context, agentID int64) ([]*Listing, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listings := make([]*Listing, 0)
+	for _, listing := range r.data {
+		if listing.AgentID == agentID {
+			listings = append(listings, listing.clone())
+		}
+	}
+	return sortByID(listings), nil
+}
+
+// CountByPropertyType counts the listings of each property type in a single
+// pass. Types without listings are absent.
+func (r *ListingRepositoryImpl) CountByPropertyType(ctx context.Context) (map[PropertyType]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	counts := make(map[PropertyType]int)
+	for _, listing := range r.data {
+		counts[listing.PropertyType]++
+	}
+	return counts, nil
+}
+
+// GetFeatured retrieves the featured listings, most recently made visible
+// first. Ties, including listings never made visible, are ordered by ID.
 func (r *ListingRepositoryImpl) GetFeatured(ctx context.Context) ([]*Listing, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	listings := make([]*Listing, 0)
 	for _, listing := range r.data {
-		listings = append(listings, listing)
+		if listing.IsFeatured {
+			listings = append(listings, listing.clone())
+		}
+	}
+	if err := SortListings(listings, SortByMadeVisibleAt, true); err != nil {
+		return nil, err
 	}
 	return listings, nil
 }
@@ -1307,10 +1821,36 @@ func (r *ListingRepositoryImpl) SearchByCity(ctx context.Context, city string) (
 	cityLower := strings.ToLower(city)
 	for _, listing := range r.data {
 		if strings.Contains(strings.ToLower(listing.AddressDetails.City), cityLower) {
-			listings = append(listings, listing)
+			listings = append(listings, listing.clone())
 		}
 	}
-	return listings, nil
+	return sortByID(listings), nil
+}
+
+// Search retrieves the listings matching every criterion set on the filter
+func (r *ListingRepositoryImpl) Search(ctx context.Context, filter ListingFilter) ([]*Listing, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listings := make([]*Listing, 0)
+	for _, listing := range r.data {
+		if filter.Matches(listing) {
+			listings = append(listings, listing.clone())
+		}
+	}
+	return sortByID(listings), nil
+}
+
+// GetByFlags retrieves the listings having every boolean flag that is set
+func (r *ListingRepositoryImpl) GetByFlags(ctx context.Context, flags ListingFlags) ([]*Listing, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listings := make([]*Listing, 0)
+	for _, listing := range r.data {
+		if flags.Matches(listing) {
+			listings = append(listings, listing.clone())
+		}
+	}
+	return sortByID(listings), nil
 }
 
 // GetByPriceRange retrieves listings within a price range
@@ -1320,10 +1860,10 @@ func (r *ListingRepositoryImpl) GetByPriceRange(ctx context.Context, minPrice, m
 	listings := make([]*Listing, 0)
 	for _, listing := range r.data {
 		if listing.PriceInCents >= minPrice && listing.PriceInCents <= maxPrice {
-			listings = append(listings, listing)
+			listings = append(listings, listing.clone())
 		}
 	}
-	return listings, nil
+	return sortByID(listings), nil
 }
 
 // GetByBedroomRange retrieves listings within a bedroom range
@@ -1333,10 +1873,10 @@ func (r *ListingRepositoryImpl) GetByBedroomRange(ctx context.Context, minBedroo
 	listings := make([]*Listing, 0)
 	for _, listing := range r.data {
 		if listing.Bedrooms >= minBedrooms && listing.Bedrooms <= maxBedrooms {
-			listings = append(listings, listing)
+			listings = append(listings, listing.clone())
 		}
 	}
-	return listings, nil
+	return sortByID(listings), nil
 }
 
 // GetByBathroomRange retrieves listings within a bathroom range
@@ -1346,8 +1886,195 @@ func (r *ListingRepositoryImpl) GetByBathroomRange(ctx context.Context, minBathr
 	listings := make([]*Listing, 0)
 	for _, listing := range r.data {
 		if listing.Bathrooms >= minBathrooms && listing.Bathrooms <= maxBathrooms {
-			listings = append(listings, listing)
+			listings = append(listings, listing.clone())
 		}
 	}
-	return listings, nil
+	return sortByID(listings), nil
+}
+
+// GetBySizeRange retrieves listings within an inclusive square footage range.
+// A minimum above the maximum is a validation error.
+func (r *ListingRepositoryImpl) GetBySizeRange(ctx context.Context, minSqFt, maxSqFt int) ([]*Listing, error) {
+	if minSqFt > maxSqFt {
+		return nil, ValidationErrorf("minSize cannot be greater than maxSize")
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listings := make([]*Listing, 0)
+	for _, listing := range r.data {
+		if listing.SizeSqFt >= minSqFt && listing.SizeSqFt <= maxSqFt {
+			listings = append(listings, listing.clone())
+		}
+	}
+	return sortByID(listings), nil
+}
+
+// GetByDepositRange retrieves listings within an estimated deposit range
+func (r *ListingRepositoryImpl) GetByDepositRange(ctx context.Context, minDeposit, maxDeposit int64) ([]*Listing, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listings := make([]*Listing, 0)
+	for _, listing := range r.data {
+		if listing.EstimatedDepositInCents >= minDeposit && listing.EstimatedDepositInCents <= maxDeposit {
+			listings = append(listings, listing.clone())
+		}
+	}
+	return sortByID(listings), nil
+}
+
+// GetByBoundingBox retrieves the listings whose coordinates fall within the box.
+// Listings without coordinates are never included.
+func (r *ListingRepositoryImpl) GetByBoundingBox(ctx context.Context, box BoundingBox) ([]*Listing, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listings := make([]*Listing, 0)
+	for _, listing := range r.data {
+		if listing.AddressDetails.Coordinates != nil && box.Contains(*listing.AddressDetails.Coordinates) {
+			listings = append(listings, listing.clone())
+		}
+	}
+	return sortByID(listings), nil
+}
+
+// GetByShortenedPostcode retrieves listings whose shortened postcode matches code, ignoring case
+func (r *ListingRepositoryImpl) GetByShortenedPostcode(ctx context.Context, code string) ([]*Listing, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listings := make([]*Listing, 0)
+	code = strings.TrimSpace(code)
+	for _, listing := range r.data {
+		if strings.EqualFold(listing.AddressDetails.ShortenedPostcode, code) {
+			listings = append(listings, listing.clone())
+		}
+	}
+	return sortByID(listings), nil
+}
+
+// ReplaceAll validates every listing and then swaps them in for the existing
+// data in a single step, so readers see either the old or the new catalogue.
+// With preserveIDs the supplied IDs are kept and must be positive and unique,
+// otherwise IDs are reassigned from 1 in the order given. Deleted listings are
+// dropped along with the rest. If any listing is invalid the existing data is
+// left untouched.
+func (r *ListingRepositoryImpl) ReplaceAll(ctx context.Context, listings []*Listing, preserveIDs bool) error {
+	if err := ValidateReplacement(listings, preserveIDs); err != nil {
+		return err
+	}
+
+	data := make(map[int64]*Listing, len(listings))
+	var maxID int64
+	now := time.Now().Format(time.RFC3339)
+	for i, listing := range listings {
+		if !preserveIDs {
+			listing.ID = int64(i + 1)
+		}
+		if listing.ID > maxID {
+			maxID = listing.ID
+		}
+		prepareReplacement(listing, now)
+		data[listing.ID] = listing.clone()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data = data
+	r.deleted = nil
+	r.priceHistory = nil
+	r.ids.Reserve(maxID)
+	return nil
+}
+
+// ValidateReplacement normalizes the postcodes of and checks every listing for
+// ReplaceAll, or any other batch write, before any is stored. With preserveIDs
+// their IDs must be positive and unique.
+func ValidateReplacement(listings []*Listing, preserveIDs bool) error {
+	seen := make(map[int64]bool, len(listings))
+	for i, listing := range listings {
+		if listing == nil {
+			return ValidationErrorf("listing at index %d is empty", i)
+		}
+		listing.AddressDetails.NormalizePostcodes()
+		if issues := ValidateListing(listing); len(issues.Errors) > 0 {
+			return ValidationErrorf("listing at index %d is invalid: %s", i, issues.Errors[0])
+		}
+		if preserveIDs {
+			if listing.ID <= 0 {
+				return ValidationErrorf("listing at index %d has invalid id: %d", i, listing.ID)
+			}
+			if seen[listing.ID] {
+				return ValidationErrorf("listing at index %d has duplicate id: %d", i, listing.ID)
+			}
+			seen[listing.ID] = true
+		}
+	}
+	return nil
+}
+
+// prepareReplacement normalizes a listing for ReplaceAll. Unlike prepareNew it
+// leaves the status, and any creation and update times, as given so a restored
+// export keeps its history.
+func prepareReplacement(listing *Listing, now string) {
+	listing.DeletedAt = nil
+	listing.Photos = normalizePhotos(listing.Photos)
+	listing.Tags = NormalizeTags(listing.Tags)
+	listing.GrossYield = listing.ComputeGrossYield()
+	if listing.MadeVisibleAt == nil {
+		listing.MadeVisibleAt = &now
+	}
+	if listing.CreatedAt == "" {
+		listing.CreatedAt = now
+	}
+	if listing.UpdatedAt == "" {
+		listing.UpdatedAt = listing.CreatedAt
+	}
+}
+
+// AddPhoto checks the photo's URLs and adds it as the listing's last photo
+func (r *ListingRepositoryImpl) AddPhoto(ctx context.Context, listingID int64, photo Photo) error {
+	if err := photo.Validate(nil); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	listing, exists := r.data[listingID]
+	if !exists {
+		return errors.Wrapf(ErrNotFound, "listing not found with id: %d", listingID)
+	}
+	photos, err := withPhoto(listing.Photos, photo)
+	if err != nil {
+		return err
+	}
+	listing.Photos = photos
+	listing.UpdatedAt = nextUpdatedAt(listing.UpdatedAt, time.Now())
+	return nil
+}
+
+// RemovePhoto removes the listing's photo with the original URL
+func (r *ListingRepositoryImpl) RemovePhoto(ctx context.Context, listingID int64, originalURL string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	listing, exists := r.data[listingID]
+	if !exists {
+		return errors.Wrapf(ErrNotFound, "listing not found with id: %d", listingID)
+	}
+	photos, err := withoutPhoto(listing.Photos, originalURL)
+	if err != nil {
+		return err
+	}
+	listing.Photos = photos
+	listing.UpdatedAt = nextUpdatedAt(listing.UpdatedAt, time.Now())
+	return nil
+}
+
+// ListPhotos returns the listing's photos in position order
+func (r *ListingRepositoryImpl) ListPhotos(ctx context.Context, listingID int64) ([]Photo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listing, exists := r.data[listingID]
+	if !exists {
+		return nil, errors.Wrapf(ErrNotFound, "listing not found with id: %d", listingID)
+	}
+	photos := make([]Photo, len(listing.Photos))
+	copy(photos, listing.Photos)
+	return photos, nil
 }
@@ -0,0 +1,43 @@
+package models
+
+import "encoding/json"
+
+// AffordableListing is a listing in the response to a request that gives a
+// deposit, flagged with whether the deposit covers the listing's minimum
+// deposit
+type AffordableListing struct {
+	*Listing
+	Affordable bool
+}
+
+// MarshalJSON encodes the listing as Listing.MarshalJSON does, with an
+// affordable field added
+func (a AffordableListing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		encodedListing
+		Affordable bool `json:"affordable"`
+	}{
+		encodedListing: a.Listing.encoded(),
+		Affordable:     a.Affordable,
+	})
+}
+
+// AffordableListings flags each listing with whether the deposit covers it
+func AffordableListings(listings []*Listing, depositInCents int64) []AffordableListing {
+	flagged := make([]AffordableListing, 0, len(listings))
+	for _, listing := range listings {
+		flagged = append(flagged, AffordableListing{Listing: listing, Affordable: listing.IsAffordable(depositInCents)})
+	}
+	return flagged
+}
+
+// AffordableSummaries returns the lightweight view of each listing, flagged
+// with whether the deposit covers it
+func AffordableSummaries(listings []*Listing, depositInCents int64) []ListingSummary {
+	summaries := Summaries(listings)
+	for i, listing := range listings {
+		affordable := listing.IsAffordable(depositInCents)
+		summaries[i].Affordable = &affordable
+	}
+	return summaries
+}
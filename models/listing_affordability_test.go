@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAffordableListings(t *testing.T) {
+	listings := []*Listing{
+		{ID: 1, MinimumDepositInCents: 1000000, EstimatedDepositInCents: 3000000},
+		{ID: 2, MinimumDepositInCents: 2500001},
+	}
+
+	flagged := AffordableListings(listings, 2500000)
+	require.Len(t, flagged, 2)
+	assert.True(t, flagged[0].Affordable)
+	assert.False(t, flagged[1].Affordable)
+
+	data, err := json.Marshal(flagged[0])
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, true, fields["affordable"])
+	// The listing's own fields are encoded as Listing.MarshalJSON encodes them
+	assert.Equal(t, float64(1), fields["id"])
+	assert.Contains(t, fields, "grossYieldPercent")
+	assert.Contains(t, fields, "pricePerSqFtInCents")
+
+	// Flagging doesn't change how the listing itself encodes
+	data, err = json.Marshal(listings[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "affordable")
+
+	summaries := AffordableSummaries(listings, 2500000)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, true, *summaries[0].Affordable)
+	assert.Equal(t, false, *summaries[1].Affordable)
+	assert.Nil(t, Summaries(listings)[0].Affordable)
+}
@@ -0,0 +1,209 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultChangeLogCapacity is how many recent changes the change log retains
+const defaultChangeLogCapacity = 1000
+
+// ChangeType describes what happened to a listing
+type ChangeType string
+
+const (
+	ChangeTypeCreated  ChangeType = "created"
+	ChangeTypeUpdated  ChangeType = "updated"
+	ChangeTypeDeleted  ChangeType = "deleted"
+	ChangeTypeRestored ChangeType = "restored"
+	ChangeTypeReplaced ChangeType = "replaced"
+)
+
+// ListingChange records a single change to the listings. ListingID is zero
+// when the whole dataset was replaced.
+type ListingChange struct {
+	Version   int64      `json:"version"`
+	Type      ChangeType `json:"type"`
+	ListingID int64      `json:"listingId,omitempty"`
+}
+
+// ListingChanges is the result of polling for changes. Version is the latest
+// version seen and should be passed as since on the next poll.
+type ListingChanges struct {
+	Version int64           `json:"version"`
+	Changes []ListingChange `json:"changes"`
+}
+
+// ListingChangeLog is an in-process event bus for listing changes. It keeps
+// the most recent changes so pollers can catch up from a version, and wakes
+// any waiters whenever a change is published.
+type ListingChangeLog struct {
+	mu       sync.Mutex
+	version  int64
+	changes  []ListingChange
+	capacity int
+	notify   chan struct{}
+}
+
+// NewListingChangeLog creates an empty change log
+func NewListingChangeLog() *ListingChangeLog {
+	return &ListingChangeLog{
+		capacity: defaultChangeLogCapacity,
+		notify:   make(chan struct{}),
+	}
+}
+
+// Publish records a change and wakes all waiters
+func (l *ListingChangeLog) Publish(changeType ChangeType, listingID int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.version++
+	l.changes = append(l.changes, ListingChange{
+		Version:   l.version,
+		Type:      changeType,
+		ListingID: listingID,
+	})
+	if len(l.changes) > l.capacity {
+		l.changes = l.changes[len(l.changes)-l.capacity:]
+	}
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
+
+// since returns the retained changes newer than version along with the current version
+func (l *ListingChangeLog) since(version int64) ListingChanges {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sinceLocked(version)
+}
+
+// sinceLocked is since for callers already holding the lock
+func (l *ListingChangeLog) sinceLocked(version int64) ListingChanges {
+	changes := make([]ListingChange, 0)
+	for _, change := range l.changes {
+		if change.Version > version {
+			changes = append(changes, change)
+		}
+	}
+	return ListingChanges{Version: l.version, Changes: changes}
+}
+
+// Wait returns the changes newer than since, blocking for up to timeout until
+// there is at least one. On timeout it returns no changes and the current
+// version. Changes older than the retained history are not returned.
+func (l *ListingChangeLog) Wait(ctx context.Context, since int64, timeout time.Duration) (ListingChanges, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		l.mu.Lock()
+		result := l.sinceLocked(since)
+		notify := l.notify
+		l.mu.Unlock()
+		if len(result.Changes) > 0 {
+			return result, nil
+		}
+		select {
+		case <-notify:
+		case <-timer.C:
+			return l.since(since), nil
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+}
+
+// ChangeRecordingListingRepository decorates a ListingRepository, publishing a
+// change to the change log after every successful write
+type ChangeRecordingListingRepository struct {
+	ListingRepository
+	changes *ListingChangeLog
+}
+
+// NewChangeRecordingListingRepository wraps repo so that writes are published to changes
+func NewChangeRecordingListingRepository(repo ListingRepository, changes *ListingChangeLog) ListingRepository {
+	return &ChangeRecordingListingRepository{
+		ListingRepository: repo,
+		changes:           changes,
+	}
+}
+
+func (r *ChangeRecordingListingRepository) Create(ctx context.Context, listing *Listing) error {
+	if err := r.ListingRepository.Create(ctx, listing); err != nil {
+		return err
+	}
+	r.changes.Publish(ChangeTypeCreated, listing.ID)
+	return nil
+}
+
+func (r *ChangeRecordingListingRepository) Update(ctx context.Context, listing *Listing) error {
+	if err := r.ListingRepository.Update(ctx, listing); err != nil {
+		return err
+	}
+	r.changes.Publish(ChangeTypeUpdated, listing.ID)
+	return nil
+}
+
+func (r *ChangeRecordingListingRepository) Upsert(ctx context.Context, listing *Listing) (bool, error) {
+	created, err := r.ListingRepository.Upsert(ctx, listing)
+	if err != nil {
+		return false, err
+	}
+	if created {
+		r.changes.Publish(ChangeTypeCreated, listing.ID)
+	} else {
+		r.changes.Publish(ChangeTypeUpdated, listing.ID)
+	}
+	return created, nil
+}
+
+func (r *ChangeRecordingListingRepository) CreateIfNotExists(ctx context.Context, listing *Listing) (*Listing, bool, error) {
+	stored, created, err := r.ListingRepository.CreateIfNotExists(ctx, listing)
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		r.changes.Publish(ChangeTypeCreated, stored.ID)
+	}
+	return stored, created, nil
+}
+
+func (r *ChangeRecordingListingRepository) Delete(ctx context.Context, id int64) error {
+	if err := r.ListingRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.changes.Publish(ChangeTypeDeleted, id)
+	return nil
+}
+
+func (r *ChangeRecordingListingRepository) Restore(ctx context.Context, id int64) error {
+	if err := r.ListingRepository.Restore(ctx, id); err != nil {
+		return err
+	}
+	r.changes.Publish(ChangeTypeRestored, id)
+	return nil
+}
+
+func (r *ChangeRecordingListingRepository) ReplaceAll(ctx context.Context, listings []*Listing, preserveIDs bool) error {
+	if err := r.ListingRepository.ReplaceAll(ctx, listings, preserveIDs); err != nil {
+		return err
+	}
+	r.changes.Publish(ChangeTypeReplaced, 0)
+	return nil
+}
+
+func (r *ChangeRecordingListingRepository) AddPhoto(ctx context.Context, listingID int64, photo Photo) error {
+	if err := r.ListingRepository.AddPhoto(ctx, listingID, photo); err != nil {
+		return err
+	}
+	r.changes.Publish(ChangeTypeUpdated, listingID)
+	return nil
+}
+
+func (r *ChangeRecordingListingRepository) RemovePhoto(ctx context.Context, listingID int64, originalURL string) error {
+	if err := r.ListingRepository.RemovePhoto(ctx, listingID, originalURL); err != nil {
+		return err
+	}
+	r.changes.Publish(ChangeTypeUpdated, listingID)
+	return nil
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListingChangeLog_Wait(t *testing.T) {
+	t.Run("create during poll returns promptly", func(t *testing.T) {
+		changes := NewListingChangeLog()
+		repo := NewChangeRecordingListingRepository(&ListingRepositoryImpl{
+			data: make(map[int64]*Listing),
+			ids:  NewSequentialIDGenerator(),
+		}, changes)
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			_ = repo.Create(context.Background(), &Listing{
+				AddressDetails: AddressDetails{
+					City:              "London",
+					ShortenedPostcode: "W1",
+					Region:            RegionLondon,
+				},
+				PropertyType: PropertyTypeApartment,
+				PriceInCents: 10000000,
+			})
+		}()
+
+		start := time.Now()
+		result, err := changes.Wait(context.Background(), 0, 5*time.Second)
+
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, int64(1), result.Version)
+		assert.Equal(t, []ListingChange{{Version: 1, Type: ChangeTypeCreated, ListingID: 1}}, result.Changes)
+	})
+
+	t.Run("no change returns empty after timeout", func(t *testing.T) {
+		changes := NewListingChangeLog()
+		changes.Publish(ChangeTypeDeleted, 4)
+
+		start := time.Now()
+		result, err := changes.Wait(context.Background(), 1, 50*time.Millisecond)
+
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		assert.Equal(t, int64(1), result.Version)
+		assert.Empty(t, result.Changes)
+	})
+
+	t.Run("earlier changes return immediately", func(t *testing.T) {
+		changes := NewListingChangeLog()
+		changes.Publish(ChangeTypeCreated, 1)
+		changes.Publish(ChangeTypeUpdated, 1)
+
+		result, err := changes.Wait(context.Background(), 1, time.Minute)
+
+		require.NoError(t, err)
+		assert.Equal(t, []ListingChange{{Version: 2, Type: ChangeTypeUpdated, ListingID: 1}}, result.Changes)
+	})
+
+	t.Run("delete and restore are published", func(t *testing.T) {
+		changes := NewListingChangeLog()
+		repo := NewChangeRecordingListingRepository(NewListingRepository(), changes)
+
+		require.NoError(t, repo.Delete(context.Background(), 187))
+		require.NoError(t, repo.Restore(context.Background(), 187))
+
+		assert.Equal(t, []ListingChange{
+			{Version: 1, Type: ChangeTypeDeleted, ListingID: 187},
+			{Version: 2, Type: ChangeTypeRestored, ListingID: 187},
+		}, changes.since(0).Changes)
+	})
+
+	t.Run("failed write is not published", func(t *testing.T) {
+		changes := NewListingChangeLog()
+		repo := NewChangeRecordingListingRepository(&ListingRepositoryImpl{
+			data: make(map[int64]*Listing),
+			ids:  NewSequentialIDGenerator(),
+		}, changes)
+
+		err := repo.Delete(context.Background(), 99)
+
+		assert.Error(t, err)
+		assert.Empty(t, changes.since(0).Changes)
+	})
+}
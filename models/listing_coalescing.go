@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"strconv"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CoalescingListingRepository decorates a ListingRepository so that identical
+// concurrent reads share a single call to the underlying repository
+type CoalescingListingRepository struct {
+	ListingRepository
+	group singleflight.Group
+}
+
+// NewCoalescingListingRepository wraps repo with read coalescing
+func NewCoalescingListingRepository(repo ListingRepository) ListingRepository {
+	return &CoalescingListingRepository{
+		ListingRepository: repo,
+	}
+}
+
+// GetByID retrieves a listing by its ID, sharing the read with concurrent
+// callers. Each caller gets its own copy of the listing.
+func (r *CoalescingListingRepository) GetByID(ctx context.Context, id int64) (*Listing, error) {
+	v, err := r.do(ctx, "GetByID:"+strconv.FormatInt(id, 10), func(ctx context.Context) (interface{}, error) {
+		return r.ListingRepository.GetByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Listing).clone(), nil
+}
+
+// GetAll retrieves all listings, sharing the read with concurrent callers.
+// Each caller gets its own copy of the slice and the listings in it.
+func (r *CoalescingListingRepository) GetAll(ctx context.Context) ([]*Listing, error) {
+	v, err := r.do(ctx, "GetAll", func(ctx context.Context) (interface{}, error) {
+		return r.ListingRepository.GetAll(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	shared := v.([]*Listing)
+	listings := make([]*Listing, len(shared))
+	for i, listing := range shared {
+		listings[i] = listing.clone()
+	}
+	return listings, nil
+}
+
+// do runs read once for all concurrent callers with the same key. The shared
+// read doesn't inherit any caller's cancellation, so one caller giving up
+// doesn't fail the rest; a cancelled caller stops waiting and returns its own
+// context error.
+func (r *CoalescingListingRepository) do(ctx context.Context, key string, read func(context.Context) (interface{}, error)) (interface{}, error) {
+	shared := context.WithoutCancel(ctx)
+	results := r.group.DoChan(key, func() (interface{}, error) {
+		return read(shared)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		return result.Val, result.Err
+	}
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingListingRepository counts reads and holds them until release is closed
+type blockingListingRepository struct {
+	ListingRepository
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (r *blockingListingRepository) GetByID(ctx context.Context, id int64) (*Listing, error) {
+	r.calls.Add(1)
+	select {
+	case <-r.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &Listing{ID: id, Tags: []string{"garden"}}, nil
+}
+
+func (r *blockingListingRepository) GetAll(ctx context.Context) ([]*Listing, error) {
+	r.calls.Add(1)
+	<-r.release
+	return []*Listing{{ID: 1}, {ID: 2}}, nil
+}
+
+func TestCoalescingListingRepository(t *testing.T) {
+	const callers = 50
+
+	tests := []struct {
+		name string
+		read func(repo ListingRepository) (int, error)
+	}{
+		{
+			name: "GetByID",
+			read: func(repo ListingRepository) (int, error) {
+				listing, err := repo.GetByID(context.Background(), 7)
+				if err != nil {
+					return 0, err
+				}
+				return int(listing.ID), nil
+			},
+		},
+		{
+			name: "GetAll",
+			read: func(repo ListingRepository) (int, error) {
+				listings, err := repo.GetAll(context.Background())
+				return len(listings), err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			underlying := &blockingListingRepository{release: make(chan struct{})}
+			repo := NewCoalescingListingRepository(underlying)
+
+			var wg sync.WaitGroup
+			results := make([]int, callers)
+			errs := make([]error, callers)
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], errs[i] = tt.read(repo)
+				}(i)
+			}
+
+			// Give every caller time to join the in-flight read before releasing it
+			time.Sleep(50 * time.Millisecond)
+			close(underlying.release)
+			wg.Wait()
+
+			assert.Equal(t, int32(1), underlying.calls.Load())
+			for i := 0; i < callers; i++ {
+				require.NoError(t, errs[i])
+				assert.Equal(t, results[0], results[i])
+			}
+		})
+	}
+}
+
+func TestCoalescingListingRepository_CallerCancelled(t *testing.T) {
+	underlying := &blockingListingRepository{release: make(chan struct{})}
+	repo := NewCoalescingListingRepository(underlying)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelledErr := make(chan error, 1)
+	go func() {
+		_, err := repo.GetByID(ctx, 7)
+		cancelledErr <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	otherResult := make(chan *Listing, 1)
+	otherErr := make(chan error, 1)
+	go func() {
+		listing, err := repo.GetByID(context.Background(), 7)
+		otherResult <- listing
+		otherErr <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// The first caller disconnecting leaves the shared read running for the other
+	cancel()
+	assert.ErrorIs(t, <-cancelledErr, context.Canceled)
+	close(underlying.release)
+	listing := <-otherResult
+	require.NoError(t, <-otherErr)
+	assert.Equal(t, int64(7), listing.ID)
+	assert.Equal(t, int32(1), underlying.calls.Load())
+}
+
+func TestCoalescingListingRepository_CopiesPerCaller(t *testing.T) {
+	underlying := &blockingListingRepository{release: make(chan struct{})}
+	repo := NewCoalescingListingRepository(underlying)
+
+	results := make([]*Listing, 2)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = repo.GetByID(context.Background(), 7)
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(underlying.release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), underlying.calls.Load())
+	require.NotNil(t, results[0])
+	require.NotNil(t, results[1])
+	results[0].Tags[0] = "changed"
+	results[0].PriceInCents = 1
+	assert.Equal(t, []string{"garden"}, results[1].Tags)
+	assert.Zero(t, results[1].PriceInCents)
+}
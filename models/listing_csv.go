@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ListingCSVHeader names the columns of a listing CSV row, in the order
+// returned by CSVRecord
+var ListingCSVHeader = []string{
+	"id", "status",
+	"addressLine1", "addressLine2", "city", "postcode", "shortenedPostcode", "country", "region",
+	"latitude", "longitude",
+	"propertyType", "bedrooms", "bathrooms", "sizeSqFt", "buildYear",
+	"priceInCents", "estimatedDepositInCents", "minimumDepositInCents", "monthlyRentalIncomeInCents",
+	"grossYield", "pricePerSqFtInCents",
+	"isCashOnly", "isCompany", "isFeatured", "isNewBuild", "isShareSale", "isTenanted",
+	"madeVisibleAt", "agentId", "tags", "photoCount", "description",
+}
+
+// CSVRecord flattens the listing into a CSV row matching ListingCSVHeader.
+// Unknown values such as missing coordinates are empty, tags are joined with
+// semicolons and photos are reduced to a count. Free text is made safe to open
+// in a spreadsheet, see spreadsheetText.
+func (l *Listing) CSVRecord() []string {
+	var latitude, longitude, pricePerSqFt, madeVisibleAt, agentID string
+	if coordinates := l.AddressDetails.Coordinates; coordinates != nil {
+		latitude = strconv.FormatFloat(coordinates.Latitude, 'f', -1, 64)
+		longitude = strconv.FormatFloat(coordinates.Longitude, 'f', -1, 64)
+	}
+	if value, ok := l.PricePerSqFtInCents(); ok {
+		pricePerSqFt = strconv.FormatInt(value, 10)
+	}
+	if l.MadeVisibleAt != nil {
+		madeVisibleAt = *l.MadeVisibleAt
+	}
+	if l.AgentID != 0 {
+		agentID = strconv.FormatInt(l.AgentID, 10)
+	}
+	address := l.AddressDetails
+	return []string{
+		strconv.FormatInt(l.ID, 10), string(l.Status),
+		spreadsheetText(address.AddressLine1), spreadsheetText(address.AddressLine2), spreadsheetText(address.City),
+		spreadsheetText(address.Postcode), spreadsheetText(address.ShortenedPostcode), spreadsheetText(address.Country),
+		string(address.Region),
+		latitude, longitude,
+		string(l.PropertyType), strconv.Itoa(l.Bedrooms), strconv.Itoa(l.Bathrooms), strconv.Itoa(l.SizeSqFt), strconv.Itoa(l.BuildYear),
+		strconv.FormatInt(l.PriceInCents, 10), strconv.FormatInt(l.EstimatedDepositInCents, 10),
+		strconv.FormatInt(l.MinimumDepositInCents, 10), strconv.FormatInt(l.MonthlyRentalIncomeInCents, 10),
+		strconv.FormatFloat(l.GrossYield, 'f', -1, 64), pricePerSqFt,
+		strconv.FormatBool(l.IsCashOnly), strconv.FormatBool(l.IsCompany), strconv.FormatBool(l.IsFeatured),
+		strconv.FormatBool(l.IsNewBuild), strconv.FormatBool(l.IsShareSale), strconv.FormatBool(l.IsTenanted),
+		madeVisibleAt, agentID, spreadsheetText(strings.Join(l.Tags, ";")), strconv.Itoa(len(l.Photos)),
+		spreadsheetText(l.Description),
+	}
+}
+
+// spreadsheetText stops free text being run as a formula when the CSV is
+// opened in a spreadsheet, by prefixing text that starts with a formula
+// character with an apostrophe
+func spreadsheetText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
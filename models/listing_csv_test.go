@@ -0,0 +1,70 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListing_CSVRecord(t *testing.T) {
+	visibleAt := "2024-03-01T12:00:00Z"
+	listing := &Listing{
+		ID:     7,
+		Status: ListingStatusPublished,
+		AddressDetails: AddressDetails{
+			AddressLine1:      "1 High Street",
+			City:              "London",
+			Postcode:          "N1 7AA",
+			ShortenedPostcode: "N1",
+			Country:           "UK",
+			Region:            RegionLondon,
+			Coordinates:       &Coordinates{Latitude: 51.5, Longitude: -0.1},
+		},
+		PropertyType:               PropertyTypeApartment,
+		Bedrooms:                   2,
+		Bathrooms:                  1,
+		SizeSqFt:                   800,
+		PriceInCents:               25000000,
+		EstimatedDepositInCents:    6250000,
+		MinimumDepositInCents:      2500000,
+		MonthlyRentalIncomeInCents: 132500,
+		GrossYield:                 0.0636,
+		IsTenanted:                 true,
+		MadeVisibleAt:              &visibleAt,
+		AgentID:                    3,
+		Tags:                       []string{"garden", "parking"},
+		Photos:                     []Photo{{ID: 1}, {ID: 2}},
+		Description:                "=HYPERLINK(\"http://example.com\")",
+	}
+
+	record := listing.CSVRecord()
+
+	require.Len(t, record, len(ListingCSVHeader))
+	fields := make(map[string]string, len(record))
+	for i, name := range ListingCSVHeader {
+		fields[name] = record[i]
+	}
+	assert.Equal(t, "7", fields["id"])
+	assert.Equal(t, "51.5", fields["latitude"])
+	assert.Equal(t, "-0.1", fields["longitude"])
+	assert.Equal(t, "25000000", fields["priceInCents"])
+	assert.Equal(t, "0.0636", fields["grossYield"])
+	assert.Equal(t, "31250", fields["pricePerSqFtInCents"])
+	assert.Equal(t, "true", fields["isTenanted"])
+	assert.Equal(t, "garden;parking", fields["tags"])
+	assert.Equal(t, "2", fields["photoCount"])
+	assert.Equal(t, `'=HYPERLINK("http://example.com")`, fields["description"])
+
+	t.Run("unknown values are empty", func(t *testing.T) {
+		record := (&Listing{ID: 8}).CSVRecord()
+		fields := make(map[string]string, len(record))
+		for i, name := range ListingCSVHeader {
+			fields[name] = record[i]
+		}
+		for _, name := range []string{"latitude", "longitude", "pricePerSqFtInCents", "madeVisibleAt", "agentId", "tags"} {
+			assert.Empty(t, fields[name], name)
+		}
+		assert.Equal(t, "0", fields["photoCount"])
+	})
+}
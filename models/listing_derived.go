@@ -0,0 +1,48 @@
+package models
+
+import "math"
+
+// ComputeGrossYield returns the annual rental income as a fraction of the price,
+// or 0 if the listing has no price
+func (l *Listing) ComputeGrossYield() float64 {
+	if l.PriceInCents <= 0 {
+		return 0
+	}
+	return float64(l.MonthlyRentalIncomeInCents*12) / float64(l.PriceInCents)
+}
+
+// ComputeEstimatedDeposit returns depositRate of the price, rounded to the
+// cent with rounding
+func (l *Listing) ComputeEstimatedDeposit(depositRate float64, rounding RoundingMode) int64 {
+	return rounding.MulRate(l.PriceInCents, depositRate)
+}
+
+// RecomputeDerivedFields refreshes the fields derived from the price and rent,
+// reporting whether any of them changed
+func (l *Listing) RecomputeDerivedFields(depositRate float64, rounding RoundingMode) bool {
+	grossYield := l.ComputeGrossYield()
+	deposit := l.ComputeEstimatedDeposit(depositRate, rounding)
+	changed := grossYield != l.GrossYield || deposit != l.EstimatedDepositInCents
+	l.GrossYield = grossYield
+	l.EstimatedDepositInCents = deposit
+	return changed
+}
+
+// VsMedianPercent returns how far the price is above (positive) or below
+// (negative) the median price as a percentage rounded to 2 decimals
+func (l *Listing) VsMedianPercent(medianPriceInCents float64) float64 {
+	if medianPriceInCents <= 0 {
+		return 0
+	}
+	percent := (float64(l.PriceInCents) - medianPriceInCents) / medianPriceInCents * 100
+	return math.Round(percent*100) / 100
+}
+
+// WithVsRegionMedian returns a copy of the listing that encodes a
+// vsRegionMedian field comparing its price to the region's median price
+func (l *Listing) WithVsRegionMedian(medianPriceInCents float64) *Listing {
+	listing := *l
+	vsMedian := l.VsMedianPercent(medianPriceInCents)
+	listing.vsRegionMedian = &vsMedian
+	return &listing
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stampDutyExemptBelowInCents is the price below which additional properties
+// pay no stamp duty at all
+const stampDutyExemptBelowInCents = 4000000
+
+// stampDutyBand charges Rate percent on the part of the price up to UpToInCents.
+// An UpToInCents of 0 means no upper limit.
+type stampDutyBand struct {
+	UpToInCents int64
+	Rate        int64
+}
+
+// stampDutyBands are the England and Northern Ireland SDLT rates for an
+// additional residential property, which include the 5% higher rates
+// surcharge that applies to buy-to-let purchases
+var stampDutyBands = []stampDutyBand{
+	{UpToInCents: 12500000, Rate: 5},
+	{UpToInCents: 25000000, Rate: 7},
+	{UpToInCents: 92500000, Rate: 10},
+	{UpToInCents: 150000000, Rate: 15},
+	{UpToInCents: 0, Rate: 17},
+}
+
+// ComputeStampDuty returns the stamp duty due on an additional property at the
+// given price, rounded down to the whole pound as HMRC does
+func ComputeStampDuty(priceInCents int64) int64 {
+	if priceInCents < stampDutyExemptBelowInCents {
+		return 0
+	}
+	// duty is in hundredths of a cent so the bands sum exactly before rounding
+	var duty, lower int64
+	for _, band := range stampDutyBands {
+		upper := band.UpToInCents
+		if upper == 0 || upper > priceInCents {
+			upper = priceInCents
+		}
+		if upper > lower {
+			duty += (upper - lower) * band.Rate
+		}
+		if upper == priceInCents {
+			break
+		}
+		lower = upper
+	}
+	return RoundDown.Div(duty, 100*100) * 100
+}
+
+// StampDutyInCents returns the stamp duty due on buying the listing
+func (l *Listing) StampDutyInCents() int64 {
+	return ComputeStampDuty(l.PriceInCents)
+}
+
+// ComputeNetYield returns the annual rental income as a fraction of the total
+// purchase cost including stamp duty, or 0 if the listing has no price
+func (l *Listing) ComputeNetYield() float64 {
+	cost := l.PriceInCents + l.StampDutyInCents()
+	if cost <= 0 {
+		return 0
+	}
+	return float64(l.MonthlyRentalIncomeInCents*12) / float64(cost)
+}
+
+// PricePerSqFt returns the price in cents divided by the size in square feet,
+// or 0 if the size is unknown
+func (l *Listing) PricePerSqFt() float64 {
+	if l.SizeSqFt <= 0 {
+		return 0
+	}
+	return float64(l.PriceInCents) / float64(l.SizeSqFt)
+}
+
+// PricePerSqFtInCents returns the price divided by the size rounded to the
+// cent with StandardRounding, and false if the size is unknown
+func (l *Listing) PricePerSqFtInCents() (int64, bool) {
+	if l.SizeSqFt <= 0 {
+		return 0, false
+	}
+	return StandardRounding.Div(l.PriceInCents, int64(l.SizeSqFt)), true
+}
+
+// FormatPrice renders cents as pounds with thousands separators, e.g.
+// £250,000 or £1,234.50 when there are pence
+func FormatPrice(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	pounds := strconv.FormatInt(cents/100, 10)
+	var grouped strings.Builder
+	for i, digit := range pounds {
+		if i > 0 && (len(pounds)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	formatted := sign + "£" + grouped.String()
+	if pence := cents % 100; pence != 0 {
+		formatted += "." + strconv.FormatInt(pence+100, 10)[1:]
+	}
+	return formatted
+}
+
+// ListingExport is a self-contained document holding a listing and every field
+// derived from it, for offline analysis
+type ListingExport struct {
+	Listing             *Listing `json:"listing"`
+	FormattedPrice      string   `json:"formattedPrice"`
+	StampDutyInCents    int64    `json:"stampDutyInCents"`
+	GrossYield          float64  `json:"grossYield"`
+	NetYield            float64  `json:"netYield"`
+	PricePerSqFtInCents *int64   `json:"pricePerSqFtInCents"`
+	DaysOnMarket        *int     `json:"daysOnMarket"`
+	ExportedAt          string   `json:"exportedAt"`
+}
+
+// NewListingExport computes the derived fields of a listing as of now. Fields
+// that can't be derived, such as price per sq ft without a size, are nil.
+func NewListingExport(listing *Listing, now time.Time) ListingExport {
+	export := ListingExport{
+		Listing:          listing,
+		FormattedPrice:   FormatPrice(listing.PriceInCents),
+		StampDutyInCents: listing.StampDutyInCents(),
+		GrossYield:       listing.ComputeGrossYield(),
+		NetYield:         listing.ComputeNetYield(),
+		ExportedAt:       now.UTC().Format(time.RFC3339),
+	}
+	if pricePerSqFt, ok := listing.PricePerSqFtInCents(); ok {
+		export.PricePerSqFtInCents = &pricePerSqFt
+	}
+	if days, ok := listing.DaysOnMarket(now); ok {
+		export.DaysOnMarket = &days
+	}
+	return export
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStampDuty(t *testing.T) {
+	tests := []struct {
+		name         string
+		priceInCents int64
+		expected     int64
+	}{
+		{name: "below the exemption threshold", priceInCents: 3999999, expected: 0},
+		{name: "first band only", priceInCents: 10000000, expected: 500000},
+		{name: "top of the second band", priceInCents: 25000000, expected: 1500000},
+		{name: "third band", priceInCents: 30000000, expected: 2000000},
+		{name: "every band", priceInCents: 200000000, expected: 25375000},
+		{name: "rounds down to the pound", priceInCents: 12500099, expected: 625000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ComputeStampDuty(tt.priceInCents))
+		})
+	}
+}
+
+func TestFormatPrice(t *testing.T) {
+	tests := []struct {
+		cents    int64
+		expected string
+	}{
+		{cents: 0, expected: "£0"},
+		{cents: 99900, expected: "£999"},
+		{cents: 25000000, expected: "£250,000"},
+		{cents: 123456789, expected: "£1,234,567.89"},
+		{cents: 123405, expected: "£1,234.05"},
+		{cents: -150000, expected: "-£1,500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatPrice(tt.cents))
+		})
+	}
+}
+
+func TestNewListingExport(t *testing.T) {
+	now := time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+	visibleAt := "2024-03-01T12:00:00Z"
+	listing := &Listing{
+		ID:                         1,
+		PriceInCents:               25000000,
+		MonthlyRentalIncomeInCents: 132500,
+		SizeSqFt:                   800,
+		MadeVisibleAt:              &visibleAt,
+	}
+
+	export := NewListingExport(listing, now)
+
+	assert.Same(t, listing, export.Listing)
+	assert.Equal(t, "£250,000", export.FormattedPrice)
+	assert.Equal(t, int64(1500000), export.StampDutyInCents)
+	assert.InDelta(t, 0.0636, export.GrossYield, 1e-9)
+	assert.InDelta(t, 1590000.0/26500000.0, export.NetYield, 1e-9)
+	require.NotNil(t, export.PricePerSqFtInCents)
+	assert.Equal(t, int64(31250), *export.PricePerSqFtInCents)
+	require.NotNil(t, export.DaysOnMarket)
+	assert.Equal(t, 10, *export.DaysOnMarket)
+	assert.Equal(t, "2024-03-11T12:00:00Z", export.ExportedAt)
+
+	t.Run("fields that can't be derived are null", func(t *testing.T) {
+		export := NewListingExport(&Listing{ID: 2}, now)
+
+		data, err := json.Marshal(export)
+		require.NoError(t, err)
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		for _, field := range []string{"listing", "formattedPrice", "stampDutyInCents", "grossYield", "netYield", "pricePerSqFtInCents", "daysOnMarket", "exportedAt"} {
+			assert.Contains(t, decoded, field)
+		}
+		assert.Nil(t, decoded["pricePerSqFtInCents"])
+		assert.Nil(t, decoded["daysOnMarket"])
+		assert.Equal(t, 0.0, decoded["netYield"])
+	})
+}
+
+func TestListing_PricePerSqFt(t *testing.T) {
+	tests := []struct {
+		name     string
+		listing  Listing
+		expected float64
+	}{
+		{name: "known size", listing: Listing{PriceInCents: 25000000, SizeSqFt: 800}, expected: 31250},
+		{name: "tiny size", listing: Listing{PriceInCents: 35000000, SizeSqFt: 32}, expected: 1093750},
+		{name: "zero size", listing: Listing{PriceInCents: 25000000}, expected: 0},
+		{name: "negative size", listing: Listing{PriceInCents: 25000000, SizeSqFt: -5}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.listing.PricePerSqFt())
+		})
+	}
+
+	t.Run("encoded read-only", func(t *testing.T) {
+		data, err := json.Marshal(Listing{PriceInCents: 25000000, SizeSqFt: 800})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"pricePerSqFtInCents":31250`)
+
+		data, err = json.Marshal(Listing{PriceInCents: 25000000})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"pricePerSqFtInCents":null`)
+
+		var decoded Listing
+		require.NoError(t, json.Unmarshal([]byte(`{"priceInCents":100,"pricePerSqFtInCents":5}`), &decoded))
+		assert.Equal(t, float64(0), decoded.PricePerSqFt())
+	})
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ListingFilter combines several criteria for ListingRepository.Search. A nil
+// field doesn't filter on that criterion; a listing must meet every set one.
+type ListingFilter struct {
+	Region       *Region
+	PropertyType *PropertyType
+	// City matches listings whose city contains it, ignoring case
+	City         *string
+	MinPrice     *int64
+	MaxPrice     *int64
+	MinBedrooms  *int
+	MaxBedrooms  *int
+	MinBathrooms *int
+	MaxBathrooms *int
+	// MinSize and MaxSize bound the size in square feet
+	MinSize *int
+	MaxSize *int
+	ListingFlags
+}
+
+// ListingFlags selects listings by their boolean attributes. A nil flag
+// doesn't filter; a listing must match every flag that is set.
+type ListingFlags struct {
+	IsTenanted  *bool
+	IsCashOnly  *bool
+	IsNewBuild  *bool
+	IsShareSale *bool
+	IsCompany   *bool
+}
+
+// IsZero reports whether no flag is set
+func (f ListingFlags) IsZero() bool {
+	return f == ListingFlags{}
+}
+
+// Matches reports whether the listing has every flag that is set
+func (f ListingFlags) Matches(listing *Listing) bool {
+	return flagMatches(listing.IsTenanted, f.IsTenanted) &&
+		flagMatches(listing.IsCashOnly, f.IsCashOnly) &&
+		flagMatches(listing.IsNewBuild, f.IsNewBuild) &&
+		flagMatches(listing.IsShareSale, f.IsShareSale) &&
+		flagMatches(listing.IsCompany, f.IsCompany)
+}
+
+// Validate checks that the region and property type are known values and that
+// no range has its minimum above its maximum
+func (f ListingFilter) Validate() error {
+	if f.Region != nil && !f.Region.IsValid() {
+		return errors.Errorf("unknown region %q", *f.Region)
+	}
+	if f.PropertyType != nil && !f.PropertyType.IsValid() {
+		return errors.Errorf("unknown property type %q", *f.PropertyType)
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return errors.New("minPrice cannot be greater than maxPrice")
+	}
+	if f.MinBedrooms != nil && f.MaxBedrooms != nil && *f.MinBedrooms > *f.MaxBedrooms {
+		return errors.New("minBedrooms cannot be greater than maxBedrooms")
+	}
+	if f.MinBathrooms != nil && f.MaxBathrooms != nil && *f.MinBathrooms > *f.MaxBathrooms {
+		return errors.New("minBathrooms cannot be greater than maxBathrooms")
+	}
+	if f.MinSize != nil && f.MaxSize != nil && *f.MinSize > *f.MaxSize {
+		return errors.New("minSize cannot be greater than maxSize")
+	}
+	return nil
+}
+
+// Matches reports whether the listing meets every criterion that is set
+func (f ListingFilter) Matches(listing *Listing) bool {
+	if f.Region != nil && listing.AddressDetails.Region != *f.Region {
+		return false
+	}
+	if f.PropertyType != nil && listing.PropertyType != *f.PropertyType {
+		return false
+	}
+	if f.City != nil && !strings.Contains(strings.ToLower(listing.AddressDetails.City), strings.ToLower(*f.City)) {
+		return false
+	}
+	return inRange(listing.PriceInCents, f.MinPrice, f.MaxPrice) &&
+		inRange(listing.Bedrooms, f.MinBedrooms, f.MaxBedrooms) &&
+		inRange(listing.Bathrooms, f.MinBathrooms, f.MaxBathrooms) &&
+		inRange(listing.SizeSqFt, f.MinSize, f.MaxSize) &&
+		f.ListingFlags.Matches(listing)
+}
+
+// inRange reports whether value is within the inclusive bounds that are set
+func inRange[T int | int64](value T, min, max *T) bool {
+	return (min == nil || value >= *min) && (max == nil || value <= *max)
+}
+
+// flagMatches reports whether value equals want, or true if want is not set
+func flagMatches(value bool, want *bool) bool {
+	return want == nil || value == *want
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// defaultGrossYieldPercentDecimals is the display precision used until configured
+const defaultGrossYieldPercentDecimals = 2
+
+// grossYieldPercentDecimals is how many decimals grossYieldPercent is rounded to
+var grossYieldPercentDecimals atomic.Int32
+
+func init() {
+	grossYieldPercentDecimals.Store(defaultGrossYieldPercentDecimals)
+}
+
+// SetGrossYieldPercentDecimals sets how many decimals the grossYieldPercent
+// field is rounded to when listings are encoded. Negative values mean 0.
+func SetGrossYieldPercentDecimals(decimals int) {
+	grossYieldPercentDecimals.Store(int32(max(decimals, 0)))
+}
+
+// GrossYieldPercent returns the gross yield as a percentage rounded to decimals
+// places, e.g. 0.0822486 with 2 decimals is 8.22
+func (l *Listing) GrossYieldPercent(decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(l.GrossYield*100*scale) / scale
+}
+
+// plainListing is Listing without its JSON methods
+type plainListing Listing
+
+// encodedListing is the JSON form of a listing. Response types that add
+// fields to a listing embed it so they encode the same fields.
+type encodedListing struct {
+	plainListing
+	GrossYieldPercent   float64  `json:"grossYieldPercent"`
+	PricePerSqFtInCents *int64   `json:"pricePerSqFtInCents"`
+	VsRegionMedian      *float64 `json:"vsRegionMedian,omitempty"`
+}
+
+// encoded returns the listing's JSON form, see MarshalJSON
+func (l Listing) encoded() encodedListing {
+	var pricePerSqFt *int64
+	if value, ok := l.PricePerSqFtInCents(); ok {
+		pricePerSqFt = &value
+	}
+	return encodedListing{
+		plainListing:        plainListing(l),
+		GrossYieldPercent:   l.GrossYieldPercent(int(grossYieldPercentDecimals.Load())),
+		PricePerSqFtInCents: pricePerSqFt,
+		VsRegionMedian:      l.vsRegionMedian,
+	}
+}
+
+// MarshalJSON encodes a listing with a grossYieldPercent display field
+// alongside the raw grossYield, the read-only pricePerSqFtInCents (null when
+// the size is unknown), plus vsRegionMedian when it has been computed
+func (l Listing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.encoded())
+}
+
+// UnmarshalJSON decodes a listing, accepting the cents fields either as JSON
+// numbers or as numeric strings (e.g. "12500000"), which some serializers emit.
+// It also notes whether isNewBuild was given so it can take precedence over
+// the build year.
+func (l *Listing) UnmarshalJSON(data []byte) error {
+	type listingAlias Listing
+	aux := struct {
+		*listingAlias
+		EstimatedDepositInCents    json.RawMessage `json:"estimatedDepositInCents"`
+		MinimumDepositInCents      json.RawMessage `json:"minimumDepositInCents"`
+		PriceInCents               json.RawMessage `json:"priceInCents"`
+		MonthlyRentalIncomeInCents json.RawMessage `json:"monthlyRentalIncomeInCents"`
+		IsNewBuild                 *bool           `json:"isNewBuild"`
+	}{
+		listingAlias: (*listingAlias)(l),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.IsNewBuild != nil {
+		l.IsNewBuild = *aux.IsNewBuild
+		l.newBuildExplicit = true
+	}
+
+	fields := []struct {
+		name  string
+		raw   json.RawMessage
+		value *int64
+	}{
+		{"estimatedDepositInCents", aux.EstimatedDepositInCents, &l.EstimatedDepositInCents},
+		{"minimumDepositInCents", aux.MinimumDepositInCents, &l.MinimumDepositInCents},
+		{"priceInCents", aux.PriceInCents, &l.PriceInCents},
+		{"monthlyRentalIncomeInCents", aux.MonthlyRentalIncomeInCents, &l.MonthlyRentalIncomeInCents},
+	}
+	for _, field := range fields {
+		if err := parseCents(field.name, field.raw, field.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCents parses a whole number of cents from a JSON number or numeric string,
+// leaving value untouched when the field is absent or null
+func parseCents(name string, raw json.RawMessage, value *int64) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	text := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return errors.Errorf("%s must be a whole number of cents, got %s", name, raw)
+		}
+		text = strings.TrimSpace(text)
+	}
+	cents, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return errors.Errorf("%s must be a whole number of cents, got %s", name, raw)
+	}
+	*value = cents
+	return nil
+}
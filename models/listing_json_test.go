@@ -0,0 +1,199 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListing_UnmarshalJSON_Cents(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedPrice int64
+		wantErr       bool
+		errMsg        string
+	}{
+		{
+			name:          "numeric price",
+			body:          `{"priceInCents": 12500000}`,
+			expectedPrice: 12500000,
+		},
+		{
+			name:          "string price",
+			body:          `{"priceInCents": "12500000"}`,
+			expectedPrice: 12500000,
+		},
+		{
+			name:          "string price with whitespace",
+			body:          `{"priceInCents": " 12500000 "}`,
+			expectedPrice: 12500000,
+		},
+		{
+			name:          "null price",
+			body:          `{"priceInCents": null}`,
+			expectedPrice: 0,
+		},
+		{
+			name:    "non-numeric string price",
+			body:    `{"priceInCents": "lots"}`,
+			wantErr: true,
+			errMsg:  `priceInCents must be a whole number of cents, got "lots"`,
+		},
+		{
+			name:    "fractional price",
+			body:    `{"priceInCents": 125.5}`,
+			wantErr: true,
+			errMsg:  "priceInCents must be a whole number of cents, got 125.5",
+		},
+		{
+			name:    "boolean price",
+			body:    `{"priceInCents": true}`,
+			wantErr: true,
+			errMsg:  "priceInCents must be a whole number of cents, got true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var listing Listing
+			err := json.Unmarshal([]byte(tt.body), &listing)
+			if tt.wantErr {
+				assert.EqualError(t, err, tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPrice, listing.PriceInCents)
+			}
+		})
+	}
+}
+
+func TestListing_UnmarshalJSON_AllFields(t *testing.T) {
+	body := `{
+		"id": 187,
+		"addressDetails": {"city": "London", "region": "London"},
+		"bedrooms": 1,
+		"priceInCents": "12500000",
+		"minimumDepositInCents": 1000000,
+		"estimatedDepositInCents": "3125000",
+		"monthlyRentalIncomeInCents": 110000,
+		"photos": [{"originalURL": "https://example.com/a.png"}]
+	}`
+
+	var listing Listing
+	err := json.Unmarshal([]byte(body), &listing)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(187), listing.ID)
+	assert.Equal(t, "London", listing.AddressDetails.City)
+	assert.Equal(t, RegionLondon, listing.AddressDetails.Region)
+	assert.Equal(t, 1, listing.Bedrooms)
+	assert.Equal(t, int64(12500000), listing.PriceInCents)
+	assert.Equal(t, int64(1000000), listing.MinimumDepositInCents)
+	assert.Equal(t, int64(3125000), listing.EstimatedDepositInCents)
+	assert.Equal(t, int64(110000), listing.MonthlyRentalIncomeInCents)
+	assert.Len(t, listing.Photos, 1)
+}
+
+func TestListing_GrossYieldPercent(t *testing.T) {
+	tests := []struct {
+		name       string
+		grossYield float64
+		decimals   int
+		expected   float64
+	}{
+		{name: "two decimals", grossYield: 0.0822486, decimals: 2, expected: 8.22},
+		{name: "rounds half up", grossYield: 0.166667, decimals: 2, expected: 16.67},
+		{name: "one decimal", grossYield: 0.102316, decimals: 1, expected: 10.2},
+		{name: "whole percent", grossYield: 0.1056, decimals: 0, expected: 11},
+		{name: "exact value", grossYield: 0.036, decimals: 3, expected: 3.6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listing := &Listing{GrossYield: tt.grossYield}
+			assert.Equal(t, tt.expected, listing.GrossYieldPercent(tt.decimals))
+		})
+	}
+}
+
+func TestListing_MarshalJSON_GrossYieldPercent(t *testing.T) {
+	defer SetGrossYieldPercentDecimals(defaultGrossYieldPercentDecimals)
+
+	listing := &Listing{ID: 1, GrossYield: 0.0822486}
+
+	data, err := json.Marshal(listing)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 0.0822486, decoded["grossYield"])
+	assert.Equal(t, 8.22, decoded["grossYieldPercent"])
+
+	SetGrossYieldPercentDecimals(1)
+	data, err = json.Marshal(listing)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 8.2, decoded["grossYieldPercent"])
+}
+
+func TestListing_DeriveNewBuild(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		json     string
+		expected bool
+	}{
+		{name: "built this year", json: `{"buildYear":2025}`, expected: true},
+		{name: "built at the limit", json: `{"buildYear":2023}`, expected: true},
+		{name: "built before the limit", json: `{"buildYear":2022}`, expected: false},
+		{name: "explicit false wins over recent build year", json: `{"buildYear":2025,"isNewBuild":false}`, expected: false},
+		{name: "explicit true wins over old build year", json: `{"buildYear":1990,"isNewBuild":true}`, expected: true},
+		{name: "unknown build year keeps flag", json: `{}`, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var listing Listing
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &listing))
+
+			listing.DeriveNewBuild(now, 2)
+
+			assert.Equal(t, tt.expected, listing.IsNewBuild)
+		})
+	}
+}
+
+func TestListingResponse_MarshalJSON(t *testing.T) {
+	t.Run("listing", func(t *testing.T) {
+		data, err := json.Marshal(NewListingResponse(&Listing{ID: 187}))
+		require.NoError(t, err)
+
+		var decoded map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.JSONEq(t, `"listing"`, string(decoded["type"]))
+		assert.Equal(t, "null", string(decoded["development"]))
+		assert.NotEqual(t, "null", string(decoded["listing"]))
+	})
+
+	t.Run("development", func(t *testing.T) {
+		data, err := json.Marshal(NewDevelopmentResponse(&Development{}))
+		require.NoError(t, err)
+
+		var decoded map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.JSONEq(t, `"development"`, string(decoded["type"]))
+		assert.Equal(t, "null", string(decoded["listing"]))
+		assert.Equal(t, "{}", string(decoded["development"]))
+	})
+}
+
+func TestNewDetailResponse(t *testing.T) {
+	listing := &Listing{ID: 187}
+
+	assert.Equal(t, NewListingResponse(listing), NewDetailResponse(listing, nil))
+	assert.Equal(t, NewDevelopmentResponse(&Development{}), NewDetailResponse(listing, &Development{}))
+}
@@ -0,0 +1,812 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// PostgresListingRepository implements ListingRepository on PostgreSQL through
+// database/sql, so listings survive restarts and can be shared by several
+// instances. The schema is created by MigratePostgres: listings, their photos
+// and their price changes each have a table.
+type PostgresListingRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresListingRepository creates a listing repository on db, which must
+// already have been migrated with MigratePostgres
+func NewPostgresListingRepository(db *sql.DB) *PostgresListingRepository {
+	return &PostgresListingRepository{db: db}
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// listingFields are the listings columns other than id, in the order
+// listingArgs returns their values
+const listingFields = `status, address_line1, address_line2, city, postcode, shortened_postcode,
+	country, region, latitude, longitude, dedupe_key, bedrooms, bathrooms, description,
+	gross_yield, is_cash_only, is_company, is_featured, is_new_build, is_share_sale,
+	is_tenanted, made_visible_at, estimated_deposit_in_cents, minimum_deposit_in_cents,
+	price_in_cents, property_type, monthly_rental_income_in_cents, size_sq_ft, build_year,
+	agent_id, tags, created_at, updated_at, deleted_at`
+
+// listingFieldCount is the number of columns in listingFields
+const listingFieldCount = 34
+
+const selectListings = `SELECT id, ` + listingFields + ` FROM listings`
+
+// notDeleted keeps soft-deleted listings out of a query
+const notDeleted = `deleted_at IS NULL`
+
+// listingArgs returns the listing's values for the columns in listingFields
+func listingArgs(listing *Listing) []any {
+	address := listing.AddressDetails
+	var latitude, longitude sql.NullFloat64
+	if address.Coordinates != nil {
+		latitude = sql.NullFloat64{Float64: address.Coordinates.Latitude, Valid: true}
+		longitude = sql.NullFloat64{Float64: address.Coordinates.Longitude, Valid: true}
+	}
+	var dedupeKey sql.NullString
+	if key, ok := address.dedupeKey(); ok {
+		dedupeKey = sql.NullString{String: key, Valid: true}
+	}
+	var madeVisibleAt, deletedAt sql.NullString
+	if listing.MadeVisibleAt != nil {
+		madeVisibleAt = sql.NullString{String: *listing.MadeVisibleAt, Valid: true}
+	}
+	if listing.DeletedAt != nil {
+		deletedAt = sql.NullString{String: *listing.DeletedAt, Valid: true}
+	}
+	return []any{
+		string(listing.Status), address.AddressLine1, address.AddressLine2, address.City,
+		address.Postcode, address.ShortenedPostcode, address.Country, string(address.Region),
+		latitude, longitude, dedupeKey, listing.Bedrooms, listing.Bathrooms, listing.Description,
+		listing.GrossYield, listing.IsCashOnly, listing.IsCompany, listing.IsFeatured,
+		listing.IsNewBuild, listing.IsShareSale, listing.IsTenanted, madeVisibleAt,
+		listing.EstimatedDepositInCents, listing.MinimumDepositInCents, listing.PriceInCents,
+		string(listing.PropertyType), listing.MonthlyRentalIncomeInCents, listing.SizeSqFt,
+		listing.BuildYear, listing.AgentID, pq.Array(listing.Tags), listing.CreatedAt,
+		listing.UpdatedAt, deletedAt,
+	}
+}
+
+// scanListing reads a row selected with selectListings
+func scanListing(rows *sql.Rows) (*Listing, error) {
+	var (
+		listing             Listing
+		latitude, longitude sql.NullFloat64
+		dedupeKey           sql.NullString
+		madeVisibleAt       sql.NullString
+		deletedAt           sql.NullString
+		tags                []string
+	)
+	address := &listing.AddressDetails
+	err := rows.Scan(
+		&listing.ID, &listing.Status, &address.AddressLine1, &address.AddressLine2, &address.City,
+		&address.Postcode, &address.ShortenedPostcode, &address.Country, &address.Region,
+		&latitude, &longitude, &dedupeKey, &listing.Bedrooms, &listing.Bathrooms, &listing.Description,
+		&listing.GrossYield, &listing.IsCashOnly, &listing.IsCompany, &listing.IsFeatured,
+		&listing.IsNewBuild, &listing.IsShareSale, &listing.IsTenanted, &madeVisibleAt,
+		&listing.EstimatedDepositInCents, &listing.MinimumDepositInCents, &listing.PriceInCents,
+		&listing.PropertyType, &listing.MonthlyRentalIncomeInCents, &listing.SizeSqFt,
+		&listing.BuildYear, &listing.AgentID, pq.Array(&tags), &listing.CreatedAt,
+		&listing.UpdatedAt, &deletedAt,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan listing")
+	}
+	if latitude.Valid && longitude.Valid {
+		address.Coordinates = &Coordinates{Latitude: latitude.Float64, Longitude: longitude.Float64}
+	}
+	if madeVisibleAt.Valid {
+		listing.MadeVisibleAt = &madeVisibleAt.String
+	}
+	if deletedAt.Valid {
+		listing.DeletedAt = &deletedAt.String
+	}
+	if len(tags) > 0 {
+		listing.Tags = tags
+	}
+	return &listing, nil
+}
+
+// placeholders returns n comma-separated placeholders numbered from first
+func placeholders(first, n int) string {
+	params := make([]string, n)
+	for i := range params {
+		params[i] = fmt.Sprintf("$%d", first+i)
+	}
+	return strings.Join(params, ", ")
+}
+
+// sqlConditions builds a WHERE clause, numbering the placeholders as
+// conditions are added
+type sqlConditions struct {
+	clauses []string
+	args    []any
+}
+
+// add appends a condition with a single "?" standing for arg
+func (c *sqlConditions) add(clause string, arg any) {
+	c.args = append(c.args, arg)
+	c.clauses = append(c.clauses, strings.Replace(clause, "?", fmt.Sprintf("$%d", len(c.args)), 1))
+}
+
+// addFlags adds a condition for every flag that is set
+func (c *sqlConditions) addFlags(flags ListingFlags) {
+	columns := []struct {
+		column string
+		want   *bool
+	}{
+		{"is_tenanted", flags.IsTenanted},
+		{"is_cash_only", flags.IsCashOnly},
+		{"is_new_build", flags.IsNewBuild},
+		{"is_share_sale", flags.IsShareSale},
+		{"is_company", flags.IsCompany},
+	}
+	for _, flag := range columns {
+		if flag.want != nil {
+			c.add(flag.column+" = ?", *flag.want)
+		}
+	}
+}
+
+// where returns the conditions joined with AND, or TRUE if there are none
+func (c *sqlConditions) where() string {
+	if len(c.clauses) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(c.clauses, " AND ")
+}
+
+// query returns the listings that aren't deleted and match where, ordered by
+// ID, with their photos
+func (r *PostgresListingRepository) query(ctx context.Context, q queryer, where string, args ...any) ([]*Listing, error) {
+	return collectListings(ctx, q, selectListings+" WHERE "+notDeleted+" AND ("+where+") ORDER BY id", args...)
+}
+
+// collectListings runs a query selecting listing rows and loads their photos
+func collectListings(ctx context.Context, q queryer, query string, args ...any) ([]*Listing, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query listings")
+	}
+	defer rows.Close()
+
+	listings := make([]*Listing, 0)
+	for rows.Next() {
+		listing, err := scanListing(rows)
+		if err != nil {
+			return nil, err
+		}
+		listings = append(listings, listing)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read listings")
+	}
+	if err := loadPhotos(ctx, q, listings); err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+// loadPhotos fills in the photos of every listing, in position order
+func loadPhotos(ctx context.Context, q queryer, listings []*Listing) error {
+	if len(listings) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*Listing, len(listings))
+	ids := make([]int64, len(listings))
+	for i, listing := range listings {
+		byID[listing.ID] = listing
+		ids[i] = listing.ID
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT listing_id, id, position, original_url, standard_url, thumbnail_url, mime_type
+		FROM photos WHERE listing_id = ANY($1) ORDER BY listing_id, position`, pq.Array(ids))
+	if err != nil {
+		return errors.Wrap(err, "failed to query photos")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var listingID int64
+		var photo Photo
+		if err := rows.Scan(&listingID, &photo.ID, &photo.Position, &photo.OriginalURL,
+			&photo.StandardURL, &photo.ThumbnailURL, &photo.MimeType); err != nil {
+			return errors.Wrap(err, "failed to scan photo")
+		}
+		listing := byID[listingID]
+		listing.Photos = append(listing.Photos, photo)
+	}
+	return errors.Wrap(rows.Err(), "failed to read photos")
+}
+
+// lock returns the listing with the given ID, locking its row until the
+// transaction ends, or ErrNotFound if there is none or it is deleted
+func (r *PostgresListingRepository) lock(ctx context.Context, tx *sql.Tx, id int64) (*Listing, error) {
+	listings, err := collectListings(ctx, tx, selectListings+" WHERE id = $1 AND "+notDeleted+" FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(listings) == 0 {
+		return nil, errors.Wrapf(ErrNotFound, "listing not found with id: %d", id)
+	}
+	return listings[0], nil
+}
+
+// inTx runs fn in a transaction, committing if it succeeds
+func (r *PostgresListingRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "failed to commit transaction")
+}
+
+// insert stores a new listing under a generated ID, which is set on listing
+func (r *PostgresListingRepository) insert(ctx context.Context, tx *sql.Tx, listing *Listing) error {
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO listings (`+listingFields+`) VALUES (`+placeholders(1, listingFieldCount)+`) RETURNING id`,
+		listingArgs(listing)...,
+	).Scan(&listing.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert listing")
+	}
+	return r.replacePhotos(ctx, tx, listing)
+}
+
+// insertWithID stores a new listing under its own ID, moving the ID sequence
+// past it so it is never generated
+func (r *PostgresListingRepository) insertWithID(ctx context.Context, tx *sql.Tx, listing *Listing) error {
+	args := append([]any{listing.ID}, listingArgs(listing)...)
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO listings (id, `+listingFields+`) VALUES (`+placeholders(1, listingFieldCount+1)+`)`,
+		args...,
+	); err != nil {
+		return errors.Wrapf(err, "failed to insert listing %d", listing.ID)
+	}
+	if err := r.reserveID(ctx, tx, listing.ID); err != nil {
+		return err
+	}
+	return r.replacePhotos(ctx, tx, listing)
+}
+
+// reserveID moves the listings ID sequence to at least id
+func (r *PostgresListingRepository) reserveID(ctx context.Context, tx *sql.Tx, id int64) error {
+	_, err := tx.ExecContext(ctx, `SELECT setval(pg_get_serial_sequence('listings', 'id'),
+		GREATEST($1::bigint, nextval(pg_get_serial_sequence('listings', 'id'))))`, id)
+	return errors.Wrap(err, "failed to reserve listing id")
+}
+
+// updateRow overwrites the stored listing with the same ID
+func (r *PostgresListingRepository) updateRow(ctx context.Context, tx *sql.Tx, listing *Listing) error {
+	args := append([]any{listing.ID}, listingArgs(listing)...)
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE listings SET (`+listingFields+`) = ROW(`+placeholders(2, listingFieldCount)+`) WHERE id = $1`,
+		args...,
+	); err != nil {
+		return errors.Wrapf(err, "failed to update listing %d", listing.ID)
+	}
+	return r.replacePhotos(ctx, tx, listing)
+}
+
+// replacePhotos swaps the stored photos of the listing for its current ones
+func (r *PostgresListingRepository) replacePhotos(ctx context.Context, tx *sql.Tx, listing *Listing) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM photos WHERE listing_id = $1`, listing.ID); err != nil {
+		return errors.Wrapf(err, "failed to clear photos of listing %d", listing.ID)
+	}
+	for _, photo := range listing.Photos {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO photos (listing_id, id, position, original_url, standard_url, thumbnail_url, mime_type)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			listing.ID, photo.ID, photo.Position, photo.OriginalURL, photo.StandardURL, photo.ThumbnailURL, photo.MimeType,
+		); err != nil {
+			return errors.Wrapf(err, "failed to store photo %d of listing %d", photo.ID, listing.ID)
+		}
+	}
+	return nil
+}
+
+// update replaces existing with listing, recording a price change if the price
+// differs
+func (r *PostgresListingRepository) update(ctx context.Context, tx *sql.Tx, existing, listing *Listing) error {
+	if existing.PriceInCents != listing.PriceInCents {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO price_changes (listing_id, changed_at, old_price_in_cents, new_price_in_cents)
+			VALUES ($1, $2, $3, $4)`,
+			listing.ID, time.Now(), existing.PriceInCents, listing.PriceInCents,
+		); err != nil {
+			return errors.Wrapf(err, "failed to record price change of listing %d", listing.ID)
+		}
+	}
+	prepareUpdate(existing, listing, time.Now())
+	return r.updateRow(ctx, tx, listing)
+}
+
+// Create adds a new listing, setting its ID
+func (r *PostgresListingRepository) Create(ctx context.Context, listing *Listing) error {
+	listing.AddressDetails.NormalizePostcodes()
+	if issues := ValidateListing(listing); len(issues.Errors) > 0 {
+		return ValidationErrorf("%s", issues.Errors[0])
+	}
+	prepareNew(listing, time.Now().Format(time.RFC3339))
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return r.insert(ctx, tx, listing)
+	})
+}
+
+// CreateIfNotExists creates the listing unless one already exists at the same
+// address, matched on the normalized address line 1 and shortened postcode. An
+// advisory lock on the address stops concurrent callers both creating it.
+func (r *PostgresListingRepository) CreateIfNotExists(ctx context.Context, listing *Listing) (*Listing, bool, error) {
+	listing.AddressDetails.NormalizePostcodes()
+	if issues := ValidateListing(listing); len(issues.Errors) > 0 {
+		return nil, false, ValidationErrorf("%s", issues.Errors[0])
+	}
+
+	var stored *Listing
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if key, ok := listing.AddressDetails.dedupeKey(); ok {
+			if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+				return errors.Wrap(err, "failed to lock address")
+			}
+			existing, err := r.query(ctx, tx, "dedupe_key = $1", key)
+			if err != nil {
+				return err
+			}
+			if len(existing) > 0 {
+				stored = existing[0]
+				return nil
+			}
+		}
+		prepareNew(listing, time.Now().Format(time.RFC3339))
+		if err := r.insert(ctx, tx, listing); err != nil {
+			return err
+		}
+		stored = listing
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return stored, stored == listing, nil
+}
+
+// GetByID retrieves a listing by its ID
+func (r *PostgresListingRepository) GetByID(ctx context.Context, id int64) (*Listing, error) {
+	listings, err := r.query(ctx, r.db, "id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(listings) == 0 {
+		return nil, errors.Wrapf(ErrNotFound, "listing not found with id: %d", id)
+	}
+	return listings[0], nil
+}
+
+// GetAll retrieves all listings
+func (r *PostgresListingRepository) GetAll(ctx context.Context) ([]*Listing, error) {
+	return r.query(ctx, r.db, "TRUE")
+}
+
+// Update updates an existing listing, keeping the visibility date, status,
+// photos and tags of the stored one when listing leaves them out
+func (r *PostgresListingRepository) Update(ctx context.Context, listing *Listing) error {
+	listing.AddressDetails.NormalizePostcodes()
+	if issues := ValidateListing(listing); len(issues.Errors) > 0 {
+		return ValidationErrorf("%s", issues.Errors[0])
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		existing, err := r.lock(ctx, tx, listing.ID)
+		if err != nil {
+			return err
+		}
+		return r.update(ctx, tx, existing, listing)
+	})
+}
+
+// Upsert creates the listing under its own ID if no listing has it, or
+// updates the existing listing otherwise. The ID of a deleted listing is a
+// conflict until the listing is restored.
+func (r *PostgresListingRepository) Upsert(ctx context.Context, listing *Listing) (bool, error) {
+	if listing.ID <= 0 {
+		return false, ValidationErrorf("invalid listing id: %d", listing.ID)
+	}
+	listing.AddressDetails.NormalizePostcodes()
+	if issues := ValidateListing(listing); len(issues.Errors) > 0 {
+		return false, ValidationErrorf("%s", issues.Errors[0])
+	}
+
+	var created bool
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		existing, err := r.lock(ctx, tx, listing.ID)
+		if err == nil {
+			return r.update(ctx, tx, existing, listing)
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		var deleted bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM listings WHERE id = $1)`, listing.ID).Scan(&deleted); err != nil {
+			return errors.Wrapf(err, "failed to look up listing %d", listing.ID)
+		}
+		if deleted {
+			return deletedIDConflict(listing.ID)
+		}
+		prepareNew(listing, time.Now().Format(time.RFC3339))
+		created = true
+		return r.insertWithID(ctx, tx, listing)
+	})
+	return created, err
+}
+
+// Delete soft-deletes a listing by its ID, keeping its row, photos and price
+// history for Restore
+func (r *PostgresListingRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE listings SET deleted_at = $2 WHERE id = $1 AND `+notDeleted,
+		id, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete listing %d", id)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete listing %d", id)
+	}
+	if deleted == 0 {
+		return errors.Wrapf(ErrNotFound, "listing not found with id: %d", id)
+	}
+	return nil
+}
+
+// Restore clears a soft-deleted listing's deleted_at, counting the restore as
+// an update. It is a conflict if another listing has since been created at the
+// same address; the address lock is shared with CreateIfNotExists.
+func (r *PostgresListingRepository) Restore(ctx context.Context, id int64) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		var dedupeKey sql.NullString
+		err := tx.QueryRowContext(ctx, `SELECT dedupe_key FROM listings WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id).
+			Scan(&dedupeKey)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.Wrapf(ErrNotFound, "deleted listing not found with id: %d", id)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to restore listing %d", id)
+		}
+		if dedupeKey.Valid {
+			if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, dedupeKey.String); err != nil {
+				return errors.Wrap(err, "failed to lock address")
+			}
+			existing, err := r.query(ctx, tx, "dedupe_key = $1", dedupeKey.String)
+			if err != nil {
+				return err
+			}
+			if len(existing) > 0 {
+				return restoreConflict(id, existing[0].ID)
+			}
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE listings SET deleted_at = NULL, updated_at = $2 WHERE id = $1`,
+			id, time.Now().Format(time.RFC3339))
+		return errors.Wrapf(err, "failed to restore listing %d", id)
+	})
+}
+
+// GetDeleted returns the soft-deleted listings ordered by ID
+func (r *PostgresListingRepository) GetDeleted(ctx context.Context) ([]*Listing, error) {
+	return collectListings(ctx, r.db, selectListings+" WHERE deleted_at IS NOT NULL ORDER BY id")
+}
+
+// PurgeDeleted permanently removes the listings deleted before the given
+// time, their photos and price history going with them
+func (r *PostgresListingRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM listings WHERE deleted_at IS NOT NULL AND deleted_at::timestamptz < $1`, before)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to purge deleted listings")
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to purge deleted listings")
+	}
+	return int(purged), nil
+}
+
+// GetByRegion retrieves all listings in a specific region
+func (r *PostgresListingRepository) GetByRegion(ctx context.Context, region string) ([]*Listing, error) {
+	return r.query(ctx, r.db, "region = $1", region)
+}
+
+// GetByPropertyType retrieves all listings of a specific property type
+func (r *PostgresListingRepository) GetByPropertyType(ctx context.Context, propertyType string) ([]*Listing, error) {
+	return r.query(ctx, r.db, "property_type = $1", propertyType)
+}
+
+// GetByAgent retrieves all listings owned by an agent
+func (r *PostgresListingRepository) GetByAgent(ctx context.Context, agentID int64) ([]*Listing, error) {
+	return r.query(ctx, r.db, "agent_id = $1", agentID)
+}
+
+// CountByPropertyType counts the listings of each property type. Types
+// without listings are absent.
+func (r *PostgresListingRepository) CountByPropertyType(ctx context.Context) (map[PropertyType]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT property_type, COUNT(*) FROM listings WHERE `+notDeleted+` GROUP BY property_type`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to count listings by property type")
+	}
+	defer rows.Close()
+	counts := make(map[PropertyType]int)
+	for rows.Next() {
+		var propertyType PropertyType
+		var count int
+		if err := rows.Scan(&propertyType, &count); err != nil {
+			return nil, errors.Wrap(err, "failed to scan property type count")
+		}
+		counts[propertyType] = count
+	}
+	return counts, errors.Wrap(rows.Err(), "failed to read property type counts")
+}
+
+// GetFeatured retrieves the featured listings, most recently made visible
+// first. Ties, including listings never made visible, are ordered by ID.
+func (r *PostgresListingRepository) GetFeatured(ctx context.Context) ([]*Listing, error) {
+	listings, err := r.query(ctx, r.db, "is_featured")
+	if err != nil {
+		return nil, err
+	}
+	if err := SortListings(listings, SortByMadeVisibleAt, true); err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+// SearchByCity searches listings by city
+func (r *PostgresListingRepository) SearchByCity(ctx context.Context, city string) ([]*Listing, error) {
+	return r.query(ctx, r.db, "strpos(lower(city), lower($1)) > 0", city)
+}
+
+// Search retrieves the listings matching every criterion set on the filter
+func (r *PostgresListingRepository) Search(ctx context.Context, filter ListingFilter) ([]*Listing, error) {
+	var conditions sqlConditions
+	if filter.Region != nil {
+		conditions.add("region = ?", string(*filter.Region))
+	}
+	if filter.PropertyType != nil {
+		conditions.add("property_type = ?", string(*filter.PropertyType))
+	}
+	if filter.City != nil {
+		conditions.add("strpos(lower(city), lower(?)) > 0", *filter.City)
+	}
+	if filter.MinPrice != nil {
+		conditions.add("price_in_cents >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		conditions.add("price_in_cents <= ?", *filter.MaxPrice)
+	}
+	if filter.MinBedrooms != nil {
+		conditions.add("bedrooms >= ?", *filter.MinBedrooms)
+	}
+	if filter.MaxBedrooms != nil {
+		conditions.add("bedrooms <= ?", *filter.MaxBedrooms)
+	}
+	if filter.MinBathrooms != nil {
+		conditions.add("bathrooms >= ?", *filter.MinBathrooms)
+	}
+	if filter.MaxBathrooms != nil {
+		conditions.add("bathrooms <= ?", *filter.MaxBathrooms)
+	}
+	if filter.MinSize != nil {
+		conditions.add("size_sq_ft >= ?", *filter.MinSize)
+	}
+	if filter.MaxSize != nil {
+		conditions.add("size_sq_ft <= ?", *filter.MaxSize)
+	}
+	conditions.addFlags(filter.ListingFlags)
+	return r.query(ctx, r.db, conditions.where(), conditions.args...)
+}
+
+// GetByFlags retrieves the listings having every boolean flag that is set
+func (r *PostgresListingRepository) GetByFlags(ctx context.Context, flags ListingFlags) ([]*Listing, error) {
+	var conditions sqlConditions
+	conditions.addFlags(flags)
+	return r.query(ctx, r.db, conditions.where(), conditions.args...)
+}
+
+// GetByPriceRange retrieves listings within a price range
+func (r *PostgresListingRepository) GetByPriceRange(ctx context.Context, minPrice, maxPrice int64) ([]*Listing, error) {
+	return r.query(ctx, r.db, "price_in_cents BETWEEN $1 AND $2", minPrice, maxPrice)
+}
+
+// GetByBedroomRange retrieves listings within a bedroom range
+func (r *PostgresListingRepository) GetByBedroomRange(ctx context.Context, minBedrooms, maxBedrooms int) ([]*Listing, error) {
+	return r.query(ctx, r.db, "bedrooms BETWEEN $1 AND $2", minBedrooms, maxBedrooms)
+}
+
+// GetByBathroomRange retrieves listings within a bathroom range
+func (r *PostgresListingRepository) GetByBathroomRange(ctx context.Context, minBathrooms, maxBathrooms int) ([]*Listing, error) {
+	return r.query(ctx, r.db, "bathrooms BETWEEN $1 AND $2", minBathrooms, maxBathrooms)
+}
+
+// GetBySizeRange retrieves listings within an inclusive square footage range.
+// A minimum above the maximum is a validation error.
+func (r *PostgresListingRepository) GetBySizeRange(ctx context.Context, minSqFt, maxSqFt int) ([]*Listing, error) {
+	if minSqFt > maxSqFt {
+		return nil, ValidationErrorf("minSize cannot be greater than maxSize")
+	}
+	return r.query(ctx, r.db, "size_sq_ft BETWEEN $1 AND $2", minSqFt, maxSqFt)
+}
+
+// GetByDepositRange retrieves listings within an estimated deposit range
+func (r *PostgresListingRepository) GetByDepositRange(ctx context.Context, minDeposit, maxDeposit int64) ([]*Listing, error) {
+	return r.query(ctx, r.db, "estimated_deposit_in_cents BETWEEN $1 AND $2", minDeposit, maxDeposit)
+}
+
+// GetByBoundingBox retrieves the listings whose coordinates fall within the
+// box. Listings without coordinates are never included. The box is applied
+// with BoundingBox.Contains so its edges are treated exactly as in memory.
+func (r *PostgresListingRepository) GetByBoundingBox(ctx context.Context, box BoundingBox) ([]*Listing, error) {
+	located, err := r.query(ctx, r.db, "latitude IS NOT NULL AND longitude IS NOT NULL")
+	if err != nil {
+		return nil, err
+	}
+	listings := make([]*Listing, 0)
+	for _, listing := range located {
+		if box.Contains(*listing.AddressDetails.Coordinates) {
+			listings = append(listings, listing)
+		}
+	}
+	return listings, nil
+}
+
+// GetByShortenedPostcode retrieves listings whose shortened postcode matches code, ignoring case
+func (r *PostgresListingRepository) GetByShortenedPostcode(ctx context.Context, code string) ([]*Listing, error) {
+	return r.query(ctx, r.db, "lower(shortened_postcode) = lower($1)", strings.TrimSpace(code))
+}
+
+// ReplaceAll validates every listing and then swaps them in for the existing
+// data in one transaction, so readers see either the old or the new catalogue.
+// Price histories and deleted listings are cleared along with the old listings.
+func (r *PostgresListingRepository) ReplaceAll(ctx context.Context, listings []*Listing, preserveIDs bool) error {
+	if err := ValidateReplacement(listings, preserveIDs); err != nil {
+		return err
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	for i, listing := range listings {
+		if !preserveIDs {
+			listing.ID = int64(i + 1)
+		}
+		prepareReplacement(listing, now)
+	}
+
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM listings`); err != nil {
+			return errors.Wrap(err, "failed to clear listings")
+		}
+		for _, listing := range listings {
+			if err := r.insertWithID(ctx, tx, listing); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetPriceHistory returns the listing's price changes, oldest first, or an
+// empty slice if it has never been repriced
+func (r *PostgresListingRepository) GetPriceHistory(ctx context.Context, id int64) ([]PriceChange, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM listings WHERE id = $1 AND `+notDeleted+`)`, id).Scan(&exists); err != nil {
+		return nil, errors.Wrapf(err, "failed to look up listing %d", id)
+	}
+	if !exists {
+		return nil, errors.Wrapf(ErrNotFound, "listing not found with id: %d", id)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT changed_at, old_price_in_cents, new_price_in_cents
+		FROM price_changes WHERE listing_id = $1 ORDER BY changed_at, id`, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query price history of listing %d", id)
+	}
+	defer rows.Close()
+	history := make([]PriceChange, 0)
+	for rows.Next() {
+		var change PriceChange
+		if err := rows.Scan(&change.ChangedAt, &change.OldPriceInCents, &change.NewPriceInCents); err != nil {
+			return nil, errors.Wrap(err, "failed to scan price change")
+		}
+		history = append(history, change)
+	}
+	return history, errors.Wrap(rows.Err(), "failed to read price history")
+}
+
+// GetPriceReduced returns the listings whose current price is below a price
+// they had before a change made at or after since. A zero since considers the
+// whole history.
+func (r *PostgresListingRepository) GetPriceReduced(ctx context.Context, since time.Time) ([]*Listing, error) {
+	return r.query(ctx, r.db, `id IN (
+		SELECT p.listing_id FROM price_changes p
+		WHERE p.changed_at >= $1 AND p.old_price_in_cents > listings.price_in_cents)`, since)
+}
+
+// GetAllSorted returns every listing ordered by field, descending if desc is
+// set, with ties broken by ascending ID
+func (r *PostgresListingRepository) GetAllSorted(ctx context.Context, field SortField, desc bool) ([]*Listing, error) {
+	if !field.IsValid() {
+		return nil, ValidationErrorf("unknown sort field %q", field)
+	}
+	listings, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := SortListings(listings, field, desc); err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+// AddPhoto checks the photo's URLs and adds it as the listing's last photo
+func (r *PostgresListingRepository) AddPhoto(ctx context.Context, listingID int64, photo Photo) error {
+	if err := photo.Validate(nil); err != nil {
+		return err
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		listing, err := r.lock(ctx, tx, listingID)
+		if err != nil {
+			return err
+		}
+		if listing.Photos, err = withPhoto(listing.Photos, photo); err != nil {
+			return err
+		}
+		return r.touchPhotos(ctx, tx, listing)
+	})
+}
+
+// RemovePhoto removes the listing's photo with the original URL
+func (r *PostgresListingRepository) RemovePhoto(ctx context.Context, listingID int64, originalURL string) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		listing, err := r.lock(ctx, tx, listingID)
+		if err != nil {
+			return err
+		}
+		if listing.Photos, err = withoutPhoto(listing.Photos, originalURL); err != nil {
+			return err
+		}
+		return r.touchPhotos(ctx, tx, listing)
+	})
+}
+
+// touchPhotos stores the listing's photos and marks it updated
+func (r *PostgresListingRepository) touchPhotos(ctx context.Context, tx *sql.Tx, listing *Listing) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE listings SET updated_at = $2 WHERE id = $1`,
+		listing.ID, nextUpdatedAt(listing.UpdatedAt, time.Now()),
+	); err != nil {
+		return errors.Wrapf(err, "failed to update listing %d", listing.ID)
+	}
+	return r.replacePhotos(ctx, tx, listing)
+}
+
+// ListPhotos returns the listing's photos in position order
+func (r *PostgresListingRepository) ListPhotos(ctx context.Context, listingID int64) ([]Photo, error) {
+	listing, err := r.GetByID(ctx, listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.Photos == nil {
+		return []Photo{}, nil
+	}
+	return listing.Photos, nil
+}
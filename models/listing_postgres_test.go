@@ -0,0 +1,330 @@
+//go:build integration
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ ListingRepository = (*PostgresListingRepository)(nil)
+
+// newTestPostgresRepository connects to the database in TEST_DATABASE_DSN,
+// migrates it and empties the listing tables. Run with:
+//
+//	TEST_DATABASE_DSN=postgres://localhost/listings_test?sslmode=disable go test -tags integration ./models
+func newTestPostgresRepository(t *testing.T) *PostgresListingRepository {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	require.NoError(t, MigratePostgres(ctx, db))
+	// Migrating again must be a no-op
+	require.NoError(t, MigratePostgres(ctx, db))
+	_, err = db.ExecContext(ctx, `TRUNCATE listings, photos, price_changes RESTART IDENTITY`)
+	require.NoError(t, err)
+	return NewPostgresListingRepository(db)
+}
+
+func newTestListing(line1 string, region Region, price int64) *Listing {
+	return &Listing{
+		AddressDetails: AddressDetails{
+			AddressLine1:      line1,
+			City:              "London",
+			ShortenedPostcode: "N1",
+			Region:            region,
+			Coordinates:       &Coordinates{Latitude: 51.5, Longitude: -0.1},
+		},
+		PropertyType:               PropertyTypeApartment,
+		Bedrooms:                   2,
+		Bathrooms:                  1,
+		PriceInCents:               price,
+		MonthlyRentalIncomeInCents: price / 200,
+		IsTenanted:                 true,
+		Tags:                       []string{"Garden"},
+		Photos: []Photo{
+			{OriginalURL: "https://example.com/b.jpg", MimeType: "image/jpeg", Position: 1},
+			{OriginalURL: "https://example.com/a.jpg", MimeType: "image/jpeg", Position: 0},
+		},
+	}
+}
+
+func TestPostgresListingRepository_CreateAndGet(t *testing.T) {
+	repo := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	listing := newTestListing("1 High Street", RegionLondon, 20000000)
+	require.NoError(t, repo.Create(ctx, listing))
+	assert.Equal(t, int64(1), listing.ID)
+
+	stored, err := repo.GetByID(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ListingStatusDraft, stored.Status)
+	assert.Equal(t, "1 High Street", stored.AddressDetails.AddressLine1)
+	assert.Equal(t, RegionLondon, stored.AddressDetails.Region)
+	assert.Equal(t, &Coordinates{Latitude: 51.5, Longitude: -0.1}, stored.AddressDetails.Coordinates)
+	assert.Equal(t, []string{"garden"}, stored.Tags)
+	assert.Equal(t, listing.ComputeGrossYield(), stored.GrossYield)
+	assert.NotNil(t, stored.MadeVisibleAt)
+	assert.NotEmpty(t, stored.CreatedAt)
+	assert.Equal(t, stored.CreatedAt, stored.UpdatedAt)
+	require.Len(t, stored.Photos, 2)
+	assert.Equal(t, "https://example.com/a.jpg", stored.Photos[0].OriginalURL)
+	assert.Equal(t, 0, stored.Photos[0].Position)
+
+	_, err = repo.GetByID(ctx, 999)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPostgresListingRepository_UpdateKeepsPhotosAndRecordsPrice(t *testing.T) {
+	repo := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	listing := newTestListing("1 High Street", RegionLondon, 20000000)
+	require.NoError(t, repo.Create(ctx, listing))
+
+	update := newTestListing("1 High Street", RegionLondon, 18000000)
+	update.ID = listing.ID
+	update.Photos = nil
+	update.Tags = nil
+	require.NoError(t, repo.Update(ctx, update))
+
+	stored, err := repo.GetByID(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(18000000), stored.PriceInCents)
+	assert.Len(t, stored.Photos, 2)
+	assert.Equal(t, []string{"garden"}, stored.Tags)
+	assert.Equal(t, listing.MadeVisibleAt, stored.MadeVisibleAt)
+	assert.Equal(t, listing.CreatedAt, stored.CreatedAt)
+	assert.NotEmpty(t, stored.UpdatedAt)
+
+	history, err := repo.GetPriceHistory(ctx, listing.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, int64(20000000), history[0].OldPriceInCents)
+	assert.Equal(t, int64(18000000), history[0].NewPriceInCents)
+
+	reduced, err := repo.GetPriceReduced(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, reduced, 1)
+	assert.Equal(t, listing.ID, reduced[0].ID)
+
+	missing := newTestListing("2 High Street", RegionLondon, 1)
+	missing.ID = 999
+	assert.ErrorIs(t, repo.Update(ctx, missing), ErrNotFound)
+}
+
+func TestPostgresListingRepository_UpsertReservesID(t *testing.T) {
+	repo := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	listing := newTestListing("1 High Street", RegionLondon, 20000000)
+	listing.ID = 50
+	created, err := repo.Upsert(ctx, listing)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	replacement := newTestListing("1 High Street", RegionLondon, 21000000)
+	replacement.ID = 50
+	created, err = repo.Upsert(ctx, replacement)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	next := newTestListing("2 High Street", RegionLondon, 20000000)
+	require.NoError(t, repo.Create(ctx, next))
+	assert.Greater(t, next.ID, int64(50))
+}
+
+func TestPostgresListingRepository_CreateIfNotExists(t *testing.T) {
+	repo := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	first, created, err := repo.CreateIfNotExists(ctx, newTestListing("1 High Street", RegionLondon, 20000000))
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	again, created, err := repo.CreateIfNotExists(ctx, newTestListing("1  HIGH street", RegionLondon, 25000000))
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, first.ID, again.ID)
+}
+
+func TestPostgresListingRepository_Queries(t *testing.T) {
+	repo := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	london := newTestListing("1 High Street", RegionLondon, 20000000)
+	wales := newTestListing("2 High Street", RegionWales, 10000000)
+	wales.AddressDetails.City = "Cardiff"
+	wales.AddressDetails.ShortenedPostcode = "CF10"
+	wales.IsTenanted = false
+	wales.IsFeatured = true
+	require.NoError(t, repo.Create(ctx, london))
+	require.NoError(t, repo.Create(ctx, wales))
+
+	byRegion, err := repo.GetByRegion(ctx, string(RegionWales))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{wales.ID}, listingIDs(byRegion))
+
+	byCity, err := repo.SearchByCity(ctx, "ARDI")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{wales.ID}, listingIDs(byCity))
+
+	byPostcode, err := repo.GetByShortenedPostcode(ctx, " cf10 ")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{wales.ID}, listingIDs(byPostcode))
+
+	byPrice, err := repo.GetByPriceRange(ctx, 15000000, 25000000)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{london.ID}, listingIDs(byPrice))
+
+	featured, err := repo.GetFeatured(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{wales.ID}, listingIDs(featured))
+
+	tenanted := true
+	maxPrice := int64(25000000)
+	region := RegionLondon
+	searched, err := repo.Search(ctx, ListingFilter{
+		Region:       &region,
+		MaxPrice:     &maxPrice,
+		ListingFlags: ListingFlags{IsTenanted: &tenanted},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{london.ID}, listingIDs(searched))
+
+	sorted, err := repo.GetAllSorted(ctx, SortByPrice, false)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{wales.ID, london.ID}, listingIDs(sorted))
+
+	inBox, err := repo.GetByBoundingBox(ctx, BoundingBox{North: 52, South: 51, East: 0, West: -1})
+	require.NoError(t, err)
+	assert.Len(t, inBox, 2)
+}
+
+func TestPostgresListingRepository_Photos(t *testing.T) {
+	repo := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	listing := newTestListing("1 High Street", RegionLondon, 20000000)
+	require.NoError(t, repo.Create(ctx, listing))
+
+	photo := Photo{
+		OriginalURL:  "https://example.com/c.jpg",
+		StandardURL:  "https://example.com/c_standard.jpg",
+		ThumbnailURL: "https://example.com/c_thumbnail.jpg",
+		MimeType:     "image/jpeg",
+	}
+	require.NoError(t, repo.AddPhoto(ctx, listing.ID, photo))
+	assert.ErrorIs(t, repo.AddPhoto(ctx, listing.ID, photo), ErrConflict)
+	assert.ErrorIs(t, repo.AddPhoto(ctx, 999, photo), ErrNotFound)
+
+	photos, err := repo.ListPhotos(ctx, listing.ID)
+	require.NoError(t, err)
+	require.Len(t, photos, 3)
+	assert.Equal(t, "https://example.com/c.jpg", photos[2].OriginalURL)
+	assert.Equal(t, 2, photos[2].Position)
+
+	require.NoError(t, repo.RemovePhoto(ctx, listing.ID, "https://example.com/a.jpg"))
+	assert.ErrorIs(t, repo.RemovePhoto(ctx, listing.ID, "https://example.com/a.jpg"), ErrNotFound)
+	photos, err = repo.ListPhotos(ctx, listing.ID)
+	require.NoError(t, err)
+	require.Len(t, photos, 2)
+	assert.Equal(t, "https://example.com/b.jpg", photos[0].OriginalURL)
+	assert.Equal(t, 0, photos[0].Position)
+}
+
+func TestPostgresListingRepository_PurgeDeleted(t *testing.T) {
+	repo := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	old := newTestListing("1 High Street", RegionLondon, 20000000)
+	recent := newTestListing("2 High Street", RegionLondon, 20000000)
+	require.NoError(t, repo.Create(ctx, old))
+	require.NoError(t, repo.Create(ctx, recent))
+	require.NoError(t, repo.Delete(ctx, old.ID))
+	require.NoError(t, repo.Delete(ctx, recent.ID))
+	_, err := repo.db.ExecContext(ctx, `UPDATE listings SET deleted_at = $2 WHERE id = $1`,
+		old.ID, time.Now().AddDate(0, 0, -60).Format(time.RFC3339))
+	require.NoError(t, err)
+
+	purged, err := repo.PurgeDeleted(ctx, time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	deleted, err := repo.GetDeleted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{recent.ID}, listingIDs(deleted))
+}
+
+func TestPostgresListingRepository_SoftDeleteAndReplaceAll(t *testing.T) {
+	repo := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	listing := newTestListing("1 High Street", RegionLondon, 20000000)
+	require.NoError(t, repo.Create(ctx, listing))
+	require.NoError(t, repo.Delete(ctx, listing.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, listing.ID), ErrNotFound)
+	_, err := repo.GetByID(ctx, listing.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	all, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+	replacement := newTestListing("1 High Street", RegionLondon, 20000000)
+	replacement.ID = listing.ID
+	_, err = repo.Upsert(ctx, replacement)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	deleted, err := repo.GetDeleted(ctx)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.NotNil(t, deleted[0].DeletedAt)
+	assert.Len(t, deleted[0].Photos, 2)
+
+	duplicate, created, err := repo.CreateIfNotExists(ctx, newTestListing("1 High Street", RegionLondon, 20000000))
+	require.NoError(t, err)
+	require.True(t, created)
+	assert.ErrorIs(t, repo.Restore(ctx, listing.ID), ErrConflict)
+	require.NoError(t, repo.Delete(ctx, duplicate.ID))
+
+	require.NoError(t, repo.Restore(ctx, listing.ID))
+	assert.ErrorIs(t, repo.Restore(ctx, listing.ID), ErrNotFound)
+	restored, err := repo.GetByID(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	require.NoError(t, repo.Delete(ctx, listing.ID))
+
+	replacements := []*Listing{
+		newTestListing("1 High Street", RegionLondon, 20000000),
+		newTestListing("2 High Street", RegionWales, 10000000),
+	}
+	replacements[0].ID = 10
+	replacements[1].ID = 20
+	require.NoError(t, repo.ReplaceAll(ctx, replacements, true))
+
+	all, err = repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{10, 20}, listingIDs(all))
+	assert.Len(t, all[0].Photos, 2)
+	deleted, err = repo.GetDeleted(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	invalid := []*Listing{newTestListing("3 High Street", RegionLondon, 1), nil}
+	assert.Error(t, repo.ReplaceAll(ctx, invalid, false))
+	all, err = repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{10, 20}, listingIDs(all))
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/requestid"
+)
+
+// SlowLoggingListingRepository decorates a ListingRepository, logging a warning
+// for any operation that takes longer than the threshold
+type SlowLoggingListingRepository struct {
+	repo      ListingRepository
+	threshold time.Duration
+	logger    *slog.Logger
+}
+
+// NewSlowLoggingListingRepository wraps repo with slow operation logging. A
+// non-positive threshold disables logging and returns repo unchanged.
+func NewSlowLoggingListingRepository(repo ListingRepository, threshold time.Duration, logger *slog.Logger) ListingRepository {
+	if threshold <= 0 {
+		return repo
+	}
+	return &SlowLoggingListingRepository{
+		repo:      repo,
+		threshold: threshold,
+		logger:    logger,
+	}
+}
+
+// observe logs the operation if it has run for longer than the threshold
+// since start, tagged with the request ID when there is one
+func (r *SlowLoggingListingRepository) observe(ctx context.Context, method string, start time.Time) {
+	if elapsed := time.Since(start); elapsed > r.threshold {
+		attrs := []any{
+			"repository", "listing",
+			"method", method,
+			"duration", elapsed,
+			"threshold", r.threshold,
+		}
+		if id := requestid.RequestIDFromContext(ctx); id != "" {
+			attrs = append(attrs, "requestID", id)
+		}
+		r.logger.WarnContext(ctx, "slow repository operation", attrs...)
+	}
+}
+
+func (r *SlowLoggingListingRepository) Create(ctx context.Context, listing *Listing) error {
+	defer r.observe(ctx, "Create", time.Now())
+	return r.repo.Create(ctx, listing)
+}
+
+func (r *SlowLoggingListingRepository) GetByID(ctx context.Context, id int64) (*Listing, error) {
+	defer r.observe(ctx, "GetByID", time.Now())
+	return r.repo.GetByID(ctx, id)
+}
+
+func (r *SlowLoggingListingRepository) GetAll(ctx context.Context) ([]*Listing, error) {
+	defer r.observe(ctx, "GetAll", time.Now())
+	return r.repo.GetAll(ctx)
+}
+
+func (r *SlowLoggingListingRepository) Update(ctx context.Context, listing *Listing) error {
+	defer r.observe(ctx, "Update", time.Now())
+	return r.repo.Update(ctx, listing)
+}
+
+func (r *SlowLoggingListingRepository) Upsert(ctx context.Context, listing *Listing) (bool, error) {
+	defer r.observe(ctx, "Upsert", time.Now())
+	return r.repo.Upsert(ctx, listing)
+}
+
+func (r *SlowLoggingListingRepository) CreateIfNotExists(ctx context.Context, listing *Listing) (*Listing, bool, error) {
+	defer r.observe(ctx, "CreateIfNotExists", time.Now())
+	return r.repo.CreateIfNotExists(ctx, listing)
+}
+
+func (r *SlowLoggingListingRepository) Delete(ctx context.Context, id int64) error {
+	defer r.observe(ctx, "Delete", time.Now())
+	return r.repo.Delete(ctx, id)
+}
+
+func (r *SlowLoggingListingRepository) Restore(ctx context.Context, id int64) error {
+	defer r.observe(ctx, "Restore", time.Now())
+	return r.repo.Restore(ctx, id)
+}
+
+func (r *SlowLoggingListingRepository) GetDeleted(ctx context.Context) ([]*Listing, error) {
+	defer r.observe(ctx, "GetDeleted", time.Now())
+	return r.repo.GetDeleted(ctx)
+}
+
+func (r *SlowLoggingListingRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	defer r.observe(ctx, "PurgeDeleted", time.Now())
+	return r.repo.PurgeDeleted(ctx, before)
+}
+
+func (r *SlowLoggingListingRepository) GetByRegion(ctx context.Context, region string) ([]*Listing, error) {
+	defer r.observe(ctx, "GetByRegion", time.Now())
+	return r.repo.GetByRegion(ctx, region)
+}
+
+func (r *SlowLoggingListingRepository) GetByAgent(ctx context.Context, agentID int64) ([]*Listing, error) {
+	defer r.observe(ctx, "GetByAgent", time.Now())
+	return r.repo.GetByAgent(ctx, agentID)
+}
+
+func (r *SlowLoggingListingRepository) GetByPropertyType(ctx context.Context, propertyType string) ([]*Listing, error) {
+	defer r.observe(ctx, "GetByPropertyType", time.Now())
+	return r.repo.GetByPropertyType(ctx, propertyType)
+}
+
+func (r *SlowLoggingListingRepository) CountByPropertyType(ctx context.Context) (map[PropertyType]int, error) {
+	defer r.observe(ctx, "CountByPropertyType", time.Now())
+	return r.repo.CountByPropertyType(ctx)
+}
+
+func (r *SlowLoggingListingRepository) GetFeatured(ctx context.Context) ([]*Listing, error) {
+	defer r.observe(ctx, "GetFeatured", time.Now())
+	return r.repo.GetFeatured(ctx)
+}
+
+func (r *SlowLoggingListingRepository) SearchByCity(ctx context.Context, city string) ([]*Listing, error) {
+	defer r.observe(ctx, "SearchByCity", time.Now())
+	return r.repo.SearchByCity(ctx, city)
+}
+
+func (r *SlowLoggingListingRepository) GetByPriceRange(ctx context.Context, minPrice, maxPrice int64) ([]*Listing, error) {
+	defer r.observe(ctx, "GetByPriceRange", time.Now())
+	return r.repo.GetByPriceRange(ctx, minPrice, maxPrice)
+}
+
+func (r *SlowLoggingListingRepository) GetByBedroomRange(ctx context.Context, minBedrooms, maxBedrooms int) ([]*Listing, error) {
+	defer r.observe(ctx, "GetByBedroomRange", time.Now())
+	return r.repo.GetByBedroomRange(ctx, minBedrooms, maxBedrooms)
+}
+
+func (r *SlowLoggingListingRepository) GetByBathroomRange(ctx context.Context, minBathrooms, maxBathrooms int) ([]*Listing, error) {
+	defer r.observe(ctx, "GetByBathroomRange", time.Now())
+	return r.repo.GetByBathroomRange(ctx, minBathrooms, maxBathrooms)
+}
+
+func (r *SlowLoggingListingRepository) GetBySizeRange(ctx context.Context, minSqFt, maxSqFt int) ([]*Listing, error) {
+	defer r.observe(ctx, "GetBySizeRange", time.Now())
+	return r.repo.GetBySizeRange(ctx, minSqFt, maxSqFt)
+}
+
+func (r *SlowLoggingListingRepository) GetByDepositRange(ctx context.Context, minDeposit, maxDeposit int64) ([]*Listing, error) {
+	defer r.observe(ctx, "GetByDepositRange", time.Now())
+	return r.repo.GetByDepositRange(ctx, minDeposit, maxDeposit)
+}
+
+func (r *SlowLoggingListingRepository) ReplaceAll(ctx context.Context, listings []*Listing, preserveIDs bool) error {
+	defer r.observe(ctx, "ReplaceAll", time.Now())
+	return r.repo.ReplaceAll(ctx, listings, preserveIDs)
+}
+
+func (r *SlowLoggingListingRepository) GetByBoundingBox(ctx context.Context, box BoundingBox) ([]*Listing, error) {
+	defer r.observe(ctx, "GetByBoundingBox", time.Now())
+	return r.repo.GetByBoundingBox(ctx, box)
+}
+
+func (r *SlowLoggingListingRepository) GetByShortenedPostcode(ctx context.Context, code string) ([]*Listing, error) {
+	defer r.observe(ctx, "GetByShortenedPostcode", time.Now())
+	return r.repo.GetByShortenedPostcode(ctx, code)
+}
+
+func (r *SlowLoggingListingRepository) GetPriceHistory(ctx context.Context, id int64) ([]PriceChange, error) {
+	defer r.observe(ctx, "GetPriceHistory", time.Now())
+	return r.repo.GetPriceHistory(ctx, id)
+}
+
+func (r *SlowLoggingListingRepository) GetByFlags(ctx context.Context, flags ListingFlags) ([]*Listing, error) {
+	defer r.observe(ctx, "GetByFlags", time.Now())
+	return r.repo.GetByFlags(ctx, flags)
+}
+
+func (r *SlowLoggingListingRepository) GetAllSorted(ctx context.Context, field SortField, desc bool) ([]*Listing, error) {
+	defer r.observe(ctx, "GetAllSorted", time.Now())
+	return r.repo.GetAllSorted(ctx, field, desc)
+}
+
+func (r *SlowLoggingListingRepository) GetPriceReduced(ctx context.Context, since time.Time) ([]*Listing, error) {
+	defer r.observe(ctx, "GetPriceReduced", time.Now())
+	return r.repo.GetPriceReduced(ctx, since)
+}
+
+func (r *SlowLoggingListingRepository) Search(ctx context.Context, filter ListingFilter) ([]*Listing, error) {
+	defer r.observe(ctx, "Search", time.Now())
+	return r.repo.Search(ctx, filter)
+}
+
+func (r *SlowLoggingListingRepository) AddPhoto(ctx context.Context, listingID int64, photo Photo) error {
+	defer r.observe(ctx, "AddPhoto", time.Now())
+	return r.repo.AddPhoto(ctx, listingID, photo)
+}
+
+func (r *SlowLoggingListingRepository) RemovePhoto(ctx context.Context, listingID int64, originalURL string) error {
+	defer r.observe(ctx, "RemovePhoto", time.Now())
+	return r.repo.RemovePhoto(ctx, listingID, originalURL)
+}
+
+func (r *SlowLoggingListingRepository) ListPhotos(ctx context.Context, listingID int64) ([]Photo, error) {
+	defer r.observe(ctx, "ListPhotos", time.Now())
+	return r.repo.ListPhotos(ctx, listingID)
+}
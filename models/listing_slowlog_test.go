@@ -0,0 +1,90 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getground/interview-backend-golang/internal/pkg/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sleepyListingRepository takes delay to answer GetByID and GetAll
+type sleepyListingRepository struct {
+	ListingRepository
+	delay time.Duration
+}
+
+func (r *sleepyListingRepository) GetByID(ctx context.Context, id int64) (*Listing, error) {
+	time.Sleep(r.delay)
+	return &Listing{ID: id}, nil
+}
+
+func (r *sleepyListingRepository) GetAll(ctx context.Context) ([]*Listing, error) {
+	return []*Listing{}, nil
+}
+
+func TestSlowLoggingListingRepository(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	repo := NewSlowLoggingListingRepository(&sleepyListingRepository{delay: 30 * time.Millisecond}, 10*time.Millisecond, logger)
+
+	listing, err := repo.GetByID(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), listing.ID)
+
+	_, err = repo.GetAll(context.Background())
+	require.NoError(t, err)
+
+	output := logs.String()
+	assert.Contains(t, output, "level=WARN")
+	assert.Contains(t, output, `msg="slow repository operation"`)
+	assert.Contains(t, output, "method=GetByID")
+	assert.Contains(t, output, "duration=")
+	assert.NotContains(t, output, "method=GetAll")
+	assert.NotContains(t, output, "requestID=")
+}
+
+func TestSlowLoggingListingRepository_RequestID(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	repo := NewSlowLoggingListingRepository(&sleepyListingRepository{delay: 30 * time.Millisecond}, 10*time.Millisecond, logger)
+
+	ctx := requestid.WithRequestID(context.Background(), "req-42")
+	_, err := repo.GetByID(ctx, 7)
+	require.NoError(t, err)
+
+	assert.Contains(t, logs.String(), "requestID=req-42")
+}
+
+func TestSlowLoggingListingRepository_CoalescedRequestIDs(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	// Wrapping the coalescing repository logs every caller of a shared read
+	coalescing := NewCoalescingListingRepository(&sleepyListingRepository{delay: 50 * time.Millisecond})
+	repo := NewSlowLoggingListingRepository(coalescing, 10*time.Millisecond, logger)
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"req-1", "req-2"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			_, err := repo.GetByID(requestid.WithRequestID(context.Background(), id), 7)
+			assert.NoError(t, err)
+		}(id)
+	}
+	wg.Wait()
+
+	assert.Contains(t, logs.String(), "requestID=req-1")
+	assert.Contains(t, logs.String(), "requestID=req-2")
+}
+
+func TestNewSlowLoggingListingRepository_Disabled(t *testing.T) {
+	underlying := &sleepyListingRepository{}
+	repo := NewSlowLoggingListingRepository(underlying, 0, slog.Default())
+	assert.Same(t, underlying, repo)
+}
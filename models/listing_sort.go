@@ -0,0 +1,119 @@
+package models
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+// SortField is a listing attribute that results can be ordered by
+type SortField string
+
+const (
+	SortByPrice         SortField = "price"
+	SortByYield         SortField = "yield"
+	SortByBedrooms      SortField = "bedrooms"
+	SortBySizeSqFt      SortField = "sizeSqFt"
+	SortByMadeVisibleAt SortField = "madeVisibleAt"
+	SortByPricePerSqFt  SortField = "pricePerSqFt"
+	SortByCreatedAt     SortField = "createdAt"
+	SortByUpdatedAt     SortField = "updatedAt"
+)
+
+// SortFields lists every supported sort field
+var SortFields = []SortField{
+	SortByPrice, SortByYield, SortByBedrooms, SortBySizeSqFt, SortByMadeVisibleAt, SortByPricePerSqFt,
+	SortByCreatedAt, SortByUpdatedAt,
+}
+
+// IsValid reports whether the sort field is supported
+func (f SortField) IsValid() bool {
+	return slices.Contains(SortFields, f)
+}
+
+// sortComparators compare two listings on each sort field in ascending order
+var sortComparators = map[SortField]func(a, b *Listing) int{
+	SortByPrice:    func(a, b *Listing) int { return cmp.Compare(a.PriceInCents, b.PriceInCents) },
+	SortByYield:    func(a, b *Listing) int { return cmp.Compare(a.GrossYield, b.GrossYield) },
+	SortByBedrooms: func(a, b *Listing) int { return cmp.Compare(a.Bedrooms, b.Bedrooms) },
+	SortBySizeSqFt: func(a, b *Listing) int { return cmp.Compare(a.SizeSqFt, b.SizeSqFt) },
+	SortByMadeVisibleAt: func(a, b *Listing) int {
+		return a.madeVisibleTime().Compare(b.madeVisibleTime())
+	},
+	SortByPricePerSqFt: func(a, b *Listing) int { return cmp.Compare(a.PricePerSqFt(), b.PricePerSqFt()) },
+	SortByCreatedAt:    func(a, b *Listing) int { return parseTimestamp(a.CreatedAt).Compare(parseTimestamp(b.CreatedAt)) },
+	SortByUpdatedAt:    func(a, b *Listing) int { return parseTimestamp(a.UpdatedAt).Compare(parseTimestamp(b.UpdatedAt)) },
+}
+
+// sortMissing reports, for the sort fields that can be unknown, whether a
+// listing has no value. Such listings sort last in either direction.
+var sortMissing = map[SortField]func(l *Listing) bool{
+	SortByPricePerSqFt: func(l *Listing) bool { return l.SizeSqFt <= 0 },
+}
+
+// SortListings orders listings in place by field, descending if desc is set.
+// Listings with equal values stay in ascending ID order whichever way they are
+// sorted, and listings without a value for the field come last. It returns an
+// error for an unsupported field.
+func SortListings(listings []*Listing, field SortField, desc bool) error {
+	compare, ok := sortComparators[field]
+	if !ok {
+		return ValidationErrorf("unknown sort field %q", field)
+	}
+	missing := sortMissing[field]
+	slices.SortFunc(listings, func(a, b *Listing) int {
+		if missing != nil {
+			if aMissing, bMissing := missing(a), missing(b); aMissing != bMissing {
+				if aMissing {
+					return 1
+				}
+				return -1
+			}
+		}
+		result := compare(a, b)
+		if desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return nil
+}
+
+// madeVisibleTime parses MadeVisibleAt, returning the zero time when it is
+// missing or malformed so such listings sort as the oldest
+func (l *Listing) madeVisibleTime() time.Time {
+	if l.MadeVisibleAt == nil {
+		return time.Time{}
+	}
+	return parseTimestamp(*l.MadeVisibleAt)
+}
+
+// parseTimestamp parses an RFC 3339 time, returning the zero time when it is
+// empty or malformed
+func parseTimestamp(value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+// GetAllSorted returns every listing ordered by field, descending if desc is
+// set, with ties broken by ascending ID
+func (r *ListingRepositoryImpl) GetAllSorted(ctx context.Context, field SortField, desc bool) ([]*Listing, error) {
+	if !field.IsValid() {
+		return nil, ValidationErrorf("unknown sort field %q", field)
+	}
+	listings, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := SortListings(listings, field, desc); err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
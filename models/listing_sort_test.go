@@ -0,0 +1,102 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortListings(t *testing.T) {
+	visibleAt := func(value string) *string { return &value }
+	listings := func() []*Listing {
+		return []*Listing{
+			{ID: 4, PriceInCents: 300, GrossYield: 0.05, Bedrooms: 2, SizeSqFt: 800, MadeVisibleAt: visibleAt("2024-03-01T00:00:00Z"), CreatedAt: "2024-02-01T00:00:00Z", UpdatedAt: "2024-05-01T00:00:00Z"},
+			{ID: 1, PriceInCents: 200, GrossYield: 0.07, Bedrooms: 1, SizeSqFt: 500, MadeVisibleAt: visibleAt("2024-01-01T00:00:00Z"), CreatedAt: "2024-04-01T00:00:00Z", UpdatedAt: "2024-04-01T00:00:00Z"},
+			{ID: 3, PriceInCents: 200, GrossYield: 0.05, Bedrooms: 3, SizeSqFt: 800, MadeVisibleAt: nil, CreatedAt: "2024-01-01T00:00:00Z", UpdatedAt: "2024-06-01T00:00:00Z"},
+			{ID: 2, PriceInCents: 100, GrossYield: 0.06, Bedrooms: 2, SizeSqFt: 1200, MadeVisibleAt: visibleAt("2024-03-01T00:00:00Z"), UpdatedAt: "2024-05-01T00:00:00Z"},
+		}
+	}
+
+	tests := []struct {
+		field       SortField
+		expectedAsc []int64
+		expectedDsc []int64
+	}{
+		{field: SortByPrice, expectedAsc: []int64{2, 1, 3, 4}, expectedDsc: []int64{4, 1, 3, 2}},
+		{field: SortByYield, expectedAsc: []int64{3, 4, 2, 1}, expectedDsc: []int64{1, 2, 3, 4}},
+		{field: SortByBedrooms, expectedAsc: []int64{1, 2, 4, 3}, expectedDsc: []int64{3, 2, 4, 1}},
+		{field: SortBySizeSqFt, expectedAsc: []int64{1, 3, 4, 2}, expectedDsc: []int64{2, 3, 4, 1}},
+		{field: SortByMadeVisibleAt, expectedAsc: []int64{3, 1, 2, 4}, expectedDsc: []int64{2, 4, 1, 3}},
+		{field: SortByCreatedAt, expectedAsc: []int64{2, 3, 4, 1}, expectedDsc: []int64{1, 4, 3, 2}},
+		{field: SortByUpdatedAt, expectedAsc: []int64{1, 2, 4, 3}, expectedDsc: []int64{3, 2, 4, 1}},
+	}
+
+	ids := func(listings []*Listing) []int64 {
+		result := make([]int64, 0, len(listings))
+		for _, listing := range listings {
+			result = append(result, listing.ID)
+		}
+		return result
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.field), func(t *testing.T) {
+			ascending := listings()
+			require.NoError(t, SortListings(ascending, tt.field, false))
+			assert.Equal(t, tt.expectedAsc, ids(ascending))
+
+			descending := listings()
+			require.NoError(t, SortListings(descending, tt.field, true))
+			assert.Equal(t, tt.expectedDsc, ids(descending))
+		})
+	}
+
+	assert.Error(t, SortListings(listings(), SortField("colour"), false))
+}
+
+func TestSortListings_PricePerSqFt(t *testing.T) {
+	listings := func() []*Listing {
+		return []*Listing{
+			{ID: 1, PriceInCents: 30000000, SizeSqFt: 1000},
+			{ID: 2, PriceInCents: 20000000},
+			{ID: 3, PriceInCents: 35000000, SizeSqFt: 32},
+			{ID: 4, PriceInCents: 10000000, SizeSqFt: 500},
+			{ID: 5, PriceInCents: 5000000},
+		}
+	}
+	ids := func(listings []*Listing) []int64 {
+		result := make([]int64, 0, len(listings))
+		for _, listing := range listings {
+			result = append(result, listing.ID)
+		}
+		return result
+	}
+
+	// Listings without a size come last, in ID order, both ways
+	ascending := listings()
+	require.NoError(t, SortListings(ascending, SortByPricePerSqFt, false))
+	assert.Equal(t, []int64{4, 1, 3, 2, 5}, ids(ascending))
+
+	descending := listings()
+	require.NoError(t, SortListings(descending, SortByPricePerSqFt, true))
+	assert.Equal(t, []int64{3, 1, 4, 2, 5}, ids(descending))
+}
+
+func TestListingRepository_GetAllSorted(t *testing.T) {
+	repo := NewListingRepository()
+
+	listings, err := repo.GetAllSorted(context.Background(), SortByPrice, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, listings)
+	for i := 1; i < len(listings); i++ {
+		assert.GreaterOrEqual(t, listings[i-1].PriceInCents, listings[i].PriceInCents)
+		if listings[i-1].PriceInCents == listings[i].PriceInCents {
+			assert.Less(t, listings[i-1].ID, listings[i].ID)
+		}
+	}
+
+	_, err = repo.GetAllSorted(context.Background(), SortField("colour"), false)
+	assert.Error(t, err)
+}
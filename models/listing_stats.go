@@ -0,0 +1,182 @@
+package models
+
+import "strconv"
+
+// RegionVelocity represents how quickly listings in a region move
+type RegionVelocity struct {
+	Region              Region  `json:"region"`
+	ListingCount        int     `json:"listingCount"`
+	AverageDaysOnMarket float64 `json:"averageDaysOnMarket"`
+}
+
+// RegionDepositDemand is the capital needed to buy every published listing in
+// a region at its minimum deposit
+type RegionDepositDemand struct {
+	Region                     Region `json:"region"`
+	ListingCount               int    `json:"listingCount"`
+	TotalMinimumDepositInCents int64  `json:"totalMinimumDepositInCents"`
+}
+
+// TimeBucket is the granularity used to group listings over time
+type TimeBucket string
+
+const (
+	TimeBucketDay   TimeBucket = "day"
+	TimeBucketWeek  TimeBucket = "week"
+	TimeBucketMonth TimeBucket = "month"
+)
+
+// IsValid reports whether b is a supported bucket granularity
+func (b TimeBucket) IsValid() bool {
+	switch b {
+	case TimeBucketDay, TimeBucketWeek, TimeBucketMonth:
+		return true
+	}
+	return false
+}
+
+// TimeBucketCount represents the number of listings in a time bucket. Bucket is
+// the bucket's start date (YYYY-MM-DD, weeks start on Monday) or YYYY-MM for months.
+type TimeBucketCount struct {
+	Bucket string `json:"bucket"`
+	Count  int    `json:"count"`
+}
+
+// RegionListings represents a set of listings belonging to a region
+type RegionListings struct {
+	Region   Region     `json:"region"`
+	Listings []*Listing `json:"listings"`
+}
+
+// PriceBand represents the listings whose price falls within a band. The
+// minimum is inclusive and the maximum exclusive; nil means unbounded.
+type PriceBand struct {
+	Label           string     `json:"label"`
+	MinPriceInCents *int64     `json:"minPriceInCents"`
+	MaxPriceInCents *int64     `json:"maxPriceInCents"`
+	Count           int        `json:"count"`
+	Listings        []*Listing `json:"listings"`
+}
+
+// PriceMedian is the median asking price of a set of listings. Region is empty
+// when the median covers every region, and the median is nil for no listings.
+type PriceMedian struct {
+	Region             Region   `json:"region,omitempty"`
+	Count              int      `json:"count"`
+	MedianPriceInCents *float64 `json:"medianPriceInCents"`
+}
+
+// RegionPricePerSqFt is the average price per square foot of the listings in
+// a region. Listings without a size are counted in ExcludedCount but left out
+// of the average, which is nil when no listing in the region has a size.
+type RegionPricePerSqFt struct {
+	Region                     Region   `json:"region"`
+	Count                      int      `json:"count"`
+	ExcludedCount              int      `json:"excludedCount"`
+	AveragePricePerSqFtInCents *float64 `json:"averagePricePerSqFtInCents"`
+}
+
+// CitySearchResult holds the listings matching a city search. CityKnown is
+// true when the city is recognised even if it has no listings right now, so
+// clients can tell "no listings in Leeds" from "no such city".
+type CitySearchResult struct {
+	City      string     `json:"city"`
+	CityKnown bool       `json:"cityKnown"`
+	Listings  []*Listing `json:"listings"`
+}
+
+// DepositAnomaly is a listing whose estimated deposit is an implausible
+// fraction of its price. Reason is "belowMinimum" or "aboveMaximum".
+type DepositAnomaly struct {
+	ID                      int64   `json:"id"`
+	PriceInCents            int64   `json:"priceInCents"`
+	EstimatedDepositInCents int64   `json:"estimatedDepositInCents"`
+	DepositRatio            float64 `json:"depositRatio"`
+	Reason                  string  `json:"reason"`
+}
+
+// Reasons a deposit is flagged as a DepositAnomaly
+const (
+	DepositAnomalyBelowMinimum = "belowMinimum"
+	DepositAnomalyAboveMaximum = "aboveMaximum"
+)
+
+// CityGroup summarises the listings in one city
+type CityGroup struct {
+	City                string  `json:"city"`
+	ListingCount        int     `json:"listingCount"`
+	AveragePriceInCents float64 `json:"averagePriceInCents"`
+}
+
+// PivotDimension is a listing attribute that listings can be counted by
+type PivotDimension string
+
+const (
+	PivotDimensionRegion       PivotDimension = "region"
+	PivotDimensionPropertyType PivotDimension = "propertyType"
+	PivotDimensionBedrooms     PivotDimension = "bedrooms"
+	PivotDimensionBathrooms    PivotDimension = "bathrooms"
+	PivotDimensionStatus       PivotDimension = "status"
+)
+
+// PivotDimensions lists every dimension a pivot can use
+var PivotDimensions = []PivotDimension{
+	PivotDimensionRegion,
+	PivotDimensionPropertyType,
+	PivotDimensionBedrooms,
+	PivotDimensionBathrooms,
+	PivotDimensionStatus,
+}
+
+// IsValid reports whether d is one of the supported pivot dimensions
+func (d PivotDimension) IsValid() bool {
+	for _, dimension := range PivotDimensions {
+		if d == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+// ValueOf returns the listing's value for the dimension
+func (d PivotDimension) ValueOf(listing *Listing) string {
+	switch d {
+	case PivotDimensionRegion:
+		return string(listing.AddressDetails.Region)
+	case PivotDimensionPropertyType:
+		return string(listing.PropertyType)
+	case PivotDimensionBedrooms:
+		return strconv.Itoa(listing.Bedrooms)
+	case PivotDimensionBathrooms:
+		return strconv.Itoa(listing.Bathrooms)
+	case PivotDimensionStatus:
+		return string(listing.Status)
+	}
+	return ""
+}
+
+// PivotTable counts listings across two dimensions. Counts[i][j] is the number
+// of listings with row value RowKeys[i] and column value ColKeys[j].
+type PivotTable struct {
+	Rows    PivotDimension `json:"rows"`
+	Cols    PivotDimension `json:"cols"`
+	RowKeys []string       `json:"rowKeys"`
+	ColKeys []string       `json:"colKeys"`
+	Counts  [][]int        `json:"counts"`
+	Total   int            `json:"total"`
+}
+
+// YieldBucket counts the listings whose gross yield is at least MinYield and
+// below MaxYield. The last bucket of a histogram also includes MaxYield.
+type YieldBucket struct {
+	MinYield float64 `json:"minYield"`
+	MaxYield float64 `json:"maxYield"`
+	Count    int     `json:"count"`
+}
+
+// YieldHistogram splits the range of gross yields into equal-width buckets.
+// The bucket counts always sum to Total.
+type YieldHistogram struct {
+	Total   int           `json:"total"`
+	Buckets []YieldBucket `json:"buckets"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import "strings"
+
+// ListingSummary is the lightweight view of a listing used by list views. It
+// leaves out the description, photo gallery and flags, which are only
+// returned by the detail endpoint.
+type ListingSummary struct {
+	ID           int64   `json:"id"`
+	Address      string  `json:"address"`
+	PriceInCents int64   `json:"priceInCents"`
+	GrossYield   float64 `json:"grossYield"`
+	Bedrooms     int     `json:"bedrooms"`
+	Bathrooms    int     `json:"bathrooms"`
+	// ThumbnailURL is the thumbnail of the primary photo, or empty if the
+	// listing has no photos
+	ThumbnailURL string `json:"thumbnailURL,omitempty"`
+	// Affordable is only set, and encoded, when the request gives a deposit,
+	// see AffordableSummaries
+	Affordable *bool `json:"affordable,omitempty"`
+}
+
+// Summary returns the lightweight view of the listing
+func (l *Listing) Summary() ListingSummary {
+	summary := ListingSummary{
+		ID:           l.ID,
+		Address:      l.AddressDetails.summary(),
+		PriceInCents: l.PriceInCents,
+		GrossYield:   l.GrossYield,
+		Bedrooms:     l.Bedrooms,
+		Bathrooms:    l.Bathrooms,
+	}
+	if primary, ok := l.PrimaryPhoto(); ok {
+		summary.ThumbnailURL = primary.ThumbnailURL
+	}
+	return summary
+}
+
+// PrimaryPhoto returns the photo with the lowest position, and false if the
+// listing has no photos
+func (l *Listing) PrimaryPhoto() (Photo, bool) {
+	if len(l.Photos) == 0 {
+		return Photo{}, false
+	}
+	primary := l.Photos[0]
+	for _, photo := range l.Photos[1:] {
+		if photo.Position < primary.Position {
+			primary = photo
+		}
+	}
+	return primary, true
+}
+
+// summary joins the city and shortened postcode, e.g. "Manchester, M1"
+func (a AddressDetails) summary() string {
+	parts := make([]string, 0, 2)
+	for _, part := range []string{a.City, a.ShortenedPostcode} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Summaries returns the lightweight view of each listing
+func Summaries(listings []*Listing) []ListingSummary {
+	summaries := make([]ListingSummary, 0, len(listings))
+	for _, listing := range listings {
+		summaries = append(summaries, listing.Summary())
+	}
+	return summaries
+}
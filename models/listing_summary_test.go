@@ -0,0 +1,77 @@
+package models
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListing_Summary(t *testing.T) {
+	tests := []struct {
+		name     string
+		listing  *Listing
+		expected ListingSummary
+	}{
+		{
+			name: "primary photo thumbnail",
+			listing: &Listing{
+				ID:             187,
+				AddressDetails: AddressDetails{City: "Manchester", ShortenedPostcode: "M1", Postcode: "M1 1AA"},
+				PriceInCents:   25000000,
+				GrossYield:     0.06,
+				Bedrooms:       2,
+				Bathrooms:      1,
+				Description:    "Bright two bed flat",
+				Photos: []Photo{
+					{ID: 2, Position: 1, ThumbnailURL: "https://example.com/2-thumb.jpg"},
+					{ID: 1, Position: 0, ThumbnailURL: "https://example.com/1-thumb.jpg"},
+				},
+			},
+			expected: ListingSummary{
+				ID:           187,
+				Address:      "Manchester, M1",
+				PriceInCents: 25000000,
+				GrossYield:   0.06,
+				Bedrooms:     2,
+				Bathrooms:    1,
+				ThumbnailURL: "https://example.com/1-thumb.jpg",
+			},
+		},
+		{
+			name:     "no photos or postcode",
+			listing:  &Listing{ID: 1, AddressDetails: AddressDetails{City: "Leeds"}, PriceInCents: 100},
+			expected: ListingSummary{ID: 1, Address: "Leeds", PriceInCents: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.listing.Summary())
+		})
+	}
+}
+
+func TestListingSummary_JSONOmitsHeavyFields(t *testing.T) {
+	listing := &Listing{
+		ID:             1,
+		AddressDetails: AddressDetails{City: "Leeds", ShortenedPostcode: "LS1"},
+		Description:    "Long description",
+		Photos:         []Photo{{ID: 1, ThumbnailURL: "https://example.com/thumb.jpg"}},
+	}
+
+	data, err := json.Marshal(AffordableSummaries([]*Listing{listing}, 0)[0])
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.ElementsMatch(t,
+		[]string{"id", "address", "priceInCents", "grossYield", "bedrooms", "bathrooms", "thumbnailURL", "affordable"},
+		slices.Collect(maps.Keys(fields)))
+	assert.NotContains(t, fields, "description")
+	assert.NotContains(t, fields, "photos")
+	assert.NotContains(t, fields, "addressDetails")
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// maxTags is how many tags a listing may have
+	maxTags = 20
+	// maxTagLength is the longest a tag may be, in characters
+	maxTagLength = 50
+)
+
+// NormalizeTag lower-cases a tag and collapses its whitespace, so "Price
+// Reduced " and "price reduced" are the same tag
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// NormalizeTags normalizes each tag, dropping empty tags and duplicates while
+// keeping the first occurrence's position. A nil slice stays nil.
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = NormalizeTag(tag); tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// HasTag reports whether the listing has the tag, ignoring case and spacing
+func (l *Listing) HasTag(tag string) bool {
+	return slices.Contains(l.Tags, NormalizeTag(tag))
+}
+
+// validateTags returns an error message for each limit the normalized tags break
+func validateTags(tags []string) []string {
+	var issues []string
+	normalized := NormalizeTags(tags)
+	if len(normalized) > maxTags {
+		issues = append(issues, fmt.Sprintf("a listing can have at most %d tags", maxTags))
+	}
+	for _, tag := range normalized {
+		if len([]rune(tag)) > maxTagLength {
+			issues = append(issues, fmt.Sprintf("tag %q is longer than %d characters", tag, maxTagLength))
+		}
+	}
+	return issues
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		expected []string
+	}{
+		{name: "nil stays nil", tags: nil, expected: nil},
+		{name: "empty stays empty", tags: []string{}, expected: []string{}},
+		{
+			name:     "case, spacing and duplicates",
+			tags:     []string{"Investor Favourite", " price   reduced ", "investor favourite", "", "  "},
+			expected: []string{"investor favourite", "price reduced"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeTags(tt.tags))
+		})
+	}
+}
+
+func TestValidateListing_Tags(t *testing.T) {
+	tooMany := make([]string, 0, maxTags+1)
+	for i := 0; i <= maxTags; i++ {
+		tooMany = append(tooMany, strings.Repeat("a", i+1))
+	}
+
+	tests := []struct {
+		name          string
+		tags          []string
+		expectedIssue string
+	}{
+		{name: "within limits", tags: []string{"investor favourite", "price reduced"}},
+		{name: "duplicates count once", tags: append(make([]string, maxTags+5), "same")},
+		{name: "too many", tags: tooMany, expectedIssue: "a listing can have at most 20 tags"},
+		{name: "too long", tags: []string{strings.Repeat("x", maxTagLength+1)}, expectedIssue: `tag "` + strings.Repeat("x", maxTagLength+1) + `" is longer than 50 characters`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := ValidateListing(&Listing{Tags: tt.tags})
+			if tt.expectedIssue == "" {
+				for _, issue := range issues.Errors {
+					assert.NotContains(t, issue, "tag")
+				}
+				return
+			}
+			assert.Contains(t, issues.Errors, tt.expectedIssue)
+		})
+	}
+}
+
+func TestListing_HasTag(t *testing.T) {
+	listing := &Listing{Tags: NormalizeTags([]string{"Price Reduced"})}
+
+	assert.True(t, listing.HasTag("price reduced"))
+	assert.True(t, listing.HasTag(" PRICE  REDUCED"))
+	assert.False(t, listing.HasTag("investor favourite"))
+}
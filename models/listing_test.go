@@ -2,7 +2,12 @@ package models
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,8 +25,8 @@ func TestNewListingRepository(t *testing.T) {
 
 func TestListingRepository_Create(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	tests := []struct {
@@ -149,8 +154,8 @@ func TestListingRepository_Create(t *testing.T) {
 
 func TestListingRepository_GetByID(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	// Create a test listing
@@ -201,8 +206,8 @@ func TestListingRepository_GetByID(t *testing.T) {
 
 func TestListingRepository_GetAll(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	// Create multiple test listings
@@ -241,8 +246,8 @@ func TestListingRepository_GetAll(t *testing.T) {
 
 func TestListingRepository_Update(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	// Create a test listing
@@ -255,6 +260,7 @@ func TestListingRepository_Update(t *testing.T) {
 		},
 		PropertyType: PropertyTypeApartment,
 		PriceInCents: 10000000,
+		AgentID:      3,
 	}
 	err := repo.Create(context.Background(), listing)
 	require.NoError(t, err)
@@ -306,12 +312,17 @@ func TestListingRepository_Update(t *testing.T) {
 			}
 		})
 	}
+
+	// An update without an owner keeps the existing one
+	updated, err := repo.GetByID(context.Background(), listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), updated.AgentID)
 }
 
 func TestListingRepository_Delete(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	// Create a test listing
@@ -357,10 +368,147 @@ func TestListingRepository_Delete(t *testing.T) {
 	}
 }
 
+func TestListingRepository_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewListingRepository()
+	before, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	counts, err := repo.CountByPropertyType(ctx)
+	require.NoError(t, err)
+
+	listing, err := repo.GetByID(ctx, 187)
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, 187))
+
+	t.Run("deleted listing is left out of queries", func(t *testing.T) {
+		_, err := repo.GetByID(ctx, 187)
+		assert.ErrorIs(t, err, ErrNotFound)
+		all, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, all, len(before)-1)
+		assert.NotContains(t, listingIDs(all), int64(187))
+		inRegion, err := repo.GetByRegion(ctx, string(listing.AddressDetails.Region))
+		require.NoError(t, err)
+		assert.NotContains(t, listingIDs(inRegion), int64(187))
+		searched, err := repo.Search(ctx, ListingFilter{City: &listing.AddressDetails.City})
+		require.NoError(t, err)
+		assert.NotContains(t, listingIDs(searched), int64(187))
+		sorted, err := repo.GetAllSorted(ctx, SortByPrice, false)
+		require.NoError(t, err)
+		assert.NotContains(t, listingIDs(sorted), int64(187))
+		deletedCounts, err := repo.CountByPropertyType(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, counts[listing.PropertyType]-1, deletedCounts[listing.PropertyType])
+		_, err = repo.ListPhotos(ctx, 187)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, repo.Update(ctx, listing.clone()), ErrNotFound)
+		assert.ErrorIs(t, repo.Delete(ctx, 187), ErrNotFound)
+	})
+
+	t.Run("deleted listing is listed separately", func(t *testing.T) {
+		deleted, err := repo.GetDeleted(ctx)
+		require.NoError(t, err)
+		require.Len(t, deleted, 1)
+		assert.Equal(t, int64(187), deleted[0].ID)
+		require.NotNil(t, deleted[0].DeletedAt)
+		_, err = time.Parse(time.RFC3339, *deleted[0].DeletedAt)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ID stays reserved", func(t *testing.T) {
+		created, err := repo.Upsert(ctx, listing.clone())
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.False(t, created)
+
+		next := listing.clone()
+		require.NoError(t, repo.Create(ctx, next))
+		assert.NotEqual(t, int64(187), next.ID)
+		require.NoError(t, repo.Delete(ctx, next.ID))
+	})
+
+	t.Run("restore conflicts with a listing at the same address", func(t *testing.T) {
+		duplicate := listing.clone()
+		duplicate.ID = 0
+		stored, created, err := repo.CreateIfNotExists(ctx, duplicate)
+		require.NoError(t, err)
+		require.True(t, created)
+
+		assert.ErrorIs(t, repo.Restore(ctx, 187), ErrConflict)
+		require.NoError(t, repo.Delete(ctx, stored.ID))
+	})
+
+	t.Run("restore brings the listing back", func(t *testing.T) {
+		require.NoError(t, repo.Restore(ctx, 187))
+		restored, err := repo.GetByID(ctx, 187)
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		assert.Equal(t, listing.CreatedAt, restored.CreatedAt)
+		assert.Equal(t, listing.Photos, restored.Photos)
+		all, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, all, len(before))
+
+		deleted, err := repo.GetDeleted(ctx)
+		require.NoError(t, err)
+		assert.NotContains(t, listingIDs(deleted), int64(187))
+		assert.ErrorIs(t, repo.Restore(ctx, 187), ErrNotFound)
+		assert.ErrorIs(t, repo.Restore(ctx, 999), ErrNotFound)
+	})
+}
+
+func TestListingRepository_PurgeDeleted(t *testing.T) {
+	ctx := context.Background()
+	repo := NewListingRepository().(*ListingRepositoryImpl)
+	require.NoError(t, repo.Delete(ctx, 185))
+	require.NoError(t, repo.Delete(ctx, 187))
+	// Age listing 185's deletion, as if it happened 60 days ago
+	old := time.Now().AddDate(0, 0, -60).Format(time.RFC3339)
+	repo.deleted[185].DeletedAt = &old
+
+	purged, err := repo.PurgeDeleted(ctx, time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	deleted, err := repo.GetDeleted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{187}, listingIDs(deleted))
+	assert.ErrorIs(t, repo.Restore(ctx, 185), ErrNotFound)
+	_, err = repo.GetPriceHistory(ctx, 185)
+	assert.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, repo.Restore(ctx, 187))
+}
+
+func TestListingRepository_GetByAgent(t *testing.T) {
+	ctx := context.Background()
+	repo := NewListingRepository()
+	owned := make([]int64, 0, 2)
+	for _, agentID := range []int64{3, 4, 3} {
+		listing := &Listing{
+			AgentID:        agentID,
+			PropertyType:   PropertyTypeApartment,
+			PriceInCents:   10000000,
+			AddressDetails: AddressDetails{City: "London", ShortenedPostcode: "W1", Region: RegionLondon},
+		}
+		require.NoError(t, repo.Create(ctx, listing))
+		if agentID == 3 {
+			owned = append(owned, listing.ID)
+		}
+	}
+	require.NoError(t, repo.Delete(ctx, owned[1]))
+
+	listings, err := repo.GetByAgent(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, owned[:1], listingIDs(listings))
+
+	listings, err = repo.GetByAgent(ctx, 99)
+	require.NoError(t, err)
+	assert.Empty(t, listings)
+}
+
 func TestListingRepository_GetByRegion(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	// Create test listings in different regions
@@ -435,8 +583,8 @@ func TestListingRepository_GetByRegion(t *testing.T) {
 
 func TestListingRepository_GetByPropertyType(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	// Create test listings with different property types
@@ -511,8 +659,8 @@ func TestListingRepository_GetByPropertyType(t *testing.T) {
 
 func TestListingRepository_SearchByCity(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	// Create test listings in different cities
@@ -592,8 +740,8 @@ func TestListingRepository_SearchByCity(t *testing.T) {
 
 func TestListingRepository_GetByPriceRange(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	// Create test listings with different prices
@@ -678,8 +826,8 @@ func TestListingRepository_GetByPriceRange(t *testing.T) {
 
 func TestListingRepository_GetByBedroomRange(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	// Create test listings with different bedroom counts
@@ -767,8 +915,8 @@ func TestListingRepository_GetByBedroomRange(t *testing.T) {
 
 func TestListingRepository_GetByBathroomRange(t *testing.T) {
 	repo := &ListingRepositoryImpl{
-		data:   make(map[int64]*Listing),
-		nextID: 1,
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
 	}
 
 	// Create test listings with different bathroom counts
@@ -853,3 +1001,1132 @@ func TestListingRepository_GetByBathroomRange(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizePhotos_LeavesInputUntouched(t *testing.T) {
+	photos := []Photo{
+		{OriginalURL: "https://example.com/b.jpg", Position: 5},
+		{OriginalURL: "https://example.com/a.jpg", Position: 3},
+	}
+	original := slices.Clone(photos)
+
+	normalized := normalizePhotos(photos)
+
+	assert.Equal(t, original, photos)
+	require.Len(t, normalized, 2)
+	assert.Equal(t, "https://example.com/a.jpg", normalized[0].OriginalURL)
+	assert.Equal(t, Photo{ID: 1, OriginalURL: "https://example.com/a.jpg", Position: 0}, normalized[0])
+	assert.Equal(t, Photo{ID: 2, OriginalURL: "https://example.com/b.jpg", Position: 1}, normalized[1])
+	assert.Nil(t, normalizePhotos(nil))
+}
+
+func TestListingRepository_PhotoOrder(t *testing.T) {
+	repo := &ListingRepositoryImpl{
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
+	}
+
+	listing := &Listing{
+		AddressDetails: AddressDetails{
+			City:              "London",
+			ShortenedPostcode: "W1",
+			Region:            RegionSouthEast,
+			Country:           "UK",
+		},
+		PropertyType: PropertyTypeApartment,
+		PriceInCents: 10000000,
+		Photos: []Photo{
+			{OriginalURL: "https://example.com/c.jpg", Position: 2},
+			{OriginalURL: "https://example.com/a.jpg", Position: 0},
+			{OriginalURL: "https://example.com/b.jpg", Position: 1},
+		},
+	}
+	err := repo.Create(context.Background(), listing)
+	require.NoError(t, err)
+
+	created, err := repo.GetByID(context.Background(), listing.ID)
+	require.NoError(t, err)
+	require.Len(t, created.Photos, 3)
+	for i, photo := range created.Photos {
+		assert.Equal(t, i, photo.Position)
+		assert.NotZero(t, photo.ID)
+	}
+	assert.Equal(t, "https://example.com/a.jpg", created.Photos[0].OriginalURL)
+	assert.Equal(t, "https://example.com/b.jpg", created.Photos[1].OriginalURL)
+	assert.Equal(t, "https://example.com/c.jpg", created.Photos[2].OriginalURL)
+	originalPhotos := append([]Photo(nil), created.Photos...)
+
+	tests := []struct {
+		name   string
+		photos []Photo
+	}{
+		{
+			name:   "photos resent unchanged",
+			photos: append([]Photo(nil), originalPhotos...),
+		},
+		{
+			name:   "photos omitted",
+			photos: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := &Listing{
+				ID: listing.ID,
+				AddressDetails: AddressDetails{
+					City:              "Manchester",
+					ShortenedPostcode: "M1",
+					Region:            RegionNorthWest,
+					Country:           "UK",
+				},
+				PropertyType: PropertyTypeDetached,
+				PriceInCents: 20000000,
+				Photos:       tt.photos,
+			}
+			err := repo.Update(context.Background(), update)
+			require.NoError(t, err)
+
+			result, err := repo.GetByID(context.Background(), listing.ID)
+			require.NoError(t, err)
+			assert.Equal(t, originalPhotos, result.Photos)
+		})
+	}
+}
+
+func TestListingRepository_GetByDepositRange(t *testing.T) {
+	repo := &ListingRepositoryImpl{
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
+	}
+
+	// Create test listings with different estimated deposits
+	listings := []*Listing{
+		{
+			AddressDetails: AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "W1",
+				Region:            RegionSouthEast,
+				Country:           "UK",
+			},
+			PropertyType:            PropertyTypeApartment,
+			PriceInCents:            10000000,
+			EstimatedDepositInCents: 2500000,
+		},
+		{
+			AddressDetails: AddressDetails{
+				City:              "Manchester",
+				ShortenedPostcode: "M1",
+				Region:            RegionNorthWest,
+				Country:           "UK",
+			},
+			PropertyType:            PropertyTypeDetached,
+			PriceInCents:            20000000,
+			EstimatedDepositInCents: 5000000,
+		},
+		{
+			AddressDetails: AddressDetails{
+				City:              "Birmingham",
+				ShortenedPostcode: "B1",
+				Region:            RegionMidlands,
+				Country:           "UK",
+			},
+			PropertyType:            PropertyTypeTerraced,
+			PriceInCents:            15000000,
+			EstimatedDepositInCents: 3750000,
+		},
+	}
+
+	for _, listing := range listings {
+		err := repo.Create(context.Background(), listing)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name          string
+		minDeposit    int64
+		maxDeposit    int64
+		expectedCount int
+	}{
+		{
+			name:          "exact deposit",
+			minDeposit:    2500000,
+			maxDeposit:    2500000,
+			expectedCount: 1,
+		},
+		{
+			name:          "middle range",
+			minDeposit:    3000000,
+			maxDeposit:    5000000,
+			expectedCount: 2,
+		},
+		{
+			name:          "wide range",
+			minDeposit:    0,
+			maxDeposit:    10000000,
+			expectedCount: 3,
+		},
+		{
+			name:          "no matches",
+			minDeposit:    6000000,
+			maxDeposit:    10000000,
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.GetByDepositRange(context.Background(), tt.minDeposit, tt.maxDeposit)
+			assert.NoError(t, err)
+			assert.Len(t, result, tt.expectedCount)
+		})
+	}
+}
+
+func TestListingRepository_ReplaceAll(t *testing.T) {
+	newListing := func(id int64, city string) *Listing {
+		return &Listing{
+			ID: id,
+			AddressDetails: AddressDetails{
+				City:              city,
+				ShortenedPostcode: "AB1",
+				Region:            RegionLondon,
+				Country:           "UK",
+			},
+			PropertyType: PropertyTypeApartment,
+			PriceInCents: 10000000,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		listings      []*Listing
+		preserveIDs   bool
+		expectedError bool
+		expectedIDs   []int64
+		expectedCity  map[int64]string
+		nextID        int64
+	}{
+		{
+			name:          "invalid listing leaves old data intact",
+			listings:      []*Listing{newListing(0, "Leeds"), newListing(0, "")},
+			expectedError: true,
+			expectedIDs:   []int64{1, 2},
+			expectedCity:  map[int64]string{1: "London", 2: "Manchester"},
+			nextID:        3,
+		},
+		{
+			name:          "duplicate preserved ids leave old data intact",
+			listings:      []*Listing{newListing(7, "Leeds"), newListing(7, "York")},
+			preserveIDs:   true,
+			expectedError: true,
+			expectedIDs:   []int64{1, 2},
+			expectedCity:  map[int64]string{1: "London", 2: "Manchester"},
+			nextID:        3,
+		},
+		{
+			name:         "reassigns ids",
+			listings:     []*Listing{newListing(7, "Leeds"), newListing(9, "York"), newListing(0, "Bath")},
+			expectedIDs:  []int64{1, 2, 3},
+			expectedCity: map[int64]string{1: "Leeds", 2: "York", 3: "Bath"},
+			nextID:       4,
+		},
+		{
+			name:         "preserves ids",
+			listings:     []*Listing{newListing(7, "Leeds"), newListing(9, "York")},
+			preserveIDs:  true,
+			expectedIDs:  []int64{7, 9},
+			expectedCity: map[int64]string{7: "Leeds", 9: "York"},
+			nextID:       10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &ListingRepositoryImpl{
+				data: make(map[int64]*Listing),
+				ids:  NewSequentialIDGenerator(),
+			}
+			require.NoError(t, repo.Create(context.Background(), newListing(0, "London")))
+			require.NoError(t, repo.Create(context.Background(), newListing(0, "Manchester")))
+
+			err := repo.ReplaceAll(context.Background(), tt.listings, tt.preserveIDs)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			all, err := repo.GetAll(context.Background())
+			require.NoError(t, err)
+			ids := make([]int64, 0, len(all))
+			for _, listing := range all {
+				ids = append(ids, listing.ID)
+				assert.Equal(t, tt.expectedCity[listing.ID], listing.AddressDetails.City)
+			}
+			assert.ElementsMatch(t, tt.expectedIDs, ids)
+			nextID, err := repo.ids.NextID(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.nextID, nextID)
+		})
+	}
+}
+
+func TestListingRepository_GetByBoundingBox(t *testing.T) {
+	repo := &ListingRepositoryImpl{
+		data: make(map[int64]*Listing),
+		ids:  NewSequentialIDGenerator(),
+	}
+	newListing := func(city string, coordinates *Coordinates) *Listing {
+		return &Listing{
+			AddressDetails: AddressDetails{
+				City:              city,
+				ShortenedPostcode: "AB1",
+				Region:            RegionLondon,
+				Coordinates:       coordinates,
+			},
+			PropertyType: PropertyTypeApartment,
+			PriceInCents: 10000000,
+		}
+	}
+	for _, listing := range []*Listing{
+		newListing("London", &Coordinates{Latitude: 51.5074, Longitude: -0.1278}),
+		newListing("Manchester", &Coordinates{Latitude: 53.4808, Longitude: -2.2426}),
+		newListing("Unknown", nil),
+	} {
+		require.NoError(t, repo.Create(context.Background(), listing))
+	}
+
+	tests := []struct {
+		name           string
+		box            BoundingBox
+		expectedCities []string
+	}{
+		{
+			name:           "london viewport",
+			box:            BoundingBox{North: 51.7, South: 51.3, East: 0.3, West: -0.5},
+			expectedCities: []string{"London"},
+		},
+		{
+			name:           "whole of england",
+			box:            BoundingBox{North: 55.8, South: 49.9, East: 1.8, West: -5.7},
+			expectedCities: []string{"London", "Manchester"},
+		},
+		{
+			name:           "edge is inclusive",
+			box:            BoundingBox{North: 53.4808, South: 53, East: -2.2426, West: -3},
+			expectedCities: []string{"Manchester"},
+		},
+		{
+			name:           "open sea",
+			box:            BoundingBox{North: 50, South: 45, East: -10, West: -20},
+			expectedCities: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listings, err := repo.GetByBoundingBox(context.Background(), tt.box)
+
+			require.NoError(t, err)
+			cities := make([]string, 0, len(listings))
+			for _, listing := range listings {
+				cities = append(cities, listing.AddressDetails.City)
+			}
+			assert.ElementsMatch(t, tt.expectedCities, cities)
+		})
+	}
+}
+
+func TestListingRepository_GetByShortenedPostcode(t *testing.T) {
+	repo := NewListingRepository()
+
+	tests := []struct {
+		name          string
+		code          string
+		expectedCount int
+	}{
+		{
+			name:          "sample listings sharing an area",
+			code:          "PR1",
+			expectedCount: 3,
+		},
+		{
+			name:          "case insensitive",
+			code:          "pr1",
+			expectedCount: 3,
+		},
+		{
+			name:          "no listings in area",
+			code:          "ZZ9",
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listings, err := repo.GetByShortenedPostcode(context.Background(), tt.code)
+
+			require.NoError(t, err)
+			assert.Len(t, listings, tt.expectedCount)
+			for _, listing := range listings {
+				assert.True(t, strings.EqualFold(tt.code, listing.AddressDetails.ShortenedPostcode))
+			}
+		})
+	}
+}
+
+func TestListingRepository_CreateAfterRestore(t *testing.T) {
+	newListing := func(id int64) *Listing {
+		return &Listing{
+			ID: id,
+			AddressDetails: AddressDetails{
+				City:              "London",
+				ShortenedPostcode: "AB1",
+				Region:            RegionLondon,
+			},
+			PropertyType: PropertyTypeApartment,
+			PriceInCents: 10000000,
+		}
+	}
+
+	t.Run("next create follows the highest restored id", func(t *testing.T) {
+		repo := &ListingRepositoryImpl{data: make(map[int64]*Listing), ids: NewSequentialIDGenerator()}
+		require.NoError(t, repo.ReplaceAll(context.Background(), []*Listing{newListing(250), newListing(100)}, true))
+
+		created := newListing(0)
+		require.NoError(t, repo.Create(context.Background(), created))
+		assert.Equal(t, int64(251), created.ID)
+
+		// A later restore with lower ids must not let old ids be handed out again
+		require.NoError(t, repo.ReplaceAll(context.Background(), []*Listing{newListing(1), newListing(2)}, true))
+		created = newListing(0)
+		require.NoError(t, repo.Create(context.Background(), created))
+		assert.Equal(t, int64(252), created.ID)
+	})
+
+	t.Run("creates racing a restore never collide", func(t *testing.T) {
+		repo := &ListingRepositoryImpl{data: make(map[int64]*Listing), ids: NewSequentialIDGenerator()}
+		restored := make([]*Listing, 0, 50)
+		for id := int64(1); id <= 50; id++ {
+			listing := newListing(id * 10)
+			listing.Description = "restored"
+			restored = append(restored, listing)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, repo.Create(context.Background(), newListing(0)))
+			}()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, repo.ReplaceAll(context.Background(), restored, true))
+		}()
+		wg.Wait()
+
+		all, err := repo.GetAll(context.Background())
+		require.NoError(t, err)
+		seen := make(map[int64]bool, len(all))
+		for _, listing := range all {
+			assert.False(t, seen[listing.ID], "duplicate id %d", listing.ID)
+			seen[listing.ID] = true
+		}
+		for _, listing := range restored {
+			assert.Equal(t, "restored", repo.data[listing.ID].Description, "restored listing %d was overwritten", listing.ID)
+		}
+	})
+
+	t.Run("rejects a generated id that is already taken", func(t *testing.T) {
+		repo := &ListingRepositoryImpl{data: make(map[int64]*Listing), ids: &fakeIDGenerator{ids: []int64{5}}}
+		require.NoError(t, repo.ReplaceAll(context.Background(), []*Listing{newListing(5)}, true))
+
+		err := repo.Create(context.Background(), newListing(0))
+
+		assert.Error(t, err)
+		stored, err := repo.GetByID(context.Background(), 5)
+		require.NoError(t, err)
+		assert.Equal(t, "London", stored.AddressDetails.City)
+	})
+}
+
+func TestListingRepository_Upsert(t *testing.T) {
+	newListing := func(id int64, city string) *Listing {
+		return &Listing{
+			ID: id,
+			AddressDetails: AddressDetails{
+				City:              city,
+				ShortenedPostcode: "AB1",
+				Region:            RegionLondon,
+			},
+			PropertyType: PropertyTypeApartment,
+			PriceInCents: 10000000,
+		}
+	}
+	repo := &ListingRepositoryImpl{data: make(map[int64]*Listing), ids: NewSequentialIDGenerator()}
+
+	created, err := repo.Upsert(context.Background(), newListing(50, "Leeds"))
+	require.NoError(t, err)
+	assert.True(t, created)
+	stored, err := repo.GetByID(context.Background(), 50)
+	require.NoError(t, err)
+	assert.Equal(t, "Leeds", stored.AddressDetails.City)
+	assert.Equal(t, ListingStatusDraft, stored.Status)
+	require.NotNil(t, stored.MadeVisibleAt)
+	visibleAt := *stored.MadeVisibleAt
+
+	created, err = repo.Upsert(context.Background(), newListing(50, "York"))
+	require.NoError(t, err)
+	assert.False(t, created)
+	stored, err = repo.GetByID(context.Background(), 50)
+	require.NoError(t, err)
+	assert.Equal(t, "York", stored.AddressDetails.City)
+	assert.Equal(t, ListingStatusDraft, stored.Status)
+	assert.Equal(t, visibleAt, *stored.MadeVisibleAt)
+
+	// The upserted ID is reserved so generated IDs don't collide with it
+	next := newListing(0, "Bath")
+	require.NoError(t, repo.Create(context.Background(), next))
+	assert.Equal(t, int64(51), next.ID)
+
+	_, err = repo.Upsert(context.Background(), newListing(0, "Bath"))
+	assert.Error(t, err)
+	_, err = repo.Upsert(context.Background(), newListing(60, ""))
+	assert.Error(t, err)
+}
+
+func TestListingRepository_CreateIfNotExists(t *testing.T) {
+	newListing := func(line1, shortenedPostcode string) *Listing {
+		return &Listing{
+			AddressDetails: AddressDetails{
+				AddressLine1:      line1,
+				City:              "London",
+				ShortenedPostcode: shortenedPostcode,
+				Region:            RegionLondon,
+			},
+			PropertyType: PropertyTypeApartment,
+			PriceInCents: 10000000,
+		}
+	}
+	repo := &ListingRepositoryImpl{data: make(map[int64]*Listing), ids: NewSequentialIDGenerator()}
+
+	first := newListing("1 High Street", "N1")
+	stored, created, err := repo.CreateIfNotExists(context.Background(), first)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Same(t, first, stored)
+	assert.NotZero(t, stored.ID)
+
+	tests := []struct {
+		name            string
+		listing         *Listing
+		expectedCreated bool
+	}{
+		{name: "same address", listing: newListing("1 High Street", "N1"), expectedCreated: false},
+		{name: "different case and spacing", listing: newListing("  1  high STREET ", "n 1"), expectedCreated: false},
+		{name: "different street", listing: newListing("2 High Street", "N1"), expectedCreated: true},
+		{name: "different postcode", listing: newListing("1 High Street", "N2"), expectedCreated: true},
+		{name: "no address line", listing: newListing("", "N1"), expectedCreated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, created, err := repo.CreateIfNotExists(context.Background(), tt.listing)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCreated, created)
+			if tt.expectedCreated {
+				assert.Same(t, tt.listing, stored)
+				assert.NotEqual(t, first.ID, stored.ID)
+			} else {
+				assert.Equal(t, first.ID, stored.ID)
+				assert.Zero(t, tt.listing.ID)
+			}
+		})
+	}
+
+	t.Run("invalid listing", func(t *testing.T) {
+		_, created, err := repo.CreateIfNotExists(context.Background(), newListing("9 New Road", ""))
+		assert.Error(t, err)
+		assert.False(t, created)
+	})
+}
+
+func TestListingRepository_GetPriceHistory(t *testing.T) {
+	repo := &ListingRepositoryImpl{data: make(map[int64]*Listing), ids: NewSequentialIDGenerator()}
+	listing := func(priceInCents int64) *Listing {
+		return &Listing{
+			ID: 1,
+			AddressDetails: AddressDetails{
+				City:              "Leeds",
+				ShortenedPostcode: "LS1",
+				Region:            RegionNorthEast,
+			},
+			PropertyType: PropertyTypeApartment,
+			PriceInCents: priceInCents,
+		}
+	}
+	_, err := repo.Upsert(context.Background(), listing(25000000))
+	require.NoError(t, err)
+
+	history, err := repo.GetPriceHistory(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+	assert.NotNil(t, history)
+
+	before := time.Now()
+	require.NoError(t, repo.Update(context.Background(), listing(24000000)))
+	require.NoError(t, repo.Update(context.Background(), listing(24000000)))
+	_, err = repo.Upsert(context.Background(), listing(22500000))
+	require.NoError(t, err)
+
+	history, err = repo.GetPriceHistory(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, int64(25000000), history[0].OldPriceInCents)
+	assert.Equal(t, int64(24000000), history[0].NewPriceInCents)
+	assert.Equal(t, int64(24000000), history[1].OldPriceInCents)
+	assert.Equal(t, int64(22500000), history[1].NewPriceInCents)
+	assert.False(t, history[0].ChangedAt.Before(before))
+	assert.False(t, history[1].ChangedAt.Before(history[0].ChangedAt))
+
+	require.NoError(t, repo.Delete(context.Background(), 1))
+	_, err = repo.GetPriceHistory(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Restoring the listing brings its history back
+	require.NoError(t, repo.Restore(context.Background(), 1))
+	history, err = repo.GetPriceHistory(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+}
+
+func TestListingRepository_GetPriceReduced(t *testing.T) {
+	ctx := context.Background()
+	repo := &ListingRepositoryImpl{data: make(map[int64]*Listing), ids: NewSequentialIDGenerator()}
+	listing := func(id, priceInCents int64) *Listing {
+		return &Listing{
+			ID: id,
+			AddressDetails: AddressDetails{
+				City:              "Leeds",
+				ShortenedPostcode: "LS1",
+				Region:            RegionNorthEast,
+			},
+			PropertyType: PropertyTypeApartment,
+			PriceInCents: priceInCents,
+		}
+	}
+	// 1 is repriced down, 2 is never reduced and 3 is cut and then raised back
+	for id := int64(1); id <= 3; id++ {
+		_, err := repo.Upsert(ctx, listing(id, 25000000))
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.Update(ctx, listing(1, 23000000)))
+	require.NoError(t, repo.Update(ctx, listing(2, 26000000)))
+	require.NoError(t, repo.Update(ctx, listing(3, 24000000)))
+	require.NoError(t, repo.Update(ctx, listing(3, 25000000)))
+
+	reduced, err := repo.GetPriceReduced(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, reduced, 1)
+	assert.Equal(t, int64(1), reduced[0].ID)
+
+	// A reduction made before the window no longer counts
+	repo.priceHistory[1][0].ChangedAt = time.Now().AddDate(0, 0, -60)
+	reduced, err = repo.GetPriceReduced(ctx, time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Empty(t, reduced)
+	reduced, err = repo.GetPriceReduced(ctx, time.Now().AddDate(0, 0, -90))
+	require.NoError(t, err)
+	assert.Len(t, reduced, 1)
+}
+
+func TestListingRepository_DeterministicOrder(t *testing.T) {
+	repo := NewListingRepository()
+
+	ids := func(listings []*Listing) []int64 {
+		result := make([]int64, 0, len(listings))
+		for _, listing := range listings {
+			result = append(result, listing.ID)
+		}
+		return result
+	}
+
+	first, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	require.Greater(t, len(first), 1)
+	expected := ids(first)
+	assert.True(t, slices.IsSorted(expected))
+
+	for i := 0; i < 20; i++ {
+		result, err := repo.GetAll(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, expected, ids(result))
+	}
+
+	byRegion, err := repo.GetByRegion(context.Background(), string(RegionLondon))
+	require.NoError(t, err)
+	assert.True(t, slices.IsSorted(ids(byRegion)))
+	byPrice, err := repo.GetByPriceRange(context.Background(), 0, 1<<62)
+	require.NoError(t, err)
+	assert.Equal(t, expected, ids(byPrice))
+}
+
+func TestListingRepository_ReadsReturnCopies(t *testing.T) {
+	repo := &ListingRepositoryImpl{data: make(map[int64]*Listing), ids: NewSequentialIDGenerator()}
+	listing := &Listing{
+		AddressDetails: AddressDetails{
+			City:              "Leeds",
+			ShortenedPostcode: "LS1",
+			Region:            RegionNorthEast,
+			Coordinates:       &Coordinates{Latitude: 53.8, Longitude: -1.55},
+		},
+		PropertyType: PropertyTypeApartment,
+		PriceInCents: 20000000,
+		Photos:       []Photo{{ID: 1, OriginalURL: "https://example.com/1.jpg"}},
+	}
+	require.NoError(t, repo.Create(context.Background(), listing))
+	id := listing.ID
+	visibleAt := *listing.MadeVisibleAt
+
+	mutate := func(l *Listing) {
+		l.PriceInCents = 1
+		l.AddressDetails.City = "Changed"
+		l.AddressDetails.Coordinates.Latitude = 0
+		l.Photos[0].OriginalURL = "https://example.com/changed.jpg"
+		l.Photos = append(l.Photos, Photo{ID: 2})
+		*l.MadeVisibleAt = "changed"
+	}
+
+	found, err := repo.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	mutate(found)
+	all, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	mutate(all[0])
+	byCity, err := repo.SearchByCity(context.Background(), "Leeds")
+	require.NoError(t, err)
+	mutate(byCity[0])
+
+	stored, err := repo.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20000000), stored.PriceInCents)
+	assert.Equal(t, "Leeds", stored.AddressDetails.City)
+	assert.Equal(t, 53.8, stored.AddressDetails.Coordinates.Latitude)
+	assert.Equal(t, []Photo{{ID: 1, OriginalURL: "https://example.com/1.jpg"}}, stored.Photos)
+	assert.Equal(t, visibleAt, *stored.MadeVisibleAt)
+}
+
+func TestListingRepository_Search(t *testing.T) {
+	repo := &ListingRepositoryImpl{data: make(map[int64]*Listing), ids: NewSequentialIDGenerator()}
+	newListing := func(city string, region Region, propertyType PropertyType, bedrooms int, priceInCents int64, tenanted bool) *Listing {
+		return &Listing{
+			AddressDetails: AddressDetails{City: city, ShortenedPostcode: "X1", Region: region},
+			PropertyType:   propertyType,
+			Bedrooms:       bedrooms,
+			PriceInCents:   priceInCents,
+			IsTenanted:     tenanted,
+		}
+	}
+	for _, listing := range []*Listing{
+		newListing("London", RegionLondon, PropertyTypeApartment, 2, 14000000, false),
+		newListing("London", RegionLondon, PropertyTypeApartment, 2, 16000000, false),
+		newListing("London", RegionLondon, PropertyTypeApartment, 3, 14000000, true),
+		newListing("London", RegionLondon, PropertyTypeDetached, 2, 14000000, false),
+		newListing("Manchester", RegionNorthWest, PropertyTypeApartment, 2, 14000000, false),
+	} {
+		require.NoError(t, repo.Create(context.Background(), listing))
+	}
+
+	region := RegionLondon
+	apartment := PropertyTypeApartment
+	city := "MANCH"
+	two := 2
+	maxPrice := int64(15000000)
+	tenanted := true
+
+	tests := []struct {
+		name        string
+		filter      ListingFilter
+		expectedIDs []int64
+	}{
+		{name: "no criteria", filter: ListingFilter{}, expectedIDs: []int64{1, 2, 3, 4, 5}},
+		{
+			name:        "2-bed apartments in London under 150k",
+			filter:      ListingFilter{Region: &region, PropertyType: &apartment, MinBedrooms: &two, MaxBedrooms: &two, MaxPrice: &maxPrice},
+			expectedIDs: []int64{1},
+		},
+		{name: "city substring", filter: ListingFilter{City: &city}, expectedIDs: []int64{5}},
+		{name: "flag", filter: ListingFilter{ListingFlags: ListingFlags{IsTenanted: &tenanted}}, expectedIDs: []int64{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.Search(context.Background(), tt.filter)
+			require.NoError(t, err)
+			ids := make([]int64, 0, len(result))
+			for _, listing := range result {
+				ids = append(ids, listing.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestListingFilter_Validate(t *testing.T) {
+	unknownRegion := Region("Atlantis")
+	low, high := int64(100), int64(200)
+	one, three := 1, 3
+
+	assert.NoError(t, ListingFilter{}.Validate())
+	assert.NoError(t, ListingFilter{MinPrice: &low, MaxPrice: &high}.Validate())
+	assert.Error(t, ListingFilter{Region: &unknownRegion}.Validate())
+	assert.Error(t, ListingFilter{MinPrice: &high, MaxPrice: &low}.Validate())
+	assert.Error(t, ListingFilter{MinBedrooms: &three, MaxBedrooms: &one}.Validate())
+	assert.EqualError(t, ListingFilter{MinSize: &three, MaxSize: &one}.Validate(), "minSize cannot be greater than maxSize")
+}
+
+func TestListingRepository_GetBySizeRange(t *testing.T) {
+	repo := NewListingRepository()
+
+	// The sample sizes run from 32 to 2342 sq ft
+	tests := []struct {
+		name     string
+		minSqFt  int
+		maxSqFt  int
+		expected []int64
+	}{
+		{name: "tight range", minSqFt: 300, maxSqFt: 301, expected: []int64{68, 79, 82, 103}},
+		{name: "smallest", minSqFt: 0, maxSqFt: 32, expected: []int64{148}},
+		{name: "largest", minSqFt: 2342, maxSqFt: 2342, expected: []int64{185}},
+		{name: "gap between sizes", minSqFt: 1235, maxSqFt: 2341, expected: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.GetBySizeRange(context.Background(), tt.minSqFt, tt.maxSqFt)
+			require.NoError(t, err)
+			ids := make([]int64, 0, len(result))
+			for _, listing := range result {
+				ids = append(ids, listing.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+
+	all, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	everything, err := repo.GetBySizeRange(context.Background(), 0, 2342)
+	require.NoError(t, err)
+	assert.Len(t, everything, len(all))
+
+	_, err = repo.GetBySizeRange(context.Background(), 301, 300)
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.EqualError(t, err, "minSize cannot be greater than maxSize")
+}
+
+func TestListing_ComputeGrossYield(t *testing.T) {
+	assert.Equal(t, 0.0, (&Listing{MonthlyRentalIncomeInCents: 100000}).ComputeGrossYield())
+
+	repo := NewListingRepository()
+	for _, id := range []int64{187, 185, 79, 68} {
+		listing, err := repo.GetByID(context.Background(), id)
+		require.NoError(t, err)
+		assert.InDelta(t, listing.GrossYield, listing.ComputeGrossYield(), 1e-6, "listing %d", id)
+	}
+}
+
+func TestListingRepository_RecomputesGrossYield(t *testing.T) {
+	repo := NewListingRepository()
+	ctx := context.Background()
+
+	listing := &Listing{
+		PropertyType:               PropertyTypeApartment,
+		PriceInCents:               20000000,
+		MonthlyRentalIncomeInCents: 100000,
+		GrossYield:                 0.5,
+		AddressDetails:             AddressDetails{City: "London", ShortenedPostcode: "N1", Region: RegionLondon},
+	}
+	require.NoError(t, repo.Create(ctx, listing))
+	stored, err := repo.GetByID(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.06, stored.GrossYield, 1e-9)
+
+	stored.MonthlyRentalIncomeInCents = 150000
+	stored.GrossYield = 0
+	require.NoError(t, repo.Update(ctx, stored))
+	updated, err := repo.GetByID(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.09, updated.GrossYield, 1e-9)
+}
+
+func TestListingRepository_GetFeatured(t *testing.T) {
+	repo := NewListingRepository()
+	ctx := context.Background()
+
+	featured, err := repo.GetFeatured(ctx)
+	require.NoError(t, err)
+	ids := make([]int64, 0, len(featured))
+	for _, listing := range featured {
+		assert.True(t, listing.IsFeatured)
+		ids = append(ids, listing.ID)
+	}
+	// 80 was made visible after 66
+	assert.Equal(t, []int64{80, 66}, ids)
+
+	listing, err := repo.GetByID(ctx, 187)
+	require.NoError(t, err)
+	listing.IsFeatured = true
+	require.NoError(t, repo.Update(ctx, listing))
+
+	featured, err = repo.GetFeatured(ctx)
+	require.NoError(t, err)
+	require.Len(t, featured, 3)
+	// 187 has never been made visible, so it sorts last
+	assert.Equal(t, int64(187), featured[2].ID)
+}
+
+func TestListingRepository_GetByFlags(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name        string
+		flags       ListingFlags
+		expectedIDs []int64
+	}{
+		{name: "share sale", flags: ListingFlags{IsShareSale: &yes}, expectedIDs: []int64{80, 81, 94, 105, 106, 178}},
+		{name: "share sale and cash only", flags: ListingFlags{IsShareSale: &yes, IsCashOnly: &yes}, expectedIDs: []int64{80, 94, 106}},
+		{name: "company and not tenanted", flags: ListingFlags{IsCompany: &yes, IsTenanted: &no}, expectedIDs: []int64{94}},
+		{name: "no match", flags: ListingFlags{IsNewBuild: &yes}, expectedIDs: []int64{}},
+	}
+
+	repo := NewListingRepository()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listings, err := repo.GetByFlags(context.Background(), tt.flags)
+			require.NoError(t, err)
+			ids := make([]int64, 0, len(listings))
+			for _, listing := range listings {
+				ids = append(ids, listing.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+
+	all, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	unfiltered, err := repo.GetByFlags(context.Background(), ListingFlags{})
+	require.NoError(t, err)
+	assert.Len(t, unfiltered, len(all))
+}
+
+func TestListingRepository_CountByPropertyType(t *testing.T) {
+	newListing := func(propertyType PropertyType) *Listing {
+		return &Listing{
+			AddressDetails: AddressDetails{City: "Leeds", ShortenedPostcode: "LS1", Region: RegionNorthEast, Country: "UK"},
+			PropertyType:   propertyType,
+			PriceInCents:   10000000,
+		}
+	}
+	repo := NewListingRepository()
+	require.NoError(t, repo.ReplaceAll(context.Background(), []*Listing{
+		newListing(PropertyTypeApartment),
+		newListing(PropertyTypeTerraced),
+		newListing(PropertyTypeApartment),
+		newListing(PropertyTypeDetached),
+		newListing(PropertyTypeApartment),
+	}, false))
+
+	counts, err := repo.CountByPropertyType(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[PropertyType]int{
+		PropertyTypeApartment: 3,
+		PropertyTypeTerraced:  1,
+		PropertyTypeDetached:  1,
+	}, counts)
+}
+
+func TestListingRepository_CreateNormalizesPostcodes(t *testing.T) {
+	repo := NewListingRepository()
+	ctx := context.Background()
+
+	listing := &Listing{
+		AddressDetails: AddressDetails{City: "London", Postcode: "w14 8ff", Region: RegionLondon},
+		PropertyType:   PropertyTypeApartment,
+		PriceInCents:   10000000,
+	}
+	require.NoError(t, repo.Create(ctx, listing))
+	assert.Equal(t, "W14 8FF", listing.AddressDetails.Postcode)
+	assert.Equal(t, "W14", listing.AddressDetails.ShortenedPostcode)
+
+	update := listing.clone()
+	update.AddressDetails.Postcode = "N1 7AA"
+	update.AddressDetails.ShortenedPostcode = ""
+	require.NoError(t, repo.Update(ctx, update))
+	stored, err := repo.GetByID(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "N1", stored.AddressDetails.ShortenedPostcode)
+
+	malformed := &Listing{
+		AddressDetails: AddressDetails{City: "London", Postcode: "W14 8F", Region: RegionLondon},
+		PropertyType:   PropertyTypeApartment,
+		PriceInCents:   10000000,
+	}
+	assert.EqualError(t, repo.Create(ctx, malformed), `postcode "W14 8F" is not a valid UK postcode`)
+}
+
+func TestListingRepository_CreateChecksMinimumDeposit(t *testing.T) {
+	repo := NewListingRepository()
+	ctx := context.Background()
+	newListing := func(minimumDeposit int64) *Listing {
+		return &Listing{
+			AddressDetails:        AddressDetails{City: "London", ShortenedPostcode: "N1", Region: RegionLondon},
+			PropertyType:          PropertyTypeApartment,
+			PriceInCents:          10000000,
+			MinimumDepositInCents: minimumDeposit,
+		}
+	}
+
+	assert.NoError(t, repo.Create(ctx, newListing(2500000)))
+	assert.EqualError(t, repo.Create(ctx, newListing(10000001)), "minimum deposit cannot be greater than the price")
+
+	update := newListing(20000000)
+	update.ID = 66
+	assert.EqualError(t, repo.Update(ctx, update), "minimum deposit cannot be greater than the price")
+}
+
+// TestListingRepository_ConcurrentAccess hammers the repository from many
+// goroutines. It is mainly useful under go test -race, which would flag a
+// listing shared between the repository and a caller.
+func TestListingRepository_ConcurrentAccess(t *testing.T) {
+	const workers = 16
+	const iterations = 50
+	repo := NewListingRepository()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				listing := &Listing{
+					AddressDetails: AddressDetails{City: "London", ShortenedPostcode: "N1", Region: RegionLondon},
+					PropertyType:   PropertyTypeApartment,
+					PriceInCents:   10000000,
+					Tags:           []string{"garden"},
+					Photos:         []Photo{{OriginalURL: "https://example.com/a.jpg"}},
+				}
+				if !assert.NoError(t, repo.Create(ctx, listing)) {
+					return
+				}
+				// Keep using the listing after handing it over, as callers do
+				listing.PriceInCents += 100
+				listing.Tags = append(listing.Tags, "parking")
+				listing.Photos[0].Position = 1
+				if !assert.NoError(t, repo.Update(ctx, listing)) {
+					return
+				}
+				listing.Description = "updated"
+
+				got, err := repo.GetByID(ctx, listing.ID)
+				if assert.NoError(t, err) {
+					got.Description = "changed by a reader"
+					got.Photos[0].OriginalURL = "https://example.com/b.jpg"
+				}
+				all, err := repo.GetAll(ctx)
+				if assert.NoError(t, err) {
+					for _, other := range all {
+						_ = other.PriceInCents + int64(len(other.Tags)) + int64(len(other.Photos))
+					}
+				}
+				if _, err := repo.Search(ctx, ListingFilter{}); !assert.NoError(t, err) {
+					return
+				}
+				if i%2 == 0 {
+					assert.NoError(t, repo.Delete(ctx, listing.ID))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	all, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	ids := make(map[int64]bool, len(all))
+	for _, listing := range all {
+		assert.False(t, ids[listing.ID], "duplicate id %d", listing.ID)
+		ids[listing.ID] = true
+		if listing.Description == "changed by a reader" {
+			t.Errorf("listing %d was changed through a copy returned by GetByID", listing.ID)
+		}
+	}
+}
+
+func TestListingRepository_Timestamps(t *testing.T) {
+	repo := NewListingRepository()
+	ctx := context.Background()
+
+	listing := &Listing{
+		PropertyType:   PropertyTypeApartment,
+		PriceInCents:   20000000,
+		AddressDetails: AddressDetails{City: "London", ShortenedPostcode: "N1", Region: RegionLondon},
+		// Supplied times are ignored
+		CreatedAt: "2000-01-01T00:00:00Z",
+		UpdatedAt: "2000-01-01T00:00:00Z",
+	}
+	before := time.Now().Truncate(time.Second)
+	require.NoError(t, repo.Create(ctx, listing))
+
+	stored, err := repo.GetByID(ctx, listing.ID)
+	require.NoError(t, err)
+	createdAt, err := time.Parse(time.RFC3339, stored.CreatedAt)
+	require.NoError(t, err)
+	assert.False(t, createdAt.Before(before))
+	assert.Equal(t, stored.CreatedAt, stored.UpdatedAt)
+	assert.Equal(t, stored.CreatedAt, *stored.MadeVisibleAt)
+	visibleAt := stored.MadeVisibleAt
+
+	// Back-to-back writes land within the same second, so each one must still
+	// move UpdatedAt forward
+	updatedAt := createdAt
+	for i := 0; i < 3; i++ {
+		stored.Description = fmt.Sprintf("update %d", i)
+		stored.CreatedAt = ""
+		if i == 2 {
+			created, err := repo.Upsert(ctx, stored)
+			require.NoError(t, err)
+			assert.False(t, created)
+		} else {
+			require.NoError(t, repo.Update(ctx, stored))
+		}
+
+		stored, err = repo.GetByID(ctx, listing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, createdAt.Format(time.RFC3339), stored.CreatedAt)
+		assert.Equal(t, visibleAt, stored.MadeVisibleAt)
+		next, err := time.Parse(time.RFC3339, stored.UpdatedAt)
+		require.NoError(t, err)
+		assert.True(t, next.After(updatedAt), "update %d left UpdatedAt at %s", i, stored.UpdatedAt)
+		updatedAt = next
+	}
+
+	// Sample listings are dated from when they were made visible
+	sample, err := repo.GetByID(ctx, 66)
+	require.NoError(t, err)
+	assert.Equal(t, *sample.MadeVisibleAt, sample.CreatedAt)
+	assert.Equal(t, sample.CreatedAt, sample.UpdatedAt)
+}
+
+func listingIDs(listings []*Listing) []int64 {
+	ids := make([]int64, len(listings))
+	for i, listing := range listings {
+		ids[i] = listing.ID
+	}
+	return ids
+}
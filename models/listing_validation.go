@@ -0,0 +1,171 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// minPlausibleSizeSqFt and maxPlausibleSizeSqFt bound believable property sizes
+	minPlausibleSizeSqFt = 100
+	maxPlausibleSizeSqFt = 10000
+)
+
+// Fields that a ValidationProfile can require
+const (
+	RequiredFieldDescription = "description"
+	RequiredFieldPhotos      = "photos"
+	RequiredFieldPostcode    = "postcode"
+)
+
+// ukPostcodePattern matches a full UK postcode such as "SW1A 1AA"
+var ukPostcodePattern = regexp.MustCompile(`^[A-Z]{1,2}[0-9][A-Z0-9]? ?[0-9][A-Z]{2}$`)
+
+// ListingIssues holds every problem found when validating a listing. Errors
+// prevent the listing from being stored, warnings do not.
+type ListingIssues struct {
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+// ValidateListing checks a listing and returns all errors and warnings rather
+// than stopping at the first problem
+func ValidateListing(listing *Listing) ListingIssues {
+	issues := ListingIssues{
+		Errors:   []string{},
+		Warnings: []string{},
+	}
+
+	if listing.AddressDetails.City == "" {
+		issues.Errors = append(issues.Errors, "city is required")
+	}
+	if postcode := listing.AddressDetails.Postcode; strings.TrimSpace(postcode) != "" {
+		if _, err := NormalizePostcode(postcode); err != nil {
+			issues.Errors = append(issues.Errors, fmt.Sprintf("postcode %q is not a valid UK postcode", postcode))
+		}
+	}
+	if listing.AddressDetails.ShortenedPostcode == "" {
+		issues.Errors = append(issues.Errors, "shortened postcode is required")
+	}
+	if listing.AddressDetails.Region == "" {
+		issues.Errors = append(issues.Errors, "region is required")
+	}
+	if listing.PropertyType == "" {
+		issues.Errors = append(issues.Errors, "property type is required")
+	}
+	if listing.PriceInCents <= 0 {
+		issues.Errors = append(issues.Errors, "price must be greater than 0")
+	}
+	if listing.MinimumDepositInCents > listing.PriceInCents {
+		issues.Errors = append(issues.Errors, "minimum deposit cannot be greater than the price")
+	}
+	if listing.Status != "" && !listing.Status.IsValid() {
+		issues.Errors = append(issues.Errors, fmt.Sprintf("status must be one of: %s, %s", ListingStatusDraft, ListingStatusPublished))
+	}
+
+	issues.Errors = append(issues.Errors, validateTags(listing.Tags)...)
+
+	if listing.AddressDetails.Postcode == "" {
+		issues.Warnings = append(issues.Warnings, "postcode is missing")
+	}
+	if listing.SizeSqFt < minPlausibleSizeSqFt || listing.SizeSqFt > maxPlausibleSizeSqFt {
+		issues.Warnings = append(issues.Warnings, fmt.Sprintf("size of %d sq ft looks implausible", listing.SizeSqFt))
+	}
+
+	return issues
+}
+
+// ValidationProfile is a set of fields that must be filled in on top of the
+// checks made by ValidateListing
+type ValidationProfile struct {
+	RequiredFields []string
+}
+
+// DraftProfile lets a listing be saved while still incomplete. It requires
+// nothing beyond ValidateListing, which the repository applies on every write.
+var DraftProfile = ValidationProfile{}
+
+// DefaultPublishProfile is the profile a listing must pass to be published
+// when no required fields are configured
+var DefaultPublishProfile = ValidationProfile{
+	RequiredFields: []string{RequiredFieldDescription, RequiredFieldPhotos, RequiredFieldPostcode},
+}
+
+// Validate checks the listing against ValidateListing and the profile's required fields
+func (p ValidationProfile) Validate(listing *Listing) ListingIssues {
+	issues := ValidateListing(listing)
+	for _, field := range p.RequiredFields {
+		switch field {
+		case RequiredFieldDescription:
+			if strings.TrimSpace(listing.Description) == "" {
+				issues.Errors = append(issues.Errors, "description is required")
+			}
+		case RequiredFieldPhotos:
+			if len(listing.Photos) == 0 {
+				issues.Errors = append(issues.Errors, "at least one photo is required")
+			}
+		case RequiredFieldPostcode:
+			if _, err := NormalizePostcode(listing.AddressDetails.Postcode); err != nil {
+				issues.Errors = append(issues.Errors, "a valid postcode is required")
+			}
+		default:
+			issues.Errors = append(issues.Errors, fmt.Sprintf("unknown required field %q", field))
+		}
+	}
+	return issues
+}
+
+// qualityProfile flags the gaps that make a listing less appealing to buyers
+var qualityProfile = ValidationProfile{
+	RequiredFields: []string{RequiredFieldDescription, RequiredFieldPhotos},
+}
+
+// ListingQualityReport lists the data-quality problems found on one listing
+type ListingQualityReport struct {
+	ListingID int64    `json:"id"`
+	Issues    []string `json:"issues"`
+}
+
+// CheckListingQuality returns every error and warning that makes the listing
+// incomplete or implausible, such as missing photos, an empty description or
+// an unlikely size. It returns an empty slice for a listing with no problems.
+func CheckListingQuality(listing *Listing) []string {
+	issues := qualityProfile.Validate(listing)
+	return append(issues.Errors, issues.Warnings...)
+}
+
+// ValidationError is returned when a listing fails ValidateListing
+type ValidationError struct {
+	Issues []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid listing: " + strings.Join(e.Issues, "; ")
+}
+
+// Is lets errors.Is match a ValidationError against ErrValidation
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// PublishError is returned when a listing fails the publish profile
+type PublishError struct {
+	Issues []string
+}
+
+func (e *PublishError) Error() string {
+	return "listing cannot be published: " + strings.Join(e.Issues, "; ")
+}
+
+// ImportResult reports the outcome of importing a single listing
+type ImportResult struct {
+	Index    int      `json:"index"`
+	ID       int64    `json:"id,omitempty"`
+	Status   int      `json:"status"`
+	Created  bool     `json:"created"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+func (r ImportResult) ItemStatus() int { return r.Status }
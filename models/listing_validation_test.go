@@ -0,0 +1,178 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateListing(t *testing.T) {
+	tests := []struct {
+		name             string
+		listing          *Listing
+		expectedErrors   []string
+		expectedWarnings []string
+	}{
+		{
+			name: "valid listing",
+			listing: &Listing{
+				AddressDetails: AddressDetails{
+					City:              "London",
+					Postcode:          "W14 9AA",
+					ShortenedPostcode: "W14",
+					Region:            RegionLondon,
+				},
+				PropertyType: PropertyTypeApartment,
+				PriceInCents: 10000000,
+				SizeSqFt:     500,
+			},
+			expectedErrors:   []string{},
+			expectedWarnings: []string{},
+		},
+		{
+			name: "warnings only",
+			listing: &Listing{
+				AddressDetails: AddressDetails{
+					City:              "London",
+					ShortenedPostcode: "W14",
+					Region:            RegionLondon,
+				},
+				PropertyType: PropertyTypeApartment,
+				PriceInCents: 10000000,
+				SizeSqFt:     32,
+			},
+			expectedErrors:   []string{},
+			expectedWarnings: []string{"postcode is missing", "size of 32 sq ft looks implausible"},
+		},
+		{
+			name:    "all errors reported",
+			listing: &Listing{SizeSqFt: 500, AddressDetails: AddressDetails{Postcode: "W14 9AA"}},
+			expectedErrors: []string{
+				"city is required",
+				"shortened postcode is required",
+				"region is required",
+				"property type is required",
+				"price must be greater than 0",
+			},
+			expectedWarnings: []string{},
+		},
+		{
+			name: "malformed postcode",
+			listing: &Listing{
+				AddressDetails: AddressDetails{
+					City:              "London",
+					Postcode:          "W14 9A",
+					ShortenedPostcode: "W14",
+					Region:            RegionLondon,
+				},
+				PropertyType: PropertyTypeApartment,
+				PriceInCents: 10000000,
+				SizeSqFt:     500,
+			},
+			expectedErrors:   []string{`postcode "W14 9A" is not a valid UK postcode`},
+			expectedWarnings: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := ValidateListing(tt.listing)
+			assert.Equal(t, tt.expectedErrors, issues.Errors)
+			assert.Equal(t, tt.expectedWarnings, issues.Warnings)
+		})
+	}
+}
+
+func TestValidationProfile_Validate(t *testing.T) {
+	incomplete := &Listing{
+		AddressDetails: AddressDetails{
+			City:              "London",
+			ShortenedPostcode: "N1",
+			Region:            RegionLondon,
+		},
+		PropertyType: PropertyTypeApartment,
+		PriceInCents: 10000000,
+	}
+
+	assert.Empty(t, DraftProfile.Validate(incomplete).Errors)
+	assert.Equal(t, []string{
+		"description is required",
+		"at least one photo is required",
+		"a valid postcode is required",
+	}, DefaultPublishProfile.Validate(incomplete).Errors)
+
+	complete := *incomplete
+	complete.Description = "Bright flat near the station"
+	complete.Photos = []Photo{{ID: 1, OriginalURL: "https://example.com/a.jpg"}}
+	complete.AddressDetails.Postcode = "n1 7aa"
+	assert.Empty(t, DefaultPublishProfile.Validate(&complete).Errors)
+}
+
+func TestNormalizePostcode(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected string
+		wantErr  bool
+	}{
+		{raw: "sw1a 1aa", expected: "SW1A 1AA"},
+		{raw: "  W148FF ", expected: "W14 8FF"},
+		{raw: "m1  1ae", expected: "M1 1AE"},
+		{raw: "EC1A1BB", expected: "EC1A 1BB"},
+		{raw: "W14", wantErr: true},
+		{raw: "not a postcode", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			postcode, err := NormalizePostcode(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, postcode)
+		})
+	}
+}
+
+func TestAddressDetails_NormalizePostcodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  AddressDetails
+		expected AddressDetails
+	}{
+		{
+			name:     "derives the shortened postcode",
+			address:  AddressDetails{Postcode: " w14 8ff"},
+			expected: AddressDetails{Postcode: "W14 8FF", ShortenedPostcode: "W14"},
+		},
+		{
+			name:     "keeps a given shortened postcode",
+			address:  AddressDetails{Postcode: "W148FF", ShortenedPostcode: "w14"},
+			expected: AddressDetails{Postcode: "W14 8FF", ShortenedPostcode: "W14"},
+		},
+		{
+			name:     "cuts a full postcode in the shortened field",
+			address:  AddressDetails{ShortenedPostcode: "W14 8FF"},
+			expected: AddressDetails{ShortenedPostcode: "W14"},
+		},
+		{
+			name:     "leaves a malformed postcode for validation",
+			address:  AddressDetails{Postcode: "W14 8F"},
+			expected: AddressDetails{Postcode: "W14 8F"},
+		},
+		{
+			name:     "clears a blank postcode",
+			address:  AddressDetails{Postcode: "  ", ShortenedPostcode: "N1"},
+			expected: AddressDetails{ShortenedPostcode: "N1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.address.NormalizePostcodes()
+			assert.Equal(t, tt.expected, tt.address)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+CREATE TABLE listings (
+    id                             BIGSERIAL PRIMARY KEY,
+    status                         TEXT NOT NULL,
+    address_line1                  TEXT NOT NULL DEFAULT '',
+    address_line2                  TEXT NOT NULL DEFAULT '',
+    city                           TEXT NOT NULL DEFAULT '',
+    postcode                       TEXT NOT NULL DEFAULT '',
+    shortened_postcode             TEXT NOT NULL DEFAULT '',
+    country                        TEXT NOT NULL DEFAULT '',
+    region                         TEXT NOT NULL DEFAULT '',
+    latitude                       DOUBLE PRECISION,
+    longitude                      DOUBLE PRECISION,
+    -- dedupe_key is the normalized address matched by CreateIfNotExists
+    dedupe_key                     TEXT,
+    bedrooms                       INTEGER NOT NULL DEFAULT 0,
+    bathrooms                      INTEGER NOT NULL DEFAULT 0,
+    description                    TEXT NOT NULL DEFAULT '',
+    gross_yield                    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    is_cash_only                   BOOLEAN NOT NULL DEFAULT FALSE,
+    is_company                     BOOLEAN NOT NULL DEFAULT FALSE,
+    is_featured                    BOOLEAN NOT NULL DEFAULT FALSE,
+    is_new_build                   BOOLEAN NOT NULL DEFAULT FALSE,
+    is_share_sale                  BOOLEAN NOT NULL DEFAULT FALSE,
+    is_tenanted                    BOOLEAN NOT NULL DEFAULT FALSE,
+    -- made_visible_at keeps the RFC 3339 text exactly as the API received it
+    made_visible_at                TEXT,
+    estimated_deposit_in_cents     BIGINT NOT NULL DEFAULT 0,
+    minimum_deposit_in_cents       BIGINT NOT NULL DEFAULT 0,
+    price_in_cents                 BIGINT NOT NULL DEFAULT 0,
+    property_type                  TEXT NOT NULL DEFAULT '',
+    monthly_rental_income_in_cents BIGINT NOT NULL DEFAULT 0,
+    size_sq_ft                     INTEGER NOT NULL DEFAULT 0,
+    build_year                     INTEGER NOT NULL DEFAULT 0,
+    agent_id                       BIGINT NOT NULL DEFAULT 0,
+    tags                           TEXT[]
+);
+
+CREATE INDEX listings_region_idx ON listings (region);
+CREATE INDEX listings_property_type_idx ON listings (property_type);
+CREATE INDEX listings_price_idx ON listings (price_in_cents);
+CREATE INDEX listings_dedupe_key_idx ON listings (dedupe_key);
+
+CREATE TABLE photos (
+    listing_id    BIGINT NOT NULL REFERENCES listings (id) ON DELETE CASCADE,
+    id            BIGINT NOT NULL,
+    position      INTEGER NOT NULL,
+    original_url  TEXT NOT NULL DEFAULT '',
+    standard_url  TEXT NOT NULL DEFAULT '',
+    thumbnail_url TEXT NOT NULL DEFAULT '',
+    mime_type     TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (listing_id, id)
+);
+
+CREATE TABLE price_changes (
+    id                 BIGSERIAL PRIMARY KEY,
+    listing_id         BIGINT NOT NULL REFERENCES listings (id) ON DELETE CASCADE,
+    changed_at         TIMESTAMPTZ NOT NULL,
+    old_price_in_cents BIGINT NOT NULL,
+    new_price_in_cents BIGINT NOT NULL
+);
+
+CREATE INDEX price_changes_listing_id_idx ON price_changes (listing_id, changed_at);
@@ -0,0 +1,9 @@
+-- created_at and updated_at hold RFC 3339 text like made_visible_at. Listings
+-- stored before they existed are dated from when they were made visible.
+ALTER TABLE listings
+    ADD COLUMN created_at TEXT NOT NULL DEFAULT '',
+    ADD COLUMN updated_at TEXT NOT NULL DEFAULT '';
+
+UPDATE listings
+SET created_at = COALESCE(made_visible_at, ''),
+    updated_at = COALESCE(made_visible_at, '');
@@ -0,0 +1,6 @@
+-- deleted_at marks a soft-deleted listing with RFC 3339 text like the other
+-- timestamps. Deleted rows keep their ID, photos and price history so they can
+-- be restored, and every query filters them out.
+ALTER TABLE listings ADD COLUMN deleted_at TEXT;
+
+CREATE INDEX listings_deleted_idx ON listings (id) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"math/big"
+	"strconv"
+)
+
+// RoundingMode decides how a monetary amount that falls between two whole
+// units is rounded
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds to the nearest unit, with halves going away from zero
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven rounds to the nearest unit, with halves going to the even unit
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundDown drops any fraction, rounding towards zero
+	RoundDown RoundingMode = "down"
+)
+
+// StandardRounding is the rounding mode used for monetary calculations unless
+// configured otherwise
+const StandardRounding = RoundHalfUp
+
+// IsValid reports whether the rounding mode is one of the defined modes
+func (m RoundingMode) IsValid() bool {
+	switch m {
+	case RoundHalfUp, RoundHalfEven, RoundDown:
+		return true
+	}
+	return false
+}
+
+// Div returns numerator / denominator rounded to a whole number, computed
+// exactly rather than through floating point. It panics if denominator is 0.
+func (m RoundingMode) Div(numerator, denominator int64) int64 {
+	return m.round(big.NewRat(numerator, denominator))
+}
+
+// MulRate returns amount * rate rounded to a whole number. The rate is taken
+// as the shortest decimal that prints as it, so a rate of 0.1 is exactly a
+// tenth rather than its nearest binary float.
+func (m RoundingMode) MulRate(amount int64, rate float64) int64 {
+	exact, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return 0
+	}
+	return m.round(exact.Mul(exact, new(big.Rat).SetInt64(amount)))
+}
+
+// round rounds x to a whole number using the mode, treating an unknown mode
+// as StandardRounding
+func (m RoundingMode) round(x *big.Rat) int64 {
+	num, den := x.Num(), x.Denom()
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient.Int64()
+	}
+	twiceRemainder := new(big.Int).Lsh(new(big.Int).Abs(remainder), 1)
+	half := twiceRemainder.Cmp(den)
+
+	var away bool
+	switch m {
+	case RoundDown:
+		away = false
+	case RoundHalfEven:
+		away = half > 0 || (half == 0 && quotient.Bit(0) == 1)
+	default:
+		away = half >= 0
+	}
+	if away {
+		if num.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient.Int64()
+}
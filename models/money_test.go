@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundingMode_Div(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        RoundingMode
+		numerator   int64
+		denominator int64
+		expected    int64
+	}{
+		{name: "exact", mode: RoundHalfUp, numerator: 300, denominator: 3, expected: 100},
+		{name: "half up below half", mode: RoundHalfUp, numerator: 249, denominator: 100, expected: 2},
+		{name: "half up at half", mode: RoundHalfUp, numerator: 250, denominator: 100, expected: 3},
+		{name: "half up negative half", mode: RoundHalfUp, numerator: -250, denominator: 100, expected: -3},
+		{name: "half even at half rounds to even", mode: RoundHalfEven, numerator: 250, denominator: 100, expected: 2},
+		{name: "half even at half from odd", mode: RoundHalfEven, numerator: 350, denominator: 100, expected: 4},
+		{name: "half even above half", mode: RoundHalfEven, numerator: 251, denominator: 100, expected: 3},
+		{name: "down", mode: RoundDown, numerator: 299, denominator: 100, expected: 2},
+		{name: "down negative", mode: RoundDown, numerator: -299, denominator: 100, expected: -2},
+		{name: "unknown mode is standard", mode: RoundingMode("bankers"), numerator: 250, denominator: 100, expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.mode.Div(tt.numerator, tt.denominator))
+		})
+	}
+}
+
+func TestRoundingMode_MulRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     RoundingMode
+		amount   int64
+		rate     float64
+		expected int64
+	}{
+		// 375 * 0.036 is 13.499999999999998 in floating point, so math.Round gives 13
+		{name: "half penny that floats get wrong", mode: RoundHalfUp, amount: 375, rate: 0.036, expected: 14},
+		{name: "half penny up", mode: RoundHalfUp, amount: 10050, rate: 0.25, expected: 2513},
+		{name: "half penny even", mode: RoundHalfEven, amount: 10050, rate: 0.25, expected: 2512},
+		{name: "half penny down", mode: RoundDown, amount: 10050, rate: 0.25, expected: 2512},
+		{name: "tenth", mode: RoundHalfUp, amount: 25, rate: 0.1, expected: 3},
+		{name: "tenth even", mode: RoundHalfEven, amount: 25, rate: 0.1, expected: 2},
+		{name: "whole amount", mode: RoundHalfUp, amount: 25000000, rate: 0.25, expected: 6250000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.mode.MulRate(tt.amount, tt.rate))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxMultiStatsFilters caps how many filters one multi-stats request may hold
+const maxMultiStatsFilters = 20
+
+// NamedStatsFilter selects the listings a set of stats is computed over. Empty
+// criteria match every listing.
+type NamedStatsFilter struct {
+	Name         string       `json:"name"`
+	Region       Region       `json:"region,omitempty"`
+	PropertyType PropertyType `json:"propertyType,omitempty"`
+}
+
+// Matches reports whether the listing meets every criterion of the filter
+func (f NamedStatsFilter) Matches(listing *Listing) bool {
+	if f.Region != "" && listing.AddressDetails.Region != f.Region {
+		return false
+	}
+	if f.PropertyType != "" && listing.PropertyType != f.PropertyType {
+		return false
+	}
+	return true
+}
+
+// MultiStatsRequest asks for stats over several named filters at once
+type MultiStatsRequest struct {
+	Filters []NamedStatsFilter `json:"filters"`
+}
+
+// Validate checks there is at least one filter, that names are present and
+// unique, and that every criterion is a known value
+func (r MultiStatsRequest) Validate() error {
+	if len(r.Filters) == 0 {
+		return errors.New("at least one filter is required")
+	}
+	if len(r.Filters) > maxMultiStatsFilters {
+		return errors.Errorf("at most %d filters are allowed", maxMultiStatsFilters)
+	}
+	names := make(map[string]bool, len(r.Filters))
+	for i, filter := range r.Filters {
+		name := strings.TrimSpace(filter.Name)
+		if name == "" {
+			return errors.Errorf("filter at index %d needs a name", i)
+		}
+		if names[name] {
+			return errors.Errorf("filter name %q is used more than once", name)
+		}
+		names[name] = true
+		if filter.Region != "" && !filter.Region.IsValid() {
+			return errors.Errorf("filter %q has an unknown region %q", name, filter.Region)
+		}
+		if filter.PropertyType != "" && !filter.PropertyType.IsValid() {
+			return errors.Errorf("filter %q has an unknown property type %q", name, filter.PropertyType)
+		}
+	}
+	return nil
+}
+
+// ListingStats summarises a set of listings. The price and yield figures are
+// nil when the set is empty.
+type ListingStats struct {
+	Name                string   `json:"name"`
+	Count               int      `json:"count"`
+	MedianPriceInCents  *float64 `json:"medianPriceInCents"`
+	AveragePriceInCents *float64 `json:"averagePriceInCents"`
+	AverageGrossYield   *float64 `json:"averageGrossYield"`
+}
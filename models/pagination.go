@@ -0,0 +1,41 @@
+package models
+
+// Page selects one page of a collection. Number counts from 1.
+type Page struct {
+	Number int
+	Size   int
+}
+
+// PageMeta describes the page returned and how many there are in total
+type PageMeta struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"pageSize"`
+	TotalItems int `json:"totalItems"`
+	TotalPages int `json:"totalPages"`
+}
+
+// Paged is one page of items along with its pagination metadata
+type Paged[T any] struct {
+	Items      []T      `json:"items"`
+	Pagination PageMeta `json:"pagination"`
+}
+
+// Paginate returns the items on the requested page. A page past the end is
+// empty rather than an error, so clients can stop when items runs out.
+func Paginate[T any](items []T, page Page) Paged[T] {
+	meta := PageMeta{
+		Page:       page.Number,
+		PageSize:   page.Size,
+		TotalItems: len(items),
+		TotalPages: (len(items) + page.Size - 1) / page.Size,
+	}
+	// Compare before multiplying so a huge page number can't overflow
+	start := len(items)
+	if page.Number-1 <= len(items)/page.Size {
+		start = min((page.Number-1)*page.Size, len(items))
+	}
+	end := start + min(page.Size, len(items)-start)
+	pageItems := make([]T, end-start)
+	copy(pageItems, items[start:end])
+	return Paged[T]{Items: pageItems, Pagination: meta}
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		name          string
+		page          Page
+		expectedItems []string
+		expectedMeta  PageMeta
+	}{
+		{
+			name:          "first page",
+			page:          Page{Number: 1, Size: 2},
+			expectedItems: []string{"a", "b"},
+			expectedMeta:  PageMeta{Page: 1, PageSize: 2, TotalItems: 5, TotalPages: 3},
+		},
+		{
+			name:          "last partial page",
+			page:          Page{Number: 3, Size: 2},
+			expectedItems: []string{"e"},
+			expectedMeta:  PageMeta{Page: 3, PageSize: 2, TotalItems: 5, TotalPages: 3},
+		},
+		{
+			name:          "past the end",
+			page:          Page{Number: 4, Size: 2},
+			expectedItems: []string{},
+			expectedMeta:  PageMeta{Page: 4, PageSize: 2, TotalItems: 5, TotalPages: 3},
+		},
+		{
+			name:          "huge page number",
+			page:          Page{Number: math.MaxInt, Size: 2},
+			expectedItems: []string{},
+			expectedMeta:  PageMeta{Page: math.MaxInt, PageSize: 2, TotalItems: 5, TotalPages: 3},
+		},
+		{
+			name:          "everything on one page",
+			page:          Page{Number: 1, Size: 10},
+			expectedItems: items,
+			expectedMeta:  PageMeta{Page: 1, PageSize: 10, TotalItems: 5, TotalPages: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paged := Paginate(items, tt.page)
+			assert.Equal(t, tt.expectedItems, paged.Items)
+			assert.Equal(t, tt.expectedMeta, paged.Pagination)
+		})
+	}
+
+	empty := Paginate([]string{}, Page{Number: 1, Size: 20})
+	assert.Equal(t, []string{}, empty.Items)
+	assert.Equal(t, PageMeta{Page: 1, PageSize: 20}, empty.Pagination)
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultPhotoMimeTypes are the image types a photo may have unless others
+// are configured
+var DefaultPhotoMimeTypes = []string{"image/jpeg", "image/png", "image/webp"}
+
+// Validate checks that the photo has three well-formed URLs and, unless
+// mimeTypes is empty, one of those image types, returning a *ValidationError
+// listing every problem
+func (p Photo) Validate(mimeTypes []string) error {
+	if issues := p.issues(mimeTypes); len(issues) > 0 {
+		return &ValidationError{Issues: issues}
+	}
+	return nil
+}
+
+// PhotoIssues checks each photo as Validate does, prefixing the problems with
+// the photo's index so they can be reported alongside other listing issues
+func PhotoIssues(photos []Photo, mimeTypes []string) []string {
+	var issues []string
+	for i, photo := range photos {
+		for _, issue := range photo.issues(mimeTypes) {
+			issues = append(issues, fmt.Sprintf("photos[%d]: %s", i, issue))
+		}
+	}
+	return issues
+}
+
+func (p Photo) issues(mimeTypes []string) []string {
+	var issues []string
+	for _, photoURL := range []struct {
+		field string
+		value string
+	}{
+		{"originalURL", p.OriginalURL},
+		{"standardURL", p.StandardURL},
+		{"thumbnailURL", p.ThumbnailURL},
+	} {
+		if strings.TrimSpace(photoURL.value) == "" {
+			issues = append(issues, photoURL.field+" is required")
+		} else if !isWebURL(photoURL.value) {
+			issues = append(issues, photoURL.field+" must be an absolute http or https URL")
+		}
+	}
+	if len(mimeTypes) > 0 && !slices.ContainsFunc(mimeTypes, func(mimeType string) bool {
+		return strings.EqualFold(mimeType, p.MimeType)
+	}) {
+		issues = append(issues, fmt.Sprintf("mimeType %q is not one of %s", p.MimeType, strings.Join(mimeTypes, ", ")))
+	}
+	return issues
+}
+
+// isWebURL reports whether raw is an absolute http or https URL with a host
+func isWebURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// withPhoto returns a copy of photos with photo added as the last one and
+// given the next free ID, or an error matching ErrConflict if a photo already
+// has its original URL
+func withPhoto(photos []Photo, photo Photo) ([]Photo, error) {
+	for _, existing := range photos {
+		if existing.OriginalURL == photo.OriginalURL {
+			return nil, ConflictErrorf("photo %q is already on the listing", photo.OriginalURL)
+		}
+	}
+	photo.ID = 0
+	photo.Position = len(photos)
+	return normalizePhotos(append(slices.Clone(photos), photo)), nil
+}
+
+// withoutPhoto returns a copy of photos without the one with originalURL,
+// renumbered from position 0, or ErrNotFound if there is no such photo
+func withoutPhoto(photos []Photo, originalURL string) ([]Photo, error) {
+	remaining := make([]Photo, 0, len(photos))
+	for _, photo := range photos {
+		if photo.OriginalURL != originalURL {
+			remaining = append(remaining, photo)
+		}
+	}
+	if len(remaining) == len(photos) {
+		return nil, errors.Wrapf(ErrNotFound, "photo not found with original URL: %s", originalURL)
+	}
+	return normalizePhotos(remaining), nil
+}
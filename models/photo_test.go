@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPhoto(name, mimeType string) Photo {
+	return Photo{
+		OriginalURL:  "https://example.com/" + name,
+		StandardURL:  "https://example.com/" + name + "_standard",
+		ThumbnailURL: "https://example.com/" + name + "_thumbnail",
+		MimeType:     mimeType,
+	}
+}
+
+func TestPhoto_Validate(t *testing.T) {
+	assert.NoError(t, newTestPhoto("a.jpg", "image/jpeg").Validate(DefaultPhotoMimeTypes))
+	assert.NoError(t, newTestPhoto("a.jpg", "IMAGE/PNG").Validate(DefaultPhotoMimeTypes))
+	// Without MIME types only the URLs are checked
+	assert.NoError(t, newTestPhoto("a.gif", "image/gif").Validate(nil))
+
+	missingURL := newTestPhoto("a.jpg", "image/png")
+	missingURL.ThumbnailURL = " "
+	err := missingURL.Validate(DefaultPhotoMimeTypes)
+	assert.ErrorIs(t, err, ErrValidation)
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{"thumbnailURL is required"}, validationErr.Issues)
+
+	malformed := newTestPhoto("a.jpg", "image/png")
+	malformed.OriginalURL = "example.com/a.jpg"
+	malformed.StandardURL = "ftp://example.com/a.jpg"
+	require.ErrorAs(t, malformed.Validate(DefaultPhotoMimeTypes), &validationErr)
+	assert.Equal(t, []string{
+		"originalURL must be an absolute http or https URL",
+		"standardURL must be an absolute http or https URL",
+	}, validationErr.Issues)
+
+	err = Photo{MimeType: "text/html"}.Validate(DefaultPhotoMimeTypes)
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{
+		"originalURL is required",
+		"standardURL is required",
+		"thumbnailURL is required",
+		`mimeType "text/html" is not one of image/jpeg, image/png, image/webp`,
+	}, validationErr.Issues)
+}
+
+func TestPhotoIssues(t *testing.T) {
+	photos := []Photo{
+		newTestPhoto("a.jpg", "image/jpeg"),
+		newTestPhoto("b.gif", "image/gif"),
+	}
+	assert.Empty(t, PhotoIssues(photos[:1], DefaultPhotoMimeTypes))
+	assert.Equal(t, []string{`photos[1]: mimeType "image/gif" is not one of image/jpeg, image/png, image/webp`}, PhotoIssues(photos, DefaultPhotoMimeTypes))
+	assert.Empty(t, PhotoIssues(photos, []string{"image/jpeg", "image/gif"}))
+}
+
+func TestListingRepository_Photos(t *testing.T) {
+	ctx := context.Background()
+	repo := NewListingRepository()
+	listing := &Listing{
+		PropertyType:   PropertyTypeApartment,
+		PriceInCents:   20000000,
+		AddressDetails: AddressDetails{City: "London", ShortenedPostcode: "N1", Region: RegionLondon},
+	}
+	require.NoError(t, repo.Create(ctx, listing))
+
+	photos, err := repo.ListPhotos(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.Empty(t, photos)
+
+	first := newTestPhoto("a.jpg", "image/jpeg")
+	// The position and ID are assigned by the repository
+	first.Position = 5
+	first.ID = 40
+	require.NoError(t, repo.AddPhoto(ctx, listing.ID, first))
+	require.NoError(t, repo.AddPhoto(ctx, listing.ID, newTestPhoto("b.png", "image/png")))
+	require.NoError(t, repo.AddPhoto(ctx, listing.ID, newTestPhoto("c.webp", "image/webp")))
+
+	photos, err = repo.ListPhotos(ctx, listing.ID)
+	require.NoError(t, err)
+	require.Len(t, photos, 3)
+	for i, photo := range photos {
+		assert.Equal(t, int64(i+1), photo.ID)
+		assert.Equal(t, i, photo.Position)
+	}
+	assert.Equal(t, "https://example.com/a.jpg", photos[0].OriginalURL)
+
+	// The listing itself carries the photos too
+	stored, err := repo.GetByID(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, photos, stored.Photos)
+
+	assert.ErrorIs(t, repo.AddPhoto(ctx, listing.ID, newTestPhoto("a.jpg", "image/jpeg")), ErrConflict)
+	missingURL := newTestPhoto("d.jpg", "image/jpeg")
+	missingURL.StandardURL = ""
+	assert.ErrorIs(t, repo.AddPhoto(ctx, listing.ID, missingURL), ErrValidation)
+	assert.ErrorIs(t, repo.AddPhoto(ctx, 999, newTestPhoto("d.jpg", "image/jpeg")), ErrNotFound)
+
+	require.NoError(t, repo.RemovePhoto(ctx, listing.ID, "https://example.com/a.jpg"))
+	photos, err = repo.ListPhotos(ctx, listing.ID)
+	require.NoError(t, err)
+	require.Len(t, photos, 2)
+	assert.Equal(t, "https://example.com/b.png", photos[0].OriginalURL)
+	assert.Equal(t, 0, photos[0].Position)
+
+	assert.ErrorIs(t, repo.RemovePhoto(ctx, listing.ID, "https://example.com/a.jpg"), ErrNotFound)
+	assert.ErrorIs(t, repo.RemovePhoto(ctx, 999, "https://example.com/b.png"), ErrNotFound)
+	_, err = repo.ListPhotos(ctx, 999)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Callers can't change the stored photos through the returned slice
+	photos[0].OriginalURL = "changed"
+	photos, err = repo.ListPhotos(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/b.png", photos[0].OriginalURL)
+}
@@ -0,0 +1,53 @@
+package models
+
+import "github.com/pkg/errors"
+
+// PortfolioHolding is a listing in a portfolio with an optional investment weight
+type PortfolioHolding struct {
+	ListingID int64    `json:"id"`
+	Weight    *float64 `json:"weight,omitempty"`
+}
+
+// BlendedYieldRequest selects the listings to blend. Weights must be given for
+// every holding or none; without weights each holding counts equally.
+type BlendedYieldRequest struct {
+	Holdings []PortfolioHolding `json:"listings"`
+}
+
+// Validate checks the request has holdings and that any weights are usable
+func (r BlendedYieldRequest) Validate() error {
+	if len(r.Holdings) == 0 {
+		return errors.New("at least one listing is required")
+	}
+	weighted := r.Holdings[0].Weight != nil
+	var total float64
+	for _, holding := range r.Holdings {
+		if (holding.Weight != nil) != weighted {
+			return errors.New("weights must be given for all listings or none")
+		}
+		if holding.Weight == nil {
+			continue
+		}
+		if *holding.Weight < 0 {
+			return errors.Errorf("weight for listing %d must not be negative", holding.ListingID)
+		}
+		total += *holding.Weight
+	}
+	if weighted && total == 0 {
+		return errors.New("weights must not all be zero")
+	}
+	return nil
+}
+
+// HoldingYield is the gross yield and normalised weight of one holding
+type HoldingYield struct {
+	ListingID  int64   `json:"id"`
+	GrossYield float64 `json:"grossYield"`
+	Weight     float64 `json:"weight"`
+}
+
+// BlendedYield is the weighted average gross yield of a selection of listings
+type BlendedYield struct {
+	BlendedGrossYield float64        `json:"blendedGrossYield"`
+	Holdings          []HoldingYield `json:"listings"`
+}
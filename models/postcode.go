@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Postcode is a validated UK postcode split into its parts
+type Postcode struct {
+	// Full is the postcode in upper case with a single space, e.g. "M1 1AE"
+	Full string
+	// Outward is the part before the space, e.g. "M1", used as the shortened postcode
+	Outward string
+	// Area is the leading letters of the outward code, e.g. "M"
+	Area string
+}
+
+// ParsePostcode validates a UK postcode, ignoring case and spacing, and
+// splits it into its parts
+func ParsePostcode(raw string) (Postcode, error) {
+	compact := strings.ToUpper(strings.Join(strings.Fields(raw), ""))
+	if len(compact) < 5 || !ukPostcodePattern.MatchString(compact) {
+		return Postcode{}, errors.Errorf("invalid UK postcode %q", raw)
+	}
+	outward, inward := compact[:len(compact)-3], compact[len(compact)-3:]
+	return Postcode{
+		Full:    outward + " " + inward,
+		Outward: outward,
+		Area:    outward[:strings.IndexAny(outward, "0123456789")],
+	}, nil
+}
+
+// NormalizePostcode upper-cases and trims a UK postcode, puts a single space
+// before the inward code and returns an error if it isn't a valid postcode
+func NormalizePostcode(raw string) (string, error) {
+	postcode, err := ParsePostcode(raw)
+	if err != nil {
+		return "", err
+	}
+	return postcode.Full, nil
+}
+
+// NormalizePostcodes tidies the postcode fields before the address is stored.
+// A valid postcode is normalized and, when the shortened postcode is missing,
+// its outward code is used for it. A full postcode given as the shortened one
+// is cut down to its outward code. A malformed postcode is left as it is for
+// ValidateListing to report.
+func (a *AddressDetails) NormalizePostcodes() {
+	a.ShortenedPostcode = strings.ToUpper(strings.TrimSpace(a.ShortenedPostcode))
+	if postcode, err := ParsePostcode(a.ShortenedPostcode); err == nil {
+		a.ShortenedPostcode = postcode.Outward
+	}
+	if strings.TrimSpace(a.Postcode) == "" {
+		a.Postcode = ""
+		return
+	}
+	postcode, err := ParsePostcode(a.Postcode)
+	if err != nil {
+		return
+	}
+	a.Postcode = postcode.Full
+	if a.ShortenedPostcode == "" {
+		a.ShortenedPostcode = postcode.Outward
+	}
+}
+
+// PostcodeLookup describes a postcode for address-entry forms. City and
+// Region are best guesses from the postcode area and are empty when unknown.
+type PostcodeLookup struct {
+	Postcode          string `json:"postcode"`
+	ShortenedPostcode string `json:"shortenedPostcode"`
+	City              string `json:"city,omitempty"`
+	Region            Region `json:"region,omitempty"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationLockID serializes migrations when several instances start at once
+const migrationLockID = 7_017_001
+
+// MigratePostgres applies the embedded migrations that the database hasn't
+// seen yet, in file name order, recording each in schema_migrations. It is
+// safe to run on every start.
+func MigratePostgres(ctx context.Context, db *sql.DB) error {
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return errors.Wrap(err, "failed to list migrations")
+	}
+	sort.Strings(names)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin migration")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return errors.Wrap(err, "failed to lock migrations")
+	}
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return errors.Wrap(err, "failed to create schema_migrations")
+	}
+
+	for _, name := range names {
+		var applied bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, name,
+		).Scan(&applied); err != nil {
+			return errors.Wrapf(err, "failed to check migration %s", name)
+		}
+		if applied {
+			continue
+		}
+		script, err := migrations.ReadFile(name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read migration %s", name)
+		}
+		if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+			return errors.Wrapf(err, "failed to apply migration %s", name)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, name); err != nil {
+			return errors.Wrapf(err, "failed to record migration %s", name)
+		}
+	}
+	return errors.Wrap(tx.Commit(), "failed to commit migrations")
+}
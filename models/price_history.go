@@ -0,0 +1,66 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PriceChange records a listing being repriced
+type PriceChange struct {
+	ChangedAt       time.Time `json:"changedAt"`
+	OldPriceInCents int64     `json:"oldPriceInCents"`
+	NewPriceInCents int64     `json:"newPriceInCents"`
+}
+
+// GetPriceHistory returns the listing's price changes, oldest first, or an
+// empty slice if it has never been repriced
+func (r *ListingRepositoryImpl) GetPriceHistory(ctx context.Context, id int64) ([]PriceChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, exists := r.data[id]; !exists {
+		return nil, errors.Wrapf(ErrNotFound, "listing not found with id: %d", id)
+	}
+	history := make([]PriceChange, len(r.priceHistory[id]))
+	copy(history, r.priceHistory[id])
+	return history, nil
+}
+
+// GetPriceReduced returns the listings whose current price is below a price
+// they had before a change made at or after since. A zero since considers the
+// whole history.
+func (r *ListingRepositoryImpl) GetPriceReduced(ctx context.Context, since time.Time) ([]*Listing, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listings := make([]*Listing, 0)
+	for id, history := range r.priceHistory {
+		listing, exists := r.data[id]
+		if !exists {
+			continue
+		}
+		for _, change := range history {
+			if !change.ChangedAt.Before(since) && change.OldPriceInCents > listing.PriceInCents {
+				listings = append(listings, listing.clone())
+				break
+			}
+		}
+	}
+	return sortByID(listings), nil
+}
+
+// recordPriceChange appends to the listing's price history if its price
+// differs from existing. r.mu must be held.
+func (r *ListingRepositoryImpl) recordPriceChange(existing, listing *Listing) {
+	if existing.PriceInCents == listing.PriceInCents {
+		return
+	}
+	if r.priceHistory == nil {
+		r.priceHistory = make(map[int64][]PriceChange)
+	}
+	r.priceHistory[listing.ID] = append(r.priceHistory[listing.ID], PriceChange{
+		ChangedAt:       time.Now(),
+		OldPriceInCents: existing.PriceInCents,
+		NewPriceInCents: listing.PriceInCents,
+	})
+}
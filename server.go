@@ -2,43 +2,161 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"log/slog"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/getground/interview-backend-golang/handlers"
+	"github.com/getground/interview-backend-golang/internal/app/agent"
 	"github.com/getground/interview-backend-golang/internal/app/example"
+	"github.com/getground/interview-backend-golang/internal/app/favorites"
+	"github.com/getground/interview-backend-golang/internal/app/listing"
 	"github.com/getground/interview-backend-golang/internal/pkg/config"
+	"github.com/getground/interview-backend-golang/internal/pkg/httpclient"
+	"github.com/getground/interview-backend-golang/internal/pkg/middleware"
+	"github.com/getground/interview-backend-golang/internal/pkg/porter"
 	"github.com/getground/interview-backend-golang/models"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
 	"go.uber.org/fx"
 )
 
+// stopTimeoutMargin is the time fx allows for the other stop hooks on top of
+// the HTTP server's drain timeout
+const stopTimeoutMargin = 5 * time.Second
+
+// databaseStartTimeout bounds connecting to and migrating the database on start
+const databaseStartTimeout = 30 * time.Second
+
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	// Run stops the app on SIGINT or SIGTERM. The stop timeout must outlast
+	// the drain, or fx would give up on the server before it finished.
 	app := fx.New(
+		fx.Supply(cfg),
+		fx.StopTimeout(cfg.Server.ShutdownTimeout+stopTimeoutMargin),
 		fx.Provide(
-			config.Load,
+			httpclient.New,
+			middleware.NewDrainSwitch,
 			models.NewExampleRepository,
 			example.NewService,
+			handlers.NewHealthHandler,
 			handlers.NewExampleHandler,
+			newListingRepository,
+			models.NewListingChangeLog,
+			models.NewAgentRepository,
+			agent.NewService,
+			handlers.NewAgentHandler,
+			newGeocoder,
+			listing.NewService,
+			handlers.NewListingHandler,
+			handlers.NewAdminHandler,
+			porter.New,
+			handlers.NewBackupHandler,
+			handlers.NewPortfolioHandler,
+			models.NewFavoritesStore,
+			favorites.NewService,
+			handlers.NewFavoritesHandler,
 			newRouter,
 			newHTTPServer,
 		),
-		fx.Invoke(startServer),
+		fx.Decorate(decorateListingRepository),
+		fx.Invoke(configureListingDisplay, startServer),
 	)
 	app.Run()
 }
 
+// newListingRepository stores listings in memory or, with the postgres
+// driver, in the database at repository.dsn after migrating it
+func newListingRepository(lc fx.Lifecycle, cfg *config.Config) (models.ListingRepository, error) {
+	switch cfg.Repository.Driver {
+	case config.RepositoryDriverMemory:
+		return models.NewListingRepository(), nil
+	case config.RepositoryDriverPostgres:
+		db, err := sql.Open("postgres", cfg.Repository.DSN)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open database")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), databaseStartTimeout)
+		defer cancel()
+		if err := db.PingContext(ctx); err != nil {
+			db.Close()
+			return nil, errors.Wrap(err, "failed to connect to database")
+		}
+		if err := models.MigratePostgres(ctx, db); err != nil {
+			db.Close()
+			return nil, errors.Wrap(err, "failed to migrate database")
+		}
+		lc.Append(fx.Hook{
+			OnStop: func(context.Context) error {
+				return db.Close()
+			},
+		})
+		return models.NewPostgresListingRepository(db), nil
+	default:
+		return nil, errors.Errorf("unknown repository driver %q", cfg.Repository.Driver)
+	}
+}
+
+// newGeocoder calls the lookup service at listing.geocoder_url through the
+// shared outbound client, or uses the built-in city centres when it is unset
+func newGeocoder(cfg *config.Config, client *http.Client) listing.Geocoder {
+	if cfg.Listing.GeocoderURL == "" {
+		return listing.NewDefaultGeocoder()
+	}
+	return listing.NewHTTPGeocoder(client, cfg.Listing.GeocoderURL)
+}
+
+// decorateListingRepository adds change recording, read coalescing and slow
+// logging. Slow logging is outermost so each caller of a coalesced read is
+// timed and logged with its own request ID.
+func decorateListingRepository(
+	cfg *config.Config,
+	changes *models.ListingChangeLog,
+	repo models.ListingRepository,
+) models.ListingRepository {
+	repo = models.NewChangeRecordingListingRepository(repo, changes)
+	repo = models.NewCoalescingListingRepository(repo)
+	return models.NewSlowLoggingListingRepository(repo, cfg.Repository.SlowThreshold, slog.Default())
+}
+
+func configureListingDisplay(cfg *config.Config) {
+	models.SetGrossYieldPercentDecimals(cfg.Listing.GrossYieldDecimals)
+}
+
 func newRouter(
+	cfg *config.Config,
+	healthHandler *handlers.HealthHandler,
 	exampleHandler *handlers.ExampleHandler,
+	listingHandler *handlers.ListingHandler,
+	adminHandler *handlers.AdminHandler,
+	backupHandler *handlers.BackupHandler,
+	portfolioHandler *handlers.PortfolioHandler,
+	favoritesHandler *handlers.FavoritesHandler,
+	agentHandler *handlers.AgentHandler,
+	drain *middleware.DrainSwitch,
 ) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.AgentIdentity())
+	router.Use(middleware.RejectWhileDraining(drain))
+	router.Use(middleware.MaxBodyBytes(cfg.Server.MaxBodyBytes))
+	router.Use(middleware.DisabledQuery(cfg.Server.DisabledQueryParams, cfg.Server.DisabledSortFields))
 	router.Use(cors.Default())
+	router.Use(middleware.Gzip(cfg.Server.GzipMinBytes))
 
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
+	router.GET("/health", healthHandler.Health)
 
 	api := router.Group("/api/v1")
 	{
@@ -46,10 +164,88 @@ func newRouter(
 		{
 			examples.POST("/", exampleHandler.CreateExample)
 			examples.GET("/", exampleHandler.GetAllExamples)
+			examples.GET("/by-email", exampleHandler.GetExampleByEmail)
 			examples.GET("/:id", exampleHandler.GetExampleByID)
 			examples.PUT("/:id", exampleHandler.UpdateExample)
 			examples.DELETE("/:id", exampleHandler.DeleteExample)
 		}
+
+		listings := api.Group("/listings", middleware.MaxQueryParams(cfg.Server.MaxQueryParams))
+		{
+			listings.GET("/", listingHandler.GetAllListings)
+			listings.POST("/", listingHandler.CreateListing)
+			listings.POST("/import", listingHandler.ImportListings)
+			listings.POST("/bulk-delete", listingHandler.DeleteListings)
+			listings.GET("/bbox", listingHandler.GetListingsInBoundingBox)
+			listings.GET("/by-city", listingHandler.SearchByCity)
+			listings.GET("/near-city", listingHandler.GetListingsNearCity)
+			listings.GET("/search", listingHandler.SearchListings)
+			listings.GET("/export.csv", listingHandler.ExportListingsCSV)
+			listings.GET("/filter-schema", listingHandler.GetFilterSchema)
+			listings.GET("/velocity", listingHandler.GetVelocityByRegion)
+			listings.GET("/created-over-time", listingHandler.GetCreatedOverTime)
+			listings.GET("/cheapest-by-region", listingHandler.GetCheapestByRegion)
+			listings.GET("/deposit-demand", listingHandler.GetDepositDemand)
+			listings.GET("/city-groups", listingHandler.GetCityGroups)
+			listings.GET("/count-by-type", listingHandler.CountByPropertyType)
+			listings.GET("/price-bands", listingHandler.GetPriceBands)
+			listings.GET("/yield-histogram", listingHandler.GetYieldHistogram)
+			listings.GET("/median-price", listingHandler.GetMedianPrice)
+			listings.GET("/ppsf-by-region", listingHandler.GetPricePerSqFtByRegion)
+			listings.GET("/pivot", listingHandler.GetPivot)
+			listings.POST("/multi-stats", listingHandler.GetMultiStats)
+			listings.GET("/changes", listingHandler.GetChanges)
+			listings.GET("/deleted", listingHandler.GetDeletedListings)
+			listings.GET("/:id", listingHandler.GetListingByID)
+			listings.PUT("/:id", listingHandler.UpsertListing)
+			listings.PATCH("/:id", listingHandler.UpdateListing)
+			listings.DELETE("/:id", listingHandler.DeleteListing)
+			listings.POST("/:id/publish", listingHandler.PublishListing)
+			listings.POST("/:id/restore", listingHandler.RestoreListing)
+			listings.GET("/:id/neighbours", listingHandler.GetNeighbours)
+			listings.GET("/:id/nearby", listingHandler.GetNearby)
+			listings.GET("/:id/export.json", listingHandler.ExportListing)
+			listings.GET("/:id/photos", listingHandler.GetListingPhotos)
+			listings.POST("/:id/photos", listingHandler.AddListingPhoto)
+			listings.DELETE("/:id/photos", listingHandler.RemoveListingPhoto)
+			listings.GET("/:id/price-history", listingHandler.GetPriceHistory)
+			listings.DELETE("/:id/photos/:photoId", listingHandler.DeleteListingPhoto)
+			listings.POST("/:id/tags", listingHandler.AddTags)
+			listings.DELETE("/:id/tags/:tag", listingHandler.RemoveTag)
+		}
+
+		api.GET("/postcode/:code", listingHandler.LookupPostcode)
+
+		agents := api.Group("/agents")
+		{
+			agents.POST("/", agentHandler.CreateAgent)
+			agents.GET("/:id", agentHandler.GetAgentByID)
+			agents.GET("/:id/listings", listingHandler.GetAgentListings)
+			agents.GET("/:id/listings/issues", listingHandler.GetAgentListingIssues)
+		}
+
+		portfolio := api.Group("/portfolio")
+		{
+			portfolio.POST("/blended-yield", portfolioHandler.GetBlendedYield)
+		}
+
+		favorites := api.Group("/favorites", middleware.UserIdentity())
+		{
+			favorites.GET("/", favoritesHandler.ListFavorites)
+			favorites.POST("/:listingId", favoritesHandler.AddFavorite)
+			favorites.DELETE("/:listingId", favoritesHandler.RemoveFavorite)
+		}
+
+		if cfg.Admin.Enabled {
+			admin := api.Group("/admin")
+			{
+				admin.POST("/recompute", adminHandler.Recompute)
+				admin.GET("/deposit-anomalies", adminHandler.GetDepositAnomalies)
+				admin.POST("/purge-deleted", adminHandler.PurgeDeleted)
+				admin.GET("/export.json", backupHandler.ExportListings)
+				admin.POST("/import", backupHandler.ImportListings)
+			}
+		}
 	}
 	return router
 }
@@ -63,12 +259,15 @@ func newHTTPServer(
 		Handler:      router,
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 }
 
 func startServer(
 	lifecycle fx.Lifecycle,
+	cfg *config.Config,
 	server *http.Server,
+	drain *middleware.DrainSwitch,
 ) {
 	lifecycle.Append(fx.Hook{
 		OnStart: func(context.Context) error {
@@ -80,7 +279,19 @@ func startServer(
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			return server.Shutdown(ctx)
+			// Turn away requests arriving on kept-alive connections while the
+			// in-flight ones finish
+			drain.StartDraining()
+			timeout := cfg.Server.ShutdownTimeout
+			slog.Info("shutting down HTTP server", "drainTimeoutSeconds", timeout.Seconds())
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			if err := server.Shutdown(ctx); err != nil {
+				slog.Warn("HTTP server did not shut down cleanly, in-flight requests were cut off", "error", err)
+				return err
+			}
+			slog.Info("HTTP server shut down cleanly")
+			return nil
 		},
 	})
 }